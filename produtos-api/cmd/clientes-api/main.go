@@ -0,0 +1,44 @@
+// Command clientes-api sobe o microsserviço de clientes, com endereços, consentimentos e os direitos do titular dos dados (LGPD).
+//
+//	AUTH_HMAC_SECRET=dev-secret go run ./cmd/clientes-api
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+
+	"produtos-api/src/config"
+	"produtos-api/src/database"
+	"produtos-api/src/logging"
+	"produtos-api/src/routes"
+	"produtos-api/src/server"
+	"produtos-api/src/services"
+	"produtos-api/src/tracing"
+)
+
+func main() {
+	logger := logging.Setup(os.Stdout)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "clientes-api")
+	if err != nil {
+		logger.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Banco próprio do microsserviço (customers.sqlite), com as mesmas regras de ambiente da API de produtos
+	db, err := database.SetupDatabase(database.Customers)
+	if err != nil {
+		logger.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+
+	setup := func(ctx context.Context, healthService services.HealthService, workers *sync.WaitGroup) http.Handler {
+		return routes.SetupCustomerRoutes(ctx, db, healthService, workers)
+	}
+	if err := server.Run(config.GetString("HTTP_ADDR", ":8082"), setup, shutdownTracing); err != nil {
+		logger.Error("Server failed", "error", err)
+		os.Exit(1)
+	}
+}
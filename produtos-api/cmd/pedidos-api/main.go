@@ -0,0 +1,44 @@
+// Command pedidos-api sobe o microsserviço de pedidos, que faz o checkout reservando o estoque na API de produtos.
+//
+//	AUTH_HMAC_SECRET=dev-secret PRODUCTS_API_KEY=pk_... go run ./cmd/pedidos-api
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+
+	"produtos-api/src/config"
+	"produtos-api/src/database"
+	"produtos-api/src/logging"
+	"produtos-api/src/routes"
+	"produtos-api/src/server"
+	"produtos-api/src/services"
+	"produtos-api/src/tracing"
+)
+
+func main() {
+	logger := logging.Setup(os.Stdout)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "pedidos-api")
+	if err != nil {
+		logger.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Banco próprio do microsserviço (orders.sqlite), com as mesmas regras de ambiente da API de produtos
+	db, err := database.SetupDatabase(database.Orders)
+	if err != nil {
+		logger.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+
+	setup := func(ctx context.Context, healthService services.HealthService, workers *sync.WaitGroup) http.Handler {
+		return routes.SetupOrderRoutes(ctx, db, healthService, workers)
+	}
+	if err := server.Run(config.GetString("HTTP_ADDR", ":8081"), setup, shutdownTracing); err != nil {
+		logger.Error("Server failed", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"produtos-api/src/controllers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// O cliente gerado deve acompanhar os métodos do servidor; rode go generate ./src/productsrpc após alterá-los
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	source, err := generate("productsrpc", controllers.ProductRPCMethods(nil))
+	require.NoError(t, err)
+
+	current, err := os.ReadFile("../../src/productsrpc/client_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(source), string(current))
+}
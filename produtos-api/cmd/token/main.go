@@ -0,0 +1,48 @@
+// Command token gera tokens JWT assinados com HMAC para desenvolvimento e testes locais.
+//
+//	AUTH_HMAC_SECRET=dev-secret go run ./cmd/token -sub maria -roles editor -ttl 1h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/config"
+)
+
+func main() {
+	secret := flag.String("secret", config.GetString("AUTH_HMAC_SECRET", ""), "segredo HMAC (padrão: AUTH_HMAC_SECRET)")
+	subject := flag.String("sub", "dev", "subject (sub) do token")
+	roles := flag.String("roles", string(auth.RoleViewer), "papéis separados por vírgula: viewer, editor, admin")
+	ttl := flag.Duration("ttl", time.Hour, "validade do token")
+	issuer := flag.String("iss", config.GetString("AUTH_ISSUER", ""), "emissor (padrão: AUTH_ISSUER)")
+	audience := flag.String("aud", config.GetString("AUTH_AUDIENCE", ""), "audiência (padrão: AUTH_AUDIENCE)")
+	flag.Parse()
+
+	if *secret == "" {
+		fmt.Fprintln(os.Stderr, "informe -secret ou defina AUTH_HMAC_SECRET")
+		os.Exit(2)
+	}
+
+	var tokenRoles []auth.Role
+	for _, name := range strings.Split(*roles, ",") {
+		role := auth.Role(strings.TrimSpace(name))
+		if !role.Valid() {
+			fmt.Fprintf(os.Stderr, "papel desconhecido: %q\n", name)
+			os.Exit(2)
+		}
+		tokenRoles = append(tokenRoles, role)
+	}
+
+	token, err := auth.Mint([]byte(*secret), *subject, tokenRoles, *ttl, *issuer, *audience)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "erro ao gerar o token: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(token)
+}
@@ -0,0 +1,44 @@
+CREATE TABLE customers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    document TEXT NOT NULL,
+    document_type TEXT NOT NULL,
+    email TEXT,
+    phone TEXT,
+    subject TEXT,
+    marketing_consent NUMERIC NOT NULL DEFAULT false,
+    data_sharing_consent NUMERIC NOT NULL DEFAULT false,
+    consent_updated_at DATETIME,
+    erased_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_customers_subject ON customers (subject);
+-- O documento é único por titular, para que o cadastro feito por outra pessoa não bloqueie o titular nem revele
+-- quais documentos existem. Clientes eliminados liberam o documento para um novo cadastro.
+CREATE UNIQUE INDEX idx_customers_subject_document ON customers (subject, document) WHERE erased_at IS NULL;
+CREATE TABLE customer_addresses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id INTEGER NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    label TEXT,
+    street TEXT NOT NULL,
+    number TEXT NOT NULL,
+    complement TEXT,
+    district TEXT,
+    city TEXT NOT NULL,
+    state TEXT NOT NULL,
+    postal_code TEXT NOT NULL,
+    is_default NUMERIC NOT NULL DEFAULT false,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_customer_addresses_customer_id ON customer_addresses (customer_id);
+CREATE TABLE consent_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id INTEGER NOT NULL,
+    purpose TEXT NOT NULL,
+    granted NUMERIC NOT NULL,
+    recorded_by TEXT,
+    created_at DATETIME
+);
+CREATE INDEX idx_consent_records_customer_id ON consent_records (customer_id);
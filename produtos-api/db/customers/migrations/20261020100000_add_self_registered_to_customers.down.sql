@@ -0,0 +1,2 @@
+DROP INDEX idx_customers_self_registered_subject;
+ALTER TABLE customers DROP COLUMN self_registered;
@@ -0,0 +1,4 @@
+ALTER TABLE customers ADD COLUMN self_registered NUMERIC NOT NULL DEFAULT false;
+-- Quem cadastra a si mesmo tem um único cliente; os cadastros feitos por editores não entram no índice.
+-- Os clientes existentes ficam fora dele e continuam cobertos pela verificação do CustomerService.
+CREATE UNIQUE INDEX idx_customers_self_registered_subject ON customers (subject) WHERE erased_at IS NULL AND self_registered;
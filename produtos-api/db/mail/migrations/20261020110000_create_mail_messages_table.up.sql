@@ -0,0 +1,22 @@
+-- Fila de e-mails das notificações, compartilhada pelos bancos de produtos e de pedidos. Cada banco registra
+-- as versões destas migrações na tabela mail_schema_migrations. As primeiras migrações de cada banco já criavam
+-- a tabela, por isso esta não falha quando ela existe; as próximas mudanças da fila ficam apenas aqui.
+CREATE TABLE IF NOT EXISTS mail_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    template TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    "to" TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text TEXT NOT NULL,
+    html TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_attempt_at DATETIME,
+    last_error TEXT,
+    sent_at DATETIME,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mail_messages_key ON mail_messages (key);
+CREATE INDEX IF NOT EXISTS idx_mail_messages_status ON mail_messages (status);
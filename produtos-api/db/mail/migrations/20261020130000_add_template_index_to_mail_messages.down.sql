@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_mail_messages_template_created_at;
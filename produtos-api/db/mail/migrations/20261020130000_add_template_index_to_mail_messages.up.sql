@@ -0,0 +1,2 @@
+-- Os avisos de volta ao estoque contam os e-mails recentes do destinatário para aplicar o limite por destinatário
+CREATE INDEX IF NOT EXISTS idx_mail_messages_template_created_at ON mail_messages (template, created_at);
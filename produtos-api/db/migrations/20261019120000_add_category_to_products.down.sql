@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_category_name;
+ALTER TABLE products DROP COLUMN category;
@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN category TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_products_category_name ON products (category, name);
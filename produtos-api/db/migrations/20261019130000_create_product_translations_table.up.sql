@@ -0,0 +1,8 @@
+CREATE TABLE product_translations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT
+);
+CREATE UNIQUE INDEX idx_product_translations_product_locale ON product_translations (product_id, locale);
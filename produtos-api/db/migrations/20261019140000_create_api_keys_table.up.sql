@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    rotated_at DATETIME,
+    revoked_at DATETIME,
+    created_by TEXT,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_api_keys_prefix ON api_keys (prefix);
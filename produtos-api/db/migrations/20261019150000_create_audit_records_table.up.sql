@@ -0,0 +1,16 @@
+CREATE TABLE audit_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    changes TEXT,
+    occurred_at DATETIME NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL
+);
+CREATE INDEX idx_audit_records_entity ON audit_records (entity_type, entity_id);
+CREATE INDEX idx_audit_records_actor ON audit_records (actor);
+CREATE INDEX idx_audit_records_occurred_at ON audit_records (occurred_at);
+CREATE UNIQUE INDEX idx_audit_records_prev_hash ON audit_records (prev_hash);
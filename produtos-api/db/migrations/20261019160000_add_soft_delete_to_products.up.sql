@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN deleted_at DATETIME;
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
@@ -0,0 +1,18 @@
+CREATE TABLE product_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    name TEXT,
+    description TEXT,
+    category TEXT,
+    price REAL,
+    stock INTEGER,
+    deleted NUMERIC,
+    created_at DATETIME,
+    valid_from DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX idx_product_revisions_product_revision ON product_revisions (product_id, revision);
+-- Os produtos existentes começam o histórico com o estado atual
+INSERT INTO product_revisions (product_id, revision, name, description, category, price, stock, deleted, created_at, valid_from)
+SELECT id, 1, name, description, category, price, stock, deleted_at IS NOT NULL, created_at, COALESCE(deleted_at, updated_at, created_at, CURRENT_TIMESTAMP)
+FROM products;
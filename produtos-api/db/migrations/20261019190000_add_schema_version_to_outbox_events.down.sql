@@ -0,0 +1,2 @@
+ALTER TABLE outbox_events DROP COLUMN trace_context;
+ALTER TABLE outbox_events DROP COLUMN schema_version;
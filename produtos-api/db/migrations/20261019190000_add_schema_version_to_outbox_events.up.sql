@@ -0,0 +1,2 @@
+ALTER TABLE outbox_events ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE outbox_events ADD COLUMN trace_context TEXT;
@@ -0,0 +1,11 @@
+CREATE TABLE stock_reservations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX idx_stock_reservations_order_product ON stock_reservations (order_id, product_id);
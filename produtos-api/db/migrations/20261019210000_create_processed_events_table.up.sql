@@ -0,0 +1,5 @@
+CREATE TABLE processed_events (
+    event_id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    processed_at DATETIME NOT NULL
+);
@@ -0,0 +1,31 @@
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    event_types TEXT,
+    secret TEXT NOT NULL,
+    active NUMERIC NOT NULL,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at DATETIME,
+    disabled_reason TEXT,
+    created_by TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_attempt_at DATETIME,
+    response_status INTEGER,
+    last_error TEXT,
+    delivered_at DATETIME,
+    replayed_at DATETIME,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_webhook_deliveries_webhook_event ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries (status);
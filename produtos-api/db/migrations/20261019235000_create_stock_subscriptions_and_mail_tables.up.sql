@@ -0,0 +1,32 @@
+CREATE TABLE stock_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    customer_id INTEGER NOT NULL DEFAULT 0,
+    locale TEXT,
+    token_hash TEXT NOT NULL,
+    created_by TEXT,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_stock_subscriptions_product_email ON stock_subscriptions (product_id, email) WHERE email <> '';
+CREATE UNIQUE INDEX idx_stock_subscriptions_product_customer ON stock_subscriptions (product_id, customer_id) WHERE customer_id <> 0;
+CREATE UNIQUE INDEX idx_stock_subscriptions_token_hash ON stock_subscriptions (token_hash);
+CREATE TABLE mail_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    template TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    "to" TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text TEXT NOT NULL,
+    html TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_attempt_at DATETIME,
+    last_error TEXT,
+    sent_at DATETIME,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_mail_messages_key ON mail_messages (key);
+CREATE INDEX idx_mail_messages_status ON mail_messages (status);
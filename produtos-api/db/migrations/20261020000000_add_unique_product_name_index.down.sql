@@ -0,0 +1 @@
+DROP INDEX idx_products_category_name_unique;
@@ -0,0 +1,7 @@
+UPDATE products SET name = name || ' (' || id || ')'
+WHERE deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM products AS other
+    WHERE other.deleted_at IS NULL AND other.category = products.category
+        AND LOWER(other.name) = LOWER(products.name) AND other.id < products.id
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_category_name_unique ON products (category, LOWER(name)) WHERE deleted_at IS NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE stock_subscription_recipients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL DEFAULT '',
+    customer_id INTEGER NOT NULL DEFAULT 0,
+    token_hash TEXT NOT NULL,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX idx_stock_subscription_recipients_recipient ON stock_subscription_recipients (email, customer_id);
+CREATE UNIQUE INDEX idx_stock_subscription_recipients_token_hash ON stock_subscription_recipients (token_hash);
@@ -0,0 +1,2 @@
+DROP INDEX idx_stock_subscriptions_notify_at;
+ALTER TABLE stock_subscriptions DROP COLUMN notify_at;
@@ -0,0 +1,2 @@
+ALTER TABLE stock_subscriptions ADD COLUMN notify_at DATETIME;
+CREATE INDEX idx_stock_subscriptions_notify_at ON stock_subscriptions (notify_at);
@@ -0,0 +1,27 @@
+CREATE TABLE orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id INTEGER,
+    email TEXT,
+    status TEXT NOT NULL,
+    total REAL NOT NULL,
+    cancel_reason TEXT,
+    stock_reserved NUMERIC NOT NULL DEFAULT false,
+    stock_released NUMERIC NOT NULL DEFAULT false,
+    created_by TEXT,
+    paid_at DATETIME,
+    shipped_at DATETIME,
+    cancelled_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_orders_status ON orders (status);
+CREATE INDEX idx_orders_updated_at ON orders (updated_at);
+CREATE TABLE order_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    unit_price REAL NOT NULL,
+    quantity INTEGER NOT NULL
+);
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mail_messages;
+DROP TABLE IF EXISTS outbox_events;
+ALTER TABLE orders DROP COLUMN locale;
@@ -0,0 +1,1056 @@
+// Package clientes Code generated by swaggo/swag. DO NOT EDIT
+package clientes
+
+import "github.com/swaggo/swag"
+
+const docTemplateclientes = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/customers": {
+            "get": {
+                "description": "Lista os clientes pela ordem de cadastro, com os endereços",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Lista os clientes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CPF ou CNPJ, com ou sem pontuação",
+                        "name": "document",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email do cliente",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de clientes (padrão 50, máximo 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Customer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cadastra o cliente com CPF ou CNPJ (com ou sem pontuação), validando os dígitos verificadores. Quem cadastra é o titular dos dados e pode consultar, corrigir, exportar e eliminar o cadastro; cada titular cadastra um cliente, exceto editores. O documento é único por titular. Os consentimentos começam negados",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Cadastra um cliente",
+                "parameters": [
+                    {
+                        "description": "Name, document, email and phone",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}": {
+            "get": {
+                "description": "Busca o cliente e os seus endereços. Clientes de outros titulares respondem 404, exceto para editores",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Busca um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Substitui o nome, o documento, o email e o telefone do cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Corrige os dados de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name, document, email and phone",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Apaga os endereços e anonimiza o cadastro, mantendo apenas o ID referenciado pelos pedidos e o histórico de consentimentos, prova das concessões e revogações. Depois da eliminação o cliente responde 404 e o documento pode ser cadastrado de novo",
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Elimina os dados pessoais de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/addresses": {
+            "post": {
+                "description": "Cadastra o endereço com UF e CEP (com ou sem pontuação). O primeiro endereço, ou o enviado com default, passa a ser o padrão. Cada cliente tem até 20 endereços",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Cadastra um endereço do cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Address",
+                        "name": "address",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerAddress"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/addresses/{address}": {
+            "put": {
+                "description": "Substitui os dados do endereço. Com default, ele passa a ser o padrão; o padrão atual só deixa de ser quando outro é marcado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Atualiza um endereço do cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do endereço",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Address",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerAddress"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove o endereço; se ele era o padrão, o endereço mais antigo restante passa a ser",
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Remove um endereço do cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do endereço",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/consents": {
+            "put": {
+                "description": "Atualiza os consentimentos informados; os omitidos não mudam. Cada mudança é registrada no histórico, exportado com os dados do cliente. Responde 409 se os consentimentos mudarem ao mesmo tempo por outra requisição",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Concede ou revoga consentimentos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "marketing_consent and data_sharing_consent",
+                        "name": "consents",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/export": {
+            "get": {
+                "description": "Entrega ao titular uma cópia dos seus dados: o cadastro, os endereços e o histórico de consentimentos, como anexo JSON",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Exporta os dados pessoais de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se o processo está vivo",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Verifica banco de dados, migrações pendentes e workers, retornando a latência de cada dependência",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se a aplicação está pronta para receber tráfego",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.Problem": {
+            "description": "Error response in the RFC 7807 (application/problem+json) format",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable error code",
+                    "type": "string",
+                    "example": "product_not_found"
+                },
+                "detail": {
+                    "description": "Occurrence-specific explanation",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Field-level validation details",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Request path",
+                    "type": "string",
+                    "example": "/products/42"
+                },
+                "request_id": {
+                    "description": "X-Request-ID of the request",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Short, human-readable summary",
+                    "type": "string",
+                    "example": "Product not found"
+                },
+                "type": {
+                    "description": "Problem type URI",
+                    "type": "string",
+                    "example": "urn:produtos-api:problem:product_not_found"
+                }
+            }
+        },
+        "models.AddressRequest": {
+            "description": "Customer address",
+            "type": "object",
+            "required": [
+                "postal_code",
+                "state"
+            ],
+            "properties": {
+                "city": {
+                    "description": "City",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "complement": {
+                    "description": "Complement",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "default": {
+                    "description": "Whether it becomes the default address",
+                    "type": "boolean"
+                },
+                "district": {
+                    "description": "District (bairro)",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "label": {
+                    "description": "Name of the address, ex: home, office",
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "number": {
+                    "description": "Number",
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "postal_code": {
+                    "description": "CEP",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State (UF)",
+                    "type": "string"
+                },
+                "street": {
+                    "description": "Street",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.ConsentRecord": {
+            "description": "Grant or withdrawal of a consent",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "When the consent changed",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "Customer ID",
+                    "type": "integer"
+                },
+                "granted": {
+                    "description": "Whether the consent was granted or withdrawn",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Record ID",
+                    "type": "integer"
+                },
+                "purpose": {
+                    "description": "marketing or data_sharing",
+                    "type": "string"
+                },
+                "recorded_by": {
+                    "description": "Subject that changed the consent",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConsentRequest": {
+            "description": "Consents of the customer",
+            "type": "object",
+            "properties": {
+                "data_sharing_consent": {
+                    "description": "Consent to share data with partners",
+                    "type": "boolean"
+                },
+                "marketing_consent": {
+                    "description": "Consent to receive marketing communications",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Customer": {
+            "description": "Customer, its addresses and consents",
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "description": "Customer addresses",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CustomerAddress"
+                    }
+                },
+                "consent_updated_at": {
+                    "description": "Last change of a consent",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Registration time",
+                    "type": "string"
+                },
+                "data_sharing_consent": {
+                    "description": "Consent to share data with partners",
+                    "type": "boolean"
+                },
+                "document": {
+                    "description": "CPF or CNPJ digits",
+                    "type": "string"
+                },
+                "document_type": {
+                    "description": "cpf or cnpj",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Contact email",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Customer ID",
+                    "type": "integer"
+                },
+                "marketing_consent": {
+                    "description": "Consent to receive marketing communications",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Full name or company name",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "Contact phone",
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "Subject (JWT sub) of the data subject",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Last update",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerAddress": {
+            "description": "Customer address",
+            "type": "object",
+            "properties": {
+                "city": {
+                    "description": "City",
+                    "type": "string"
+                },
+                "complement": {
+                    "description": "Complement",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "Customer ID",
+                    "type": "integer"
+                },
+                "default": {
+                    "description": "Whether it is the default address",
+                    "type": "boolean"
+                },
+                "district": {
+                    "description": "District (bairro)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Address ID",
+                    "type": "integer"
+                },
+                "label": {
+                    "description": "Name of the address, ex: home, office",
+                    "type": "string"
+                },
+                "number": {
+                    "description": "Number",
+                    "type": "string"
+                },
+                "postal_code": {
+                    "description": "CEP digits",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State (UF)",
+                    "type": "string"
+                },
+                "street": {
+                    "description": "Street",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Last update",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerExport": {
+            "description": "Personal data of the customer",
+            "type": "object",
+            "properties": {
+                "consents": {
+                    "description": "Consent history",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConsentRecord"
+                    }
+                },
+                "customer": {
+                    "description": "Customer and addresses",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    ]
+                },
+                "exported_at": {
+                    "description": "Export time",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerRequest": {
+            "description": "Customer data",
+            "type": "object",
+            "required": [
+                "document"
+            ],
+            "properties": {
+                "document": {
+                    "description": "CPF or CNPJ",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Contact email",
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "name": {
+                    "description": "Full name or company name",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "phone": {
+                    "description": "Contact phone",
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "services.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.HealthReport": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/services.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfoclientes holds exported Swagger Info so clients can modify it
+var SwaggerInfoclientes = &swag.Spec{
+	Version:          "",
+	Host:             "",
+	BasePath:         "",
+	Schemes:          []string{},
+	Title:            "",
+	Description:      "",
+	InfoInstanceName: "clientes",
+	SwaggerTemplate:  docTemplateclientes,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfoclientes.InstanceName(), SwaggerInfoclientes)
+}
@@ -15,53 +15,48 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products": {
+        "/api-keys": {
             "get": {
-                "description": "Retorna todos os produtos",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lista as chaves de API com escopos, expiração e último uso; as chaves em si não são retornadas",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
-                ],
-                "summary": "Retorna todos os produtos",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Nome do produto",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Contagem de produtos",
-                        "name": "count",
-                        "in": "query"
-                    }
+                    "chaves de API"
                 ],
+                "summary": "Lista as chaves de API",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Product"
+                                "$ref": "#/definitions/models.APIKey"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Cria um novo produto",
+                "description": "Cria uma chave de API para um cliente de máquina. A chave só é exibida nesta resposta; envie-a no header X-API-Key",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,17 +64,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "chaves de API"
                 ],
-                "summary": "Cria um novo produto",
+                "summary": "Cria uma chave de API",
                 "parameters": [
                     {
-                        "description": "Product data",
-                        "name": "product",
+                        "description": "Name, scopes and optional expires_at",
+                        "name": "apiKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     }
                 ],
@@ -87,41 +82,106 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.IssuedAPIKey"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
-            "get": {
-                "description": "Retorna um produto pelo ID",
-                "consumes": [
+        "/api-keys/{id}": {
+            "delete": {
+                "description": "Revoga a chave; o cadastro é mantido para consulta",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "chaves de API"
+                ],
+                "summary": "Revoga uma chave de API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da chave de API",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/{id}/rotate": {
+            "post": {
+                "description": "Substitui a chave mantendo nome e escopos; a chave anterior deixa de ser aceita imediatamente",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "chaves de API"
                 ],
-                "summary": "Retorna um produto pelo ID",
+                "summary": "Gera uma nova chave de API",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID do produto",
+                        "description": "ID da chave de API",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -131,76 +191,203 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.IssuedAPIKey"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza um produto",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Lista as mudanças do catálogo em ordem cronológica, com ator, request ID e diff por campo. Use after_id com o último ID recebido para paginar",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "auditoria"
                 ],
-                "summary": "Atualiza um produto",
+                "summary": "Consulta o registro de auditoria",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tipo da entidade, ex: product",
+                        "name": "entity",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "ID do produto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ID da entidade",
+                        "name": "entity_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Product data",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Product"
-                        }
+                        "type": "string",
+                        "description": "Ator da mudança",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do intervalo (RFC 3339), inclusivo",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fim do intervalo (RFC 3339), exclusivo",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Retorna registros com ID maior que o informado",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de registros (padrão 100, máximo 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditRecord"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deleta um produto",
+            }
+        },
+        "/audit/verify": {
+            "get": {
+                "description": "Recalcula a cadeia de hashes e informa o primeiro registro alterado ou removido",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auditoria"
+                ],
+                "summary": "Verifica a integridade do registro de auditoria",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audit.Verification"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se o processo está vivo",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retorna todos os produtos",
                 "consumes": [
                     "application/json"
                 ],
@@ -210,49 +397,2405 @@ const docTemplate = `{
                 "tags": [
                     "produtos"
                 ],
-                "summary": "Deleta um produto",
+                "summary": "Retorna todos os produtos",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do produto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Nome do produto",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contagem de produtos",
+                        "name": "count",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Product"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.Product": {
-            "description": "A product model",
+            },
+            "post": {
+                "description": "Cria um novo produto",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Cria um novo produto",
+                "parameters": [
+                    {
+                        "description": "Product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/stream": {
+            "get": {
+                "description": "Envia as mudanças dos produtos à medida que acontecem, como eventos SSE com o tipo da mudança em event e a posição no stream em id. Ao reconectar, o EventSource envia o header Last-Event-ID e o stream continua da última posição recebida; se ela não estiver mais disponível, o evento reset indica que os produtos devem ser recarregados",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Acompanha as mudanças do catálogo por Server-Sent Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IDs dos produtos, separados por vírgula",
+                        "name": "product_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categoria dos produtos",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Retoma o stream após a posição informada",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alternativa ao header Last-Event-ID",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ticket de POST /products/stream/tickets, para navegadores",
+                        "name": "ticket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/stream/tickets": {
+            "post": {
+                "description": "Emite um ticket de curta duração (STREAM_TICKET_TTL) com as credenciais desta requisição. Navegadores não enviam headers em EventSource e WebSocket: abra /products/stream ou /products/stream/ws com o ticket no parâmetro ticket antes de expires_at. O ticket não é renovado nas reconexões automáticas; ao expirar, emita outro e reconecte com last_event_id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Emite um ticket para os streams de mudanças",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StreamTicket"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/stream/ws": {
+            "get": {
+                "description": "Equivalente a /products/stream por WebSocket: cada mudança é uma mensagem de texto com o JSON de models.ProductChange. Para retomar, informe em last_event_id o campo position da última mudança recebida; uma mensagem {\"type\":\"reset\"} indica que os produtos devem ser recarregados",
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Acompanha as mudanças do catálogo por WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IDs dos produtos, separados por vírgula",
+                        "name": "product_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categoria dos produtos",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Retoma o stream após a posição informada",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ticket de POST /products/stream/tickets, para navegadores",
+                        "name": "ticket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/trash": {
+            "get": {
+                "description": "Retorna os produtos excluídos que ainda podem ser restaurados, dos mais antigos para os mais recentes. Produtos na lixeira há mais que o período de retenção são removidos definitivamente",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Retorna os produtos da lixeira",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Product"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Retorna um produto pelo ID. Com as_of, retorna a versão vigente naquele instante, sem tradução",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Retorna um produto pelo ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instante da consulta (RFC 3339), ex: 2026-01-01T00:00Z",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Atualiza um produto",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Atualiza um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move o produto para a lixeira, de onde pode ser restaurado até o fim do período de retenção",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Deleta um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/restore": {
+            "post": {
+                "description": "Restaura um produto excluído, junto com as suas traduções",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Restaura um produto da lixeira",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/revisions": {
+            "get": {
+                "description": "Lista todas as revisões do produto, da mais antiga para a mais recente, inclusive as remoções para a lixeira",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Lista as versões de um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/revisions/{revision}/revert": {
+            "post": {
+                "description": "Cria uma nova revisão com o conteúdo da revisão informada. Produtos na lixeira precisam ser restaurados antes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Reverte um produto para uma versão anterior",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número da revisão",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/subscriptions": {
+            "post": {
+                "description": "Registra um e-mail ou um cliente (customer_id, do próprio titular ou qualquer um para editores) para receber um único e-mail, no idioma do Accept-Language, quando o estoque do produto sair de zero. O token de cancelamento só é exibido nesta resposta",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "avisos de estoque"
+                ],
+                "summary": "Inscreve um destinatário no aviso de volta ao estoque",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "email or customer_id",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.IssuedStockSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/translations": {
+            "get": {
+                "description": "Retorna o nome e a descrição do produto em cada locale cadastrado",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traduções"
+                ],
+                "summary": "Retorna as traduções de um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductTranslation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/translations/{locale}": {
+            "get": {
+                "description": "Retorna o nome e a descrição do produto no locale informado",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traduções"
+                ],
+                "summary": "Retorna a tradução de um produto em um locale",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale BCP 47, ex: pt-BR",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Cria ou substitui o nome e a descrição do produto no locale informado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traduções"
+                ],
+                "summary": "Cria ou substitui a tradução de um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale BCP 47, ex: pt-BR",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation data",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTranslation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a tradução do produto no locale informado; as leituras voltam a usar os campos base",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traduções"
+                ],
+                "summary": "Remove a tradução de um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale BCP 47, ex: pt-BR",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Verifica banco de dados, migrações pendentes e workers, retornando a latência de cada dependência",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se a aplicação está pronta para receber tráfego",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/rpc": {
+            "post": {
+                "description": "Interface JSON-RPC 2.0 para chamadas internas entre os microsserviços, com as mesmas operações de /products: products.create, products.get, products.list, products.count, products.update, products.delete, products.trash, products.restore, products.revisions e products.revert. Aceita lotes (array) e notificações (sem id), que não têm resposta; sem respostas, retorna 204. Cada chamada é autorizada, limitada (ROUTE_RATE_LIMITS) e tem o prazo (ROUTE_TIMEOUTS) da rota REST equivalente; o lote como um todo não tem o prazo de REQUEST_TIMEOUT. Os erros dos métodos trazem em data o mesmo problema (RFC 7807) da rota REST; erros de validação usam o código -32602",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "json-rpc"
+                ],
+                "summary": "Executa chamadas JSON-RPC 2.0 do catálogo",
+                "parameters": [
+                    {
+                        "description": "Chamada ou lote de chamadas",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/jsonrpc.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonrpc.Response"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-reservations": {
+            "post": {
+                "description": "Usado no checkout do microsserviço de pedidos: baixa o estoque de todos os itens do pedido, ou de nenhum. A falta de estoque responde 409 insufficient_stock, com o ID de cada produto em falta no param dos erros. Repetir a reserva de um pedido retorna as reservas existentes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservas de estoque"
+                ],
+                "summary": "Reserva o estoque de um pedido",
+                "parameters": [
+                    {
+                        "description": "Order ID and items",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockReservationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockReservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-reservations/{id}": {
+            "get": {
+                "description": "Lista as reservas do pedido com o status de cada produto: reserved, released ou shipped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservas de estoque"
+                ],
+                "summary": "Lista as reservas de estoque de um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockReservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Usado no cancelamento do pedido e como compensação de um checkout que falhou. Pedidos sem reservas ou já liberados respondem 204; pedidos já enviados respondem 409",
+                "tags": [
+                    "reservas de estoque"
+                ],
+                "summary": "Devolve ao estoque as reservas de um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-reservations/{id}/commit": {
+            "post": {
+                "description": "Usado no envio do pedido: as reservas passam a shipped e não podem mais ser devolvidas ao estoque",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservas de estoque"
+                ],
+                "summary": "Torna definitivas as reservas de um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockReservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-subscriptions/{token}": {
+            "get": {
+                "description": "Página HTML aberta pelo link de cancelamento dos e-mails de aviso. Não cancela nada: o botão da página envia o POST para a mesma URL; não exige autenticação",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "avisos de estoque"
+                ],
+                "summary": "Mostra a confirmação do cancelamento dos avisos de volta ao estoque",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de cancelamento",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Página de confirmação",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Recebe o formulário da página de confirmação e cancela os avisos como o DELETE, respondendo com uma página HTML; não exige autenticação",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "avisos de estoque"
+                ],
+                "summary": "Cancela os avisos de volta ao estoque pela página de confirmação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de cancelamento",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Página de cancelamento concluído",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Página de erro",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Página de erro",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancela o aviso com o token retornado na inscrição, ou todos os avisos pendentes do destinatário com o token do link de um aviso enviado; não exige autenticação",
+                "tags": [
+                    "avisos de estoque"
+                ],
+                "summary": "Cancela um aviso de volta ao estoque",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de cancelamento",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Lista os webhooks com estado e falhas seguidas; os segredos não são retornados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lista os webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cadastra um endpoint que recebe os eventos do catálogo por HTTP. Sem event_types, recebe todos os eventos. O segredo usado para verificar o header X-Webhook-Signature só é exibido nesta resposta",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Cadastra um webhook",
+                "parameters": [
+                    {
+                        "description": "URL and optional event_types",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.IssuedWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Busca um webhook pelo ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Busca um webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Substitui URL, event_types e active. Reativar um webhook desativado por falhas zera a contagem de falhas e retoma as entregas pendentes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Atualiza um webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "URL, event_types and active",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove o webhook e o seu registro de entregas; as entregas pendentes são descartadas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Remove um webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Lista as entregas da mais recente para a mais antiga, com tentativas, status HTTP e último erro. Use before_id com o último ID recebido para paginar",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Consulta o registro de entregas de um webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, succeeded ou failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo do evento, ex: ProductCreated",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Retorna entregas com ID menor que o informado",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de entregas (padrão 100, máximo 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery}/replay": {
+            "post": {
+                "description": "Coloca a entrega de novo na fila com as tentativas zeradas; o corpo e o ID do evento são os originais",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Reenvia uma entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da entrega",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/rotate": {
+            "post": {
+                "description": "Substitui o segredo de assinatura; as próximas entregas, inclusive as pendentes, são assinadas com o novo segredo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Gera um novo segredo para o webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IssuedWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Verification": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "description": "Number of records checked",
+                    "type": "integer"
+                },
+                "first_invalid_id": {
+                    "description": "First record whose hash or link does not match",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "Whether the chain is intact",
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.Problem": {
+            "description": "Error response in the RFC 7807 (application/problem+json) format",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable error code",
+                    "type": "string",
+                    "example": "product_not_found"
+                },
+                "detail": {
+                    "description": "Occurrence-specific explanation",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Field-level validation details",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Request path",
+                    "type": "string",
+                    "example": "/products/42"
+                },
+                "request_id": {
+                    "description": "X-Request-ID of the request",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Short, human-readable summary",
+                    "type": "string",
+                    "example": "Product not found"
+                },
+                "type": {
+                    "description": "Problem type URI",
+                    "type": "string",
+                    "example": "urn:produtos-api:problem:product_not_found"
+                }
+            }
+        },
+        "jsonrpc.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "jsonrpc.Request": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "jsonrpc": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object"
+                }
+            }
+        },
+        "jsonrpc.Response": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/jsonrpc.Error"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "jsonrpc": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.APIKey": {
+            "description": "API key metadata; the key itself is only returned when created or rotated",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that created the key",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Expiration time; empty means no expiration",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "API key ID",
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "description": "Last successful authentication",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Client name, e.g. orders-service",
+                    "type": "string",
+                    "maxLength": 80,
+                    "minLength": 2
+                },
+                "prefix": {
+                    "description": "Public key prefix, used to identify the key",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Revocation time",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "description": "Last rotation of the key",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Granted scopes, e.g. products:write",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AuditRecord": {
+            "description": "Audit record of a catalog mutation",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update, delete, restore, purge or revert",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Subject that made the change",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "Field-level diff",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "entity_id": {
+                    "description": "Entity ID",
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "description": "Entity type, e.g. product",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "Hash of this record",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Audit record ID",
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "description": "Time of the change",
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "description": "Hash of the previous record",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "X-Request-ID of the change",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "description": "Value of a field before and after a change",
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "New value; empty on delete"
+                },
+                "before": {
+                    "description": "Previous value; empty on create"
+                },
+                "field": {
+                    "description": "Field name",
+                    "type": "string"
+                }
+            }
+        },
+        "models.IssuedAPIKey": {
+            "description": "API key metadata and the plain key, shown only once",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that created the key",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Expiration time; empty means no expiration",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "API key ID",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Plain API key, send it in the X-API-Key header",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Last successful authentication",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Client name, e.g. orders-service",
+                    "type": "string",
+                    "maxLength": 80,
+                    "minLength": 2
+                },
+                "prefix": {
+                    "description": "Public key prefix, used to identify the key",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Revocation time",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "description": "Last rotation of the key",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Granted scopes, e.g. products:write",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.IssuedStockSubscription": {
+            "description": "Subscription and its unsubscribe token, shown only once",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Subscription time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that subscribed",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "Recipient customer",
+                    "type": "integer"
+                },
+                "email": {
+                    "description": "Recipient address",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Subscription ID",
+                    "type": "integer"
+                },
+                "locale": {
+                    "description": "Language of the notification",
+                    "type": "string"
+                },
+                "product_id": {
+                    "description": "Product awaited",
+                    "type": "integer"
+                },
+                "unsubscribe_token": {
+                    "description": "Token for DELETE /stock-subscriptions/{token}",
+                    "type": "string"
+                }
+            }
+        },
+        "models.IssuedWebhook": {
+            "description": "Webhook subscription and its signing secret, shown only once",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Inactive webhooks receive no deliveries",
+                    "type": "boolean"
+                },
+                "consecutive_failures": {
+                    "description": "Failed attempts since the last success",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that created the webhook",
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "description": "When the webhook was disabled after repeated failures",
+                    "type": "string"
+                },
+                "disabled_reason": {
+                    "description": "Why the webhook was disabled",
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "Delivered event types; empty delivers every event",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "Webhook ID",
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Secret used to verify the X-Webhook-Signature header",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Last update",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Endpoint that receives the deliveries",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "models.Product": {
+            "description": "A product model",
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
+                "category": {
+                    "description": "Product Category",
+                    "type": "string",
+                    "maxLength": 60
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Deletion time; set only for products in the trash",
+                    "type": "string"
+                },
                 "description": {
                     "description": "Product Description",
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 2000
                 },
                 "id": {
                     "description": "Product ID",
                     "type": "integer"
                 },
+                "name": {
+                    "description": "Product Name",
+                    "type": "string",
+                    "maxLength": 120,
+                    "minLength": 2
+                },
+                "price": {
+                    "description": "Product Price",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "stock": {
+                    "description": "Product Stock",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "updated_at": {
+                    "description": "Last update time",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductChange": {
+            "description": "Catalog change sent by GET /products/stream",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Category of the product when it changed",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Event payload, as published to the broker",
+                    "type": "object"
+                },
+                "event_id": {
+                    "description": "Unique event ID",
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "description": "When the change happened",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Stream position, sent as the SSE id; resume after it with Last-Event-ID",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "Changed product",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "ProductCreated, ProductUpdated, ProductDeleted or StockChanged",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductRevision": {
+            "description": "A past or current version of a product",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Product Category",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Creation time of the product",
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "Whether the product was in the trash in this revision",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Product Description",
+                    "type": "string"
+                },
                 "name": {
                     "description": "Product Name",
                     "type": "string"
@@ -261,9 +2804,310 @@ const docTemplate = `{
                     "description": "Product Price",
                     "type": "number"
                 },
+                "product_id": {
+                    "description": "Product ID",
+                    "type": "integer"
+                },
+                "revision": {
+                    "description": "Sequential revision number, starting at 1",
+                    "type": "integer"
+                },
                 "stock": {
                     "description": "Product Stock",
                     "type": "integer"
+                },
+                "valid_from": {
+                    "description": "When this revision became current",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductTranslation": {
+            "description": "Localized name and description of a product",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "description": "Localized Description",
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "locale": {
+                    "description": "BCP 47 locale, e.g. pt-BR",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Localized Name",
+                    "type": "string",
+                    "maxLength": 120,
+                    "minLength": 2
+                },
+                "product_id": {
+                    "description": "Product ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StockReservation": {
+            "description": "Stock held for an order",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "OrderPlaced event, or X-Request-ID of the checkout, that created the reservation",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Reservation ID",
+                    "type": "integer"
+                },
+                "order_id": {
+                    "description": "Order ID in the orders service",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "Reserved product",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Reserved units",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "reserved, released or shipped",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockReservationItem": {
+            "type": "object",
+            "required": [
+                "product_id"
+            ],
+            "properties": {
+                "product_id": {
+                    "description": "Reserved product",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Reserved units",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StockReservationRequest": {
+            "description": "Stock reservation of an order; repeated products are summed",
+            "type": "object",
+            "required": [
+                "order_id"
+            ],
+            "properties": {
+                "items": {
+                    "description": "Reserved items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockReservationItem"
+                    }
+                },
+                "order_id": {
+                    "description": "Order ID in the orders service",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StockSubscriptionRequest": {
+            "description": "Back-in-stock subscription of an email or a customer",
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "description": "Recipient customer",
+                    "type": "integer"
+                },
+                "email": {
+                    "description": "Recipient address",
+                    "type": "string",
+                    "maxLength": 254
+                }
+            }
+        },
+        "models.StreamTicket": {
+            "description": "Ticket for GET /products/stream and GET /products/stream/ws",
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "The connection must be opened before this instant",
+                    "type": "string"
+                },
+                "ticket": {
+                    "description": "Send it in the ticket query parameter",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "description": "Webhook subscription; the secret is only returned when created or rotated",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Inactive webhooks receive no deliveries",
+                    "type": "boolean"
+                },
+                "consecutive_failures": {
+                    "description": "Failed attempts since the last success",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that created the webhook",
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "description": "When the webhook was disabled after repeated failures",
+                    "type": "string"
+                },
+                "disabled_reason": {
+                    "description": "Why the webhook was disabled",
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "Delivered event types; empty delivers every event",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "Webhook ID",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "Last update",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Endpoint that receives the deliveries",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "description": "Delivery of an event to a webhook",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts since the delivery was queued",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "When the delivery was queued",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "When the endpoint accepted the delivery",
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "Delivered event ID",
+                    "type": "string"
+                },
+                "event_type": {
+                    "description": "Delivered event type",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Delivery ID",
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "description": "Time of the last attempt",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "Error of the last failed attempt",
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "Earliest time of the next attempt",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "JSON request body",
+                    "type": "string"
+                },
+                "replayed_at": {
+                    "description": "Last manual replay",
+                    "type": "string"
+                },
+                "response_status": {
+                    "description": "HTTP status of the last attempt",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, succeeded or failed",
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "description": "Target webhook",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.HealthReport": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/services.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,724 @@
+// Package pedidos Code generated by swaggo/swag. DO NOT EDIT
+package pedidos
+
+import "github.com/swaggo/swag"
+
+const docTemplatepedidos = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/healthz": {
+            "get": {
+                "description": "Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se o processo está vivo",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Lista os pedidos do mais recente para o mais antigo, com os itens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Lista os pedidos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, paid, shipped ou cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de pedidos (padrão 50, máximo 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cria o pedido com o nome e o preço atuais de cada produto e reserva o estoque de todos os itens na API de produtos, ou de nenhum. Sem estoque suficiente, responde 409 insufficient_stock com o ID de cada produto em falta no param dos erros, e nenhum pedido é criado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Faz o checkout de um pedido",
+                "parameters": [
+                    {
+                        "description": "Customer and items",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "Busca o pedido e os seus itens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Busca um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "description": "Cancela um pedido pending ou paid e devolve o estoque reservado. Se a API de produtos estiver indisponível, o pedido é cancelado com stock_released=false e o estoque é devolvido em segundo plano",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Cancela um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "cancellation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderCancelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/pay": {
+            "post": {
+                "description": "Passa o pedido de pending para paid. Outros status respondem 409 invalid_order_transition",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Confirma o pagamento de um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/ship": {
+            "post": {
+                "description": "Passa o pedido de paid para shipped, tornando as reservas de estoque definitivas. Outros status respondem 409 invalid_order_transition",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedidos"
+                ],
+                "summary": "Envia um pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Verifica banco de dados, migrações pendentes e workers, retornando a latência de cada dependência",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Indica se a aplicação está pronta para receber tráfego",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/services.HealthReport"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.Problem": {
+            "description": "Error response in the RFC 7807 (application/problem+json) format",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable error code",
+                    "type": "string",
+                    "example": "product_not_found"
+                },
+                "detail": {
+                    "description": "Occurrence-specific explanation",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Field-level validation details",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Request path",
+                    "type": "string",
+                    "example": "/products/42"
+                },
+                "request_id": {
+                    "description": "X-Request-ID of the request",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Short, human-readable summary",
+                    "type": "string",
+                    "example": "Product not found"
+                },
+                "type": {
+                    "description": "Problem type URI",
+                    "type": "string",
+                    "example": "urn:produtos-api:problem:product_not_found"
+                }
+            }
+        },
+        "models.Order": {
+            "description": "Order and its items",
+            "type": "object",
+            "properties": {
+                "cancel_reason": {
+                    "description": "Why the order was cancelled",
+                    "type": "string"
+                },
+                "cancelled_at": {
+                    "description": "When the order was cancelled",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Checkout time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "Subject that placed the order",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "Customer who placed the order",
+                    "type": "integer"
+                },
+                "email": {
+                    "description": "Contact email",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Order ID",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "Ordered items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderItem"
+                    }
+                },
+                "locale": {
+                    "description": "Language negotiated at checkout, used in notifications",
+                    "type": "string"
+                },
+                "paid_at": {
+                    "description": "When the payment was confirmed",
+                    "type": "string"
+                },
+                "shipped_at": {
+                    "description": "When the order was shipped",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, paid, shipped or cancelled",
+                    "type": "string"
+                },
+                "stock_released": {
+                    "description": "Whether the stock of a cancelled order was given back",
+                    "type": "boolean"
+                },
+                "stock_reserved": {
+                    "description": "Whether the products API confirmed the reservation",
+                    "type": "boolean"
+                },
+                "total": {
+                    "description": "Sum of the items at checkout prices",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "description": "Last update",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderCancelRequest": {
+            "description": "Cancellation of an order",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Why the order was cancelled",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.OrderItem": {
+            "description": "Ordered product",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Item ID",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Product name at checkout",
+                    "type": "string"
+                },
+                "order_id": {
+                    "description": "Order ID",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "Product ID in the products API",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Ordered units",
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "description": "Product price at checkout",
+                    "type": "number"
+                }
+            }
+        },
+        "models.OrderItemRequest": {
+            "type": "object",
+            "required": [
+                "product_id"
+            ],
+            "properties": {
+                "product_id": {
+                    "description": "Product ID in the products API",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Ordered units",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderRequest": {
+            "description": "Checkout of an order",
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "description": "Customer who places the order",
+                    "type": "integer"
+                },
+                "email": {
+                    "description": "Contact email",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Ordered items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderItemRequest"
+                    }
+                }
+            }
+        },
+        "services.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.HealthReport": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/services.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfopedidos holds exported Swagger Info so clients can modify it
+var SwaggerInfopedidos = &swag.Spec{
+	Version:          "",
+	Host:             "",
+	BasePath:         "",
+	Schemes:          []string{},
+	Title:            "",
+	Description:      "",
+	InfoInstanceName: "pedidos",
+	SwaggerTemplate:  docTemplatepedidos,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfopedidos.InstanceName(), SwaggerInfopedidos)
+}
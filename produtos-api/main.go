@@ -1,13 +1,42 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
+	"sync"
+
+	"produtos-api/src/config"
+	"produtos-api/src/database"
+	"produtos-api/src/logging"
 	"produtos-api/src/routes"
+	"produtos-api/src/server"
+	"produtos-api/src/services"
+	"produtos-api/src/tracing"
 )
 
 func main() {
-	router := routes.SetupRoutes()
-	log.Println("Server is running on port 8080...")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	logger := logging.Setup(os.Stdout)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.ServiceName)
+	if err != nil {
+		logger.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Inicializa o banco de dados (real ou de testes, dependendo do ambiente)
+	db, err := database.SetupDatabase(database.Products)
+	if err != nil {
+		logger.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+
+	// HTTP_ADDR permite rodar a API de produtos ao lado dos outros microsserviços
+	setup := func(ctx context.Context, healthService services.HealthService, workers *sync.WaitGroup) http.Handler {
+		return routes.SetupRoutes(ctx, db, healthService, workers)
+	}
+	if err := server.Run(config.GetString("HTTP_ADDR", ":8080"), setup, shutdownTracing); err != nil {
+		logger.Error("Server failed", "error", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"produtos-api/src/models"
+)
+
+// Precision é a precisão dos horários dos registros; o horário entra no hash e precisa
+// sobreviver à ida e volta pelo banco sem alterações
+const Precision = time.Microsecond
+
+// Diff compara duas versões de uma entidade campo a campo, pelos nomes JSON, desconsiderando
+// os campos em ignore. before nil representa uma criação e after nil uma remoção.
+func Diff(before, after interface{}, ignore ...string) ([]models.FieldChange, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	changes := []models.FieldChange{}
+	for _, name := range ignore {
+		delete(names, name)
+	}
+	for name := range names {
+		if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			changes = append(changes, models.FieldChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes, nil
+}
+
+// fields converte a entidade em um mapa pelos nomes JSON, com os mesmos tipos que a leitura
+// do registro salvo produz (números como float64)
+func fields(entity interface{}) (map[string]interface{}, error) {
+	if value := reflect.ValueOf(entity); entity == nil || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	err = json.Unmarshal(data, &values)
+	return values, err
+}
+
+// hashedRecord define os campos que entram no hash e a ordem em que são serializados
+type hashedRecord struct {
+	EntityType string               `json:"entity_type"`
+	EntityID   uint                 `json:"entity_id"`
+	Action     string               `json:"action"`
+	Actor      string               `json:"actor"`
+	RequestID  string               `json:"request_id"`
+	Changes    []models.FieldChange `json:"changes"`
+	OccurredAt string               `json:"occurred_at"`
+	PrevHash   string               `json:"prev_hash"`
+}
+
+// Hash calcula o hash do registro a partir do seu conteúdo e do hash do registro anterior
+func Hash(record *models.AuditRecord) (string, error) {
+	data, err := json.Marshal(hashedRecord{
+		EntityType: record.EntityType,
+		EntityID:   record.EntityID,
+		Action:     record.Action,
+		Actor:      record.Actor,
+		RequestID:  record.RequestID,
+		Changes:    record.Changes,
+		OccurredAt: record.OccurredAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:   record.PrevHash,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verification é o resultado da verificação da cadeia de registros
+type Verification struct {
+	Valid        bool  `json:"valid"`                      // Whether the chain is intact
+	Checked      int   `json:"checked"`                    // Number of records checked
+	FirstInvalid *uint `json:"first_invalid_id,omitempty"` // First record whose hash or link does not match
+}
+
+// Verifier confere a cadeia em lotes, na ordem de ID: cada registro deve apontar para o hash
+// do anterior e o hash armazenado deve corresponder ao conteúdo
+type Verifier struct {
+	Result   Verification
+	prevHash string
+}
+
+// NewVerifier cria o verificador a partir do primeiro registro da cadeia
+func NewVerifier() *Verifier {
+	return &Verifier{Result: Verification{Valid: true}}
+}
+
+// Add verifica o próximo lote; retorna false quando a cadeia já está quebrada
+func (v *Verifier) Add(records []models.AuditRecord) (bool, error) {
+	for i := range records {
+		if !v.Result.Valid {
+			return false, nil
+		}
+
+		record := &records[i]
+		hash, err := Hash(record)
+		if err != nil {
+			return false, err
+		}
+
+		v.Result.Checked++
+		if record.PrevHash != v.prevHash || record.Hash != hash {
+			v.Result.Valid = false
+			v.Result.FirstInvalid = &record.ID
+			return false, nil
+		}
+		v.prevHash = record.Hash
+	}
+
+	return v.Result.Valid, nil
+}
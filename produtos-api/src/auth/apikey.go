@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader é o header usado pelos clientes de máquina para enviar a chave de API
+const APIKeyHeader = "X-API-Key"
+
+// As chaves têm o formato pak_<prefixo>_<segredo>. O prefixo é público e localiza a chave
+// no banco; apenas o hash SHA-256 da chave completa é armazenado.
+const (
+	apiKeyTag         = "pak_"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+	ScopeRead         = "read"
+	ScopeWrite        = "write"
+	scopeSeparator    = ":"
+)
+
+// GenerateAPIKey cria uma nova chave e retorna a chave em texto puro, o prefixo e o hash
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	secretBytes := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyTag + prefix + "_" + hex.EncodeToString(secretBytes)
+
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// APIKeyPrefix extrai o prefixo público da chave; ok é false quando o formato é inválido
+func APIKeyPrefix(key string) (prefix string, ok bool) {
+	rest, found := strings.CutPrefix(key, apiKeyTag)
+	if !found {
+		return "", false
+	}
+
+	prefix, secret, found := strings.Cut(rest, "_")
+	if !found || len(prefix) != 2*apiKeyPrefixBytes || len(secret) != 2*apiKeySecretBytes {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// HashAPIKey calcula o hash armazenado da chave
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchAPIKey compara a chave com o hash armazenado em tempo constante
+func MatchAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// ScopeFor retorna o escopo exigido de uma chave de API para a rota, no formato
+// "<recurso>:read" para leituras e "<recurso>:write" para as demais operações,
+// onde o recurso é o primeiro segmento do template (ex: "POST /products/{id}" → "products:write")
+func ScopeFor(method, route string) string {
+	resource, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+
+	action := ScopeWrite
+	if method == http.MethodGet || method == http.MethodHead {
+		action = ScopeRead
+	}
+
+	return resource + scopeSeparator + action
+}
+
+// ScopeGrants indica se o escopo concedido cobre o escopo requerido; write inclui read
+func ScopeGrants(granted, required string) bool {
+	grantedResource, grantedAction, _ := strings.Cut(granted, scopeSeparator)
+	requiredResource, requiredAction, _ := strings.Cut(required, scopeSeparator)
+
+	if grantedResource != requiredResource {
+		return false
+	}
+
+	return grantedAction == requiredAction || (grantedAction == ScopeWrite && requiredAction == ScopeRead)
+}
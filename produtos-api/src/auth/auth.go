@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Role representa um papel de acesso; cada papel inclui as permissões dos papéis abaixo dele
+type Role string
+
+const (
+	RolePublic Role = "public"
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// rank define a hierarquia dos papéis: admin > editor > viewer > public
+var rank = map[Role]int{
+	RolePublic: 0,
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Valid indica se o papel é conhecido
+func (r Role) Valid() bool {
+	_, ok := rank[r]
+	return ok
+}
+
+// Includes indica se o papel concede as permissões do papel requerido
+func (r Role) Includes(required Role) bool {
+	have, ok := rank[r]
+	return ok && have >= rank[required]
+}
+
+// Authentication methods
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal identifica quem está fazendo a requisição. Usuários (JWT) são autorizados pelos
+// papéis; chaves de API, pelos escopos.
+type Principal struct {
+	Subject string
+	Roles   []Role
+	Scopes  []string
+	Method  string
+}
+
+// HasRole indica se algum dos papéis do principal concede o papel requerido
+func (p *Principal) HasRole(required Role) bool {
+	if required == RolePublic {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(p.Roles, func(role Role) bool { return role.Includes(required) })
+}
+
+// HasScope indica se algum dos escopos do principal concede o escopo requerido
+func (p *Principal) HasScope(required string) bool {
+	if p == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(p.Scopes, func(scope string) bool { return ScopeGrants(scope, required) })
+}
+
+type principalKey struct{}
+
+// WithPrincipal guarda o principal autenticado no contexto
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom retorna o principal autenticado, ou nil para requisições anônimas
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"produtos-api/src/config"
+)
+
+// LoadKeySet monta o KeySet a partir das variáveis AUTH_HMAC_SECRET, AUTH_JWKS_FILE,
+// AUTH_ISSUER e AUTH_AUDIENCE
+func LoadKeySet() (*KeySet, error) {
+	keys := &KeySet{
+		HMACSecret: []byte(config.GetString("AUTH_HMAC_SECRET", "")),
+		Issuer:     config.GetString("AUTH_ISSUER", ""),
+		Audience:   config.GetString("AUTH_AUDIENCE", ""),
+	}
+
+	if path := config.GetString("AUTH_JWKS_FILE", ""); path != "" {
+		publicKeys, err := LoadJWKS(path)
+		if err != nil {
+			return nil, err
+		}
+		keys.PublicKeys = publicKeys
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims são as claims aceitas nos tokens da API: as registradas no RFC 7519 mais os papéis
+type Claims struct {
+	Roles []Role `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// KeySet reúne as chaves aceitas para validar tokens: um segredo HMAC e/ou chaves públicas de um JWKS
+type KeySet struct {
+	HMACSecret []byte
+	PublicKeys map[string]interface{} // kid -> *rsa.PublicKey ou *ecdsa.PublicKey
+	Issuer     string
+	Audience   string
+}
+
+// Empty indica que nenhuma chave foi configurada
+func (ks *KeySet) Empty() bool {
+	return len(ks.HMACSecret) == 0 && len(ks.PublicKeys) == 0
+}
+
+// Parse valida a assinatura, a expiração, o emissor e a audiência do token
+func (ks *KeySet) Parse(token string) (*Claims, error) {
+	if ks.Empty() {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(ks.validMethods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if ks.Issuer != "" {
+		options = append(options, jwt.WithIssuer(ks.Issuer))
+	}
+	if ks.Audience != "" {
+		options = append(options, jwt.WithAudience(ks.Audience))
+	}
+
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(token, claims, ks.keyFunc, options...); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (ks *KeySet) validMethods() []string {
+	var methods []string
+	if len(ks.HMACSecret) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if len(ks.PublicKeys) > 0 {
+		methods = append(methods, "RS256", "RS384", "RS512", "ES256", "ES384", "ES512")
+	}
+
+	return methods
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return ks.HMACSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.PublicKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// Mint gera um token HMAC-SHA256 para desenvolvimento e testes
+func Mint(secret []byte, subject string, roles []Role, ttl time.Duration, issuer, audience string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	if audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKS lê as chaves públicas RSA e EC de um arquivo JWKS (RFC 7517)
+func LoadJWKS(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o JWKS: %v", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("JWKS inválido: %v", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, key := range set.Keys {
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("chave %q do JWKS inválida: %v", key.Kid, err)
+		}
+		keys[key.Kid] = publicKey
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Policy mapeia cada rota, no formato "MÉTODO /template", para o papel mínimo exigido.
+// Rotas sem entrada exigem o papel Default. Com PublicReads, as rotas GET que exigiriam
+// apenas viewer passam a ser públicas. TicketRoutes são as rotas que aceitam um ticket na query.
+type Policy struct {
+	Routes       map[string]Role
+	Default      Role
+	PublicReads  bool
+	TicketRoutes map[string]bool
+}
+
+// Required retorna o papel mínimo exigido para a rota
+func (p Policy) Required(method, route string) Role {
+	role, ok := p.Routes[method+" "+route]
+	if !ok {
+		role = p.Default
+	}
+
+	if p.PublicReads && role == RoleViewer && (method == http.MethodGet || method == http.MethodHead) {
+		return RolePublic
+	}
+
+	return role
+}
+
+// APIKeyScopes lista os escopos que podem ser concedidos a chaves de API: os das rotas
+// protegidas abaixo de admin. Rotas de admin nunca são acessíveis por chaves de API.
+func (p Policy) APIKeyScopes() []string {
+	var scopes []string
+	for route, role := range p.Routes {
+		if role == RolePublic || !RoleEditor.Includes(role) {
+			continue
+		}
+
+		method, template, _ := strings.Cut(route, " ")
+		if scope := ScopeFor(method, template); !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+
+	return scopes
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TicketParam é o parâmetro de query com o ticket nas rotas que o aceitam
+const TicketParam = "ticket"
+
+// ticketAudience identifica os tickets, que não são aceitos como tokens da API
+const ticketAudience = "produtos-api:ticket"
+
+type ticketClaims struct {
+	Roles  []Role   `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Method string   `json:"method"`
+	jwt.RegisteredClaims
+}
+
+// Tickets emite e valida tickets: tokens de curta duração com o principal de uma requisição já autenticada,
+// para conexões de navegadores que não enviam headers, como EventSource e WebSocket. A chave HMAC é
+// derivada de Secret, então um ticket nunca é válido como token assinado com o mesmo segredo.
+type Tickets struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// Issue gera um ticket para o principal, válido por TTL a partir de now
+func (t Tickets) Issue(principal *Principal, now time.Time) (string, time.Time, error) {
+	if principal == nil {
+		return "", time.Time{}, errors.New("tickets require an authenticated principal")
+	}
+
+	expiresAt := now.Add(t.TTL)
+	claims := ticketClaims{
+		Roles:  principal.Roles,
+		Scopes: principal.Scopes,
+		Method: principal.Method,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   principal.Subject,
+			Audience:  jwt.ClaimStrings{ticketAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	ticket, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.key())
+	return ticket, expiresAt, err
+}
+
+// Parse valida a assinatura e a expiração do ticket e retorna o principal que o emitiu
+func (t Tickets) Parse(ticket string) (*Principal, error) {
+	claims := &ticketClaims{}
+	_, err := jwt.ParseWithClaims(ticket, claims, func(*jwt.Token) (interface{}, error) {
+		return t.key(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired(), jwt.WithAudience(ticketAudience))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{Subject: claims.Subject, Roles: claims.Roles, Scopes: claims.Scopes, Method: claims.Method}, nil
+}
+
+func (t Tickets) key() []byte {
+	mac := hmac.New(sha256.New, t.Secret)
+	mac.Write([]byte(ticketAudience))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,59 @@
+package broker
+
+import (
+	"context"
+	"time"
+)
+
+// Message é um evento publicado no barramento. Mensagens com a mesma Key são entregues
+// na ordem de publicação (no Kafka, a Key define a partição). Headers carrega, entre outros,
+// o trace context W3C (traceparent) da mudança que originou o evento.
+type Message struct {
+	Topic         string
+	Key           string
+	ID            string
+	Type          string
+	SchemaVersion int
+	Headers       map[string]string
+	Value         []byte
+	Timestamp     time.Time
+}
+
+// Headers acrescentados às mensagens enviadas para um tópico de dead letter, com o motivo da falha
+const (
+	HeaderDeadLetterError    = "dlq_error"
+	HeaderDeadLetterTopic    = "dlq_original_topic"
+	HeaderDeadLetterFailedAt = "dlq_failed_at"
+)
+
+// DeadLetter copia a mensagem para o tópico de dead letter, com os headers do motivo da falha
+func DeadLetter(msg Message, topic string, cause error) Message {
+	headers := make(map[string]string, len(msg.Headers)+3)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderDeadLetterError] = cause.Error()
+	headers[HeaderDeadLetterTopic] = msg.Topic
+	headers[HeaderDeadLetterFailedAt] = time.Now().UTC().Format(time.RFC3339Nano)
+
+	dead := msg
+	dead.Topic = topic
+	dead.Headers = headers
+	return dead
+}
+
+// Broker publica mensagens no barramento de eventos. Publish só retorna sucesso depois
+// que o broker confirmou o recebimento; em caso de erro a mensagem pode ou não ter sido
+// recebida, por isso os consumidores devem tolerar duplicatas.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Handler processa uma mensagem consumida; o trace context dos headers já está em ctx.
+// A mensagem só é confirmada depois que o handler retorna nil; um erro faz ela ser reprocessada.
+type Handler func(ctx context.Context, msg Message) error
+
+// Consumer entrega as mensagens dos tópicos assinados ao handler até o cancelamento de ctx
+type Consumer interface {
+	Consume(ctx context.Context, handler Handler) error
+}
@@ -0,0 +1,28 @@
+package broker
+
+import (
+	"context"
+	"errors"
+)
+
+// Fanout publica cada mensagem em todos os brokers. Falha se algum deles falhar; como a publicação
+// é repetida em todos, os que já haviam recebido a mensagem a recebem de novo.
+type Fanout []Broker
+
+func (f Fanout) Publish(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, broker := range f {
+		if err := broker.Publish(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PublisherFunc adapta uma função para a interface Broker
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
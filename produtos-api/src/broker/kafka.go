@@ -0,0 +1,295 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"produtos-api/src/logging"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers fixos das mensagens no Kafka; os demais headers de Message são copiados como estão
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+	HeaderContentType   = "content-type"
+)
+
+// Limites do backoff entre as tentativas de processar uma mensagem consumida
+const (
+	consumeRetryBase = time.Second
+	consumeRetryMax  = 30 * time.Second
+)
+
+var (
+	tracer = otel.Tracer("produtos-api/src/broker")
+	logger = logging.Logger("broker")
+)
+
+// KafkaConfig define a conexão com o cluster e a criação dos tópicos
+type KafkaConfig struct {
+	Brokers           []string
+	ClientID          string
+	Partitions        int32
+	ReplicationFactor int16
+}
+
+func (cfg KafkaConfig) options() []kgo.Opt {
+	return []kgo.Opt{kgo.SeedBrokers(cfg.Brokers...), kgo.ClientID(cfg.ClientID)}
+}
+
+// KafkaBroker publica mensagens no Kafka. As mensagens são particionadas pela Key (o ID do produto),
+// o que garante a ordem por produto; o produtor é idempotente e espera a confirmação de todas as réplicas.
+type KafkaBroker struct {
+	client *kgo.Client
+	config KafkaConfig
+}
+
+// NewKafkaBroker cria o produtor; a conexão com o cluster só é aberta no primeiro uso
+func NewKafkaBroker(cfg KafkaConfig) (*KafkaBroker, error) {
+	client, err := kgo.NewClient(append(cfg.options(),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaBroker{client: client, config: cfg}, nil
+}
+
+// EnsureTopic cria o tópico com as partições e o fator de replicação configurados, se ainda não existir
+func (b *KafkaBroker) EnsureTopic(ctx context.Context, topic string) error {
+	request := kmsg.NewPtrCreateTopicsRequest()
+	requestTopic := kmsg.NewCreateTopicsRequestTopic()
+	requestTopic.Topic = topic
+	requestTopic.NumPartitions = b.config.Partitions
+	requestTopic.ReplicationFactor = b.config.ReplicationFactor
+	request.Topics = append(request.Topics, requestTopic)
+
+	response, err := request.RequestWith(ctx, b.client)
+	if err != nil {
+		return err
+	}
+	for _, created := range response.Topics {
+		if err := kerr.ErrorForCode(created.ErrorCode); err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+			return fmt.Errorf("creating topic %s: %w", created.Topic, err)
+		}
+	}
+
+	return nil
+}
+
+// Publish envia a mensagem e espera a confirmação do cluster
+func (b *KafkaBroker) Publish(ctx context.Context, msg Message) error {
+	ctx, span := tracer.Start(ctx, msg.Topic+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(msg)...))
+	defer span.End()
+
+	record := &kgo.Record{
+		Topic:     msg.Topic,
+		Key:       []byte(msg.Key),
+		Value:     msg.Value,
+		Timestamp: msg.Timestamp,
+		Headers:   recordHeaders(ctx, msg),
+	}
+
+	err := b.client.ProduceSync(ctx, record).FirstErr()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	span.SetAttributes(attribute.Int("messaging.destination.partition.id", int(record.Partition)))
+
+	return nil
+}
+
+// Close espera as mensagens pendentes e encerra as conexões
+func (b *KafkaBroker) Close() {
+	b.client.Close()
+}
+
+// KafkaConsumer consome tópicos como membro de um consumer group. Os offsets só são confirmados
+// depois que o handler processou as mensagens, então a entrega é at-least-once.
+type KafkaConsumer struct {
+	client      *kgo.Client
+	retryBase   time.Duration
+	deadLetters Broker
+	dlqTopic    string
+	maxAttempts int
+}
+
+// NewKafkaConsumer cria o consumidor do grupo; sem offset confirmado, o grupo começa do início dos tópicos
+func NewKafkaConsumer(cfg KafkaConfig, group string, topics ...string) (*KafkaConsumer, error) {
+	client, err := kgo.NewClient(append(cfg.options(),
+		kgo.ConsumerGroup(group),
+		kgo.ConsumeTopics(topics...),
+		kgo.DisableAutoCommit(),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaConsumer{client: client, retryBase: consumeRetryBase}, nil
+}
+
+// WithDeadLetter faz as mensagens que falharem maxAttempts vezes seguidas serem publicadas em topic por
+// deadLetters e confirmadas, liberando as seguintes da partição. Sem dead letter, são reprocessadas até ter sucesso.
+func (c *KafkaConsumer) WithDeadLetter(deadLetters Broker, topic string, maxAttempts int) *KafkaConsumer {
+	c.deadLetters = deadLetters
+	c.dlqTopic = topic
+	c.maxAttempts = maxAttempts
+	return c
+}
+
+// Consume entrega as mensagens ao handler na ordem de cada partição. Uma mensagem com erro é
+// reprocessada com backoff, segurando as seguintes da partição, até ter sucesso ou ir para o dead letter.
+func (c *KafkaConsumer) Consume(ctx context.Context, handler Handler) error {
+	for {
+		fetches := c.client.PollFetches(ctx)
+		if fetches.IsClientClosed() || ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			logger.WarnContext(ctx, "fetch failed", "topic", topic, "partition", partition, "error", err)
+		})
+
+		var handled []*kgo.Record
+		for iter := fetches.RecordIter(); !iter.Done(); {
+			record := iter.Next()
+			if err := c.handle(ctx, record, handler); err != nil {
+				// Cancelado no meio do lote: confirma o que já foi processado
+				c.commit(context.WithoutCancel(ctx), handled)
+				return err
+			}
+			handled = append(handled, record)
+		}
+		c.commit(ctx, handled)
+	}
+}
+
+// Close sai do consumer group e encerra as conexões
+func (c *KafkaConsumer) Close() {
+	c.client.Close()
+}
+
+// handle chama o handler até ter sucesso ou, com WithDeadLetter, até a mensagem ir para o dead letter;
+// só retorna erro se ctx for cancelado
+func (c *KafkaConsumer) handle(ctx context.Context, record *kgo.Record, handler Handler) error {
+	msg := messageFrom(record)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Headers))
+
+	delay := c.retryBase
+	for attempt := 1; ; attempt++ {
+		err := c.process(ctx, msg, record.Partition, handler)
+		if err == nil {
+			return nil
+		}
+		logger.WarnContext(ctx, "message handling failed", "topic", msg.Topic, "event_id", msg.ID,
+			"attempt", attempt, "error", err)
+
+		if c.deadLetters != nil && attempt >= c.maxAttempts {
+			// Se a publicação falhar, a mensagem continua sendo reprocessada e é tentada de novo na próxima falha
+			dlqErr := c.deadLetters.Publish(ctx, DeadLetter(msg, c.dlqTopic, err))
+			if dlqErr == nil {
+				logger.ErrorContext(ctx, "message sent to dead letter topic", "topic", msg.Topic, "event_id", msg.ID,
+					"dlq_topic", c.dlqTopic, "attempts", attempt, "error", err)
+				return nil
+			}
+			logger.WarnContext(ctx, "dead letter publication failed", "topic", msg.Topic, "event_id", msg.ID, "error", dlqErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, consumeRetryMax)
+	}
+}
+
+func (c *KafkaConsumer) process(ctx context.Context, msg Message, partition int32, handler Handler) error {
+	ctx, span := tracer.Start(ctx, msg.Topic+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(append(messagingAttributes(msg), attribute.Int("messaging.destination.partition.id", int(partition)))...))
+	defer span.End()
+
+	err := handler(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (c *KafkaConsumer) commit(ctx context.Context, records []*kgo.Record) {
+	if len(records) == 0 {
+		return
+	}
+	// Uma falha ao confirmar só causa reentrega, que os handlers toleram
+	if err := c.client.CommitRecords(ctx, records...); err != nil {
+		logger.WarnContext(ctx, "offset commit failed", "error", err)
+	}
+}
+
+// recordHeaders monta os headers do registro, incluindo o trace context do span de publicação
+func recordHeaders(ctx context.Context, msg Message) []kgo.RecordHeader {
+	carrier := propagation.MapCarrier{}
+	for key, value := range msg.Headers {
+		carrier[key] = value
+	}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	carrier[HeaderEventID] = msg.ID
+	carrier[HeaderEventType] = msg.Type
+	carrier[HeaderSchemaVersion] = strconv.Itoa(msg.SchemaVersion)
+	carrier[HeaderContentType] = "application/json"
+
+	headers := make([]kgo.RecordHeader, 0, len(carrier))
+	for key, value := range carrier {
+		headers = append(headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+	}
+
+	return headers
+}
+
+func messageFrom(record *kgo.Record) Message {
+	headers := make(map[string]string, len(record.Headers))
+	for _, header := range record.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	version, _ := strconv.Atoi(headers[HeaderSchemaVersion])
+
+	return Message{
+		Topic:         record.Topic,
+		Key:           string(record.Key),
+		ID:            headers[HeaderEventID],
+		Type:          headers[HeaderEventType],
+		SchemaVersion: version,
+		Headers:       headers,
+		Value:         record.Value,
+		Timestamp:     record.Timestamp,
+	}
+}
+
+func messagingAttributes(msg Message) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.String("messaging.message.id", msg.ID),
+		attribute.String("messaging.kafka.message.key", msg.Key),
+	}
+}
@@ -0,0 +1,174 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestCluster sobe um cluster Kafka de um nó em processo (kfake), no lugar de um cluster real
+func newTestCluster(t *testing.T) KafkaConfig {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	return KafkaConfig{Brokers: cluster.ListenAddrs(), ClientID: "produtos-api-test", Partitions: 3, ReplicationFactor: 1}
+}
+
+func TestKafkaPublishAndConsume(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tracerProvider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+
+	cfg := newTestCluster(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	producer, err := NewKafkaBroker(cfg)
+	require.NoError(t, err)
+	defer producer.Close()
+	require.NoError(t, producer.EnsureTopic(ctx, "products"))
+	// Criar de novo não é erro
+	require.NoError(t, producer.EnsureTopic(ctx, "products"))
+
+	parentCtx, parent := otel.Tracer("test").Start(ctx, "request")
+	for i := 1; i <= 6; i++ {
+		err := producer.Publish(parentCtx, Message{
+			Topic:         "products",
+			Key:           fmt.Sprint(i % 2),
+			ID:            fmt.Sprintf("event-%d", i),
+			Type:          "ProductUpdated",
+			SchemaVersion: 1,
+			Headers:       map[string]string{"request_id": "req-1"},
+			Value:         []byte(fmt.Sprintf(`{"seq":%d}`, i)),
+			Timestamp:     time.Now(),
+		})
+		require.NoError(t, err)
+	}
+	parent.End()
+
+	consumer, err := NewKafkaConsumer(cfg, "produtos-api-test", "products")
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	var mu sync.Mutex
+	var received []Message
+	var traceIDs []trace.TraceID
+	consumeCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Consume(consumeCtx, func(ctx context.Context, msg Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, msg)
+			traceIDs = append(traceIDs, trace.SpanContextFromContext(ctx).TraceID())
+			if len(received) == 6 {
+				stop()
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for messages")
+	}
+
+	require.Len(t, received, 6)
+	byKey := map[string][]string{}
+	for i, msg := range received {
+		byKey[msg.Key] = append(byKey[msg.Key], msg.ID)
+		assert.Equal(t, "ProductUpdated", msg.Type)
+		assert.Equal(t, 1, msg.SchemaVersion)
+		assert.Equal(t, "req-1", msg.Headers["request_id"])
+		assert.Equal(t, "application/json", msg.Headers[HeaderContentType])
+		// O trace da requisição que publicou continua no consumidor
+		assert.Equal(t, parent.SpanContext().TraceID(), traceIDs[i])
+	}
+	// Mensagens com a mesma chave ficam na mesma partição e chegam na ordem de publicação
+	assert.Equal(t, []string{"event-1", "event-3", "event-5"}, byKey["1"])
+	assert.Equal(t, []string{"event-2", "event-4", "event-6"}, byKey["0"])
+}
+
+func TestKafkaConsumeRetriesUntilHandled(t *testing.T) {
+	cfg := newTestCluster(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	producer, err := NewKafkaBroker(cfg)
+	require.NoError(t, err)
+	defer producer.Close()
+	require.NoError(t, producer.EnsureTopic(ctx, "orders"))
+	require.NoError(t, producer.Publish(ctx, Message{Topic: "orders", Key: "1", ID: "a", Value: []byte(`{}`)}))
+
+	consumer, err := NewKafkaConsumer(cfg, "retry-test", "orders")
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	attempts := 0
+	consumeCtx, stop := context.WithCancel(ctx)
+	err = consumer.Consume(consumeCtx, func(ctx context.Context, msg Message) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("temporary failure")
+		}
+		stop()
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestKafkaConsumeSendsPoisonMessageToDeadLetter(t *testing.T) {
+	cfg := newTestCluster(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	producer, err := NewKafkaBroker(cfg)
+	require.NoError(t, err)
+	defer producer.Close()
+	require.NoError(t, producer.EnsureTopic(ctx, "orders"))
+	require.NoError(t, producer.Publish(ctx, Message{Topic: "orders", Key: "1", ID: "poison", Value: []byte(`{}`)}))
+	require.NoError(t, producer.Publish(ctx, Message{Topic: "orders", Key: "1", ID: "next", Value: []byte(`{}`)}))
+
+	deadLetters := NewMemoryBroker()
+	consumer, err := NewKafkaConsumer(cfg, "dlq-test", "orders")
+	require.NoError(t, err)
+	defer consumer.Close()
+	consumer.WithDeadLetter(deadLetters, "orders.dlq", 3)
+	consumer.retryBase = time.Millisecond
+
+	attempts := map[string]int{}
+	consumeCtx, stop := context.WithCancel(ctx)
+	err = consumer.Consume(consumeCtx, func(ctx context.Context, msg Message) error {
+		attempts[msg.ID]++
+		if msg.ID == "poison" {
+			return fmt.Errorf("handler failure")
+		}
+		stop()
+		return nil
+	})
+
+	// A mensagem com falha vai para o dead letter depois de 3 tentativas e libera a seguinte da partição
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, map[string]int{"poison": 3, "next": 1}, attempts)
+	published := deadLetters.Messages()
+	require.Len(t, published, 1)
+	assert.Equal(t, "orders.dlq", published[0].Topic)
+	assert.Equal(t, "poison", published[0].ID)
+	assert.Equal(t, "orders", published[0].Headers[HeaderDeadLetterTopic])
+	assert.Equal(t, "handler failure", published[0].Headers[HeaderDeadLetterError])
+}
@@ -0,0 +1,58 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryHistory limita quantas mensagens o MemoryBroker guarda; as mais antigas são descartadas
+const memoryHistory = 1000
+
+// MemoryBroker guarda as mensagens publicadas em memória. Serve para testes e para rodar
+// a API sem um barramento: as mensagens não saem do processo.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewMemoryBroker cria o broker em memória
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.messages = append(b.messages, msg)
+	if len(b.messages) > memoryHistory {
+		b.messages = b.messages[len(b.messages)-memoryHistory:]
+	}
+
+	return nil
+}
+
+// Messages retorna uma cópia das mensagens publicadas, na ordem de publicação
+func (b *MemoryBroker) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Message(nil), b.messages...)
+}
+
+// SetError faz as próximas publicações falharem com err, simulando um broker indisponível; nil restaura
+func (b *MemoryBroker) SetError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.err = err
+}
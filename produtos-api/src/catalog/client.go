@@ -0,0 +1,203 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var tracer = otel.Tracer("produtos-api/src/catalog")
+
+// responseLimit limita o corpo lido das respostas da API de produtos
+const responseLimit = 1 << 20
+
+// requestIDHeader é o header de correlação da API de produtos (middlewares.RequestIDHeader, que
+// não é importado para que os serviços possam depender deste pacote)
+const requestIDHeader = "X-Request-ID"
+
+var (
+	// ErrNotFound indica que o produto, ou as reservas do pedido, não existem
+	ErrNotFound = errors.New("catalog: not found")
+	// ErrConflict indica que as reservas do pedido já foram devolvidas ou enviadas
+	ErrConflict = errors.New("catalog: stock reservation closed")
+)
+
+// InsufficientStockError lista os produtos sem estoque suficiente para a reserva
+type InsufficientStockError struct {
+	ProductIDs []uint
+}
+
+func (e *InsufficientStockError) Error() string {
+	ids := make([]string, 0, len(e.ProductIDs))
+	for _, id := range e.ProductIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	return "catalog: insufficient stock for products " + strings.Join(ids, ", ")
+}
+
+// StatusError é uma resposta de erro não mapeada da API de produtos, no formato RFC 7807
+type StatusError struct {
+	Status int
+	Code   string
+	Title  string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("catalog: unexpected status %d (%s): %s", e.Status, e.Code, e.Title)
+}
+
+// Config define o endereço e as credenciais usadas nas chamadas para a API de produtos.
+// APIKey é enviada no header X-API-Key; sem ela, Token é enviado como Bearer.
+type Config struct {
+	BaseURL string
+	APIKey  string
+	Token   string
+	Timeout time.Duration
+}
+
+// Client chama a API de produtos, propagando o trace context e o X-Request-ID da requisição de origem
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+// NewClient cria o cliente da API de produtos
+func NewClient(config Config) *Client {
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Client{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// GetProduct busca o produto; retorna ErrNotFound se ele não existir ou estiver na lixeira
+func (c *Client) GetProduct(ctx context.Context, id uint) (*models.Product, error) {
+	var product models.Product
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/products/%d", id), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ReserveStock reserva o estoque de todos os itens do pedido, ou de nenhum; retorna *InsufficientStockError
+// com os produtos em falta. Repetir a reserva de um pedido é seguro e retorna as reservas existentes.
+func (c *Client) ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	if err := c.do(ctx, http.MethodPost, "/stock-reservations", request, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ReleaseStock devolve ao estoque as reservas do pedido; pedidos sem reservas ou já liberados não são erro
+func (c *Client) ReleaseStock(ctx context.Context, orderID uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/stock-reservations/%d", orderID), nil, nil)
+}
+
+// CommitStock torna definitivas as reservas do pedido; retorna ErrConflict se elas já foram devolvidas
+func (c *Client) CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/stock-reservations/%d/commit", orderID), nil, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// problem é o subconjunto do application/problem+json usado para mapear os erros
+type problem struct {
+	Title  string `json:"title"`
+	Code   string `json:"code"`
+	Errors []struct {
+		Field string `json:"field"`
+		Code  string `json:"code"`
+		Param string `json:"param"`
+	} `json:"errors"`
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	ctx, span := tracer.Start(ctx, "catalog "+method+" "+path)
+	defer span.End()
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if in != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.APIKey != "" {
+		request.Header.Set(auth.APIKeyHeader, c.config.APIKey)
+	} else if c.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		request.Header.Set(requestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer response.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	reader := io.LimitReader(response.Body, responseLimit)
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		if out == nil || response.StatusCode == http.StatusNoContent {
+			io.Copy(io.Discard, reader)
+			return nil
+		}
+		return json.NewDecoder(reader).Decode(out)
+	}
+
+	err = problemError(response.StatusCode, reader)
+	span.RecordError(err)
+	return err
+}
+
+// problemError converte a resposta de erro nos erros do pacote
+func problemError(status int, body io.Reader) error {
+	var p problem
+	json.NewDecoder(body).Decode(&p)
+
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, p.Title)
+	case status == http.StatusConflict && p.Code == "insufficient_stock":
+		insufficient := &InsufficientStockError{}
+		for _, fe := range p.Errors {
+			if id, err := strconv.ParseUint(fe.Param, 10, 64); err == nil && fe.Code == "insufficient_stock" {
+				insufficient.ProductIDs = append(insufficient.ProductIDs, uint(id))
+			}
+		}
+		return insufficient
+	case status == http.StatusConflict && p.Code == "stock_reservation_closed":
+		return fmt.Errorf("%w: %s", ErrConflict, p.Title)
+	}
+
+	return &StatusError{Status: status, Code: p.Code, Title: p.Title}
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+)
+
+// APIKeyController is a struct that defines the API key controller
+type APIKeyController struct {
+	service services.APIKeyService
+}
+
+// NewAPIKeyController is a function that creates a new API key controller
+func NewAPIKeyController(service services.APIKeyService) *APIKeyController {
+	return &APIKeyController{service: service}
+}
+
+// CreateAPIKey Cria uma chave de API
+// @Summary Cria uma chave de API
+// @Description Cria uma chave de API para um cliente de máquina. A chave só é exibida nesta resposta; envie-a no header X-API-Key
+// @Tags chaves de API
+// @Accept json
+// @Produce json
+// @Param apiKey body models.APIKey true "Name, scopes and optional expires_at"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /api-keys [post]
+func (kc *APIKeyController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	issued, err := kc.service.CreateAPIKey(r.Context(), &key)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(issued)
+}
+
+// GetAPIKeys Lista as chaves de API
+// @Summary Lista as chaves de API
+// @Description Lista as chaves de API com escopos, expiração e último uso; as chaves em si não são retornadas
+// @Tags chaves de API
+// @Produce json
+// @Success 200 {object} []models.APIKey
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /api-keys [get]
+func (kc *APIKeyController) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := kc.service.GetAPIKeys(r.Context())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RotateAPIKey Gera uma nova chave de API
+// @Summary Gera uma nova chave de API
+// @Description Substitui a chave mantendo nome e escopos; a chave anterior deixa de ser aceita imediatamente
+// @Tags chaves de API
+// @Produce json
+// @Param id path int true "ID da chave de API"
+// @Success 200 {object} models.IssuedAPIKey
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /api-keys/{id}/rotate [post]
+func (kc *APIKeyController) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	issued, err := kc.service.RotateAPIKey(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(issued)
+}
+
+// RevokeAPIKey Revoga uma chave de API
+// @Summary Revoga uma chave de API
+// @Description Revoga a chave; o cadastro é mantido para consulta
+// @Tags chaves de API
+// @Produce json
+// @Param id path int true "ID da chave de API"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /api-keys/{id} [delete]
+func (kc *APIKeyController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	if err := kc.service.RevokeAPIKey(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
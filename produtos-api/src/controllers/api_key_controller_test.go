@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.IssuedAPIKey, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*models.IssuedAPIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id uint) (*models.IssuedAPIKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.IssuedAPIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateAPIKeyController(t *testing.T) {
+	mockService := new(MockAPIKeyService)
+	controller := NewAPIKeyController(mockService)
+
+	key := &models.APIKey{Name: "orders-service", Scopes: []string{"products:write"}}
+	mockService.On("CreateAPIKey", mock.Anything, key).Return(&models.IssuedAPIKey{
+		APIKey: models.APIKey{ID: 1, Name: "orders-service", Prefix: "0a1b2c3d4e5f", Hash: "secret-hash", Scopes: key.Scopes},
+		Key:    "pak_0a1b2c3d4e5f_plain",
+	}, nil)
+
+	body := `{"name":"orders-service","scopes":["products:write"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(controller.CreateAPIKey).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"key":"pak_0a1b2c3d4e5f_plain"`)
+	assert.NotContains(t, rr.Body.String(), "secret-hash")
+	mockService.AssertExpectations(t)
+}
+
+func TestRotateAPIKeyControllerRevoked(t *testing.T) {
+	mockService := new(MockAPIKeyService)
+	controller := NewAPIKeyController(mockService)
+
+	mockService.On("RotateAPIKey", mock.Anything, uint(1)).Return((*models.IssuedAPIKey)(nil),
+		services.ConflictError(services.CodeAPIKeyRevoked, "API key has been revoked", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api-keys/{id}/rotate", controller.RotateAPIKey).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/api-keys/1/rotate", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"api_key_revoked"`)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"produtos-api/src/repositories"
+	"produtos-api/src/services"
+)
+
+// AuditController is a struct that defines the audit controller
+type AuditController struct {
+	service services.AuditService
+}
+
+// NewAuditController is a function that creates a new audit controller
+func NewAuditController(service services.AuditService) *AuditController {
+	return &AuditController{service: service}
+}
+
+// GetAuditRecords Consulta o registro de auditoria
+// @Summary Consulta o registro de auditoria
+// @Description Lista as mudanças do catálogo em ordem cronológica, com ator, request ID e diff por campo. Use after_id com o último ID recebido para paginar
+// @Tags auditoria
+// @Produce json
+// @Param entity query string false "Tipo da entidade, ex: product"
+// @Param entity_id query int false "ID da entidade"
+// @Param actor query string false "Ator da mudança"
+// @Param from query string false "Início do intervalo (RFC 3339), inclusivo"
+// @Param to query string false "Fim do intervalo (RFC 3339), exclusivo"
+// @Param after_id query int false "Retorna registros com ID maior que o informado"
+// @Param limit query int false "Quantidade máxima de registros (padrão 100, máximo 1000)"
+// @Success 200 {object} []models.AuditRecord
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /audit [get]
+func (ac *AuditController) GetAuditRecords(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	records, err := ac.service.FindAuditRecords(r.Context(), filter)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
+
+// VerifyAuditChain Verifica a integridade do registro de auditoria
+// @Summary Verifica a integridade do registro de auditoria
+// @Description Recalcula a cadeia de hashes e informa o primeiro registro alterado ou removido
+// @Tags auditoria
+// @Produce json
+// @Success 200 {object} audit.Verification
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /audit/verify [get]
+func (ac *AuditController) VerifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	result, err := ac.service.VerifyAuditChain(r.Context())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseAuditFilter converte os parâmetros da consulta, reportando todos os inválidos
+func parseAuditFilter(query url.Values) (repositories.AuditFilter, error) {
+	filter := repositories.AuditFilter{EntityType: query.Get("entity"), Actor: query.Get("actor")}
+	var fields []services.FieldError
+
+	parseUint := func(name string) uint {
+		value := query.Get(name)
+		if value == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			fields = append(fields, invalidParam(name))
+		}
+		return uint(parsed)
+	}
+	parseTime := func(name string) time.Time {
+		value := query.Get(name)
+		if value == "" {
+			return time.Time{}
+		}
+		parsed, err := parseTimestamp(value)
+		if err != nil {
+			fields = append(fields, invalidParam(name))
+		}
+		return parsed
+	}
+
+	filter.EntityID = parseUint("entity_id")
+	filter.AfterID = parseUint("after_id")
+	filter.Limit = int(parseUint("limit"))
+	filter.From = parseTime("from")
+	filter.To = parseTime("to")
+
+	if len(fields) > 0 {
+		return filter, services.ValidationError(fields...)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"produtos-api/src/audit"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) FindAuditRecords(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditRecord, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditRecord), args.Error(1)
+}
+
+func (m *MockAuditService) VerifyAuditChain(ctx context.Context) (audit.Verification, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(audit.Verification), args.Error(1)
+}
+
+func TestGetAuditRecordsController(t *testing.T) {
+	mockService := new(MockAuditService)
+	controller := NewAuditController(mockService)
+
+	filter := repositories.AuditFilter{
+		EntityType: "product",
+		EntityID:   7,
+		Actor:      "maria",
+		From:       time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mockService.On("FindAuditRecords", mock.Anything, filter).Return([]models.AuditRecord{
+		{ID: 1, EntityType: "product", EntityID: 7, Action: "update", Actor: "maria",
+			Changes: []models.FieldChange{{Field: "price", Before: 2.5, After: 3.5}}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit?entity=product&entity_id=7&actor=maria&from=2026-10-01T00:00:00Z", nil)
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(controller.GetAuditRecords).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `{"field":"price","before":2.5,"after":3.5}`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAuditRecordsControllerInvalidQuery(t *testing.T) {
+	mockService := new(MockAuditService)
+	controller := NewAuditController(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit?entity_id=abc&from=yesterday", nil)
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(controller.GetAuditRecords).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"entity_id"`)
+	assert.Contains(t, rr.Body.String(), `"field":"from"`)
+	mockService.AssertNotCalled(t, "FindAuditRecords", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+)
+
+// CustomerController is a struct that defines the customer controller of the clients service
+type CustomerController struct {
+	service services.CustomerService
+}
+
+// NewCustomerController is a function that creates a new customer controller
+func NewCustomerController(service services.CustomerService) *CustomerController {
+	return &CustomerController{service: service}
+}
+
+// CreateCustomer Cadastra um cliente
+// @Summary Cadastra um cliente
+// @Description Cadastra o cliente com CPF ou CNPJ (com ou sem pontuação), validando os dígitos verificadores. Quem cadastra é o titular dos dados e pode consultar, corrigir, exportar e eliminar o cadastro; cada titular cadastra um cliente, exceto editores. O documento é único por titular. Os consentimentos começam negados
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param customer body models.CustomerRequest true "Name, document, email and phone"
+// @Success 201 {object} models.Customer
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers [post]
+func (cc *CustomerController) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var request models.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	customer, err := cc.service.CreateCustomer(r.Context(), &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// GetCustomers Lista os clientes
+// @Summary Lista os clientes
+// @Description Lista os clientes pela ordem de cadastro, com os endereços
+// @Tags clientes
+// @Produce json
+// @Param document query string false "CPF ou CNPJ, com ou sem pontuação"
+// @Param email query string false "Email do cliente"
+// @Param limit query int false "Quantidade máxima de clientes (padrão 50, máximo 500)"
+// @Success 200 {object} []models.Customer
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers [get]
+func (cc *CustomerController) GetCustomers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCustomerFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	customers, err := cc.service.GetCustomers(r.Context(), filter)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customers)
+}
+
+// GetCustomerByID Busca um cliente
+// @Summary Busca um cliente
+// @Description Busca o cliente e os seus endereços. Clientes de outros titulares respondem 404, exceto para editores
+// @Tags clientes
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Success 200 {object} models.Customer
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id} [get]
+func (cc *CustomerController) GetCustomerByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	customer, err := cc.service.GetCustomerByID(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customer)
+}
+
+// UpdateCustomer Corrige os dados de um cliente
+// @Summary Corrige os dados de um cliente
+// @Description Substitui o nome, o documento, o email e o telefone do cliente
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Param customer body models.CustomerRequest true "Name, document, email and phone"
+// @Success 200 {object} models.Customer
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id} [put]
+func (cc *CustomerController) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var request models.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	customer, err := cc.service.UpdateCustomer(r.Context(), id, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customer)
+}
+
+// EraseCustomer Elimina os dados pessoais de um cliente
+// @Summary Elimina os dados pessoais de um cliente
+// @Description Apaga os endereços e anonimiza o cadastro, mantendo apenas o ID referenciado pelos pedidos e o histórico de consentimentos, prova das concessões e revogações. Depois da eliminação o cliente responde 404 e o documento pode ser cadastrado de novo
+// @Tags clientes
+// @Param id path int true "ID do cliente"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id} [delete]
+func (cc *CustomerController) EraseCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	if err := cc.service.EraseCustomer(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportCustomer Exporta os dados pessoais de um cliente
+// @Summary Exporta os dados pessoais de um cliente
+// @Description Entrega ao titular uma cópia dos seus dados: o cadastro, os endereços e o histórico de consentimentos, como anexo JSON
+// @Tags clientes
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Success 200 {object} models.CustomerExport
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id}/export [get]
+func (cc *CustomerController) ExportCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	export, err := cc.service.ExportCustomer(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%d.json"`, id))
+	json.NewEncoder(w).Encode(export)
+}
+
+// UpdateConsents Concede ou revoga consentimentos
+// @Summary Concede ou revoga consentimentos
+// @Description Atualiza os consentimentos informados; os omitidos não mudam. Cada mudança é registrada no histórico, exportado com os dados do cliente. Responde 409 se os consentimentos mudarem ao mesmo tempo por outra requisição
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Param consents body models.ConsentRequest true "marketing_consent and data_sharing_consent"
+// @Success 200 {object} models.Customer
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id}/consents [put]
+func (cc *CustomerController) UpdateConsents(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var request models.ConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	customer, err := cc.service.UpdateConsents(r.Context(), id, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customer)
+}
+
+// AddAddress Cadastra um endereço do cliente
+// @Summary Cadastra um endereço do cliente
+// @Description Cadastra o endereço com UF e CEP (com ou sem pontuação). O primeiro endereço, ou o enviado com default, passa a ser o padrão. Cada cliente tem até 20 endereços
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Param address body models.AddressRequest true "Address"
+// @Success 201 {object} models.CustomerAddress
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id}/addresses [post]
+func (cc *CustomerController) AddAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var request models.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	address, err := cc.service.AddAddress(r.Context(), id, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(address)
+}
+
+// UpdateAddress Atualiza um endereço do cliente
+// @Summary Atualiza um endereço do cliente
+// @Description Substitui os dados do endereço. Com default, ele passa a ser o padrão; o padrão atual só deixa de ser quando outro é marcado
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Param address path int true "ID do endereço"
+// @Param body body models.AddressRequest true "Address"
+// @Success 200 {object} models.CustomerAddress
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id}/addresses/{address} [put]
+func (cc *CustomerController) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	addressID, err := parseUintVar(r, "address")
+	if err != nil {
+		writeProblem(w, r, services.ValidationError(invalidParam("address")))
+		return
+	}
+
+	var request models.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	address, err := cc.service.UpdateAddress(r.Context(), id, addressID, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(address)
+}
+
+// DeleteAddress Remove um endereço do cliente
+// @Summary Remove um endereço do cliente
+// @Description Remove o endereço; se ele era o padrão, o endereço mais antigo restante passa a ser
+// @Tags clientes
+// @Param id path int true "ID do cliente"
+// @Param address path int true "ID do endereço"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /customers/{id}/addresses/{address} [delete]
+func (cc *CustomerController) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	addressID, err := parseUintVar(r, "address")
+	if err != nil {
+		writeProblem(w, r, services.ValidationError(invalidParam("address")))
+		return
+	}
+
+	if err := cc.service.DeleteAddress(r.Context(), id, addressID); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseCustomerFilter(query url.Values) (models.CustomerFilter, error) {
+	filter := models.CustomerFilter{Document: query.Get("document"), Email: query.Get("email")}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, services.ValidationError(invalidParam("limit"))
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomerService struct {
+	mock.Mock
+}
+
+func (m *MockCustomerService) CreateCustomer(ctx context.Context, request *models.CustomerRequest) (*models.Customer, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) GetCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) UpdateCustomer(ctx context.Context, id uint, request *models.CustomerRequest) (*models.Customer, error) {
+	args := m.Called(ctx, id, request)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) UpdateConsents(ctx context.Context, id uint, request *models.ConsentRequest) (*models.Customer, error) {
+	args := m.Called(ctx, id, request)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) AddAddress(ctx context.Context, customerID uint, request *models.AddressRequest) (*models.CustomerAddress, error) {
+	args := m.Called(ctx, customerID, request)
+	return args.Get(0).(*models.CustomerAddress), args.Error(1)
+}
+
+func (m *MockCustomerService) UpdateAddress(ctx context.Context, customerID, addressID uint, request *models.AddressRequest) (*models.CustomerAddress, error) {
+	args := m.Called(ctx, customerID, addressID, request)
+	return args.Get(0).(*models.CustomerAddress), args.Error(1)
+}
+
+func (m *MockCustomerService) DeleteAddress(ctx context.Context, customerID, addressID uint) error {
+	args := m.Called(ctx, customerID, addressID)
+	return args.Error(0)
+}
+
+func (m *MockCustomerService) ExportCustomer(ctx context.Context, id uint) (*models.CustomerExport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.CustomerExport), args.Error(1)
+}
+
+func (m *MockCustomerService) EraseCustomer(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newCustomerRouter(service services.CustomerService) *mux.Router {
+	controller := NewCustomerController(service)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/customers", controller.CreateCustomer).Methods(http.MethodPost)
+	r.HandleFunc("/customers", controller.GetCustomers).Methods(http.MethodGet)
+	r.HandleFunc("/customers/{id}", controller.GetCustomerByID).Methods(http.MethodGet)
+	r.HandleFunc("/customers/{id}", controller.UpdateCustomer).Methods(http.MethodPut)
+	r.HandleFunc("/customers/{id}", controller.EraseCustomer).Methods(http.MethodDelete)
+	r.HandleFunc("/customers/{id}/export", controller.ExportCustomer).Methods(http.MethodGet)
+	r.HandleFunc("/customers/{id}/consents", controller.UpdateConsents).Methods(http.MethodPut)
+	r.HandleFunc("/customers/{id}/addresses", controller.AddAddress).Methods(http.MethodPost)
+	r.HandleFunc("/customers/{id}/addresses/{address}", controller.UpdateAddress).Methods(http.MethodPut)
+	r.HandleFunc("/customers/{id}/addresses/{address}", controller.DeleteAddress).Methods(http.MethodDelete)
+	return r
+}
+
+func TestCreateCustomerController(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("CreateCustomer", mock.Anything, &models.CustomerRequest{Name: "Maria Souza", Document: "529.982.247-25"}).
+		Return(&models.Customer{ID: 7, Name: "Maria Souza", Document: "52998224725", DocumentType: models.DocumentCPF, Addresses: []models.CustomerAddress{}}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"Maria Souza","document":"529.982.247-25"}`))
+	rr := httptest.NewRecorder()
+
+	newCustomerRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"document_type":"cpf"`)
+	assert.Contains(t, rr.Body.String(), `"addresses":[]`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateCustomerControllerInvalidDocument(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("CreateCustomer", mock.Anything, mock.Anything).Return((*models.Customer)(nil), services.ValidationError(
+		services.FieldError{Field: "document", Code: "cpf_cnpj", Message: "document must be a valid CPF or CNPJ"},
+	))
+
+	req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"Maria","document":"123"}`))
+	req.Header.Set("Accept-Language", "pt-BR")
+	rr := httptest.NewRecorder()
+
+	newCustomerRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "documento deve ser um CPF ou CNPJ válido")
+}
+
+func TestGetCustomersController(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("GetCustomers", mock.Anything, models.CustomerFilter{Email: "maria@example.com", Limit: 10}).
+		Return([]models.Customer{{ID: 7}}, nil)
+	router := newCustomerRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/customers?email=maria@example.com&limit=10", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":7`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/customers?limit=dez", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNumberOfCalls(t, "GetCustomers", 1)
+}
+
+func TestExportCustomerController(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("ExportCustomer", mock.Anything, uint(7)).Return(&models.CustomerExport{
+		Customer: models.Customer{ID: 7, Name: "Maria Souza"},
+		Consents: []models.ConsentRecord{{ID: 1, Purpose: models.ConsentMarketing, Granted: true}},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	newCustomerRouter(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/customers/7/export", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename="customer-7.json"`, rr.Header().Get("Content-Disposition"))
+	assert.Contains(t, rr.Body.String(), `"purpose":"marketing"`)
+}
+
+func TestEraseCustomerController(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("EraseCustomer", mock.Anything, uint(7)).Return(nil)
+	mockService.On("EraseCustomer", mock.Anything, uint(8)).Return(services.NotFoundError(services.CodeCustomerNotFound, "Customer not found", nil))
+	router := newCustomerRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/customers/7", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/customers/8", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"customer_not_found"`)
+}
+
+func TestUpdateConsentsController(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("UpdateConsents", mock.Anything, uint(7), mock.MatchedBy(func(request *models.ConsentRequest) bool {
+		return request.MarketingConsent != nil && *request.MarketingConsent && request.DataSharingConsent == nil
+	})).Return(&models.Customer{ID: 7, MarketingConsent: true}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/customers/7/consents", strings.NewReader(`{"marketing_consent":true}`))
+	rr := httptest.NewRecorder()
+
+	newCustomerRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"marketing_consent":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCustomerAddressControllers(t *testing.T) {
+	mockService := new(MockCustomerService)
+	mockService.On("AddAddress", mock.Anything, uint(7), mock.Anything).
+		Return(&models.CustomerAddress{ID: 3, CustomerID: 7, State: "SP", PostalCode: "01310100", Default: true}, nil)
+	mockService.On("UpdateAddress", mock.Anything, uint(7), uint(3), mock.Anything).
+		Return(&models.CustomerAddress{ID: 3, CustomerID: 7, Number: "1001"}, nil)
+	mockService.On("DeleteAddress", mock.Anything, uint(7), uint(4)).
+		Return(services.NotFoundError(services.CodeAddressNotFound, "Address not found", nil))
+	router := newCustomerRouter(mockService)
+
+	body := `{"street":"Av. Paulista","number":"1000","city":"São Paulo","state":"SP","postal_code":"01310-100"}`
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/customers/7/addresses", strings.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"default":true`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/customers/7/addresses/3", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/customers/7/addresses/4", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"address_not_found"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/customers/7/addresses/x", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"address"`)
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"produtos-api/src/services"
+)
+
+// HealthController is a struct that defines the health controller
+type HealthController struct {
+	service services.HealthService
+}
+
+// NewHealthController is a function that creates a new health controller
+func NewHealthController(service services.HealthService) *HealthController {
+	return &HealthController{service: service}
+}
+
+// Liveness Indica se o processo está vivo
+// @Summary Indica se o processo está vivo
+// @Description Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (hc *HealthController) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": services.StatusUp})
+}
+
+// Readiness Indica se a aplicação está pronta para receber tráfego
+// @Summary Indica se a aplicação está pronta para receber tráfego
+// @Description Verifica banco de dados, migrações pendentes e workers, retornando a latência de cada dependência
+// @Tags health
+// @Produce json
+// @Success 200 {object} services.HealthReport
+// @Failure 503 {object} services.HealthReport
+// @Router /readyz [get]
+func (hc *HealthController) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := hc.service.Readiness(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Healthy() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"produtos-api/src/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Readiness(ctx context.Context) services.HealthReport {
+	args := m.Called(ctx)
+	return args.Get(0).(services.HealthReport)
+}
+
+func (m *MockHealthService) RegisterCheck(name string, check services.HealthCheck) {
+	m.Called(name, check)
+}
+
+func (m *MockHealthService) RegisterWorker(name string) *services.WorkerState {
+	args := m.Called(name)
+	return args.Get(0).(*services.WorkerState)
+}
+
+func (m *MockHealthService) Drain() {
+	m.Called()
+}
+
+func (m *MockHealthService) Draining() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockHealthService) Drained() <-chan struct{} {
+	args := m.Called()
+	return args.Get(0).(chan struct{})
+}
+
+func TestLivenessController(t *testing.T) {
+	controller := NewHealthController(new(MockHealthService))
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr := httptest.NewRecorder()
+
+	controller.Liveness(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"up"`)
+}
+
+func TestReadinessController(t *testing.T) {
+	mockService := new(MockHealthService)
+	controller := NewHealthController(mockService)
+
+	mockService.On("Readiness", mock.Anything).Return(services.HealthReport{
+		Status:       services.StatusUp,
+		Dependencies: map[string]services.DependencyStatus{"database": {Status: services.StatusUp, LatencyMs: 0.5}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	controller.Readiness(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"database"`)
+	assert.Contains(t, rr.Body.String(), `"latency_ms":0.5`)
+	mockService.AssertExpectations(t)
+}
+
+func TestReadinessControllerUnavailable(t *testing.T) {
+	mockService := new(MockHealthService)
+	controller := NewHealthController(mockService)
+
+	mockService.On("Readiness", mock.Anything).Return(services.HealthReport{
+		Status:       services.StatusDraining,
+		Dependencies: map[string]services.DependencyStatus{},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	controller.Readiness(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"draining"`)
+	mockService.AssertExpectations(t)
+}
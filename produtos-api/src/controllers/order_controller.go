@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+)
+
+// OrderController is a struct that defines the order controller of the orders service
+type OrderController struct {
+	service services.OrderService
+}
+
+// NewOrderController is a function that creates a new order controller
+func NewOrderController(service services.OrderService) *OrderController {
+	return &OrderController{service: service}
+}
+
+// CreateOrder Faz o checkout de um pedido
+// @Summary Faz o checkout de um pedido
+// @Description Cria o pedido com o nome e o preço atuais de cada produto e reserva o estoque de todos os itens na API de produtos, ou de nenhum. Sem estoque suficiente, responde 409 insufficient_stock com o ID de cada produto em falta no param dos erros, e nenhum pedido é criado
+// @Tags pedidos
+// @Accept json
+// @Produce json
+// @Param order body models.OrderRequest true "Customer and items"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders [post]
+func (oc *OrderController) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var request models.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	order, err := oc.service.Checkout(r.Context(), &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetOrders Lista os pedidos
+// @Summary Lista os pedidos
+// @Description Lista os pedidos do mais recente para o mais antigo, com os itens
+// @Tags pedidos
+// @Produce json
+// @Param status query string false "pending, paid, shipped ou cancelled"
+// @Param customer_id query int false "ID do cliente"
+// @Param limit query int false "Quantidade máxima de pedidos (padrão 50, máximo 500)"
+// @Success 200 {object} []models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders [get]
+func (oc *OrderController) GetOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	orders, err := oc.service.GetOrders(r.Context(), filter)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(orders)
+}
+
+// GetOrderByID Busca um pedido
+// @Summary Busca um pedido
+// @Description Busca o pedido e os seus itens
+// @Tags pedidos
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders/{id} [get]
+func (oc *OrderController) GetOrderByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	order, err := oc.service.GetOrderByID(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+// PayOrder Confirma o pagamento de um pedido
+// @Summary Confirma o pagamento de um pedido
+// @Description Passa o pedido de pending para paid. Outros status respondem 409 invalid_order_transition
+// @Tags pedidos
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders/{id}/pay [post]
+func (oc *OrderController) PayOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	order, err := oc.service.PayOrder(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+// ShipOrder Envia um pedido
+// @Summary Envia um pedido
+// @Description Passa o pedido de paid para shipped, tornando as reservas de estoque definitivas. Outros status respondem 409 invalid_order_transition
+// @Tags pedidos
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders/{id}/ship [post]
+func (oc *OrderController) ShipOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	order, err := oc.service.ShipOrder(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+// CancelOrder Cancela um pedido
+// @Summary Cancela um pedido
+// @Description Cancela um pedido pending ou paid e devolve o estoque reservado. Se a API de produtos estiver indisponível, o pedido é cancelado com stock_released=false e o estoque é devolvido em segundo plano
+// @Tags pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Param cancellation body models.OrderCancelRequest false "Reason"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /orders/{id}/cancel [post]
+func (oc *OrderController) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	// O corpo é opcional: sem ele, o pedido é cancelado sem motivo
+	var request models.OrderCancelRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeProblem(w, r, invalidBody(err))
+			return
+		}
+	}
+
+	order, err := oc.service.CancelOrder(r.Context(), id, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+func parseOrderFilter(query url.Values) (models.OrderFilter, error) {
+	filter := models.OrderFilter{Status: query.Get("status")}
+	var fields []services.FieldError
+
+	parseUint := func(name string) uint {
+		value := query.Get(name)
+		if value == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			fields = append(fields, invalidParam(name))
+		}
+		return uint(parsed)
+	}
+
+	filter.CustomerID = parseUint("customer_id")
+	filter.Limit = int(parseUint("limit"))
+
+	if len(fields) > 0 {
+		return filter, services.ValidationError(fields...)
+	}
+
+	return filter, nil
+}
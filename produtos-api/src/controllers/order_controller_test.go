@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) Checkout(ctx context.Context, request *models.OrderRequest) (*models.Order, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrderByID(ctx context.Context, id uint) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) PayOrder(ctx context.Context, id uint) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ShipOrder(ctx context.Context, id uint) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) CancelOrder(ctx context.Context, id uint, request *models.OrderCancelRequest) (*models.Order, error) {
+	args := m.Called(ctx, id, request)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) Compensate(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func newOrderRouter(service services.OrderService) *mux.Router {
+	controller := NewOrderController(service)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/orders", controller.CreateOrder).Methods(http.MethodPost)
+	r.HandleFunc("/orders", controller.GetOrders).Methods(http.MethodGet)
+	r.HandleFunc("/orders/{id}", controller.GetOrderByID).Methods(http.MethodGet)
+	r.HandleFunc("/orders/{id}/pay", controller.PayOrder).Methods(http.MethodPost)
+	r.HandleFunc("/orders/{id}/ship", controller.ShipOrder).Methods(http.MethodPost)
+	r.HandleFunc("/orders/{id}/cancel", controller.CancelOrder).Methods(http.MethodPost)
+	return r
+}
+
+func TestCreateOrderController(t *testing.T) {
+	mockService := new(MockOrderService)
+	request := &models.OrderRequest{CustomerID: 7, Items: []models.OrderItemRequest{{ProductID: 1, Quantity: 2}}}
+	mockService.On("Checkout", mock.Anything, request).Return(&models.Order{
+		ID: 42, CustomerID: 7, Status: models.OrderPending, Total: 5, StockReserved: true,
+		Items: []models.OrderItem{{ID: 1, OrderID: 42, ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"customer_id":7,"items":[{"product_id":1,"quantity":2}]}`))
+	rr := httptest.NewRecorder()
+
+	newOrderRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"pending"`)
+	assert.Contains(t, rr.Body.String(), `"name":"Caneta"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateOrderControllerUnavailableCatalog(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("Checkout", mock.Anything, mock.Anything).
+		Return((*models.Order)(nil), services.UnavailableError(services.CodeCatalogUnavailable, "Products API is unavailable", nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"items":[{"product_id":1,"quantity":2}]}`))
+	req.Header.Set("Accept-Language", "pt-BR")
+	rr := httptest.NewRecorder()
+
+	newOrderRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), "A API de produtos está indisponível")
+}
+
+func TestGetOrdersController(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("GetOrders", mock.Anything, models.OrderFilter{Status: "paid", CustomerID: 7, Limit: 10}).
+		Return([]models.Order{{ID: 42, Status: models.OrderPaid}}, nil)
+	router := newOrderRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders?status=paid&customer_id=7&limit=10", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":42`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders?customer_id=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"customer_id"`)
+	mockService.AssertNumberOfCalls(t, "GetOrders", 1)
+}
+
+func TestOrderTransitionControllers(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("PayOrder", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPaid}, nil)
+	mockService.On("ShipOrder", mock.Anything, uint(42)).
+		Return((*models.Order)(nil), services.ConflictError(services.CodeInvalidOrderTransition, "Order cannot be shipped from status pending", nil))
+	mockService.On("CancelOrder", mock.Anything, uint(42), &models.OrderCancelRequest{Reason: "desistência"}).
+		Return(&models.Order{ID: 42, Status: models.OrderCancelled, CancelReason: "desistência", StockReleased: true}, nil)
+	mockService.On("CancelOrder", mock.Anything, uint(43), &models.OrderCancelRequest{}).
+		Return(&models.Order{ID: 43, Status: models.OrderCancelled}, nil)
+	router := newOrderRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders/42/pay", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"paid"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders/42/ship", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"invalid_order_transition"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders/42/cancel", strings.NewReader(`{"reason":"desistência"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"stock_released":true`)
+
+	// O motivo do cancelamento é opcional
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders/43/cancel", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetOrderByIDController(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("GetOrderByID", mock.Anything, uint(41)).
+		Return((*models.Order)(nil), services.NotFoundError(services.CodeOrderNotFound, "Order not found", nil))
+	router := newOrderRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/41", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"order_not_found"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/0", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
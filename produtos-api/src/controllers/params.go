@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+)
+
+// timestampLayouts são os formatos aceitos em parâmetros de data: RFC 3339, com ou sem os segundos
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00"}
+
+// parseID lê o parâmetro {id} da rota, aceitando apenas inteiros positivos
+func parseID(r *http.Request) (uint, error) {
+	return parseUintVar(r, "id")
+}
+
+// parseUintVar lê uma variável de rota que deve ser um inteiro positivo
+func parseUintVar(r *http.Request, name string) (uint, error) {
+	value, err := strconv.ParseUint(mux.Vars(r)[name], 10, 0)
+	if err == nil && value == 0 {
+		err = strconv.ErrRange
+	}
+
+	return uint(value), err
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timestampLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+func invalidParam(name string) services.FieldError {
+	return services.FieldError{Field: name, Code: "invalid", Message: name + " is invalid"}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"produtos-api/src/i18n"
+	"produtos-api/src/logging"
+	"produtos-api/src/services"
+)
+
+// StatusClientClosedRequest é o código não padrão (popularizado pelo nginx) para requisições canceladas pelo cliente
+const StatusClientClosedRequest = 499
+
+// ProblemContentType é o media type das respostas de erro (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix forma o "type" de cada problema a partir do código estável do erro
+const problemTypePrefix = "urn:produtos-api:problem:"
+
+// Códigos de erro gerados pelos próprios controllers
+const (
+	CodeInvalidID           = "invalid_id"
+	CodeInvalidBody         = "invalid_body"
+	CodeRequestTimeout      = "request_timeout"
+	CodeClientClosedRequest = "client_closed_request"
+	CodeInternalError       = "internal_error"
+)
+
+var logger = logging.Logger("controllers")
+
+// Problem represents an error response in the RFC 7807 format
+// @Description Error response in the RFC 7807 (application/problem+json) format
+type Problem struct {
+	Type      string                `json:"type" example:"urn:produtos-api:problem:product_not_found"` // Problem type URI
+	Title     string                `json:"title" example:"Product not found"`                         // Short, human-readable summary
+	Status    int                   `json:"status" example:"404"`                                      // HTTP status code
+	Detail    string                `json:"detail,omitempty"`                                          // Occurrence-specific explanation
+	Instance  string                `json:"instance,omitempty" example:"/products/42"`                 // Request path
+	Code      string                `json:"code" example:"product_not_found"`                          // Stable error code
+	RequestID string                `json:"request_id,omitempty"`                                      // X-Request-ID of the request
+	Errors    []services.FieldError `json:"errors,omitempty"`                                          // Field-level validation details
+}
+
+// WriteProblem expõe o mapeador central de erros para os middlewares
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, r, err)
+}
+
+// writeProblem é o mapeador central de erros: converte o erro em application/problem+json,
+// registra a causa original no log e responde ao cliente apenas a mensagem sanitizada
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem, locale := resolveProblem(r, err)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("Content-Language", locale)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// resolveProblem converte o erro no problema localizado para o Accept-Language da requisição e registra
+// a causa original no log; retorna também o locale negociado
+func resolveProblem(r *http.Request, err error) (Problem, string) {
+	problem := problemFor(r, err)
+
+	level := slog.LevelWarn
+	if problem.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.Log(r.Context(), level, problem.Title, "status", problem.Status, "code", problem.Code, "error", err)
+
+	locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+	return localize(problem, locale), locale
+}
+
+func problemFor(r *http.Request, err error) Problem {
+	problem := Problem{
+		Status:    http.StatusInternalServerError,
+		Title:     "Internal server error",
+		Code:      CodeInternalError,
+		Instance:  r.URL.Path,
+		RequestID: logging.RequestID(r.Context()),
+	}
+
+	switch ctxErr := contextError(r, err); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		problem.Status, problem.Title, problem.Code = http.StatusGatewayTimeout, "Request timed out", CodeRequestTimeout
+	case errors.Is(ctxErr, context.Canceled):
+		problem.Status, problem.Title, problem.Code = StatusClientClosedRequest, "Client closed request", CodeClientClosedRequest
+	default:
+		if domainErr, ok := services.AsDomainError(err); ok {
+			problem.Status = statusFor(domainErr.Kind)
+			problem.Title = domainErr.Message
+			problem.Code = domainErr.Code
+			problem.Errors = domainErr.Fields
+		}
+	}
+
+	problem.Type = problemTypePrefix + problem.Code
+	return problem
+}
+
+// localize traduz o título e as mensagens de validação do problema para o locale negociado.
+// Códigos sem entrada no catálogo mantêm a mensagem original do erro.
+func localize(problem Problem, locale string) Problem {
+	if key := "problem." + problem.Code; i18n.Has(i18n.DefaultLocale, key) {
+		problem.Title = i18n.T(locale, key, nil)
+	}
+
+	fields := make([]services.FieldError, len(problem.Errors))
+	for i, field := range problem.Errors {
+		if key := "validation." + field.Code; i18n.Has(i18n.DefaultLocale, key) {
+			field.Message = i18n.T(locale, key, map[string]string{
+				"field": fieldLabel(locale, field.Field),
+				"param": field.Param,
+			})
+		}
+		fields[i] = field
+	}
+	if len(fields) > 0 {
+		problem.Errors = fields
+	}
+
+	return problem
+}
+
+func fieldLabel(locale, field string) string {
+	if key := "field." + field; i18n.Has(i18n.DefaultLocale, key) {
+		return i18n.T(locale, key, nil)
+	}
+
+	return field
+}
+
+// contextError retorna o erro de contexto presente na cadeia do erro. O contexto da requisição só é consultado
+// quando o próprio erro é de contexto (para distinguir o prazo expirado do cliente que desconectou) ou de
+// indisponibilidade; os demais erros de domínio mantêm o seu status mesmo depois do prazo.
+func contextError(r *http.Request, err error) error {
+	var chainErr error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		chainErr = context.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		chainErr = context.Canceled
+	default:
+		if domainErr, ok := services.AsDomainError(err); !ok || domainErr.Kind != services.KindUnavailable {
+			return nil
+		}
+	}
+
+	if ctxErr := r.Context().Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return chainErr
+}
+
+func statusFor(kind services.ErrorKind) int {
+	switch kind {
+	case services.KindNotFound:
+		return http.StatusNotFound
+	case services.KindValidation:
+		return http.StatusBadRequest
+	case services.KindConflict:
+		return http.StatusConflict
+	case services.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case services.KindUnauthorized:
+		return http.StatusUnauthorized
+	case services.KindForbidden:
+		return http.StatusForbidden
+	case services.KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// invalidID é o erro para IDs de rota que não são inteiros positivos
+func invalidID(err error) error {
+	return &services.DomainError{
+		Kind:    services.KindValidation,
+		Code:    CodeInvalidID,
+		Message: "Invalid ID",
+		Fields:  []services.FieldError{{Field: "id", Code: "invalid", Message: "id must be a positive integer"}},
+		Err:     err,
+	}
+}
+
+// invalidBody é o erro para corpos de requisição que não são JSON válido
+func invalidBody(err error) error {
+	return &services.DomainError{Kind: services.KindValidation, Code: CodeInvalidBody, Message: "Invalid input", Err: err}
+}
@@ -3,12 +3,9 @@ package controllers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"produtos-api/src/models"
 	"produtos-api/src/services"
-
-	"github.com/gorilla/mux"
 )
 
 // ProductController is a struct that defines the product controller
@@ -29,18 +26,21 @@ func NewProductController(service services.ProductService) *ProductController {
 // @Produce json
 // @Param product body models.Product true "Product data"
 // @Success 201 {object} models.Product
-// @Failure 400 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Failure 504 {object} controllers.Problem
 // @Router /products [post]
 func (pc *ProductController) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var product models.Product
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+		writeProblem(w, r, invalidBody(err))
 		return
 	}
 
-	if err := pc.service.CreateProduct(&product); err != nil {
-		http.Error(w, "Failed to create product", http.StatusInternalServerError)
+	if err := pc.service.CreateProduct(r.Context(), &product); err != nil {
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -57,14 +57,16 @@ func (pc *ProductController) CreateProduct(w http.ResponseWriter, r *http.Reques
 // @Param name query string false "Nome do produto"
 // @Param count query string false "Contagem de produtos"
 // @Success 200 {object} []models.Product
-// @Failure 500 {object} string
+// @Failure 500 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Failure 504 {object} controllers.Problem
 // @Router /products [get]
 func (pc *ProductController) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
 	if name != "" {
-		products, err := pc.service.GetProductByName(name)
+		products, err := pc.service.GetProductByName(r.Context(), name)
 		if err != nil {
-			http.Error(w, "Failed to retrieve products", http.StatusInternalServerError)
+			writeProblem(w, r, err)
 			return
 		}
 
@@ -74,16 +76,20 @@ func (pc *ProductController) GetAllProducts(w http.ResponseWriter, r *http.Reque
 
 	count := r.URL.Query().Get("count")
 	if count != "" {
-		count := pc.service.GetProductsCount()
+		count := pc.service.GetProductsCount(r.Context())
+		if err := r.Context().Err(); err != nil {
+			writeProblem(w, r, err)
+			return
+		}
 		response := map[string]int64{"count": count}
 
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	products, err := pc.service.GetAllProducts()
+	products, err := pc.service.GetAllProducts(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to retrieve products", http.StatusInternalServerError)
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -92,25 +98,39 @@ func (pc *ProductController) GetAllProducts(w http.ResponseWriter, r *http.Reque
 
 // GetProductByID Retorna um produto pelo ID
 // @Summary Retorna um produto pelo ID
-// @Description Retorna um produto pelo ID
+// @Description Retorna um produto pelo ID. Com as_of, retorna a versão vigente naquele instante, sem tradução
 // @Tags produtos
 // @Accept json
 // @Produce json
 // @Param id path int true "ID do produto"
+// @Param as_of query string false "Instante da consulta (RFC 3339), ex: 2026-01-01T00:00Z"
 // @Success 200 {object} models.Product
-// @Failure 400 {object} string
-// @Failure 404 {object} string
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Failure 504 {object} controllers.Problem
 // @Router /products/{id} [get]
 func (pc *ProductController) GetProductByID(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	id, err := parseID(r)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeProblem(w, r, invalidID(err))
 		return
 	}
 
-	product, err := pc.service.GetProductByID(uint(id))
+	var product *models.Product
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		asOf, parseErr := parseTimestamp(value)
+		if parseErr != nil {
+			writeProblem(w, r, services.ValidationError(invalidParam("as_of")))
+			return
+		}
+		product, err = pc.service.GetProductAsOf(r.Context(), id, asOf)
+	} else {
+		product, err = pc.service.GetProductByID(r.Context(), id)
+	}
 	if err != nil {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -126,25 +146,29 @@ func (pc *ProductController) GetProductByID(w http.ResponseWriter, r *http.Reque
 // @Param id path int true "ID do produto"
 // @Param product body models.Product true "Product data"
 // @Success 200 {object} models.Product
-// @Failure 400 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Failure 504 {object} controllers.Problem
 // @Router /products/{id} [put]
 func (pc *ProductController) UpdateProduct(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	id, err := parseID(r)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeProblem(w, r, invalidID(err))
 		return
 	}
 
 	var product models.Product
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+		writeProblem(w, r, invalidBody(err))
 		return
 	}
-	product.ID = uint(id)
+	product.ID = id
 
-	if err := pc.service.UpdateProduct(&product); err != nil {
-		http.Error(w, "Failed to update product", http.StatusInternalServerError)
+	if err := pc.service.UpdateProduct(r.Context(), &product); err != nil {
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -153,26 +177,142 @@ func (pc *ProductController) UpdateProduct(w http.ResponseWriter, r *http.Reques
 
 // DeleteProduct Deleta um produto
 // @Summary Deleta um produto
-// @Description Deleta um produto
+// @Description Move o produto para a lixeira, de onde pode ser restaurado até o fim do período de retenção
 // @Tags produtos
 // @Accept json
 // @Produce json
 // @Param id path int true "ID do produto"
 // @Success 204
-// @Failure 400 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Failure 504 {object} controllers.Problem
 // @Router /products/{id} [delete]
 func (pc *ProductController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	id, err := parseID(r)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeProblem(w, r, invalidID(err))
 		return
 	}
 
-	if err := pc.service.DeleteProduct(uint(id)); err != nil {
-		http.Error(w, "Failed to delete product", http.StatusInternalServerError)
+	if err := pc.service.DeleteProduct(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetTrash Retorna os produtos da lixeira
+// @Summary Retorna os produtos da lixeira
+// @Description Retorna os produtos excluídos que ainda podem ser restaurados, dos mais antigos para os mais recentes. Produtos na lixeira há mais que o período de retenção são removidos definitivamente
+// @Tags produtos
+// @Produce json
+// @Success 200 {object} []models.Product
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/trash [get]
+func (pc *ProductController) GetTrash(w http.ResponseWriter, r *http.Request) {
+	products, err := pc.service.GetDeletedProducts(r.Context())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(products)
+}
+
+// RestoreProduct Restaura um produto da lixeira
+// @Summary Restaura um produto da lixeira
+// @Description Restaura um produto excluído, junto com as suas traduções
+// @Tags produtos
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/restore [post]
+func (pc *ProductController) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	product, err := pc.service.RestoreProduct(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetRevisions Lista as versões de um produto
+// @Summary Lista as versões de um produto
+// @Description Lista todas as revisões do produto, da mais antiga para a mais recente, inclusive as remoções para a lixeira
+// @Tags produtos
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Success 200 {object} []models.ProductRevision
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/revisions [get]
+func (pc *ProductController) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	revisions, err := pc.service.GetProductRevisions(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// RevertProduct Reverte um produto para uma versão anterior
+// @Summary Reverte um produto para uma versão anterior
+// @Description Cria uma nova revisão com o conteúdo da revisão informada. Produtos na lixeira precisam ser restaurados antes
+// @Tags produtos
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Param revision path int true "Número da revisão"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/revisions/{revision}/revert [post]
+func (pc *ProductController) RevertProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+	revision, err := parseUintVar(r, "revision")
+	if err != nil {
+		writeProblem(w, r, services.ValidationError(invalidParam("revision")))
+		return
+	}
+
+	product, err := pc.service.RevertProduct(r.Context(), id, revision)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(product)
+}
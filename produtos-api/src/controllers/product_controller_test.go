@@ -1,63 +1,99 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"produtos-api/src/i18n"
 	"produtos-api/src/models"
+	"produtos-api/src/services"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockProductService struct {
 	mock.Mock
 }
 
-func (m *MockProductService) CreateProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *MockProductService) CreateProduct(ctx context.Context, product *models.Product) error {
+	args := m.Called(ctx, product)
 	return args.Error(0)
 }
 
-func (m *MockProductService) GetAllProducts() ([]models.Product, error) {
-	args := m.Called()
+func (m *MockProductService) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
 
-func (m *MockProductService) GetProductByName(name string) ([]models.Product, error) {
-	args := m.Called(name)
+func (m *MockProductService) GetProductByName(ctx context.Context, name string) ([]models.Product, error) {
+	args := m.Called(ctx, name)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
 
-func (m *MockProductService) GetProductsCount() int64 {
-	args := m.Called()
+func (m *MockProductService) GetProductsCount(ctx context.Context) int64 {
+	args := m.Called(ctx)
 	return args.Get(0).(int64)
 }
 
-func (m *MockProductService) GetProductByID(id uint) (*models.Product, error) {
-	args := m.Called(id)
+func (m *MockProductService) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockProductService) UpdateProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *MockProductService) UpdateProduct(ctx context.Context, product *models.Product) error {
+	args := m.Called(ctx, product)
 	return args.Error(0)
 }
 
-func (m *MockProductService) DeleteProduct(id uint) error {
-	args := m.Called(id)
+func (m *MockProductService) DeleteProduct(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockProductService) GetDeletedProducts(ctx context.Context) ([]models.Product, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockProductService) RestoreProduct(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int, error) {
+	args := m.Called(ctx, retention)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProductService) GetProductAsOf(ctx context.Context, id uint, asOf time.Time) (*models.Product, error) {
+	args := m.Called(ctx, id, asOf)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.ProductRevision), args.Error(1)
+}
+
+func (m *MockProductService) RevertProduct(ctx context.Context, id, revision uint) (*models.Product, error) {
+	args := m.Called(ctx, id, revision)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
 func TestCreateProductController(t *testing.T) {
 	mockService := new(MockProductService)
 	controller := NewProductController(mockService)
 
 	product := &models.Product{Name: "New Product", Price: 99.99}
 
-	mockService.On("CreateProduct", product).Return(nil)
+	mockService.On("CreateProduct", mock.Anything, product).Return(nil)
 
 	productJSON := `{"name":"New Product","price":99.99}`
 	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(productJSON))
@@ -76,7 +112,7 @@ func TestGetAllProductsController(t *testing.T) {
 	mockService := new(MockProductService)
 	controller := NewProductController(mockService)
 
-	mockService.On("GetAllProducts").Return([]models.Product{
+	mockService.On("GetAllProducts", mock.Anything).Return([]models.Product{
 		{ID: 1, Name: "Product 1", Price: 100},
 		{ID: 2, Name: "Product 2", Price: 150},
 	}, nil)
@@ -98,7 +134,7 @@ func TestGetProductByIDController(t *testing.T) {
 
 	product := &models.Product{ID: 1, Name: "Product 1", Price: 100}
 
-	mockService.On("GetProductByID", uint(1)).Return(product, nil)
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return(product, nil)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
@@ -120,7 +156,7 @@ func TestUpdateProductController(t *testing.T) {
 
 	product := &models.Product{ID: 1, Name: "Updated Product", Price: 120}
 
-	mockService.On("UpdateProduct", product).Return(nil)
+	mockService.On("UpdateProduct", mock.Anything, product).Return(nil)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/products/{id:[0-9]+}", controller.UpdateProduct).Methods(http.MethodPut)
@@ -143,7 +179,7 @@ func TestDeleteProductController(t *testing.T) {
 	mockService := new(MockProductService)
 	controller := NewProductController(mockService)
 
-	mockService.On("DeleteProduct", uint(1)).Return(nil)
+	mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/products/{id:[0-9]+}", controller.DeleteProduct).Methods(http.MethodDelete)
@@ -157,3 +193,347 @@ func TestDeleteProductController(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, rr.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestGetAllProductsControllerTimeout(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetAllProducts", mock.Anything).Return([]models.Product(nil), context.DeadlineExceeded)
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetAllProducts(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllProductsControllerClientCanceled(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetAllProducts", mock.Anything).Return([]models.Product(nil), context.Canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/products", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	controller.GetAllProducts(rr, req)
+
+	assert.Equal(t, StatusClientClosedRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerNotFoundAfterDeadline(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return((*models.Product)(nil),
+		services.NotFoundError(services.CodeProductNotFound, "Product not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	// Erros de domínio mantêm o status mesmo quando o prazo expira antes da resposta
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerTimeout(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return((*models.Product)(nil), context.DeadlineExceeded)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerNotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return((*models.Product)(nil),
+		services.NotFoundError(services.CodeProductNotFound, "Product not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"code":"product_not_found"`)
+	assert.Contains(t, rr.Body.String(), `"instance":"/products/1"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerUnavailable(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return((*models.Product)(nil),
+		services.UnavailableError(services.CodeDatabaseUnavailable, "Product storage is unavailable", errors.New("disk I/O error")))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "disk I/O error")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerInvalidID(t *testing.T) {
+	controller := NewProductController(new(MockProductService))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/abc", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"invalid_id"`)
+	assert.Contains(t, rr.Body.String(), `"field":"id"`)
+}
+
+func TestDeleteProductControllerNotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(
+		services.NotFoundError(services.CodeProductNotFound, "Product not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.DeleteProduct).Methods(http.MethodDelete)
+
+	req := httptest.NewRequest(http.MethodDelete, "/products/1", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateProductControllerValidationFailed(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("CreateProduct", mock.Anything, mock.Anything).Return(services.ValidationError(
+		services.FieldError{Field: "name", Code: "required", Message: "name is required"},
+		services.FieldError{Field: "price", Code: "gte", Message: "price must be greater than or equal to 0"},
+	))
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"price":-1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	controller.CreateProduct(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"validation_failed"`)
+	assert.Contains(t, rr.Body.String(), `"field":"name"`)
+	assert.Contains(t, rr.Body.String(), `"field":"price"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestProblemCodesHaveCatalogEntries(t *testing.T) {
+	codes := []string{
+		services.CodeProductNotFound, services.CodeValidationFailed, services.CodeProductConflict,
+		services.CodeDatabaseUnavailable, CodeInvalidID, CodeInvalidBody, CodeRequestTimeout,
+		CodeClientClosedRequest, CodeInternalError, services.CodeTranslationNotFound,
+		services.CodeUnauthorized, services.CodeForbidden, services.CodeAPIKeyNotFound, services.CodeAPIKeyRevoked,
+		services.CodeRateLimited, services.CodeConcurrentModification, services.CodeRevisionNotFound,
+		services.CodeWebhookNotFound, services.CodeDeliveryNotFound, services.CodeWebhookDisabled,
+		services.CodeInsufficientStock, services.CodeStockReservationNotFound, services.CodeStockReservationClosed,
+		services.CodeOrderNotFound, services.CodeInvalidOrderTransition, services.CodeCatalogUnavailable,
+		services.CodeOrderStorageUnavailable, services.CodeCustomerNotFound, services.CodeCustomerConflict,
+		services.CodeAddressNotFound, services.CodeCustomerStorageUnavailable, services.CodeStockSubscriptionNotFound,
+		services.CodeStockSubscriptionConflict, services.CodeProductInStock, services.CodeCustomersUnavailable,
+	}
+
+	for _, locale := range i18n.Locales() {
+		for _, code := range codes {
+			assert.True(t, i18n.Has(locale, "problem."+code), "problem.%s is missing from locale %s", code, locale)
+		}
+	}
+}
+
+func TestCreateProductControllerLocalizedValidation(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("CreateProduct", mock.Anything, mock.Anything).Return(services.ValidationError(
+		services.FieldError{Field: "price", Code: "gte", Param: "0", Message: "price must be greater than or equal to 0"},
+	))
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Caneta","price":-1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9")
+	rr := httptest.NewRecorder()
+
+	controller.CreateProduct(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "pt-BR", rr.Header().Get("Content-Language"))
+	assert.Contains(t, rr.Body.String(), `"title":"Falha na validação"`)
+	assert.Contains(t, rr.Body.String(), `"message":"preço deve ser maior ou igual a 0"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTrashController(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("GetDeletedProducts", mock.Anything).Return([]models.Product{
+		{ID: 3, Name: "Caneta", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/trash", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetTrash(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"deleted_at":"2026-10-01T12:00:00Z"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreProductController(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("RestoreProduct", mock.Anything, uint(3)).Return(&models.Product{ID: 3, Name: "Caneta"}, nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}/restore", controller.RestoreProduct).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/products/3/restore", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"Caneta"`)
+	assert.Contains(t, rr.Body.String(), `"deleted_at":null`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreProductControllerConflict(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("RestoreProduct", mock.Anything, uint(3)).Return(&models.Product{},
+		services.ConflictError(services.CodeProductConflict, "Product already exists", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}/restore", controller.RestoreProduct).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/products/3/restore", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProductByIDControllerAsOf(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	asOf := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("GetProductAsOf", mock.Anything, uint(1), asOf).Return(&models.Product{ID: 1, Name: "Caneta"}, nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1?as_of=2026-01-01T00:00Z", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"Caneta"`)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "GetProductByID", mock.Anything, uint(1))
+}
+
+func TestGetProductByIDControllerInvalidAsOf(t *testing.T) {
+	controller := NewProductController(new(MockProductService))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}", controller.GetProductByID).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1?as_of=yesterday", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"as_of"`)
+}
+
+func TestGetRevisionsController(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("GetProductRevisions", mock.Anything, uint(1)).Return([]models.ProductRevision{
+		{ProductID: 1, Revision: 1, Name: "Caneta"},
+		{ProductID: 1, Revision: 2, Name: "Caneta azul"},
+	}, nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}/revisions", controller.GetRevisions).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1/revisions", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"revision":2`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevertProductControllerRevisionNotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductController(mockService)
+
+	mockService.On("RevertProduct", mock.Anything, uint(1), uint(7)).Return(&models.Product{},
+		services.NotFoundError(services.CodeRevisionNotFound, "Revision not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id:[0-9]+}/revisions/{revision}/revert", controller.RevertProduct).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/products/1/revisions/7/revert", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"revision_not_found"`)
+	mockService.AssertExpectations(t)
+}
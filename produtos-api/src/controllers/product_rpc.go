@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/jsonrpc"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+)
+
+// Authorizer decide se o principal pode acessar a rota; implementado por services.AuthService
+type Authorizer interface {
+	Authorize(principal *auth.Principal, method, route string) error
+}
+
+// RPCMethod descreve um método JSON-RPC do catálogo. A chamada é autorizada pela política da rota REST
+// equivalente (HTTPMethod e Route), com os mesmos papéis e escopos de chaves de API. Params e Result são
+// valores dos tipos dos parâmetros e do resultado, usados pelo cmd/rpcgen para gerar o cliente tipado.
+type RPCMethod struct {
+	Name       string
+	Client     string
+	Doc        string
+	HTTPMethod string
+	Route      string
+	Params     interface{}
+	Result     interface{}
+	Handler    jsonrpc.Handler
+}
+
+// ProductRPCMethods lista os métodos JSON-RPC do catálogo, executados pelo service informado. O service pode
+// ser nil quando apenas a descrição dos métodos é usada, como no cmd/rpcgen.
+func ProductRPCMethods(service services.ProductService) []RPCMethod {
+	return []RPCMethod{
+		{
+			Name: "products.create", Client: "CreateProduct", Doc: "cadastra um produto",
+			HTTPMethod: http.MethodPost, Route: "/products",
+			Params: models.Product{}, Result: models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var product models.Product
+				if err := decodeParams(params, &product); err != nil {
+					return nil, err
+				}
+				product.ID = 0
+				if err := service.CreateProduct(ctx, &product); err != nil {
+					return nil, err
+				}
+				return product, nil
+			},
+		},
+		{
+			Name: "products.get", Client: "GetProduct", Doc: "retorna um produto pelo ID; com as_of, a versão vigente naquele instante",
+			HTTPMethod: http.MethodGet, Route: "/products/{id}",
+			Params: models.ProductGetParams{}, Result: models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductGetParams
+				if err := decodeIDParams(params, &p, &p.ID); err != nil {
+					return nil, err
+				}
+				if p.AsOf != nil {
+					return service.GetProductAsOf(ctx, p.ID, *p.AsOf)
+				}
+				return service.GetProductByID(ctx, p.ID)
+			},
+		},
+		{
+			Name: "products.list", Client: "ListProducts", Doc: "lista os produtos; com name, apenas os produtos com esse nome",
+			HTTPMethod: http.MethodGet, Route: "/products",
+			Params: models.ProductListParams{}, Result: []models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductListParams
+				if err := decodeParams(params, &p); err != nil {
+					return nil, err
+				}
+				if p.Name != "" {
+					return service.GetProductByName(ctx, p.Name)
+				}
+				return service.GetAllProducts(ctx)
+			},
+		},
+		{
+			Name: "products.count", Client: "CountProducts", Doc: "retorna a quantidade de produtos do catálogo",
+			HTTPMethod: http.MethodGet, Route: "/products",
+			Result: models.ProductCount{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				count := service.GetProductsCount(ctx)
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return models.ProductCount{Count: count}, nil
+			},
+		},
+		{
+			Name: "products.update", Client: "UpdateProduct", Doc: "atualiza o produto identificado por id",
+			HTTPMethod: http.MethodPut, Route: "/products/{id}",
+			Params: models.Product{}, Result: models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var product models.Product
+				if err := decodeIDParams(params, &product, &product.ID); err != nil {
+					return nil, err
+				}
+				if err := service.UpdateProduct(ctx, &product); err != nil {
+					return nil, err
+				}
+				return product, nil
+			},
+		},
+		{
+			Name: "products.delete", Client: "DeleteProduct", Doc: "move o produto para a lixeira",
+			HTTPMethod: http.MethodDelete, Route: "/products/{id}",
+			Params: models.ProductIDParams{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductIDParams
+				if err := decodeIDParams(params, &p, &p.ID); err != nil {
+					return nil, err
+				}
+				return nil, service.DeleteProduct(ctx, p.ID)
+			},
+		},
+		{
+			Name: "products.trash", Client: "GetTrash", Doc: "lista os produtos na lixeira",
+			HTTPMethod: http.MethodGet, Route: "/products/trash",
+			Result: []models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				return service.GetDeletedProducts(ctx)
+			},
+		},
+		{
+			Name: "products.restore", Client: "RestoreProduct", Doc: "restaura um produto da lixeira",
+			HTTPMethod: http.MethodPost, Route: "/products/{id}/restore",
+			Params: models.ProductIDParams{}, Result: models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductIDParams
+				if err := decodeIDParams(params, &p, &p.ID); err != nil {
+					return nil, err
+				}
+				return service.RestoreProduct(ctx, p.ID)
+			},
+		},
+		{
+			Name: "products.revisions", Client: "GetRevisions", Doc: "lista o histórico de versões do produto",
+			HTTPMethod: http.MethodGet, Route: "/products/{id}/revisions",
+			Params: models.ProductIDParams{}, Result: []models.ProductRevision{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductIDParams
+				if err := decodeIDParams(params, &p, &p.ID); err != nil {
+					return nil, err
+				}
+				return service.GetProductRevisions(ctx, p.ID)
+			},
+		},
+		{
+			Name: "products.revert", Client: "RevertProduct", Doc: "cria uma nova revisão do produto com o conteúdo da revisão informada",
+			HTTPMethod: http.MethodPost, Route: "/products/{id}/revisions/{revision}/revert",
+			Params: models.ProductRevertParams{}, Result: models.Product{},
+			Handler: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p models.ProductRevertParams
+				if err := decodeIDParams(params, &p, &p.ID); err != nil {
+					return nil, err
+				}
+				if p.Revision == 0 {
+					return nil, services.ValidationError(invalidParam("revision"))
+				}
+				return service.RevertProduct(ctx, p.ID, p.Revision)
+			},
+		},
+	}
+}
+
+// RPCLimits aplica a cada chamada JSON-RPC o limite de requisições e o prazo da rota REST equivalente, para que
+// um lote não escape de ROUTE_RATE_LIMITS e ROUTE_TIMEOUTS. Identity identifica o cliente da requisição como o
+// middleware RateLimit; sem RateLimits ou Timeout, as chamadas não são limitadas ou não têm prazo próprio.
+type RPCLimits struct {
+	RateLimits services.RateLimitService
+	Identity   func(r *http.Request) string
+	Timeout    func(method, route string) time.Duration
+}
+
+// ProductRPCController is a struct that defines the JSON-RPC 2.0 controller of the catalog
+type ProductRPCController struct {
+	server   *jsonrpc.Server
+	identity func(r *http.Request) string
+}
+
+// rpcIdentityKey guarda no contexto a identidade do cliente para o limite de requisições das chamadas
+type rpcIdentityKey struct{}
+
+// NewProductRPCController is a function that creates the JSON-RPC 2.0 controller with the catalog methods. Every
+// call is authorized, rate limited and timed out by the policies of the equivalent REST route, and errors carry
+// the same problem in data.
+func NewProductRPCController(service services.ProductService, authorizer Authorizer, limits RPCLimits, maxBatch int) *ProductRPCController {
+	server := jsonrpc.NewServer(jsonrpc.Options{MaxBatch: maxBatch, MapError: rpcError})
+	for _, method := range ProductRPCMethods(service) {
+		method := method
+		server.Register(method.Name, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			if err := authorizer.Authorize(auth.PrincipalFrom(ctx), method.HTTPMethod, method.Route); err != nil {
+				return nil, err
+			}
+			if err := limits.allow(ctx, method); err != nil {
+				return nil, err
+			}
+			if limits.Timeout != nil {
+				if timeout := limits.Timeout(method.HTTPMethod, method.Route); timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, timeout)
+					defer cancel()
+				}
+			}
+			return method.Handler(ctx, params)
+		})
+	}
+
+	return &ProductRPCController{server: server, identity: limits.Identity}
+}
+
+// allow consome o limite da rota REST equivalente para a chamada. Como no middleware RateLimit,
+// falhas do backend liberam a chamada.
+func (l RPCLimits) allow(ctx context.Context, method RPCMethod) error {
+	if l.RateLimits == nil {
+		return nil
+	}
+
+	identity, _ := ctx.Value(rpcIdentityKey{}).(string)
+	_, err := l.RateLimits.Allow(ctx, identity, method.HTTPMethod, method.Route)
+	if domainErr, ok := services.AsDomainError(err); ok && domainErr.Kind == services.KindRateLimited {
+		return err
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "rate limit backend failed, allowing call", "method", method.Name, "error", err)
+	}
+
+	return nil
+}
+
+// ServeRPC Executa chamadas JSON-RPC 2.0 do catálogo
+// @Summary Executa chamadas JSON-RPC 2.0 do catálogo
+// @Description Interface JSON-RPC 2.0 para chamadas internas entre os microsserviços, com as mesmas operações de /products: products.create, products.get, products.list, products.count, products.update, products.delete, products.trash, products.restore, products.revisions e products.revert. Aceita lotes (array) e notificações (sem id), que não têm resposta; sem respostas, retorna 204. Cada chamada é autorizada, limitada (ROUTE_RATE_LIMITS) e tem o prazo (ROUTE_TIMEOUTS) da rota REST equivalente; o lote como um todo não tem o prazo de REQUEST_TIMEOUT. Os erros dos métodos trazem em data o mesmo problema (RFC 7807) da rota REST; erros de validação usam o código -32602
+// @Tags json-rpc
+// @Accept json
+// @Produce json
+// @Param request body jsonrpc.Request true "Chamada ou lote de chamadas"
+// @Success 200 {object} jsonrpc.Response
+// @Success 204
+// @Failure 401 {object} controllers.Problem
+// @Failure 429 {object} controllers.Problem
+// @Router /rpc [post]
+func (rc *ProductRPCController) ServeRPC(w http.ResponseWriter, r *http.Request) {
+	if rc.identity != nil {
+		r = r.WithContext(context.WithValue(r.Context(), rpcIdentityKey{}, rc.identity(r)))
+	}
+	rc.server.ServeHTTP(w, r)
+}
+
+// rpcError converte o erro de um método no erro JSON-RPC com o problema localizado em data. Erros de validação
+// usam o código de params inválidos; os demais, os códigos de erro do servidor: -32000 menos os dois últimos
+// dígitos do status HTTP equivalente para 4xx (ex: 404 → -32004) e -32050 menos eles para 5xx (ex: 503 → -32053).
+func rpcError(r *http.Request, err error) *jsonrpc.Error {
+	problem, _ := resolveProblem(r, err)
+	problem.Instance = ""
+
+	return jsonrpc.NewError(rpcCodeFor(problem.Status), problem.Title, problem)
+}
+
+func rpcCodeFor(status int) int {
+	switch {
+	case status == http.StatusBadRequest:
+		return jsonrpc.CodeInvalidParams
+	case status == http.StatusInternalServerError:
+		return jsonrpc.CodeInternalError
+	case status >= 500:
+		return -32050 - (status - 500)
+	default:
+		return -32000 - (status - 400)
+	}
+}
+
+// decodeParams decodifica os parâmetros nomeados da chamada; parâmetros omitidos mantêm os valores zero
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return invalidBody(err)
+	}
+
+	return nil
+}
+
+// decodeIDParams decodifica os parâmetros e exige o id do produto
+func decodeIDParams(params json.RawMessage, v interface{}, id *uint) error {
+	if err := decodeParams(params, v); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return invalidID(nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/jsonrpc"
+	"produtos-api/src/models"
+	"produtos-api/src/productsrpc"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// readOnlyAuthorizer libera apenas as rotas GET, como uma chave de API com o escopo products:read
+type readOnlyAuthorizer struct{}
+
+func (readOnlyAuthorizer) Authorize(principal *auth.Principal, method, route string) error {
+	if method != http.MethodGet {
+		return services.ForbiddenError("Insufficient permissions", nil)
+	}
+	return nil
+}
+
+// allowAllAuthorizer libera todas as rotas
+type allowAllAuthorizer struct{}
+
+func (allowAllAuthorizer) Authorize(principal *auth.Principal, method, route string) error {
+	return nil
+}
+
+func newRPCTestClient(t *testing.T, service services.ProductService) *productsrpc.Client {
+	controller := NewProductRPCController(service, readOnlyAuthorizer{}, RPCLimits{}, 10)
+	httpServer := httptest.NewServer(http.HandlerFunc(controller.ServeRPC))
+	t.Cleanup(httpServer.Close)
+
+	return productsrpc.NewClient(jsonrpc.ClientConfig{URL: httpServer.URL})
+}
+
+func TestProductRPCGetProduct(t *testing.T) {
+	mockService := new(MockProductService)
+	client := newRPCTestClient(t, mockService)
+	ctx := context.Background()
+
+	mockService.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Price: 2.5}, nil)
+	mockService.On("GetProductByID", mock.Anything, uint(2)).Return((*models.Product)(nil),
+		services.NotFoundError(services.CodeProductNotFound, "Product not found", nil))
+
+	product, err := client.GetProduct(ctx, models.ProductGetParams{ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Caneta", product.Name)
+
+	_, err = client.GetProduct(ctx, models.ProductGetParams{ID: 2})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32004, rpcErr.Code)
+	assert.Equal(t, services.CodeProductNotFound, productsrpc.ErrorCode(err))
+
+	// Parâmetros inválidos usam o código da especificação e trazem os campos no problema
+	_, err = client.GetProduct(ctx, models.ProductGetParams{})
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+	assert.Equal(t, CodeInvalidID, productsrpc.ErrorCode(err))
+
+	// As escritas seguem a política da rota REST equivalente
+	err = client.DeleteProduct(ctx, models.ProductIDParams{ID: 1})
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32003, rpcErr.Code)
+	assert.Equal(t, services.CodeForbidden, productsrpc.ErrorCode(err))
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "DeleteProduct", mock.Anything, mock.Anything)
+}
+
+func TestProductRPCBatch(t *testing.T) {
+	mockService := new(MockProductService)
+	client := newRPCTestClient(t, mockService)
+
+	mockService.On("GetProductsCount", mock.Anything).Return(int64(3))
+	mockService.On("GetProductByName", mock.Anything, "Caneta").Return([]models.Product{{ID: 1, Name: "Caneta"}}, nil)
+	mockService.On("GetAllProducts", mock.Anything).Return([]models.Product{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Once()
+
+	var count models.ProductCount
+	var products []models.Product
+	calls := []*jsonrpc.BatchCall{
+		{Method: "products.count", Result: &count},
+		{Method: "products.list", Params: models.ProductListParams{Name: "Caneta"}, Result: &products},
+		{Method: "products.list", Notification: true},
+		{Method: "products.revert", Params: models.ProductRevertParams{ID: 1}},
+	}
+	require.NoError(t, client.Batch(context.Background(), calls))
+
+	assert.NoError(t, calls[0].Error)
+	assert.Equal(t, int64(3), count.Count)
+	assert.NoError(t, calls[1].Error)
+	assert.Len(t, products, 1)
+	assert.Equal(t, services.CodeForbidden, productsrpc.ErrorCode(calls[3].Error))
+	mockService.AssertExpectations(t)
+}
+
+func TestProductRPCLocalizesErrors(t *testing.T) {
+	mockService := new(MockProductService)
+	controller := NewProductRPCController(mockService, readOnlyAuthorizer{}, RPCLimits{}, 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"2.0","method":"products.get","params":{"id":"1"},"id":1}`))
+	req.Header.Set("Accept-Language", "pt-BR")
+	rr := httptest.NewRecorder()
+	controller.ServeRPC(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":-32602`)
+	assert.Contains(t, rr.Body.String(), `"code":"invalid_body"`)
+	assert.Contains(t, rr.Body.String(), `"message":"Dados de entrada inválidos"`)
+}
+
+func TestProductRPCLimitsEachCall(t *testing.T) {
+	mockService := new(MockProductService)
+	rateLimits := services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(ratelimit.Limit{}, []string{"POST /products=2/m"}))
+	controller := NewProductRPCController(mockService, allowAllAuthorizer{}, RPCLimits{
+		RateLimits: rateLimits,
+		Identity:   func(r *http.Request) string { return "key:orders" },
+		Timeout: func(method, route string) time.Duration {
+			if method == http.MethodGet && route == "/products" {
+				return time.Minute
+			}
+			return 0
+		},
+	}, 10)
+	httpServer := httptest.NewServer(http.HandlerFunc(controller.ServeRPC))
+	t.Cleanup(httpServer.Close)
+	client := productsrpc.NewClient(jsonrpc.ClientConfig{URL: httpServer.URL})
+
+	hasDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+		_, ok := ctx.Deadline()
+		return ok
+	})
+	mockService.On("CreateProduct", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockService.On("GetProductsCount", hasDeadline).Return(int64(2))
+
+	// O lote consome o limite de POST /products uma vez por chamada
+	calls := []*jsonrpc.BatchCall{
+		{Method: "products.create", Params: models.Product{Name: "Caneta", Price: 2.5}},
+		{Method: "products.create", Params: models.Product{Name: "Lápis", Price: 1.5}},
+		{Method: "products.create", Params: models.Product{Name: "Borracha", Price: 1}},
+		{Method: "products.count"},
+	}
+	require.NoError(t, client.Batch(context.Background(), calls))
+
+	assert.NoError(t, calls[0].Error)
+	assert.NoError(t, calls[1].Error)
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, calls[2].Error, &rpcErr)
+	assert.Equal(t, -32029, rpcErr.Code)
+	assert.Equal(t, services.CodeRateLimited, productsrpc.ErrorCode(calls[2].Error))
+	// Cada chamada tem o prazo da sua rota REST
+	assert.NoError(t, calls[3].Error)
+	mockService.AssertExpectations(t)
+}
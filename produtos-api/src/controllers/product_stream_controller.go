@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"golang.org/x/net/websocket"
+)
+
+// streamReset é enviado quando a posição pedida já não está disponível: o cliente deve recarregar os produtos
+const streamReset = "reset"
+
+// TicketIssuer emite os tickets das conexões de navegadores; implementado por services.AuthServiceRepo
+type TicketIssuer interface {
+	IssueTicket(ctx context.Context, principal *auth.Principal) (string, time.Time, error)
+}
+
+// ProductStreamController is a struct that defines the product change stream controller
+type ProductStreamController struct {
+	service      services.ChangeStreamService
+	tickets      TicketIssuer
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+}
+
+// NewProductStreamController is a function that creates a new product change stream controller. Heartbeats keep
+// idle connections open through proxies; writes that exceed writeTimeout close the connection of slow clients.
+// Tickets authenticate the connections of browsers, which cannot send headers in EventSource and WebSocket.
+func NewProductStreamController(service services.ChangeStreamService, tickets TicketIssuer, heartbeat, writeTimeout time.Duration) *ProductStreamController {
+	return &ProductStreamController{service: service, tickets: tickets, heartbeat: heartbeat, writeTimeout: writeTimeout}
+}
+
+// CreateStreamTicket Emite um ticket para os streams de mudanças
+// @Summary Emite um ticket para os streams de mudanças
+// @Description Emite um ticket de curta duração (STREAM_TICKET_TTL) com as credenciais desta requisição. Navegadores não enviam headers em EventSource e WebSocket: abra /products/stream ou /products/stream/ws com o ticket no parâmetro ticket antes de expires_at. O ticket não é renovado nas reconexões automáticas; ao expirar, emita outro e reconecte com last_event_id
+// @Tags produtos
+// @Produce json
+// @Success 201 {object} models.StreamTicket
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/stream/tickets [post]
+func (sc *ProductStreamController) CreateStreamTicket(w http.ResponseWriter, r *http.Request) {
+	ticket, expiresAt, err := sc.tickets.IssueTicket(r.Context(), auth.PrincipalFrom(r.Context()))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.StreamTicket{Ticket: ticket, ExpiresAt: expiresAt})
+}
+
+// StreamProducts Acompanha as mudanças do catálogo por Server-Sent Events
+// @Summary Acompanha as mudanças do catálogo por Server-Sent Events
+// @Description Envia as mudanças dos produtos à medida que acontecem, como eventos SSE com o tipo da mudança em event e a posição no stream em id. Ao reconectar, o EventSource envia o header Last-Event-ID e o stream continua da última posição recebida; se ela não estiver mais disponível, o evento reset indica que os produtos devem ser recarregados
+// @Tags produtos
+// @Produce text/event-stream
+// @Param product_ids query string false "IDs dos produtos, separados por vírgula"
+// @Param category query string false "Categoria dos produtos"
+// @Param Last-Event-ID header int false "Retoma o stream após a posição informada"
+// @Param last_event_id query int false "Alternativa ao header Last-Event-ID"
+// @Param ticket query string false "Ticket de POST /products/stream/tickets, para navegadores"
+// @Success 200 {object} models.ProductChange
+// @Failure 400 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/stream [get]
+func (sc *ProductStreamController) StreamProducts(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := sc.subscribe(w, r)
+	if !ok {
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(format string, args ...interface{}) error {
+		controller.SetWriteDeadline(time.Now().Add(sc.writeTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	err := controller.Flush()
+	if err == nil && subscription.Expired() {
+		err = write("event: %s\ndata: {}\n\n", streamReset)
+	}
+	for err == nil {
+		var changes []models.ProductChange
+		changes, err = sc.next(r.Context(), subscription)
+		if changes == nil && err == nil {
+			err = write(": heartbeat\n\n")
+		}
+		for _, change := range changes {
+			data, _ := json.Marshal(change)
+			if err = write("id: %d\nevent: %s\ndata: %s\n\n", change.Position, change.Type, data); err != nil {
+				break
+			}
+		}
+	}
+}
+
+// StreamProductsWebSocket Acompanha as mudanças do catálogo por WebSocket
+// @Summary Acompanha as mudanças do catálogo por WebSocket
+// @Description Equivalente a /products/stream por WebSocket: cada mudança é uma mensagem de texto com o JSON de models.ProductChange. Para retomar, informe em last_event_id o campo position da última mudança recebida; uma mensagem {"type":"reset"} indica que os produtos devem ser recarregados
+// @Tags produtos
+// @Param product_ids query string false "IDs dos produtos, separados por vírgula"
+// @Param category query string false "Categoria dos produtos"
+// @Param last_event_id query int false "Retoma o stream após a posição informada"
+// @Param ticket query string false "Ticket de POST /products/stream/tickets, para navegadores"
+// @Success 101 {object} models.ProductChange
+// @Failure 400 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/stream/ws [get]
+func (sc *ProductStreamController) StreamProductsWebSocket(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := sc.subscribe(w, r)
+	if !ok {
+		return
+	}
+
+	// A autenticação é feita pelos headers ou pelo ticket da query, e não por cookies, por isso qualquer origem é aceita
+	server := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			sc.serveWebSocket(r.Context(), ws, subscription)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+func (sc *ProductStreamController) serveWebSocket(ctx context.Context, ws *websocket.Conn, subscription services.ChangeSubscription) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Depois do upgrade o servidor HTTP não acompanha mais a conexão: a leitura detecta o fechamento pelo cliente
+	go func() {
+		defer cancel()
+		var message string
+		for websocket.Message.Receive(ws, &message) == nil {
+		}
+	}()
+
+	// Os heartbeats são frames de ping, respondidos automaticamente pelos navegadores
+	ws.PayloadType = websocket.PingFrame
+	send := func(value interface{}) error {
+		ws.SetWriteDeadline(time.Now().Add(sc.writeTimeout))
+		if value == nil {
+			_, err := ws.Write(nil)
+			return err
+		}
+		return websocket.JSON.Send(ws, value)
+	}
+
+	var err error
+	if subscription.Expired() {
+		err = send(map[string]string{"type": streamReset})
+	}
+	for err == nil {
+		var changes []models.ProductChange
+		changes, err = sc.next(ctx, subscription)
+		if changes == nil && err == nil {
+			err = send(nil)
+		}
+		for _, change := range changes {
+			if err = send(change); err != nil {
+				break
+			}
+		}
+	}
+
+	ws.Close()
+}
+
+// subscribe lê os filtros e a posição e cria a assinatura, respondendo com o problema quando não é possível
+func (sc *ProductStreamController) subscribe(w http.ResponseWriter, r *http.Request) (services.ChangeSubscription, bool) {
+	filter, lastEventID, err := parseStreamParams(r)
+	if err != nil {
+		writeProblem(w, r, err)
+		return nil, false
+	}
+
+	subscription, err := sc.service.Subscribe(r.Context(), filter, lastEventID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+// next aguarda as próximas mudanças; retorna nil sem erro quando é hora de enviar um heartbeat
+func (sc *ProductStreamController) next(ctx context.Context, subscription services.ChangeSubscription) ([]models.ProductChange, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, sc.heartbeat)
+	defer cancel()
+
+	changes, err := subscription.Next(waitCtx)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, nil
+	}
+
+	return changes, err
+}
+
+// parseStreamParams converte os filtros e a posição do stream, reportando todos os parâmetros inválidos
+func parseStreamParams(r *http.Request) (services.ChangeFilter, *uint, error) {
+	query := r.URL.Query()
+	filter := services.ChangeFilter{Category: query.Get("category")}
+	var fields []services.FieldError
+
+	if value := query.Get("product_ids"); value != "" {
+		for _, item := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 0)
+			if err != nil || id == 0 {
+				fields = append(fields, invalidParam("product_ids"))
+				break
+			}
+			filter.ProductIDs = append(filter.ProductIDs, uint(id))
+		}
+	}
+
+	var lastEventID *uint
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = query.Get("last_event_id")
+	}
+	if value != "" {
+		if position, err := strconv.ParseUint(value, 10, 0); err == nil {
+			lastEventID = new(uint)
+			*lastEventID = uint(position)
+		} else {
+			fields = append(fields, invalidParam("last_event_id"))
+		}
+	}
+
+	if len(fields) > 0 {
+		return filter, nil, services.ValidationError(fields...)
+	}
+
+	return filter, lastEventID, nil
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+type MockChangeStreamService struct {
+	mock.Mock
+}
+
+func (m *MockChangeStreamService) Subscribe(ctx context.Context, filter services.ChangeFilter, lastEventID *uint) (services.ChangeSubscription, error) {
+	args := m.Called(ctx, filter, lastEventID)
+	return args.Get(0).(services.ChangeSubscription), args.Error(1)
+}
+
+// stubSubscription entrega os lotes em ordem e encerra o stream depois do último; um lote nil espera o prazo de ctx
+type stubSubscription struct {
+	batches [][]models.ProductChange
+	expired bool
+}
+
+func (s *stubSubscription) Next(ctx context.Context) ([]models.ProductChange, error) {
+	if len(s.batches) == 0 {
+		return nil, services.ErrStreamClosed
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	if batch == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return batch, nil
+}
+
+func (s *stubSubscription) Expired() bool {
+	return s.expired
+}
+
+func newTestChange(position, productID uint, changeType string) models.ProductChange {
+	return models.ProductChange{
+		Position:   position,
+		EventID:    "event",
+		Type:       changeType,
+		ProductID:  productID,
+		Category:   "Livros",
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Data:       json.RawMessage(`{"id":1}`),
+	}
+}
+
+func newStreamRouter(service services.ChangeStreamService) *mux.Router {
+	controller := NewProductStreamController(service, nil, 10*time.Millisecond, time.Second)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/stream", controller.StreamProducts).Methods(http.MethodGet)
+	r.HandleFunc("/products/stream/ws", controller.StreamProductsWebSocket).Methods(http.MethodGet)
+	return r
+}
+
+func TestStreamProductsController(t *testing.T) {
+	mockService := new(MockChangeStreamService)
+	subscription := &stubSubscription{batches: [][]models.ProductChange{
+		{newTestChange(8, 1, "ProductUpdated"), newTestChange(9, 1, "StockChanged")},
+		nil,
+	}}
+	lastEventID := uint(7)
+	filter := services.ChangeFilter{ProductIDs: []uint{1, 2}, Category: "Livros"}
+	mockService.On("Subscribe", mock.Anything, filter, &lastEventID).Return(subscription, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/stream?product_ids=1,2&category=Livros", nil)
+	req.Header.Set("Last-Event-ID", "7")
+	rr := httptest.NewRecorder()
+
+	newStreamRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+	assert.True(t, rr.Flushed)
+
+	messages := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n\n"), "\n\n")
+	require.Len(t, messages, 3)
+	assert.True(t, strings.HasPrefix(messages[0], "id: 8\nevent: ProductUpdated\ndata: {"), messages[0])
+	assert.Contains(t, messages[0], `"data":{"id":1}`)
+	assert.True(t, strings.HasPrefix(messages[1], "id: 9\nevent: StockChanged\n"), messages[1])
+	assert.Equal(t, ": heartbeat", messages[2])
+	mockService.AssertExpectations(t)
+}
+
+func TestStreamProductsControllerExpiredPosition(t *testing.T) {
+	mockService := new(MockChangeStreamService)
+	subscription := &stubSubscription{expired: true}
+	mockService.On("Subscribe", mock.Anything, services.ChangeFilter{}, mock.Anything).Return(subscription, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/stream?last_event_id=3", nil)
+	rr := httptest.NewRecorder()
+
+	newStreamRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "event: reset\ndata: {}\n\n", rr.Body.String())
+}
+
+func TestStreamProductsControllerInvalidParams(t *testing.T) {
+	mockService := new(MockChangeStreamService)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/stream?product_ids=1,abc", nil)
+	req.Header.Set("Last-Event-ID", "-1")
+	rr := httptest.NewRecorder()
+
+	newStreamRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"product_ids"`)
+	assert.Contains(t, rr.Body.String(), `"field":"last_event_id"`)
+	mockService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStreamProductsControllerUnavailable(t *testing.T) {
+	mockService := new(MockChangeStreamService)
+	mockService.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).
+		Return((*stubSubscription)(nil), services.UnavailableError(services.CodeDatabaseUnavailable, "Product storage is unavailable", errors.New("database is locked")))
+
+	req := httptest.NewRequest(http.MethodGet, "/products/stream", nil)
+	rr := httptest.NewRecorder()
+
+	newStreamRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+}
+
+func TestStreamProductsWebSocketController(t *testing.T) {
+	mockService := new(MockChangeStreamService)
+	subscription := &stubSubscription{expired: true, batches: [][]models.ProductChange{
+		{newTestChange(4, 2, "ProductCreated")},
+		nil,
+	}}
+	mockService.On("Subscribe", mock.Anything, services.ChangeFilter{Category: "Livros"}, mock.Anything).Return(subscription, nil)
+
+	server := httptest.NewServer(newStreamRouter(mockService))
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/products/stream/ws?category=Livros&last_event_id=1", "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var reset map[string]string
+	require.NoError(t, websocket.JSON.Receive(ws, &reset))
+	assert.Equal(t, "reset", reset["type"])
+
+	var change models.ProductChange
+	require.NoError(t, websocket.JSON.Receive(ws, &change))
+	assert.Equal(t, uint(4), change.Position)
+	assert.Equal(t, "ProductCreated", change.Type)
+	assert.JSONEq(t, `{"id":1}`, string(change.Data))
+
+	// Depois do heartbeat a assinatura é encerrada e o servidor fecha a conexão
+	var message string
+	assert.Error(t, websocket.Message.Receive(ws, &message))
+}
+
+// stubTicketIssuer emite um ticket fixo para o principal autenticado
+type stubTicketIssuer struct{}
+
+func (stubTicketIssuer) IssueTicket(ctx context.Context, principal *auth.Principal) (string, time.Time, error) {
+	if principal == nil {
+		return "", time.Time{}, services.UnauthorizedError("Authentication required", nil)
+	}
+	return "ticket-" + principal.Subject, time.Date(2026, 10, 19, 12, 0, 30, 0, time.UTC), nil
+}
+
+func TestCreateStreamTicketController(t *testing.T) {
+	controller := NewProductStreamController(new(MockChangeStreamService), stubTicketIssuer{}, time.Second, time.Second)
+
+	req := httptest.NewRequest(http.MethodPost, "/products/stream/tickets", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleViewer}}))
+	rr := httptest.NewRecorder()
+	controller.CreateStreamTicket(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var ticket models.StreamTicket
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ticket))
+	assert.Equal(t, "ticket-maria", ticket.Ticket)
+
+	rr = httptest.NewRecorder()
+	controller.CreateStreamTicket(rr, httptest.NewRequest(http.MethodPost, "/products/stream/tickets", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
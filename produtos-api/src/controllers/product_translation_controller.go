@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ProductTranslationController is a struct that defines the product translation controller
+type ProductTranslationController struct {
+	service services.ProductTranslationService
+}
+
+// NewProductTranslationController is a function that creates a new product translation controller
+func NewProductTranslationController(service services.ProductTranslationService) *ProductTranslationController {
+	return &ProductTranslationController{service: service}
+}
+
+// GetTranslations Retorna as traduções de um produto
+// @Summary Retorna as traduções de um produto
+// @Description Retorna o nome e a descrição do produto em cada locale cadastrado
+// @Tags traduções
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Success 200 {object} []models.ProductTranslation
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/translations [get]
+func (tc *ProductTranslationController) GetTranslations(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	translations, err := tc.service.GetTranslations(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(translations)
+}
+
+// GetTranslation Retorna a tradução de um produto em um locale
+// @Summary Retorna a tradução de um produto em um locale
+// @Description Retorna o nome e a descrição do produto no locale informado
+// @Tags traduções
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Param locale path string true "Locale BCP 47, ex: pt-BR"
+// @Success 200 {object} models.ProductTranslation
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/translations/{locale} [get]
+func (tc *ProductTranslationController) GetTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	translation, err := tc.service.GetTranslation(r.Context(), id, mux.Vars(r)["locale"])
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(translation)
+}
+
+// PutTranslation Cria ou substitui a tradução de um produto
+// @Summary Cria ou substitui a tradução de um produto
+// @Description Cria ou substitui o nome e a descrição do produto no locale informado
+// @Tags traduções
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Param locale path string true "Locale BCP 47, ex: pt-BR"
+// @Param translation body models.ProductTranslation true "Translation data"
+// @Success 200 {object} models.ProductTranslation
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/translations/{locale} [put]
+func (tc *ProductTranslationController) PutTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var translation models.ProductTranslation
+	if err := json.NewDecoder(r.Body).Decode(&translation); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+	translation.ProductID = id
+	translation.Locale = mux.Vars(r)["locale"]
+
+	if err := tc.service.SaveTranslation(r.Context(), &translation); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(translation)
+}
+
+// DeleteTranslation Remove a tradução de um produto
+// @Summary Remove a tradução de um produto
+// @Description Remove a tradução do produto no locale informado; as leituras voltam a usar os campos base
+// @Tags traduções
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Param locale path string true "Locale BCP 47, ex: pt-BR"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/translations/{locale} [delete]
+func (tc *ProductTranslationController) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	if err := tc.service.DeleteTranslation(r.Context(), id, mux.Vars(r)["locale"]); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
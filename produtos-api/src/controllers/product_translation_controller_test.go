@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductTranslationService struct {
+	mock.Mock
+}
+
+func (m *MockProductTranslationService) GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]models.ProductTranslation), args.Error(1)
+}
+
+func (m *MockProductTranslationService) GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error) {
+	args := m.Called(ctx, productID, locale)
+	return args.Get(0).(*models.ProductTranslation), args.Error(1)
+}
+
+func (m *MockProductTranslationService) SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error {
+	args := m.Called(ctx, translation)
+	return args.Error(0)
+}
+
+func (m *MockProductTranslationService) DeleteTranslation(ctx context.Context, productID uint, locale string) error {
+	args := m.Called(ctx, productID, locale)
+	return args.Error(0)
+}
+
+func TestPutTranslationController(t *testing.T) {
+	mockService := new(MockProductTranslationService)
+	controller := NewProductTranslationController(mockService)
+
+	translation := &models.ProductTranslation{ProductID: 1, Locale: "es-ES", Name: "Bolígrafo", Description: "Bolígrafo azul"}
+	mockService.On("SaveTranslation", mock.Anything, translation).Return(nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id}/translations/{locale}", controller.PutTranslation).Methods(http.MethodPut)
+
+	body := `{"name":"Bolígrafo","description":"Bolígrafo azul"}`
+	req := httptest.NewRequest(http.MethodPut, "/products/1/translations/es-ES", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"locale":"es-ES"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTranslationsController(t *testing.T) {
+	mockService := new(MockProductTranslationService)
+	controller := NewProductTranslationController(mockService)
+
+	mockService.On("GetTranslations", mock.Anything, uint(1)).Return([]models.ProductTranslation{
+		{ProductID: 1, Locale: "pt-BR", Name: "Caneta"},
+		{ProductID: 1, Locale: "pt-PT", Name: "Esferográfica"},
+	}, nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id}/translations", controller.GetTranslations).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1/translations", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Esferográfica")
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteTranslationControllerNotFound(t *testing.T) {
+	mockService := new(MockProductTranslationService)
+	controller := NewProductTranslationController(mockService)
+
+	mockService.On("DeleteTranslation", mock.Anything, uint(1), "fr-FR").Return(
+		services.NotFoundError(services.CodeTranslationNotFound, "Translation not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id}/translations/{locale}", controller.DeleteTranslation).Methods(http.MethodDelete)
+
+	req := httptest.NewRequest(http.MethodDelete, "/products/1/translations/fr-FR", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"translation_not_found"`)
+	mockService.AssertExpectations(t)
+}
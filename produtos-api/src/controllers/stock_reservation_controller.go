@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+)
+
+// StockReservationController is a struct that defines the stock reservation controller
+type StockReservationController struct {
+	service services.InventoryService
+}
+
+// NewStockReservationController is a function that creates a new stock reservation controller
+func NewStockReservationController(service services.InventoryService) *StockReservationController {
+	return &StockReservationController{service: service}
+}
+
+// CreateStockReservation Reserva o estoque de um pedido
+// @Summary Reserva o estoque de um pedido
+// @Description Usado no checkout do microsserviço de pedidos: baixa o estoque de todos os itens do pedido, ou de nenhum. A falta de estoque responde 409 insufficient_stock, com o ID de cada produto em falta no param dos erros. Repetir a reserva de um pedido retorna as reservas existentes
+// @Tags reservas de estoque
+// @Accept json
+// @Produce json
+// @Param reservation body models.StockReservationRequest true "Order ID and items"
+// @Success 201 {object} []models.StockReservation
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /stock-reservations [post]
+func (sc *StockReservationController) CreateStockReservation(w http.ResponseWriter, r *http.Request) {
+	var request models.StockReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	reservations, err := sc.service.ReserveStock(r.Context(), &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reservations)
+}
+
+// GetStockReservations Lista as reservas de estoque de um pedido
+// @Summary Lista as reservas de estoque de um pedido
+// @Description Lista as reservas do pedido com o status de cada produto: reserved, released ou shipped
+// @Tags reservas de estoque
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} []models.StockReservation
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /stock-reservations/{id} [get]
+func (sc *StockReservationController) GetStockReservations(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	reservations, err := sc.service.GetStockReservations(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(reservations)
+}
+
+// ReleaseStockReservation Devolve ao estoque as reservas de um pedido
+// @Summary Devolve ao estoque as reservas de um pedido
+// @Description Usado no cancelamento do pedido e como compensação de um checkout que falhou. Pedidos sem reservas ou já liberados respondem 204; pedidos já enviados respondem 409
+// @Tags reservas de estoque
+// @Param id path int true "ID do pedido"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /stock-reservations/{id} [delete]
+func (sc *StockReservationController) ReleaseStockReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	if err := sc.service.ReleaseStock(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CommitStockReservation Torna definitivas as reservas de um pedido
+// @Summary Torna definitivas as reservas de um pedido
+// @Description Usado no envio do pedido: as reservas passam a shipped e não podem mais ser devolvidas ao estoque
+// @Tags reservas de estoque
+// @Produce json
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} []models.StockReservation
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /stock-reservations/{id}/commit [post]
+func (sc *StockReservationController) CommitStockReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	reservations, err := sc.service.CommitStock(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(reservations)
+}
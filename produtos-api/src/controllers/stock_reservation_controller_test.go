@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) HandleOrderEvent(ctx context.Context, msg broker.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+func (m *MockInventoryService) GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+func (m *MockInventoryService) ReleaseStock(ctx context.Context, orderID uint) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+func newStockReservationRouter(service services.InventoryService) *mux.Router {
+	controller := NewStockReservationController(service)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/stock-reservations", controller.CreateStockReservation).Methods(http.MethodPost)
+	r.HandleFunc("/stock-reservations/{id}", controller.GetStockReservations).Methods(http.MethodGet)
+	r.HandleFunc("/stock-reservations/{id}", controller.ReleaseStockReservation).Methods(http.MethodDelete)
+	r.HandleFunc("/stock-reservations/{id}/commit", controller.CommitStockReservation).Methods(http.MethodPost)
+	return r
+}
+
+func TestCreateStockReservationController(t *testing.T) {
+	mockService := new(MockInventoryService)
+	request := &models.StockReservationRequest{OrderID: 42, Items: []models.StockReservationItem{{ProductID: 1, Quantity: 3}}}
+	mockService.On("ReserveStock", mock.Anything, request).Return([]models.StockReservation{
+		{ID: 7, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/stock-reservations", strings.NewReader(`{"order_id":42,"items":[{"product_id":1,"quantity":3}]}`))
+	rr := httptest.NewRecorder()
+
+	newStockReservationRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"reserved"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateStockReservationControllerInsufficientStock(t *testing.T) {
+	mockService := new(MockInventoryService)
+	conflict := services.ConflictError(services.CodeInsufficientStock, "Insufficient stock for the order", nil)
+	conflict.Fields = []services.FieldError{{Field: "items", Code: services.CodeInsufficientStock, Param: "2", Message: "items: insufficient stock for product 2"}}
+	mockService.On("ReserveStock", mock.Anything, mock.Anything).Return([]models.StockReservation(nil), conflict)
+
+	req := httptest.NewRequest(http.MethodPost, "/stock-reservations", strings.NewReader(`{"order_id":42,"items":[{"product_id":2,"quantity":5}]}`))
+	req.Header.Set("Accept-Language", "pt-BR")
+	rr := httptest.NewRecorder()
+
+	newStockReservationRouter(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"insufficient_stock"`)
+	assert.Contains(t, rr.Body.String(), `"param":"2"`)
+	assert.Contains(t, rr.Body.String(), "estoque insuficiente para o produto 2")
+}
+
+func TestStockReservationControllerByOrder(t *testing.T) {
+	mockService := new(MockInventoryService)
+	mockService.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{{ID: 7, OrderID: 42}}, nil)
+	mockService.On("ReleaseStock", mock.Anything, uint(42)).Return(nil)
+	mockService.On("CommitStock", mock.Anything, uint(42)).Return([]models.StockReservation{{ID: 7, Status: models.ReservationShipped}}, nil)
+	router := newStockReservationRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stock-reservations/42", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"order_id":42`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/stock-reservations/42", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/stock-reservations/42/commit", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"shipped"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockReservationControllerErrors(t *testing.T) {
+	mockService := new(MockInventoryService)
+	mockService.On("GetStockReservations", mock.Anything, uint(41)).
+		Return([]models.StockReservation(nil), services.NotFoundError(services.CodeStockReservationNotFound, "Stock reservation not found", nil))
+	mockService.On("ReleaseStock", mock.Anything, uint(42)).
+		Return(services.ConflictError(services.CodeStockReservationClosed, "Stock reservation of the order was already shipped", nil))
+	router := newStockReservationRouter(mockService)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stock-reservations/41", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/stock-reservations/42", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"stock_reservation_closed"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/stock-reservations/abc/commit", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/stock-reservations", strings.NewReader(`{"order_id":`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "CommitStock", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"produtos-api/src/i18n"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+)
+
+// unsubscribePage é a página aberta pelo link de cancelamento dos avisos. O GET só mostra o botão de
+// confirmação, que envia o POST: leitores de e-mail e antivírus que abrem os links não cancelam os avisos.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: sans-serif; color: #222;">
+  <h1 style="font-size: 20px;">{{.Title}}</h1>
+  <p>{{.Message}}</p>
+  {{- if .Submit}}
+  <form method="post"><button type="submit">{{.Submit}}</button></form>
+  {{- end}}
+</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Locale, Title, Message, Submit string
+}
+
+// StockSubscriptionController is a struct that defines the back-in-stock subscription controller
+type StockSubscriptionController struct {
+	service services.StockSubscriptionService
+}
+
+// NewStockSubscriptionController is a function that creates a new back-in-stock subscription controller
+func NewStockSubscriptionController(service services.StockSubscriptionService) *StockSubscriptionController {
+	return &StockSubscriptionController{service: service}
+}
+
+// Subscribe Inscreve um destinatário no aviso de volta ao estoque
+// @Summary Inscreve um destinatário no aviso de volta ao estoque
+// @Description Registra um e-mail ou um cliente (customer_id, do próprio titular ou qualquer um para editores) para receber um único e-mail, no idioma do Accept-Language, quando o estoque do produto sair de zero. O token de cancelamento só é exibido nesta resposta
+// @Tags avisos de estoque
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do produto"
+// @Param subscription body models.StockSubscriptionRequest true "email or customer_id"
+// @Success 201 {object} models.IssuedStockSubscription
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /products/{id}/subscriptions [post]
+func (sc *StockSubscriptionController) Subscribe(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var request models.StockSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	issued, err := sc.service.Subscribe(r.Context(), id, &request)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(issued)
+}
+
+// Unsubscribe Cancela um aviso de volta ao estoque
+// @Summary Cancela um aviso de volta ao estoque
+// @Description Cancela o aviso com o token retornado na inscrição, ou todos os avisos pendentes do destinatário com o token do link de um aviso enviado; não exige autenticação
+// @Tags avisos de estoque
+// @Param token path string true "Token de cancelamento"
+// @Success 204
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /stock-subscriptions/{token} [delete]
+func (sc *StockSubscriptionController) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := sc.service.Unsubscribe(r.Context(), mux.Vars(r)["token"]); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ConfirmUnsubscribe Mostra a confirmação do cancelamento dos avisos de volta ao estoque
+// @Summary Mostra a confirmação do cancelamento dos avisos de volta ao estoque
+// @Description Página HTML aberta pelo link de cancelamento dos e-mails de aviso. Não cancela nada: o botão da página envia o POST para a mesma URL; não exige autenticação
+// @Tags avisos de estoque
+// @Produce html
+// @Param token path string true "Token de cancelamento"
+// @Success 200 {string} string "Página de confirmação"
+// @Router /stock-subscriptions/{token} [get]
+func (sc *StockSubscriptionController) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+	writeUnsubscribePage(w, http.StatusOK, unsubscribePageData{
+		Locale:  locale,
+		Title:   i18n.T(locale, "page.unsubscribe.title", nil),
+		Message: i18n.T(locale, "page.unsubscribe.confirm", nil),
+		Submit:  i18n.T(locale, "page.unsubscribe.submit", nil),
+	})
+}
+
+// UnsubscribeFromPage Cancela os avisos de volta ao estoque pela página de confirmação
+// @Summary Cancela os avisos de volta ao estoque pela página de confirmação
+// @Description Recebe o formulário da página de confirmação e cancela os avisos como o DELETE, respondendo com uma página HTML; não exige autenticação
+// @Tags avisos de estoque
+// @Produce html
+// @Param token path string true "Token de cancelamento"
+// @Success 200 {string} string "Página de cancelamento concluído"
+// @Failure 404 {string} string "Página de erro"
+// @Failure 503 {string} string "Página de erro"
+// @Router /stock-subscriptions/{token} [post]
+func (sc *StockSubscriptionController) UnsubscribeFromPage(w http.ResponseWriter, r *http.Request) {
+	if err := sc.service.Unsubscribe(r.Context(), mux.Vars(r)["token"]); err != nil {
+		problem, locale := resolveProblem(r, err)
+		writeUnsubscribePage(w, problem.Status, unsubscribePageData{
+			Locale:  locale,
+			Title:   i18n.T(locale, "page.unsubscribe.title", nil),
+			Message: problem.Title,
+		})
+		return
+	}
+
+	locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+	writeUnsubscribePage(w, http.StatusOK, unsubscribePageData{
+		Locale:  locale,
+		Title:   i18n.T(locale, "page.unsubscribe.done_title", nil),
+		Message: i18n.T(locale, "page.unsubscribe.done", nil),
+	})
+}
+
+func writeUnsubscribePage(w http.ResponseWriter, status int, data unsubscribePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", data.Locale)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(status)
+	unsubscribePage.Execute(w, data)
+}
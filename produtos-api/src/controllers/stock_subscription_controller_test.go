@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/mail"
+	"produtos-api/src/models"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockStockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockStockSubscriptionService) Subscribe(ctx context.Context, productID uint, request *models.StockSubscriptionRequest) (*models.IssuedStockSubscription, error) {
+	args := m.Called(ctx, productID, request)
+	return args.Get(0).(*models.IssuedStockSubscription), args.Error(1)
+}
+
+func (m *MockStockSubscriptionService) Unsubscribe(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionService) HandleEvent(ctx context.Context, msg broker.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionService) NotifyDue(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSubscribeController(t *testing.T) {
+	mockService := new(MockStockSubscriptionService)
+	controller := NewStockSubscriptionController(mockService)
+
+	mockService.On("Subscribe", mock.Anything, uint(1), &models.StockSubscriptionRequest{Email: "maria@example.com"}).Return(
+		&models.IssuedStockSubscription{
+			StockSubscription: models.StockSubscription{ID: 7, ProductID: 1, Email: "maria@example.com", TokenHash: "hash"},
+			UnsubscribeToken:  "unsub_plain",
+		}, nil)
+	mockService.On("Subscribe", mock.Anything, uint(2), mock.Anything).Return((*models.IssuedStockSubscription)(nil),
+		services.ConflictError(services.CodeProductInStock, "Product is in stock", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/products/{id}/subscriptions", controller.Subscribe).Methods(http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/products/1/subscriptions", strings.NewReader(`{"email":"maria@example.com"}`)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"unsubscribe_token":"unsub_plain"`)
+	assert.NotContains(t, rr.Body.String(), "hash")
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/products/2/subscriptions", strings.NewReader(`{"customer_id":3}`)))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"product_in_stock"`)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/products/1/subscriptions", strings.NewReader(`{"email":`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUnsubscribeController(t *testing.T) {
+	mockService := new(MockStockSubscriptionService)
+	controller := NewStockSubscriptionController(mockService)
+
+	mockService.On("Unsubscribe", mock.Anything, "unsub_valid").Return(nil)
+	mockService.On("Unsubscribe", mock.Anything, "unsub_used").Return(
+		services.NotFoundError(services.CodeStockSubscriptionNotFound, "Stock subscription not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/stock-subscriptions/{token}", controller.Unsubscribe).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/stock-subscriptions/unsub_valid", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/stock-subscriptions/unsub_used", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"stock_subscription_not_found"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestUnsubscribeLinkFromEmail(t *testing.T) {
+	mockService := new(MockStockSubscriptionService)
+	controller := NewStockSubscriptionController(mockService)
+
+	mockService.On("Unsubscribe", mock.Anything, "unsub_recipient").Return(nil).Once()
+	mockService.On("Unsubscribe", mock.Anything, "unsub_recipient").Return(
+		services.NotFoundError(services.CodeStockSubscriptionNotFound, "Stock subscription not found", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/stock-subscriptions/{token}", controller.ConfirmUnsubscribe).Methods(http.MethodGet)
+	r.HandleFunc("/stock-subscriptions/{token}", controller.UnsubscribeFromPage).Methods(http.MethodPost)
+	r.HandleFunc("/stock-subscriptions/{token}", controller.Unsubscribe).Methods(http.MethodDelete)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// O link é montado como o serviço monta com o STOCK_UNSUBSCRIBE_URL padrão
+	content, err := mail.Render(mail.TemplateBackInStock, "pt-BR", mail.BackInStock{
+		ProductID: 1, ProductName: "Caneta", Price: 2.5,
+		UnsubscribeURL: strings.ReplaceAll(server.URL+"/stock-subscriptions/{token}", "{token}", "unsub_recipient"),
+	})
+	require.NoError(t, err)
+	link := regexp.MustCompile(`<a href="([^"]+)"`).FindStringSubmatch(content.HTML)
+	require.Len(t, link, 2)
+	assert.Contains(t, content.Text, link[1])
+
+	// Abrir o link só mostra a confirmação
+	resp, err := http.Get(link[1])
+	require.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(page), `<form method="post">`)
+	mockService.AssertNotCalled(t, "Unsubscribe", mock.Anything, mock.Anything)
+
+	// O formulário envia o POST para a própria URL do link
+	resp, err = http.Post(link[1], "application/x-www-form-urlencoded", nil)
+	require.NoError(t, err)
+	page, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(page), "Alerts cancelled")
+
+	resp, err = http.Post(link[1], "application/x-www-form-urlencoded", nil)
+	require.NoError(t, err)
+	page, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, string(page), "Stock subscription not found")
+	mockService.AssertExpectations(t)
+}
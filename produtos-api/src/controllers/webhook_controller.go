@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/services"
+)
+
+// WebhookController is a struct that defines the webhook controller
+type WebhookController struct {
+	service services.WebhookService
+}
+
+// NewWebhookController is a function that creates a new webhook controller
+func NewWebhookController(service services.WebhookService) *WebhookController {
+	return &WebhookController{service: service}
+}
+
+// CreateWebhook Cadastra um webhook
+// @Summary Cadastra um webhook
+// @Description Cadastra um endpoint que recebe os eventos do catálogo por HTTP. Sem event_types, recebe todos os eventos. O segredo usado para verificar o header X-Webhook-Signature só é exibido nesta resposta
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.Webhook true "URL and optional event_types"
+// @Success 201 {object} models.IssuedWebhook
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks [post]
+func (wc *WebhookController) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+
+	issued, err := wc.service.CreateWebhook(r.Context(), &webhook)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(issued)
+}
+
+// GetWebhooks Lista os webhooks
+// @Summary Lista os webhooks
+// @Description Lista os webhooks com estado e falhas seguidas; os segredos não são retornados
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} []models.Webhook
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks [get]
+func (wc *WebhookController) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := wc.service.GetWebhooks(r.Context())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// GetWebhook Busca um webhook
+// @Summary Busca um webhook
+// @Description Busca um webhook pelo ID
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id} [get]
+func (wc *WebhookController) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	webhook, err := wc.service.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook Atualiza um webhook
+// @Summary Atualiza um webhook
+// @Description Substitui URL, event_types e active. Reativar um webhook desativado por falhas zera a contagem de falhas e retoma as entregas pendentes
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Param webhook body models.Webhook true "URL, event_types and active"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id} [put]
+func (wc *WebhookController) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	var webhook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		writeProblem(w, r, invalidBody(err))
+		return
+	}
+	webhook.ID = id
+
+	if err := wc.service.UpdateWebhook(r.Context(), &webhook); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook Remove um webhook
+// @Summary Remove um webhook
+// @Description Remove o webhook e o seu registro de entregas; as entregas pendentes são descartadas
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Success 204
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id} [delete]
+func (wc *WebhookController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	if err := wc.service.DeleteWebhook(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateWebhookSecret Gera um novo segredo para o webhook
+// @Summary Gera um novo segredo para o webhook
+// @Description Substitui o segredo de assinatura; as próximas entregas, inclusive as pendentes, são assinadas com o novo segredo
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Success 200 {object} models.IssuedWebhook
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id}/rotate [post]
+func (wc *WebhookController) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	issued, err := wc.service.RotateWebhookSecret(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(issued)
+}
+
+// GetWebhookDeliveries Consulta o registro de entregas de um webhook
+// @Summary Consulta o registro de entregas de um webhook
+// @Description Lista as entregas da mais recente para a mais antiga, com tentativas, status HTTP e último erro. Use before_id com o último ID recebido para paginar
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Param status query string false "pending, succeeded ou failed"
+// @Param event_type query string false "Tipo do evento, ex: ProductCreated"
+// @Param before_id query int false "Retorna entregas com ID menor que o informado"
+// @Param limit query int false "Quantidade máxima de entregas (padrão 100, máximo 1000)"
+// @Success 200 {object} []models.WebhookDelivery
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id}/deliveries [get]
+func (wc *WebhookController) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	filter, err := parseDeliveryFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	filter.WebhookID = id
+
+	deliveries, err := wc.service.GetWebhookDeliveries(r.Context(), filter)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// ReplayWebhookDelivery Reenvia uma entrega
+// @Summary Reenvia uma entrega
+// @Description Coloca a entrega de novo na fila com as tentativas zeradas; o corpo e o ID do evento são os originais
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID do webhook"
+// @Param delivery path int true "ID da entrega"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} controllers.Problem
+// @Failure 401 {object} controllers.Problem
+// @Failure 403 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 503 {object} controllers.Problem
+// @Router /webhooks/{id}/deliveries/{delivery}/replay [post]
+func (wc *WebhookController) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, invalidID(err))
+		return
+	}
+
+	deliveryID, err := parseUintVar(r, "delivery")
+	if err != nil {
+		writeProblem(w, r, services.ValidationError(invalidParam("delivery")))
+		return
+	}
+
+	delivery, err := wc.service.ReplayWebhookDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// parseDeliveryFilter converte os parâmetros da consulta, reportando todos os inválidos
+func parseDeliveryFilter(query url.Values) (repositories.WebhookDeliveryFilter, error) {
+	filter := repositories.WebhookDeliveryFilter{Status: query.Get("status"), EventType: query.Get("event_type")}
+	var fields []services.FieldError
+
+	parseUint := func(name string) uint {
+		value := query.Get(name)
+		if value == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			fields = append(fields, invalidParam(name))
+		}
+		return uint(parsed)
+	}
+
+	filter.BeforeID = parseUint("before_id")
+	filter.Limit = int(parseUint("limit"))
+
+	if len(fields) > 0 {
+		return filter, services.ValidationError(fields...)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.IssuedWebhook, error) {
+	args := m.Called(ctx, webhook)
+	return args.Get(0).(*models.IssuedWebhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) RotateWebhookSecret(ctx context.Context, id uint) (*models.IssuedWebhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.IssuedWebhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhookDeliveries(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, deliveryID)
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func TestCreateWebhookController(t *testing.T) {
+	mockService := new(MockWebhookService)
+	controller := NewWebhookController(mockService)
+
+	webhook := &models.Webhook{URL: "https://partner.example.com/hooks", EventTypes: []string{"ProductCreated"}}
+	mockService.On("CreateWebhook", mock.Anything, webhook).Return(&models.IssuedWebhook{
+		Webhook: models.Webhook{ID: 1, URL: webhook.URL, EventTypes: webhook.EventTypes, Active: true},
+		Secret:  "whsec_plain",
+	}, nil)
+
+	body := `{"url":"https://partner.example.com/hooks","event_types":["ProductCreated"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(controller.CreateWebhook).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"secret":"whsec_plain"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetWebhookDeliveriesController(t *testing.T) {
+	mockService := new(MockWebhookService)
+	controller := NewWebhookController(mockService)
+
+	filter := repositories.WebhookDeliveryFilter{WebhookID: 1, Status: "failed", EventType: "StockChanged", BeforeID: 50, Limit: 10}
+	mockService.On("GetWebhookDeliveries", mock.Anything, filter).Return([]models.WebhookDelivery{
+		{ID: 42, WebhookID: 1, EventID: "e1", EventType: "StockChanged", Status: "failed", Attempts: 8, ResponseStatus: 500},
+	}, nil)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/webhooks/{id}/deliveries", controller.GetWebhookDeliveries).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/1/deliveries?status=failed&event_type=StockChanged&before_id=50&limit=10", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":42`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetWebhookDeliveriesControllerInvalidParams(t *testing.T) {
+	mockService := new(MockWebhookService)
+	controller := NewWebhookController(mockService)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/webhooks/{id}/deliveries", controller.GetWebhookDeliveries).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/1/deliveries?before_id=abc&limit=-1", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"before_id"`)
+	assert.Contains(t, rr.Body.String(), `"field":"limit"`)
+	mockService.AssertNotCalled(t, "GetWebhookDeliveries", mock.Anything, mock.Anything)
+}
+
+func TestReplayWebhookDeliveryController(t *testing.T) {
+	mockService := new(MockWebhookService)
+	controller := NewWebhookController(mockService)
+
+	mockService.On("ReplayWebhookDelivery", mock.Anything, uint(1), uint(42)).Return(
+		&models.WebhookDelivery{ID: 42, WebhookID: 1, Status: models.DeliveryPending}, nil)
+	mockService.On("ReplayWebhookDelivery", mock.Anything, uint(2), uint(42)).Return((*models.WebhookDelivery)(nil),
+		services.ConflictError(services.CodeWebhookDisabled, "Webhook is disabled", nil))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/webhooks/{id}/deliveries/{delivery}/replay", controller.ReplayWebhookDelivery).Methods(http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/42/replay", nil))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"pending"`)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks/2/deliveries/42/replay", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"webhook_disabled"`)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/abc/replay", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+package customers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var tracer = otel.Tracer("produtos-api/src/customers")
+
+// responseLimit limita o corpo lido das respostas da API de clientes
+const responseLimit = 1 << 20
+
+// requestIDHeader é o header de correlação entre os microsserviços (middlewares.RequestIDHeader)
+const requestIDHeader = "X-Request-ID"
+
+// ErrNotFound indica que o cliente não existe ou teve os dados apagados
+var ErrNotFound = errors.New("customers: not found")
+
+// StatusError é uma resposta de erro não mapeada da API de clientes, no formato RFC 7807
+type StatusError struct {
+	Status int
+	Code   string
+	Title  string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("customers: unexpected status %d (%s): %s", e.Status, e.Code, e.Title)
+}
+
+// Config define o endereço da API de clientes e o token enviado como Bearer. O token precisa do
+// papel editor para ler clientes de qualquer titular.
+type Config struct {
+	BaseURL string
+	Token   string
+	Timeout time.Duration
+}
+
+// Client chama a API de clientes, propagando o trace context e o X-Request-ID da requisição de origem
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+// NewClient cria o cliente da API de clientes
+func NewClient(config Config) *Client {
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Client{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// GetCustomer busca o cliente; retorna ErrNotFound se ele não existir ou tiver os dados apagados
+func (c *Client) GetCustomer(ctx context.Context, id uint) (*models.Customer, error) {
+	path := fmt.Sprintf("/customers/%d", id)
+	ctx, span := tracer.Start(ctx, "customers GET "+path)
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		request.Header.Set(requestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	reader := io.LimitReader(response.Body, responseLimit)
+	if response.StatusCode == http.StatusOK {
+		var customer models.Customer
+		if err := json.NewDecoder(reader).Decode(&customer); err != nil {
+			return nil, err
+		}
+		return &customer, nil
+	}
+
+	var problem struct {
+		Title string `json:"title"`
+		Code  string `json:"code"`
+	}
+	json.NewDecoder(reader).Decode(&problem)
+	if response.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("%w: %s", ErrNotFound, problem.Title)
+	} else {
+		err = &StatusError{Status: response.StatusCode, Code: problem.Code, Title: problem.Title}
+	}
+	span.RecordError(err)
+	return nil, err
+}
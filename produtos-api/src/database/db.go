@@ -3,14 +3,89 @@ package database
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"produtos-api/src/config"
+	"produtos-api/src/logging"
 	"produtos-api/src/models"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// SetupDatabase inicializa a conexão com o banco de dados real ou de testes
-func SetupDatabase() (*gorm.DB, error) {
+// Schema descreve o banco de um microsserviço: o arquivo do SQLite, os modelos migrados
+// automaticamente, o diretório das migrações SQL, as migrações compartilhadas com outros bancos
+// e os comandos executados depois da migração. SetupMigrations são arquivos de MigrationsDir
+// idempotentes, também aplicados na inicialização para o que o GORM não declara nas tags, como
+// índices de expressão; assim o arquivo é a única definição e o golang-migrate não falha depois.
+type Schema struct {
+	File            string
+	Models          []interface{}
+	MigrationsDir   string
+	Shared          []SharedMigrations
+	Setup           []string
+	SetupMigrations []string
+}
+
+// SharedMigrations são as migrações SQL de tabelas comuns a vários bancos, mantidas em um só diretório.
+// Cada banco registra as versões aplicadas em Table, separada da schema_migrations das próprias migrações.
+type SharedMigrations struct {
+	Dir   string
+	Table string
+}
+
+// MailMigrations criam a fila de e-mails das notificações
+var MailMigrations = SharedMigrations{Dir: "db/mail/migrations", Table: "mail_schema_migrations"}
+
+// Products é o banco do catálogo de produtos
+var Products = Schema{
+	File: "products.sqlite",
+	Models: []interface{}{
+		&models.Product{},
+		&models.ProductTranslation{},
+		&models.APIKey{},
+		&models.AuditRecord{},
+		&models.ProductRevision{},
+		&models.OutboxEvent{},
+		&models.StockReservation{},
+		&models.ProcessedEvent{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.StockSubscription{},
+		&models.StockSubscriptionRecipient{},
+		&models.MailMessage{},
+	},
+	MigrationsDir: "db/migrations",
+	Shared:        []SharedMigrations{MailMigrations},
+	Setup:         []string{backfillRevisionsSQL},
+	// Unicidade do nome (sem diferenciar maiúsculas) na categoria entre os produtos ativos, que a validação
+	// só verifica antes de gravar. Os nomes repetidos de bancos existentes recebem o ID como sufixo.
+	SetupMigrations: []string{"20261020000000_add_unique_product_name_index.up.sql"},
+}
+
+// Orders é o banco do microsserviço de pedidos, com a sua própria outbox e fila de e-mails
+var Orders = Schema{
+	File:          "orders.sqlite",
+	Models:        []interface{}{&models.Order{}, &models.OrderItem{}, &models.OutboxEvent{}, &models.MailMessage{}},
+	MigrationsDir: "db/orders/migrations",
+	Shared:        []SharedMigrations{MailMigrations},
+}
+
+// Customers é o banco do microsserviço de clientes
+var Customers = Schema{
+	File:          "customers.sqlite",
+	Models:        []interface{}{&models.Customer{}, &models.CustomerAddress{}, &models.ConsentRecord{}},
+	MigrationsDir: "db/customers/migrations",
+}
+
+// backfillRevisionsSQL inicia o histórico dos produtos que ainda não têm revisões com o seu estado atual
+const backfillRevisionsSQL = `INSERT INTO product_revisions (product_id, revision, name, description, category, price, stock, deleted, created_at, valid_from)
+SELECT id, 1, name, description, category, price, stock, deleted_at IS NOT NULL, created_at, COALESCE(deleted_at, updated_at, created_at, CURRENT_TIMESTAMP)
+FROM products
+WHERE NOT EXISTS (SELECT 1 FROM product_revisions WHERE product_revisions.product_id = products.id)`
+
+// SetupDatabase inicializa a conexão com o banco de dados real ou de testes do schema
+func SetupDatabase(schema Schema) (*gorm.DB, error) {
 	var db *gorm.DB
 	var err error
 
@@ -23,21 +98,46 @@ func SetupDatabase() (*gorm.DB, error) {
 	}
 
 	// Em produção, usamos a base de dados real
-	db, err = gorm.Open(sqlite.Open("products.sqlite"), &gorm.Config{})
+	db, err = gorm.Open(sqlite.Open(schema.File), gormConfig())
 
 	if err != nil {
 		return nil, fmt.Errorf("erro ao conectar ao banco de dados: %v", err)
 	}
 
-	// Migrar o modelo de produto
-	err = db.AutoMigrate(&models.Product{})
+	// Migrar os modelos da aplicação
+	err = db.AutoMigrate(schema.Models...)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao migrar o modelo de produto: %v", err)
+		return nil, fmt.Errorf("erro ao migrar os modelos de %s: %v", schema.File, err)
+	}
+
+	for _, statement := range schema.Setup {
+		if err = db.Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("erro ao preparar %s: %v", schema.File, err)
+		}
+	}
+
+	if err = applySetupMigrations(db, schema); err != nil {
+		return nil, err
 	}
 
 	return db, nil
 }
 
+// applySetupMigrations executa os arquivos de SetupMigrations do schema
+func applySetupMigrations(db *gorm.DB, schema Schema) error {
+	for _, name := range schema.SetupMigrations {
+		statements, err := os.ReadFile(filepath.Join(schema.MigrationsDir, name))
+		if err != nil {
+			return fmt.Errorf("erro ao ler a migração %s: %v", name, err)
+		}
+		if err = db.Exec(string(statements)).Error; err != nil {
+			return fmt.Errorf("erro ao aplicar a migração %s em %s: %v", name, schema.File, err)
+		}
+	}
+
+	return nil
+}
+
 // isTesting verifica se estamos em ambiente de testes
 func isTesting() bool {
 	// Verifique se a variável de ambiente TEST_ENV está definida como "true"
@@ -47,10 +147,19 @@ func isTesting() bool {
 // SetupTestDatabase configura o banco de dados para o ambiente de testes
 func SetupTestDatabase() (*gorm.DB, error) {
 	// Aqui, criamos um banco de dados em memória para os testes
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), gormConfig())
 	if err != nil {
 		return nil, fmt.Errorf("erro ao configurar banco de dados de testes: %v", err)
 	}
 
 	return db, nil
 }
+
+// gormConfig direciona os logs do GORM para o slog; DB_SLOW_QUERY_THRESHOLD define o limite de consulta lenta.
+// TranslateError converte os erros do driver em erros do GORM, como gorm.ErrDuplicatedKey.
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		TranslateError: true,
+		Logger:         logging.NewGormLogger("database", config.GetDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond)),
+	}
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"testing"
+
+	"produtos-api/src/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSetupMigrationsRenameDuplicateProductNames(t *testing.T) {
+	db, err := SetupTestDatabase()
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Product{}))
+
+	products := []models.Product{
+		{Name: "Caneta", Category: "papelaria"},
+		{Name: "caneta", Category: "papelaria"},
+		{Name: "Caneta", Category: "escritorio"},
+		{Name: "Caneta", Category: "papelaria", DeletedAt: gorm.DeletedAt{Valid: true}},
+	}
+	require.NoError(t, db.Create(&products).Error)
+
+	schema := Products
+	schema.MigrationsDir = "../../db/migrations"
+	require.NoError(t, applySetupMigrations(db, schema))
+	// A migração é idempotente: a inicialização seguinte e o golang-migrate podem aplicá-la de novo
+	require.NoError(t, applySetupMigrations(db, schema))
+
+	var names []string
+	require.NoError(t, db.Unscoped().Model(&models.Product{}).Order("id").Pluck("name", &names).Error)
+	assert.Equal(t, []string{"Caneta", "caneta (2)", "Caneta", "Caneta"}, names)
+
+	err = db.Create(&models.Product{Name: "CANETA", Category: "papelaria"}).Error
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Ping verifica a conectividade com o banco de dados através do pool do GORM
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("erro ao obter o pool de conexões: %v", err)
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+// PendingMigrations retorna as migrações que ainda não foram aplicadas no banco.
+// As tabelas dos modelos migrados automaticamente sempre são verificadas; as tabelas de versões
+// do golang-migrate, das migrações do banco e das compartilhadas, só são consultadas quando existirem.
+func PendingMigrations(ctx context.Context, db *gorm.DB, schema Schema) ([]string, error) {
+	var pending []string
+	migrator := db.WithContext(ctx).Migrator()
+
+	for _, model := range schema.Models {
+		if !migrator.HasTable(model) {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return nil, err
+			}
+			pending = append(pending, stmt.Schema.Table)
+		}
+	}
+
+	versions, err := pendingVersions(ctx, db, "schema_migrations", schema.MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	pending = append(pending, versions...)
+
+	for _, shared := range schema.Shared {
+		versions, err := pendingVersions(ctx, db, shared.Table, shared.Dir)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, versions...)
+	}
+
+	return pending, nil
+}
+
+// pendingVersions compara a versão gravada na tabela do golang-migrate com os arquivos do diretório
+func pendingVersions(ctx context.Context, db *gorm.DB, table, dir string) ([]string, error) {
+	if !db.WithContext(ctx).Migrator().HasTable(table) {
+		return nil, nil
+	}
+
+	var state struct {
+		Version int64
+		Dirty   bool
+	}
+	if err := db.WithContext(ctx).Table(table).Select("version, dirty").Take(&state).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", table, err)
+	}
+	if state.Dirty {
+		return nil, fmt.Errorf("migração %d está marcada como dirty", state.Version)
+	}
+
+	versions, err := migrationVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, version := range versions {
+		if version > state.Version {
+			pending = append(pending, strconv.FormatInt(version, 10))
+		}
+	}
+
+	return pending, nil
+}
+
+// migrationVersions lê as versões dos arquivos *.up.sql do diretório de migrações do golang-migrate
+func migrationVersions(dir string) ([]int64, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions, nil
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"time"
+
+	"produtos-api/src/models"
+)
+
+// Tipos dos eventos de domínio publicados pelo microsserviço de produtos
+const (
+	ProductCreated = "ProductCreated"
+	ProductUpdated = "ProductUpdated"
+	ProductDeleted = "ProductDeleted"
+	StockChanged   = "StockChanged"
+	// StockInsufficient compensa um OrderPlaced que não pôde ser atendido
+	StockInsufficient = "StockInsufficient"
+)
+
+// Tipos dos eventos publicados pelo microsserviço de pedidos e consumidos aqui
+const (
+	OrderPlaced    = "OrderPlaced"
+	OrderCancelled = "OrderCancelled"
+	OrderShipped   = "OrderShipped"
+)
+
+// OrderConfirmed é publicado pelo microsserviço de pedidos quando o pagamento é confirmado; é consumido
+// pelas notificações, que enviam o e-mail de confirmação ao cliente
+const OrderConfirmed = "OrderConfirmed"
+
+// Published lista os tipos de evento publicados pela API, que podem ser assinados pelos webhooks
+var Published = []string{ProductCreated, ProductUpdated, ProductDeleted, StockChanged, StockInsufficient}
+
+// Tipos de agregado dos eventos
+const (
+	AggregateProduct = "product"
+	AggregateOrder   = "order"
+)
+
+// Product é o payload de ProductCreated, ProductUpdated e ProductDeleted
+type Product struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Price       float64   `json:"price"`
+	Stock       int       `json:"stock"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StockChange é o payload de StockChanged
+type StockChange struct {
+	ProductID uint `json:"product_id"`
+	Previous  int  `json:"previous"`
+	Current   int  `json:"current"`
+}
+
+// OrderItem é um item de OrderPlaced
+type OrderItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// Order é o payload de OrderPlaced: o estoque dos itens deve ser reservado
+type Order struct {
+	OrderID  uint        `json:"order_id"`
+	Items    []OrderItem `json:"items"`
+	PlacedAt time.Time   `json:"placed_at"`
+}
+
+// OrderCancellation é o payload de OrderCancelled: a reserva do pedido deve ser devolvida ao estoque
+type OrderCancellation struct {
+	OrderID     uint      `json:"order_id"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+// OrderShipment é o payload de OrderShipped: a reserva do pedido saiu definitivamente do estoque
+type OrderShipment struct {
+	OrderID   uint      `json:"order_id"`
+	ShippedAt time.Time `json:"shipped_at"`
+}
+
+// OrderConfirmation é o payload de OrderConfirmed, com o necessário para avisar o cliente;
+// Locale é o idioma negociado no checkout
+type OrderConfirmation struct {
+	OrderID     uint            `json:"order_id"`
+	CustomerID  uint            `json:"customer_id"`
+	Email       string          `json:"email"`
+	Locale      string          `json:"locale"`
+	Items       []ConfirmedItem `json:"items"`
+	Total       float64         `json:"total"`
+	ConfirmedAt time.Time       `json:"confirmed_at"`
+}
+
+// ConfirmedItem é um item de OrderConfirmed, com o nome e o preço do checkout
+type ConfirmedItem struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	UnitPrice float64 `json:"unit_price"`
+	Quantity  int     `json:"quantity"`
+}
+
+// MissingStock é um item de StockInsufficient; Available é zero para produtos inexistentes
+type MissingStock struct {
+	ProductID uint `json:"product_id"`
+	Requested int  `json:"requested"`
+	Available int  `json:"available"`
+}
+
+// StockShortage é o payload de StockInsufficient: nenhum item do pedido foi reservado
+type StockShortage struct {
+	OrderID uint           `json:"order_id"`
+	EventID string         `json:"event_id"`
+	Items   []MissingStock `json:"items"`
+}
+
+// NewProduct cria o payload com o estado do produto
+func NewProduct(product *models.Product) Product {
+	return Product{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		UpdatedAt:   product.UpdatedAt,
+	}
+}
+
+// NewOrder cria o payload de OrderPlaced com os itens do pedido
+func NewOrder(order *models.Order) Order {
+	placed := Order{OrderID: order.ID, Items: []OrderItem{}, PlacedAt: order.CreatedAt}
+	for _, item := range order.Items {
+		placed.Items = append(placed.Items, OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	return placed
+}
+
+// NewOrderConfirmation cria o payload com o pedido pago
+func NewOrderConfirmation(order *models.Order) OrderConfirmation {
+	confirmation := OrderConfirmation{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Email:      order.Email,
+		Locale:     order.Locale,
+		Items:      []ConfirmedItem{},
+		Total:      order.Total,
+	}
+	if order.PaidAt != nil {
+		confirmation.ConfirmedAt = *order.PaidAt
+	}
+	for _, item := range order.Items {
+		confirmation.Items = append(confirmation.Items, ConfirmedItem{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			UnitPrice: item.UnitPrice,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return confirmation
+}
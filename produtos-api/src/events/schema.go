@@ -0,0 +1,203 @@
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// CurrentVersions é a versão do schema com que cada tipo de evento é publicado e, para os eventos
+// consumidos, a versão contra a qual são validados. Ao evoluir um payload, crie schemas/<Tipo>.v<N+1>.json,
+// incremente a versão aqui e garanta que TestSchemasAreCompatible passa.
+var CurrentVersions = map[string]int{
+	ProductCreated:    1,
+	ProductUpdated:    1,
+	ProductDeleted:    1,
+	StockChanged:      1,
+	StockInsufficient: 1,
+	OrderPlaced:       1,
+	OrderCancelled:    1,
+	OrderShipped:      1,
+	OrderConfirmed:    1,
+}
+
+var schemaFileName = regexp.MustCompile(`^([A-Za-z]+)\.v([0-9]+)\.json$`)
+
+// Schema é o subconjunto do JSON Schema usado nos contratos dos eventos:
+// type, properties, required, items e format (apenas date-time)
+type Schema struct {
+	ID         string             `json:"$id,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+// LoadSchema lê o schema da versão do tipo de evento
+func LoadSchema(eventType string, version int) (*Schema, error) {
+	data, err := schemaFiles.ReadFile(path.Join("schemas", fmt.Sprintf("%s.v%d.json", eventType, version)))
+	if err != nil {
+		return nil, fmt.Errorf("schema %s v%d not found: %w", eventType, version, err)
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("schema %s v%d is invalid: %w", eventType, version, err)
+	}
+
+	return &schema, nil
+}
+
+// SchemaVersions lista as versões existentes de cada tipo de evento, em ordem crescente
+func SchemaVersions() (map[string][]int, error) {
+	entries, err := fs.ReadDir(schemaFiles, "schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string][]int{}
+	for _, entry := range entries {
+		match := schemaFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected schema file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[2])
+		versions[match[1]] = append(versions[match[1]], version)
+	}
+	for _, list := range versions {
+		sort.Ints(list)
+	}
+
+	return versions, nil
+}
+
+// Validate verifica se o documento JSON segue o schema
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(at string, value interface{}) error {
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object", at)
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s.%s: required property is missing", at, name)
+			}
+		}
+		// Propriedades desconhecidas são aceitas para que consumidores antigos leiam versões novas
+		for name, property := range s.Properties {
+			if field, ok := object[name]; ok {
+				if err := property.validate(at+"."+name, field); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", at)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", at, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", at)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				return fmt.Errorf("%s: expected RFC 3339 date-time", at)
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if _, err := number.Int64(); !ok || err != nil {
+			return fmt.Errorf("%s: expected integer", at)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s: expected number", at)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", at)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", at, s.Type)
+	}
+
+	return nil
+}
+
+// CheckCompatibility verifica se newer pode substituir older nos dois sentidos: consumidores novos leem
+// eventos antigos e consumidores antigos leem eventos novos. Para isso newer não pode remover nem mudar
+// o tipo de propriedades existentes, nem tornar obrigatória uma propriedade que antes era opcional ou nova.
+func CheckCompatibility(older, newer *Schema) error {
+	return checkCompatibility("$", older, newer)
+}
+
+func checkCompatibility(at string, older, newer *Schema) error {
+	if older.Type != newer.Type {
+		return fmt.Errorf("%s: type changed from %s to %s", at, older.Type, newer.Type)
+	}
+
+	wasRequired := map[string]bool{}
+	for _, name := range older.Required {
+		wasRequired[name] = true
+	}
+	isRequired := map[string]bool{}
+	for _, name := range newer.Required {
+		isRequired[name] = true
+		if !wasRequired[name] {
+			return fmt.Errorf("%s.%s: property became required", at, name)
+		}
+	}
+
+	for name, property := range older.Properties {
+		next, ok := newer.Properties[name]
+		if !ok {
+			return fmt.Errorf("%s.%s: property was removed", at, name)
+		}
+		if wasRequired[name] && !isRequired[name] {
+			return fmt.Errorf("%s.%s: required property became optional", at, name)
+		}
+		if err := checkCompatibility(at+"."+name, property, next); err != nil {
+			return err
+		}
+	}
+
+	if older.Items != nil && newer.Items != nil {
+		return checkCompatibility(at+"[]", older.Items, newer.Items)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"produtos-api/src/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// payloads associa cada tipo de evento a um exemplo do payload publicado
+var payloads = map[string]interface{}{
+	ProductCreated:    NewProduct(&models.Product{ID: 1, Name: "Caneta", Price: 2.5, Stock: 10, UpdatedAt: time.Now()}),
+	ProductUpdated:    NewProduct(&models.Product{ID: 1, Name: "Caneta", Price: 2.5, Stock: 10, UpdatedAt: time.Now()}),
+	ProductDeleted:    NewProduct(&models.Product{ID: 1, Name: "Caneta", Price: 2.5, Stock: 10, UpdatedAt: time.Now()}),
+	StockChanged:      StockChange{ProductID: 1, Previous: 10, Current: 7},
+	StockInsufficient: StockShortage{OrderID: 1, EventID: "e1", Items: []MissingStock{{ProductID: 1, Requested: 3, Available: 1}}},
+	OrderPlaced:       Order{OrderID: 1, Items: []OrderItem{{ProductID: 1, Quantity: 3}}, PlacedAt: time.Now()},
+	OrderCancelled:    OrderCancellation{OrderID: 1, CancelledAt: time.Now()},
+	OrderShipped:      OrderShipment{OrderID: 1, ShippedAt: time.Now()},
+	OrderConfirmed: OrderConfirmation{OrderID: 1, Email: "maria@example.com", Locale: "pt-BR", Total: 5,
+		Items: []ConfirmedItem{{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}}, ConfirmedAt: time.Now()},
+}
+
+func TestSchemasAreCompatible(t *testing.T) {
+	versions, err := SchemaVersions()
+	require.NoError(t, err)
+
+	for eventType, current := range CurrentVersions {
+		list := versions[eventType]
+		require.NotEmpty(t, list, "%s has no schema", eventType)
+		assert.Equal(t, current, list[len(list)-1], "%s is not published with its latest schema", eventType)
+
+		for i, version := range list {
+			assert.Equal(t, i+1, version, "%s schema versions must be sequential", eventType)
+			if i == 0 {
+				continue
+			}
+			older, err := LoadSchema(eventType, list[i-1])
+			require.NoError(t, err)
+			newer, err := LoadSchema(eventType, version)
+			require.NoError(t, err)
+			assert.NoError(t, CheckCompatibility(older, newer), "%s v%d is incompatible with v%d", eventType, version, list[i-1])
+		}
+	}
+
+	for eventType := range versions {
+		_, ok := CurrentVersions[eventType]
+		assert.True(t, ok, "schema for unknown event type %s", eventType)
+	}
+}
+
+func TestPayloadsMatchCurrentSchemas(t *testing.T) {
+	for eventType, version := range CurrentVersions {
+		schema, err := LoadSchema(eventType, version)
+		require.NoError(t, err)
+
+		payload, ok := payloads[eventType]
+		require.True(t, ok, "no example payload for %s", eventType)
+		data, err := json.Marshal(payload)
+		require.NoError(t, err)
+		assert.NoError(t, schema.Validate(data), eventType)
+
+		// Um campo novo no payload exige uma nova versão do schema
+		var properties []string
+		for name := range schema.Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+		assert.Equal(t, properties, jsonFields(reflect.TypeOf(payload)), "%s payload and schema v%d differ", eventType, version)
+	}
+}
+
+func TestCheckCompatibilityRejectsBreakingChanges(t *testing.T) {
+	older := &Schema{Type: "object", Required: []string{"id"}, Properties: map[string]*Schema{
+		"id":   {Type: "integer"},
+		"note": {Type: "string"},
+	}}
+
+	cases := map[string]*Schema{
+		"removed property": {Type: "object", Required: []string{"id"}, Properties: map[string]*Schema{
+			"id": {Type: "integer"},
+		}},
+		"changed type": {Type: "object", Required: []string{"id"}, Properties: map[string]*Schema{
+			"id": {Type: "string"}, "note": {Type: "string"},
+		}},
+		"new required property": {Type: "object", Required: []string{"id", "sku"}, Properties: map[string]*Schema{
+			"id": {Type: "integer"}, "note": {Type: "string"}, "sku": {Type: "string"},
+		}},
+		"required became optional": {Type: "object", Properties: map[string]*Schema{
+			"id": {Type: "integer"}, "note": {Type: "string"},
+		}},
+	}
+	for name, newer := range cases {
+		assert.Error(t, CheckCompatibility(older, newer), name)
+	}
+
+	compatible := &Schema{Type: "object", Required: []string{"id"}, Properties: map[string]*Schema{
+		"id": {Type: "integer"}, "note": {Type: "string"}, "sku": {Type: "string"},
+	}}
+	assert.NoError(t, CheckCompatibility(older, compatible))
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := LoadSchema(StockChanged, 1)
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate([]byte(`{"product_id":1,"previous":2,"current":3,"extra":true}`)))
+	assert.Error(t, schema.Validate([]byte(`{"product_id":1,"previous":2}`)))
+	assert.Error(t, schema.Validate([]byte(`{"product_id":1,"previous":2.5,"current":3}`)))
+}
+
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
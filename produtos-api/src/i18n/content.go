@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type contentLocalesKey struct{}
+
+// CanonicalLocale valida e normaliza um locale BCP 47, ex: "pt-br" -> "pt-BR"
+func CanonicalLocale(locale string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil {
+		return "", err
+	}
+
+	return tag.String(), nil
+}
+
+// ContentCandidates converte um ?locale= ou Accept-Language na lista ordenada de locales
+// a procurar no conteúdo dos produtos. Cada locale é seguido do seu idioma base
+// (ex: "pt-PT" e depois "pt"), sem repetições.
+func ContentCandidates(value string) []string {
+	tags, _, err := language.ParseAcceptLanguage(value)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []string
+	seen := map[string]bool{}
+	add := func(locale string) {
+		if locale != "" && locale != "und" && !seen[locale] {
+			seen[locale] = true
+			candidates = append(candidates, locale)
+		}
+	}
+
+	for _, tag := range tags {
+		add(tag.String())
+		base, confidence := tag.Base()
+		if confidence != language.No {
+			add(base.String())
+		}
+	}
+
+	return candidates
+}
+
+// WithContentLocales guarda no contexto os locales preferidos para o conteúdo dos produtos
+func WithContentLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, contentLocalesKey{}, locales)
+}
+
+// ContentLocales retorna os locales preferidos para o conteúdo, ou nil quando o cliente não pediu tradução
+func ContentLocales(ctx context.Context) []string {
+	locales, _ := ctx.Value(contentLocalesKey{}).([]string)
+	return locales
+}
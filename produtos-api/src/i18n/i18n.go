@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale é usado quando o Accept-Language não corresponde a nenhum idioma suportado
+// e como fallback para chaves sem tradução
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+var (
+	// catalogs guarda as mensagens de cada locale, carregadas dos arquivos locales/<locale>.json
+	catalogs = map[string]map[string]string{}
+	locales  []string
+
+	// matcher e supported compartilham a mesma ordem; o locale padrão ocupa a posição 0
+	matcher   language.Matcher
+	supported = []string{DefaultLocale}
+)
+
+func init() {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: erro ao ler os catálogos: %v", err))
+	}
+
+	tags := []language.Tag{language.Make(DefaultLocale)}
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: erro ao ler o catálogo %s: %v", locale, err))
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: catálogo %s inválido: %v", locale, err))
+		}
+
+		catalogs[locale] = messages
+		locales = append(locales, locale)
+		if locale != DefaultLocale {
+			tags = append(tags, language.Make(locale))
+			supported = append(supported, locale)
+		}
+	}
+	sort.Strings(locales)
+
+	// O primeiro tag do matcher é o fallback quando nada corresponde
+	matcher = language.NewMatcher(tags)
+}
+
+// Locales retorna os locales suportados
+func Locales() []string {
+	return append([]string(nil), locales...)
+}
+
+// Keys retorna as chaves do catálogo do locale informado
+func Keys(locale string) []string {
+	keys := make([]string, 0, len(catalogs[locale]))
+	for key := range catalogs[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Negotiate escolhe o locale suportado que melhor atende ao header Accept-Language
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return supported[index]
+}
+
+// Has indica se a chave existe no catálogo do locale
+func Has(locale, key string) bool {
+	_, ok := catalogs[locale][key]
+	return ok
+}
+
+// T traduz a chave para o locale, substituindo os parâmetros no formato {nome}.
+// Chaves sem tradução usam o locale padrão; chaves inexistentes retornam a própria chave.
+func T(locale, key string, params map[string]string) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+
+	return message
+}
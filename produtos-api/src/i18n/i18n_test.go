@@ -0,0 +1,35 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsHaveTheSameKeys(t *testing.T) {
+	defaultKeys := Keys(DefaultLocale)
+	assert.NotEmpty(t, defaultKeys)
+
+	for _, locale := range Locales() {
+		for _, key := range defaultKeys {
+			assert.True(t, Has(locale, key), "key %q is missing from locale %s", key, locale)
+		}
+		for _, key := range Keys(locale) {
+			assert.True(t, Has(DefaultLocale, key), "key %q from locale %s is missing from the default locale", key, locale)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, "pt-BR", Negotiate("pt-BR,pt;q=0.9,en;q=0.8"))
+	assert.Equal(t, "pt-BR", Negotiate("pt"))
+	assert.Equal(t, "en", Negotiate("en-US"))
+	assert.Equal(t, DefaultLocale, Negotiate("ja"))
+	assert.Equal(t, DefaultLocale, Negotiate(""))
+}
+
+func TestTranslateFallsBackToDefaultLocale(t *testing.T) {
+	assert.Equal(t, "preço deve ser maior ou igual a 0", T("pt-BR", "validation.gte", map[string]string{"field": "preço", "param": "0"}))
+	assert.Equal(t, "Product not found", T("fr", "problem.product_not_found", nil))
+	assert.Equal(t, "unknown.key", T("pt-BR", "unknown.key", nil))
+}
@@ -0,0 +1,236 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// responseLimit limita o corpo lido das respostas
+const responseLimit = 8 << 20
+
+// requestIDHeader é o header de correlação entre os microsserviços (middlewares.RequestIDHeader)
+const requestIDHeader = "X-Request-ID"
+
+// ErrMissingResponse indica que o servidor não respondeu uma chamada do lote
+var ErrMissingResponse = errors.New("jsonrpc: missing response")
+
+// StatusError é uma resposta HTTP que não é JSON-RPC, como as recusas da autenticação, do limite de
+// requisições ou do prazo, no formato RFC 7807
+type StatusError struct {
+	Status int
+	Code   string
+	Title  string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("jsonrpc: unexpected status %d (%s): %s", e.Status, e.Code, e.Title)
+}
+
+// ClientConfig define o endpoint e as credenciais do cliente. APIKey é enviada no header X-API-Key;
+// sem ela, Token é enviado como Bearer.
+type ClientConfig struct {
+	URL     string
+	APIKey  string
+	Token   string
+	Timeout time.Duration
+}
+
+// Client chama um servidor JSON-RPC 2.0 por HTTP, propagando o trace context e o X-Request-ID da requisição de origem
+type Client struct {
+	config ClientConfig
+	client *http.Client
+	nextID atomic.Uint64
+}
+
+// NewClient cria o cliente
+func NewClient(config ClientConfig) *Client {
+	return &Client{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// BatchCall é uma chamada de um lote. Depois de Batch, Error traz o erro da chamada e Result, quando não
+// é nil, o resultado decodificado. Notificações não têm resultado nem erro.
+type BatchCall struct {
+	Method       string
+	Params       interface{}
+	Result       interface{}
+	Notification bool
+	Error        error
+}
+
+// Call chama o método e decodifica o resultado em result, que pode ser nil. Erros do método são *Error.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	request, err := c.request(method, params, false)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.post(ctx, method, request)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return ErrMissingResponse
+	}
+
+	var response Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+
+	return decodeResult(&response, result)
+}
+
+// Notify envia a notificação; o servidor não informa o resultado nem os erros do método
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	request, err := c.request(method, params, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.post(ctx, method, request)
+	return err
+}
+
+// Batch envia as chamadas em uma única requisição. O erro retornado é do transporte ou do lote como um
+// todo; os erros de cada chamada ficam em BatchCall.Error.
+func (c *Client) Batch(ctx context.Context, calls []*BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	requests := make([]*Request, len(calls))
+	pending := map[string]*BatchCall{}
+	for i, call := range calls {
+		request, err := c.request(call.Method, call.Params, call.Notification)
+		if err != nil {
+			return err
+		}
+		requests[i] = request
+		if !call.Notification {
+			call.Error = ErrMissingResponse
+			pending[string(request.ID)] = call
+		}
+	}
+
+	body, err := c.post(ctx, "batch", requests)
+	if err != nil || body == nil {
+		return err
+	}
+	body = bytes.TrimSpace(body)
+
+	// Um lote recusado por inteiro, como um lote grande demais, é respondido com um único erro
+	if len(body) > 0 && body[0] == '{' {
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			return err
+		}
+		return decodeResult(&response, nil)
+	}
+
+	var responses []Response
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return err
+	}
+	for i := range responses {
+		if call, ok := pending[string(responses[i].ID)]; ok {
+			call.Error = decodeResult(&responses[i], call.Result)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) request(method string, params interface{}, notification bool) (*Request, error) {
+	request := &Request{JSONRPC: Version, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		request.Params = data
+	}
+	if !notification {
+		request.ID = json.RawMessage(strconv.FormatUint(c.nextID.Add(1), 10))
+	}
+
+	return request, nil
+}
+
+// post envia o corpo e retorna a resposta, ou nil quando o servidor responde 204
+func (c *Client) post(ctx context.Context, method string, payload interface{}) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "jsonrpc call "+method)
+	defer span.End()
+	span.SetAttributes(attribute.String("rpc.system", "jsonrpc"), attribute.String("rpc.method", method))
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		request.Header.Set(auth.APIKeyHeader, c.config.APIKey)
+	} else if c.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		request.Header.Set(requestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	reader := io.LimitReader(response.Body, responseLimit)
+	switch response.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(reader)
+	case http.StatusNoContent:
+		return nil, nil
+	}
+
+	var problem struct {
+		Title string `json:"title"`
+		Code  string `json:"code"`
+	}
+	json.NewDecoder(reader).Decode(&problem)
+	err = &StatusError{Status: response.StatusCode, Code: problem.Code, Title: problem.Title}
+	span.RecordError(err)
+	return nil, err
+}
+
+func decodeResult(response *Response, result interface{}) error {
+	if response.Error != nil {
+		return response.Error
+	}
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
@@ -0,0 +1,292 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("produtos-api/src/jsonrpc")
+
+// Version é a versão do protocolo, obrigatória no campo jsonrpc das requisições e respostas
+const Version = "2.0"
+
+// Códigos de erro definidos pela especificação do JSON-RPC 2.0. Os códigos de -32000 a -32099 são
+// reservados aos erros de aplicação do servidor.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// DefaultMaxBytes limita o corpo das requisições quando Options.MaxBytes não é definido
+const DefaultMaxBytes = 1 << 20
+
+// reservedPrefix é o prefixo dos métodos reservados pela especificação
+const reservedPrefix = "rpc."
+
+// Request é uma chamada JSON-RPC. Sem id, a chamada é uma notificação e não tem resposta.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+	ID      json.RawMessage `json:"id,omitempty" swaggertype:"integer" example:"1"`
+}
+
+// IsNotification indica se a chamada é uma notificação
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// Response é a resposta de uma chamada: result no sucesso ou error na falha. O id é null quando não
+// pôde ser lido da requisição.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id" swaggertype:"integer" example:"1"`
+}
+
+// Error é o erro de uma chamada; data traz os detalhes definidos pela aplicação
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// DecodeData decodifica os detalhes do erro em v
+func (e *Error) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return errors.New("jsonrpc: error has no data")
+	}
+
+	return json.Unmarshal(e.Data, v)
+}
+
+// NewError cria um erro com os detalhes em data; detalhes que não podem ser serializados são omitidos
+func NewError(code int, message string, data interface{}) *Error {
+	rpcErr := &Error{Code: code, Message: message}
+	if data != nil {
+		rpcErr.Data, _ = json.Marshal(data)
+	}
+
+	return rpcErr
+}
+
+// Handler executa um método. params é o valor do campo params, ou nil quando omitido. Erros do tipo *Error
+// são respondidos como estão; os demais passam por Options.MapError.
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Options define os limites do servidor e a conversão dos erros dos métodos
+type Options struct {
+	MaxBatch int   // máximo de chamadas por lote; <= 0 não limita
+	MaxBytes int64 // tamanho máximo do corpo; <= 0 usa DefaultMaxBytes
+	// MapError converte os erros dos métodos em erros JSON-RPC e é chamado também para as notificações, cujos
+	// erros não são respondidos; sem ele, os erros são respondidos como internal error
+	MapError func(r *http.Request, err error) *Error
+}
+
+// Server atende chamadas JSON-RPC 2.0 por HTTP POST: uma chamada ou um lote por requisição. As chamadas de um
+// lote são executadas em ordem, e a resposta traz apenas as das chamadas que não são notificações; sem
+// respostas, o servidor responde 204.
+type Server struct {
+	methods map[string]Handler
+	options Options
+}
+
+// NewServer cria um servidor sem métodos
+func NewServer(options Options) *Server {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+
+	return &Server{methods: map[string]Handler{}, options: options}
+}
+
+// Register registra o método; nomes vazios, repetidos ou com o prefixo reservado rpc. são erros de programação
+func (s *Server) Register(method string, handler Handler) {
+	if method == "" || strings.HasPrefix(method, reservedPrefix) {
+		panic("jsonrpc: invalid method name " + method)
+	}
+	if _, ok := s.methods[method]; ok {
+		panic("jsonrpc: method " + method + " already registered")
+	}
+
+	s.methods[method] = handler
+}
+
+// Methods lista os métodos registrados em ordem alfabética
+func (s *Server) Methods() []string {
+	methods := make([]string, 0, len(s.methods))
+	for method := range s.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.options.MaxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Request body too large"}))
+			return
+		}
+		writeJSON(w, errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error"}))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if !json.Valid(body) {
+		writeJSON(w, errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error"}))
+		return
+	}
+
+	if body[0] != '[' {
+		if response := s.call(r, body); response != nil {
+			writeJSON(w, response)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var batch []json.RawMessage
+	json.Unmarshal(body, &batch)
+	switch {
+	case len(batch) == 0:
+		writeJSON(w, errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request"}))
+		return
+	case s.options.MaxBatch > 0 && len(batch) > s.options.MaxBatch:
+		writeJSON(w, errorResponse(nil, NewError(CodeInvalidRequest, "Batch too large", map[string]int{"max_batch": s.options.MaxBatch})))
+		return
+	}
+
+	responses := make([]*Response, 0, len(batch))
+	for _, raw := range batch {
+		if response := s.call(r, raw); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, responses)
+}
+
+// call executa uma chamada e retorna a sua resposta, ou nil para as notificações
+func (s *Server) call(r *http.Request, raw json.RawMessage) *Response {
+	var request Request
+	if err := json.Unmarshal(raw, &request); err != nil || request.JSONRPC != Version || request.Method == "" ||
+		!validID(request.ID) || !validParams(request.Params) {
+		// Chamadas inválidas sempre têm resposta: sem uma chamada válida, não há como saber se era uma notificação
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request"})
+	}
+	if bytes.Equal(request.Params, []byte("null")) {
+		request.Params = nil
+	}
+
+	ctx, span := tracer.Start(r.Context(), "jsonrpc "+request.Method)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", request.Method),
+		attribute.Bool("rpc.jsonrpc.notification", request.IsNotification()),
+	)
+
+	handler, ok := s.methods[request.Method]
+	if !ok {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", CodeMethodNotFound))
+		if request.IsNotification() {
+			return nil
+		}
+		return errorResponse(request.ID, &Error{Code: CodeMethodNotFound, Message: "Method not found"})
+	}
+
+	result, err := handler(ctx, request.Params)
+	var data json.RawMessage
+	if err == nil {
+		data, err = json.Marshal(result)
+	}
+	if err != nil {
+		rpcErr := s.mapError(r.WithContext(ctx), err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, rpcErr.Message)
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", rpcErr.Code))
+		if request.IsNotification() {
+			return nil
+		}
+		return errorResponse(request.ID, rpcErr)
+	}
+	if request.IsNotification() {
+		return nil
+	}
+
+	return &Response{JSONRPC: Version, Result: data, ID: request.ID}
+}
+
+func (s *Server) mapError(r *http.Request, err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	if s.options.MapError != nil {
+		if rpcErr := s.options.MapError(r, err); rpcErr != nil {
+			return rpcErr
+		}
+	}
+
+	return &Error{Code: CodeInternalError, Message: "Internal error"}
+}
+
+// validID aceita os ids da especificação: string, número ou null, além do id omitido das notificações
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(id, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case nil, string, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// validParams aceita params estruturados (objeto ou array), null ou omitido
+func validParams(params json.RawMessage) bool {
+	return len(params) == 0 || params[0] == '{' || params[0] == '[' || bytes.Equal(params, []byte("null"))
+}
+
+func errorResponse(id json.RawMessage, rpcErr *Error) *Response {
+	return &Response{JSONRPC: Version, Error: rpcErr, ID: id}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,213 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNegative = errors.New("negative operand")
+
+type sumParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// newTestServer registra sum, que soma a e b, e log, que só conta as chamadas
+func newTestServer(notified *atomic.Int64) *Server {
+	server := NewServer(Options{
+		MaxBatch: 3,
+		MapError: func(r *http.Request, err error) *Error {
+			return NewError(-32022, err.Error(), map[string]string{"code": "negative_operand"})
+		},
+	})
+	server.Register("sum", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p sumParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params"}
+		}
+		if p.A < 0 || p.B < 0 {
+			return nil, errNegative
+		}
+		return p.A + p.B, nil
+	})
+	server.Register("log", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		notified.Add(1)
+		return nil, nil
+	})
+
+	return server
+}
+
+func serve(server *Server, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body)))
+	return rr
+}
+
+func TestServerCall(t *testing.T) {
+	var notified atomic.Int64
+	server := newTestServer(&notified)
+
+	cases := map[string]struct {
+		body     string
+		response string
+	}{
+		"result":           {`{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1}`, `{"jsonrpc":"2.0","result":3,"id":1}`},
+		"string id":        {`{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":"abc"}`, `{"jsonrpc":"2.0","result":3,"id":"abc"}`},
+		"null id":          {`{"jsonrpc":"2.0","method":"log","id":null}`, `{"jsonrpc":"2.0","result":null,"id":null}`},
+		"mapped error":     {`{"jsonrpc":"2.0","method":"sum","params":{"a":-1},"id":2}`, `{"jsonrpc":"2.0","error":{"code":-32022,"message":"negative operand","data":{"code":"negative_operand"}},"id":2}`},
+		"invalid params":   {`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":3}`, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":3}`},
+		"method not found": {`{"jsonrpc":"2.0","method":"foobar","id":4}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":4}`},
+		"parse error":      {`{"jsonrpc":"2.0","method":"sum","params":"bar","baz]`, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`},
+		"wrong version":    {`{"jsonrpc":"1.0","method":"sum","id":5}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"method type":      {`{"jsonrpc":"2.0","method":1,"params":"bar"}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"object id":        {`{"jsonrpc":"2.0","method":"sum","id":{}}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"scalar params":    {`{"jsonrpc":"2.0","method":"sum","params":1,"id":6}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"empty batch":      {`[]`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"batch too large":  {`[1,2,3,4]`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Batch too large","data":{"max_batch":3}},"id":null}`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(server, tc.body)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestServerNotifications(t *testing.T) {
+	var notified atomic.Int64
+	server := newTestServer(&notified)
+
+	// Notificações não têm resposta, nem quando falham ou o método não existe
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","method":"log","params":[1]}`,
+		`{"jsonrpc":"2.0","method":"sum","params":{"a":-1}}`,
+		`{"jsonrpc":"2.0","method":"foobar"}`,
+		`[{"jsonrpc":"2.0","method":"log"},{"jsonrpc":"2.0","method":"log"}]`,
+	} {
+		rr := serve(server, body)
+		assert.Equal(t, http.StatusNoContent, rr.Code, body)
+		assert.Empty(t, rr.Body.String())
+	}
+	assert.Equal(t, int64(3), notified.Load())
+}
+
+func TestServerBatch(t *testing.T) {
+	var notified atomic.Int64
+	server := newTestServer(&notified)
+
+	rr := serve(server, `[
+		{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":"1"},
+		{"jsonrpc":"2.0","method":"log","params":[7]},
+		1
+	]`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[
+		{"jsonrpc":"2.0","result":3,"id":"1"},
+		{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}
+	]`, rr.Body.String())
+	assert.Equal(t, int64(1), notified.Load())
+}
+
+func TestServerBodyLimit(t *testing.T) {
+	server := NewServer(Options{MaxBytes: 16})
+	server.Register("echo", func(ctx context.Context, params json.RawMessage) (interface{}, error) { return params, nil })
+
+	rr := serve(server, `{"jsonrpc":"2.0","method":"echo","params":["a long string"],"id":1}`)
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Request body too large"},"id":null}`, rr.Body.String())
+}
+
+func TestServerRegisterRejectsReservedNames(t *testing.T) {
+	server := NewServer(Options{})
+	handler := func(ctx context.Context, params json.RawMessage) (interface{}, error) { return nil, nil }
+
+	server.Register("b", handler)
+	server.Register("a", handler)
+
+	assert.Panics(t, func() { server.Register("rpc.discover", handler) })
+	assert.Panics(t, func() { server.Register("a", handler) })
+	assert.Equal(t, []string{"a", "b"}, server.Methods())
+}
+
+func TestClient(t *testing.T) {
+	var notified atomic.Int64
+	var authorization atomic.Value
+	server := newTestServer(&notified)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	client := NewClient(ClientConfig{URL: httpServer.URL, Token: "secret"})
+	ctx := context.Background()
+
+	var sum int
+	require.NoError(t, client.Call(ctx, "sum", sumParams{A: 2, B: 3}, &sum))
+	assert.Equal(t, 5, sum)
+	assert.Equal(t, "Bearer secret", authorization.Load())
+
+	err := client.Call(ctx, "sum", sumParams{A: -1}, &sum)
+	var rpcErr *Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32022, rpcErr.Code)
+	var data struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, rpcErr.DecodeData(&data))
+	assert.Equal(t, "negative_operand", data.Code)
+
+	require.NoError(t, client.Notify(ctx, "log", nil))
+	assert.Equal(t, int64(1), notified.Load())
+
+	var first, second int
+	calls := []*BatchCall{
+		{Method: "sum", Params: sumParams{A: 1, B: 1}, Result: &first},
+		{Method: "log", Notification: true},
+		{Method: "foobar"},
+		{Method: "sum", Params: sumParams{A: 4, B: 5}, Result: &second},
+	}
+	require.NoError(t, client.Batch(ctx, calls[:3]))
+	assert.NoError(t, calls[0].Error)
+	assert.NoError(t, calls[1].Error)
+	require.ErrorAs(t, calls[2].Error, &rpcErr)
+	assert.Equal(t, CodeMethodNotFound, rpcErr.Code)
+	assert.Equal(t, 2, first)
+	assert.Equal(t, int64(2), notified.Load())
+
+	// O lote acima do limite do servidor é recusado por inteiro
+	err = client.Batch(ctx, calls)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeInvalidRequest, rpcErr.Code)
+	assert.Equal(t, 0, second)
+}
+
+func TestClientStatusError(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"title":"Rate limit exceeded","status":429,"code":"rate_limited"}`))
+	}))
+	defer httpServer.Close()
+
+	err := NewClient(ClientConfig{URL: httpServer.URL}).Call(context.Background(), "sum", nil, nil)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.Status)
+	assert.Equal(t, "rate_limited", statusErr.Code)
+}
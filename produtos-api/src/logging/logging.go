@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"produtos-api/src/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// base é o handler JSON configurado por Setup; antes disso usa o handler padrão do slog
+	base atomic.Pointer[slog.Handler]
+
+	defaultLevel = new(slog.LevelVar)
+
+	mu     sync.Mutex
+	levels = map[string]*slog.LevelVar{}
+)
+
+// Setup configura o logger JSON da aplicação. LOG_LEVEL define o nível padrão e LOG_LEVELS
+// os níveis por pacote, ex: LOG_LEVELS="controllers=debug,database=warn".
+func Setup(w io.Writer) *slog.Logger {
+	defaultLevel.Set(parseLevel(config.GetString("LOG_LEVEL", "info"), slog.LevelInfo))
+
+	mu.Lock()
+	// Loggers criados antes de Setup passam a usar o novo nível padrão
+	for _, level := range levels {
+		level.Set(defaultLevel.Level())
+	}
+	for _, entry := range config.GetList("LOG_LEVELS", nil) {
+		pkg, level, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		levelFor(strings.TrimSpace(pkg)).Set(parseLevel(level, defaultLevel.Level()))
+	}
+	mu.Unlock()
+
+	var handler slog.Handler = &contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	base.Store(&handler)
+
+	logger := slog.New(&packageHandler{level: defaultLevel})
+	slog.SetDefault(logger)
+
+	return logger
+}
+
+// Logger retorna o logger de um pacote, respeitando o nível configurado para ele em LOG_LEVELS.
+// Pode ser chamado na inicialização do pacote, antes de Setup.
+func Logger(pkg string) *slog.Logger {
+	mu.Lock()
+	level := levelFor(pkg)
+	mu.Unlock()
+
+	return slog.New(&packageHandler{level: level}).With("package", pkg)
+}
+
+// levelFor deve ser chamado com mu travado
+func levelFor(pkg string) *slog.LevelVar {
+	level, ok := levels[pkg]
+	if !ok {
+		level = new(slog.LevelVar)
+		level.Set(defaultLevel.Level())
+		levels[pkg] = level
+	}
+
+	return level
+}
+
+func parseLevel(value string, fallback slog.Level) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return fallback
+	}
+
+	return level
+}
+
+func current() slog.Handler {
+	if handler := base.Load(); handler != nil {
+		return *handler
+	}
+
+	return &contextHandler{slog.NewJSONHandler(os.Stderr, nil)}
+}
+
+// packageHandler aplica o nível do pacote e delega para o handler base no momento do log,
+// o que permite criar loggers em variáveis de pacote antes de Setup ser chamado
+type packageHandler struct {
+	level slog.Leveler
+	ops   []func(slog.Handler) slog.Handler
+}
+
+func (h *packageHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *packageHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler := current()
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+
+	return handler.Handle(ctx, record)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *packageHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := append(append([]func(slog.Handler) slog.Handler(nil), h.ops...), op)
+	return &packageHandler{level: h.level, ops: ops}
+}
+
+// contextHandler adiciona o request ID e o trace ID presentes no contexto a cada registro
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := RequestID(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID retorna um contexto com o request ID da requisição
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID retorna o request ID presente no contexto ou "" se não houver
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// lines decodifica os registros JSON escritos no buffer
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	buf.Reset()
+	return records
+}
+
+func TestSetupAppliesPackageLevels(t *testing.T) {
+	// Loggers criados antes de Setup, como as variáveis de pacote, seguem a configuração
+	early := Logger("early")
+
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_LEVELS", "controllers=debug, database=error, broken")
+	var buf bytes.Buffer
+	Setup(&buf)
+
+	early.Info("ignored")
+	early.Warn("kept")
+	Logger("controllers").Debug("debug enabled")
+	Logger("database").Warn("ignored")
+
+	records := lines(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "kept", records[0]["msg"])
+	assert.Equal(t, "early", records[0]["package"])
+	assert.Equal(t, "debug enabled", records[1]["msg"])
+	assert.Equal(t, "DEBUG", records[1]["level"])
+}
+
+func TestContextHandlerAddsRequestAndTraceIDs(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	var buf bytes.Buffer
+	Setup(&buf)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	Logger("services").InfoContext(ctx, "with context", "product_id", 7)
+	Logger("services").Info("without context")
+
+	records := lines(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "req-1", records[0]["request_id"])
+	assert.Equal(t, traceID.String(), records[0]["trace_id"])
+	assert.Equal(t, float64(7), records[0]["product_id"])
+	assert.NotContains(t, records[1], "request_id")
+	assert.Equal(t, "", RequestID(context.Background()))
+}
+
+func TestGormLoggerTrace(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	var buf bytes.Buffer
+	Setup(&buf)
+	logger := NewGormLogger("database", 100*time.Millisecond)
+	query := func() (string, int64) { return "SELECT * FROM products", 3 }
+	ctx := context.Background()
+
+	// Registros não encontrados e cancelamentos não são falhas; consultas rápidas só aparecem em debug
+	logger.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	logger.Trace(ctx, time.Now(), query, context.Canceled)
+	logger.Trace(ctx, time.Now(), query, nil)
+	assert.Empty(t, lines(t, &buf))
+
+	logger.Trace(ctx, time.Now(), query, errors.New("disk I/O error"))
+	logger.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+
+	records := lines(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "query failed", records[0]["msg"])
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Equal(t, "disk I/O error", records[0]["error"])
+	assert.Equal(t, "slow query", records[1]["msg"])
+	assert.Equal(t, "SELECT * FROM products", records[1]["sql"])
+	assert.Equal(t, float64(3), records[1]["rows"])
+}
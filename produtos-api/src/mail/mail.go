@@ -0,0 +1,126 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Transportes disponíveis em Config.Transport
+const (
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportMemory = "memory"
+)
+
+// Message é um e-mail pronto para envio, com o corpo em texto e em HTML
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Transport entrega as mensagens. Send só retorna sucesso depois que o destino aceitou a mensagem;
+// em caso de erro a fila de e-mails tenta de novo, por isso o destino deve tolerar duplicatas.
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config define o transporte: SMTP para produção, arquivos .eml em Dir ou memória para desenvolvimento e testes
+type Config struct {
+	Transport string
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	Dir       string
+	Timeout   time.Duration
+}
+
+// NewTransport cria o transporte definido em cfg.Transport
+func NewTransport(cfg Config) (Transport, error) {
+	switch cfg.Transport {
+	case TransportSMTP:
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("mail: SMTP host is required")
+		}
+		return NewSMTPTransport(cfg), nil
+	case TransportFile:
+		return NewFileTransport(cfg.Dir)
+	case TransportMemory:
+		return NewMemoryTransport(), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown transport %q", cfg.Transport)
+	}
+}
+
+// Build monta a mensagem no formato RFC 5322, com as versões em texto e HTML em multipart/alternative
+func Build(msg Message, date time.Time) ([]byte, error) {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid sender: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid recipient: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	boundary := "alt-" + hex.EncodeToString(id[:8])
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	// O cliente de e-mail exibe a última parte que souber mostrar, por isso o HTML vem depois do texto
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writer := quotedprintable.NewWriter(&buf)
+		if _, err := writer.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// address retorna apenas o endereço, sem o nome de exibição, como exigido pelo envelope SMTP
+func address(value string) (string, error) {
+	parsed, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.Address, nil
+}
@@ -0,0 +1,232 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"produtos-api/src/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var order = OrderConfirmed{
+	OrderID: 42,
+	Items:   []OrderItem{{Name: "Caneta <azul>", Quantity: 2, UnitPrice: 1234.5}},
+	Total:   2469,
+}
+
+func TestLocalesHaveTheSameTemplates(t *testing.T) {
+	expected := Templates(i18n.DefaultLocale)
+	assert.Equal(t, []string{TemplateBackInStock, TemplateOrderConfirmed}, expected)
+	for _, locale := range i18n.Locales() {
+		assert.Equal(t, expected, Templates(locale), "templates of %s", locale)
+	}
+}
+
+func TestRenderUsesTheLocale(t *testing.T) {
+	content, err := Render(TemplateOrderConfirmed, "pt-BR", order)
+	require.NoError(t, err)
+	assert.Equal(t, "Pedido nº 42 confirmado", content.Subject)
+	assert.Contains(t, content.Text, "- 2 x Caneta <azul>: R$ 1.234,50")
+	assert.Contains(t, content.Text, "Total: R$ 2.469,00")
+
+	content, err = Render(TemplateOrderConfirmed, "en-US", order)
+	require.NoError(t, err)
+	assert.Equal(t, "Order #42 confirmed", content.Subject)
+	assert.Contains(t, content.Text, "Total: R$ 2,469.00")
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	content, err := Render(TemplateOrderConfirmed, "en", order)
+	require.NoError(t, err)
+	assert.Contains(t, content.HTML, "<td>Caneta &lt;azul&gt;</td>")
+	assert.NotContains(t, content.HTML, "<azul>")
+}
+
+func TestRenderBackInStockUnsubscribeLink(t *testing.T) {
+	url := "https://loja.example.com/unsubscribe?token=unsub_1&x=<y>"
+	for _, locale := range i18n.Locales() {
+		content, err := Render(TemplateBackInStock, locale, BackInStock{ProductID: 1, ProductName: "Caneta", Price: 2.5, UnsubscribeURL: url})
+		require.NoError(t, err)
+		assert.Contains(t, content.Text, url, locale)
+		assert.Contains(t, content.HTML, `href="https://loja.example.com/unsubscribe?token=unsub_1&amp;x=%3cy%3e"`, locale)
+	}
+
+	content, err := Render(TemplateBackInStock, "en", BackInStock{ProductID: 1, ProductName: "Caneta", Price: 2.5})
+	require.NoError(t, err)
+	assert.NotContains(t, content.HTML, "href")
+}
+
+func TestRenderFallsBackToTheDefaultLocale(t *testing.T) {
+	content, err := Render(TemplateBackInStock, "ja", BackInStock{ProductID: 1, ProductName: "Caneta", Price: 2.5})
+	require.NoError(t, err)
+	assert.Equal(t, "Caneta is back in stock", content.Subject)
+
+	_, err = Render("unknown", "en", nil)
+	assert.Error(t, err)
+}
+
+func TestBuildCreatesMultipartMessage(t *testing.T) {
+	data, err := Build(Message{
+		From:    "Loja <loja@example.com>",
+		To:      "maria@example.com",
+		Subject: "Pedido nº 42 confirmado",
+		Text:    "Olá",
+		HTML:    "<p>Olá</p>",
+	}, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Pedido nº 42 confirmado", subject)
+	assert.Equal(t, "<maria@example.com>", msg.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>"))
+
+	parts := readParts(t, msg)
+	assert.Equal(t, []string{"text/plain: Olá", "text/html: <p>Olá</p>"}, parts)
+
+	_, err = Build(Message{From: "loja@example.com", To: "not an address"}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestFileTransportWritesEmlFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	transport, err := NewTransport(Config{Transport: TransportFile, Dir: dir})
+	require.NoError(t, err)
+
+	msg := Message{From: "loja@example.com", To: "maria@example.com", Subject: "Oi", Text: "texto"}
+	require.NoError(t, transport.Send(context.Background(), msg))
+	require.NoError(t, transport.Send(context.Background(), msg))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, ".eml", filepath.Ext(entry.Name()))
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	transport := NewMemoryTransport()
+	require.NoError(t, transport.Send(context.Background(), Message{To: "maria@example.com", Subject: "Oi"}))
+	assert.Error(t, transport.Send(context.Background(), Message{To: "maria"}))
+
+	messages := transport.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Oi", messages[0].Subject)
+}
+
+func TestNewTransportRejectsInvalidConfig(t *testing.T) {
+	_, err := NewTransport(Config{Transport: "pigeon"})
+	assert.Error(t, err)
+	_, err = NewTransport(Config{Transport: TransportSMTP})
+	assert.Error(t, err)
+	_, err = NewTransport(Config{Transport: TransportFile})
+	assert.Error(t, err)
+}
+
+func TestSMTPTransportSendsMessage(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	transport, err := NewTransport(Config{Transport: TransportSMTP, Host: "127.0.0.1", Port: addr.Port, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	err = transport.Send(context.Background(), Message{
+		From:    "Loja <loja@example.com>",
+		To:      "Maria <maria@example.com>",
+		Subject: "Oi",
+		Text:    "texto",
+	})
+	require.NoError(t, err)
+
+	commands := <-received
+	assert.Contains(t, commands, "MAIL FROM:<loja@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<maria@example.com>")
+	assert.Contains(t, commands, "Subject: Oi")
+	assert.Contains(t, commands, "QUIT")
+}
+
+// serveSMTP atende uma sessão SMTP mínima, sem STARTTLS nem autenticação, e envia as linhas recebidas
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var lines []string
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		switch {
+		case inData:
+			if line == "." {
+				inData = false
+				reply("250 queued")
+			}
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			reply("250 localhost")
+		case line == "DATA":
+			inData = true
+			reply("354 end with .")
+		case line == "QUIT":
+			reply("221 bye")
+			received <- lines
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+	received <- lines
+}
+
+// readParts lê as partes de multipart/alternative no formato "tipo: corpo"
+func readParts(t *testing.T, msg *mail.Message) []string {
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	var parts []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts = append(parts, contentType+": "+strings.TrimSpace(string(body)))
+	}
+
+	return parts
+}
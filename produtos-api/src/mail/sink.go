@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileTransport grava cada mensagem como um arquivo .eml em um diretório, que pode ser aberto
+// por qualquer cliente de e-mail; útil em desenvolvimento, sem um servidor SMTP
+type FileTransport struct {
+	dir string
+	seq atomic.Uint64
+}
+
+// NewFileTransport cria o transporte, criando o diretório se necessário
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileTransport{dir: dir}, nil
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := Build(msg, now)
+	if err != nil {
+		return err
+	}
+
+	// O arquivo é escrito com outro nome e renomeado, para que um leitor nunca veja uma mensagem pela metade
+	name := fmt.Sprintf("%s-%d-%04d.eml", now.UTC().Format("20060102T150405.000000000"), os.Getpid(), t.seq.Add(1))
+	temp := filepath.Join(t.dir, "."+name)
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(temp, filepath.Join(t.dir, name))
+}
+
+// MemoryTransport guarda as mensagens em memória; usado nos testes e quando nenhum transporte é configurado
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(ctx context.Context, msg Message) error {
+	if _, err := address(msg.To); err != nil {
+		return fmt.Errorf("mail: invalid recipient: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages retorna uma cópia das mensagens enviadas, na ordem de envio
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Message(nil), t.messages...)
+}
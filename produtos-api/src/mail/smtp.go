@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPTransport envia as mensagens por SMTP. Usa STARTTLS quando o servidor oferece e só autentica
+// quando Username estiver definido, o que permite usar sinks locais como o Mailpit sem credenciais.
+type SMTPTransport struct {
+	config Config
+}
+
+// NewSMTPTransport cria o transporte SMTP; a conexão é aberta a cada envio
+func NewSMTPTransport(cfg Config) *SMTPTransport {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &SMTPTransport{config: cfg}
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg Message) error {
+	data, err := Build(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := address(msg.From)
+	if err != nil {
+		return err
+	}
+	to, err := address(msg.To)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(t.config.Host, strconv.Itoa(t.config.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// O prazo da conexão acompanha o contexto: o net/smtp não recebe contexto
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, t.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: t.config.Host}); err != nil {
+			return err
+		}
+	}
+	if t.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
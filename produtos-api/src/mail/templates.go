@@ -0,0 +1,145 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"produtos-api/src/i18n"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Templates dos e-mails enviados pelas notificações
+const (
+	TemplateOrderConfirmed = "order_confirmed"
+	TemplateBackInStock    = "back_in_stock"
+)
+
+// OrderConfirmed são os dados do template order_confirmed
+type OrderConfirmed struct {
+	OrderID uint
+	Items   []OrderItem
+	Total   float64
+}
+
+// OrderItem é um item do pedido confirmado
+type OrderItem struct {
+	Name      string
+	Quantity  int
+	UnitPrice float64
+}
+
+// BackInStock são os dados do template back_in_stock
+type BackInStock struct {
+	ProductID      uint
+	ProductName    string
+	Price          float64
+	UnsubscribeURL string
+}
+
+// Content é o e-mail renderizado, ainda sem remetente e destinatário
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Cada template fica em templates/<locale>/<nome>.tmpl e define os blocos subject, text e html.
+// O assunto e o texto são renderizados com text/template; o HTML, com html/template, que escapa os dados.
+//
+//go:embed templates/*/*.tmpl
+var files embed.FS
+
+type localeTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates guarda os templates de cada locale, indexados por locale e nome
+var templates = map[string]map[string]localeTemplates{}
+
+func init() {
+	paths, err := fs.Glob(files, "templates/*/*.tmpl")
+	if err != nil {
+		panic(fmt.Sprintf("mail: erro ao listar os templates: %v", err))
+	}
+
+	for _, file := range paths {
+		locale := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+
+		data, err := files.ReadFile(file)
+		if err != nil {
+			panic(fmt.Sprintf("mail: erro ao ler o template %s: %v", file, err))
+		}
+		funcs := templateFuncs(locale)
+		text, err := texttemplate.New(name).Funcs(funcs).Parse(string(data))
+		if err != nil {
+			panic(fmt.Sprintf("mail: template %s inválido: %v", file, err))
+		}
+		html, err := htmltemplate.New(name).Funcs(funcs).Parse(string(data))
+		if err != nil {
+			panic(fmt.Sprintf("mail: template %s inválido: %v", file, err))
+		}
+
+		if templates[locale] == nil {
+			templates[locale] = map[string]localeTemplates{}
+		}
+		templates[locale][name] = localeTemplates{text: text, html: html}
+	}
+}
+
+// templateFuncs retorna as funções dos templates, formatando os números no padrão do locale
+func templateFuncs(locale string) map[string]interface{} {
+	printer := message.NewPrinter(language.Make(locale))
+	return map[string]interface{}{
+		"money": func(value float64) string { return printer.Sprintf("R$ %.2f", value) },
+	}
+}
+
+// Render renderiza o template no locale mais próximo de locale, ou no locale padrão quando o template
+// não tiver tradução
+func Render(name, locale string, data interface{}) (*Content, error) {
+	set, ok := templates[i18n.Negotiate(locale)][name]
+	if !ok {
+		if set, ok = templates[i18n.DefaultLocale][name]; !ok {
+			return nil, fmt.Errorf("mail: unknown template %q", name)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := set.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := set.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return nil, err
+	}
+
+	return &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    strings.TrimSpace(html.String()) + "\n",
+	}, nil
+}
+
+// Templates lista, em ordem alfabética, os templates disponíveis no locale
+func Templates(locale string) []string {
+	var names []string
+	for name := range templates[locale] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// Namespace é o prefixo de todas as métricas da aplicação
+const Namespace = "produtos_api"
+
+// Metrics agrupa o registro e os coletores expostos em /metrics
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	httpInFlight    prometheus.Gauge
+	dbQueryDuration *prometheus.HistogramVec
+	dbQueryErrors   *prometheus.CounterVec
+}
+
+// New cria os coletores em um registro próprio, incluindo as métricas do runtime Go e do processo
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total de requisições HTTP processadas, por rota, método e código de status.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latência das requisições HTTP, por rota e método.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Requisições HTTP em andamento.",
+		}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duração das consultas executadas pelo GORM, por operação e tabela.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "table"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Total de consultas do GORM que retornaram erro, por operação e tabela.",
+		}, []string{"operation", "table"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.dbQueryDuration,
+		m.dbQueryErrors,
+	)
+
+	return m
+}
+
+// Handler retorna o handler HTTP do endpoint /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// ObserveRequest registra uma requisição HTTP finalizada
+func (m *Metrics) ObserveRequest(method, route, code string, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// InFlight retorna o gauge de requisições em andamento
+func (m *Metrics) InFlight() prometheus.Gauge {
+	return m.httpInFlight
+}
+
+// RegisterDBStats expõe as estatísticas do pool de conexões do banco de dados
+func (m *Metrics) RegisterDBStats(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return m.Registry.Register(collectors.NewDBStatsCollector(sqlDB, "products"))
+}
+
+// RegisterProductsCount expõe a quantidade de produtos cadastrados. A contagem é reaproveitada por maxAge,
+// para que coletas frequentes não consultem o banco a cada scrape.
+func (m *Metrics) RegisterProductsCount(count func() int64, maxAge time.Duration) error {
+	cached := &cachedCount{count: count, maxAge: maxAge, now: time.Now}
+
+	return m.Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "products",
+		Help:      "Quantidade de produtos cadastrados no catálogo.",
+	}, func() float64 {
+		return float64(cached.value())
+	}))
+}
+
+// cachedCount guarda o último valor da contagem e o recalcula quando tem mais de maxAge
+type cachedCount struct {
+	mu        sync.Mutex
+	count     func() int64
+	maxAge    time.Duration
+	now       func() time.Time
+	last      int64
+	updatedAt time.Time
+}
+
+func (c *cachedCount) value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := c.now(); c.updatedAt.IsZero() || now.Sub(c.updatedAt) >= c.maxAge {
+		c.last, c.updatedAt = c.count(), now
+	}
+
+	return c.last
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestObserveRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest("GET", "/products/{id}", "200", 20*time.Millisecond)
+	m.ObserveRequest("GET", "/products/{id}", "200", 30*time.Millisecond)
+	m.ObserveRequest("GET", "unmatched", "404", time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/products/{id}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestHandlerExposesProductsCount(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterProductsCount(func() int64 { return 42 }, time.Minute))
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Contains(t, rr.Body.String(), "produtos_api_products 42")
+	assert.Contains(t, rr.Body.String(), "go_goroutines")
+}
+
+func TestCachedCount(t *testing.T) {
+	calls := int64(0)
+	now := time.Now()
+	cached := &cachedCount{
+		count:  func() int64 { calls++; return calls },
+		maxAge: 30 * time.Second,
+		now:    func() time.Time { return now },
+	}
+
+	assert.Equal(t, int64(1), cached.value())
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, int64(1), cached.value())
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, int64(2), cached.value())
+}
+
+func TestGormPlugin(t *testing.T) {
+	m := New()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(NewGormPlugin(m)))
+	require.NoError(t, db.AutoMigrate(&widget{}))
+
+	require.NoError(t, db.Create(&widget{Name: "a"}).Error)
+	var found widget
+	require.NoError(t, db.First(&found).Error)
+
+	// Registro não encontrado não é erro de banco; tabela inexistente é
+	assert.ErrorIs(t, db.First(&found, 99).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, db.Table("missing").Find(&[]widget{}).Error)
+
+	expected := `
+		# HELP produtos_api_db_query_errors_total Total de consultas do GORM que retornaram erro, por operação e tabela.
+		# TYPE produtos_api_db_query_errors_total counter
+		produtos_api_db_query_errors_total{operation="query",table="missing"} 1
+	`
+	assert.NoError(t, testutil.CollectAndCompare(m.dbQueryErrors, strings.NewReader(expected)))
+	assert.Equal(t, uint64(1), sampleCount(t, m, "create", "widgets"))
+	assert.Equal(t, uint64(2), sampleCount(t, m, "query", "widgets"))
+}
+
+// sampleCount retorna quantas consultas da operação na tabela foram medidas
+func sampleCount(t *testing.T, m *Metrics, operation, table string) uint64 {
+	families, err := m.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "produtos_api_db_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["table"] == table {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	return 0
+}
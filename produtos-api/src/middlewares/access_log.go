@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"produtos-api/src/logging"
+
+	"github.com/gorilla/mux"
+)
+
+var accessLogger = logging.Logger("http")
+
+// AccessLog registra uma linha por requisição com status, bytes enviados e latência
+func AccessLog() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := newResponseRecorder(w)
+
+			next.ServeHTTP(recorder, r)
+
+			remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				remoteIP = r.RemoteAddr
+			}
+
+			accessLogger.InfoContext(r.Context(), "request completed",
+				"method", r.Method,
+				"route", RouteTemplate(r),
+				"path", r.URL.Path,
+				"status", recorder.status,
+				"bytes", recorder.bytes,
+				"latency_ms", float64(time.Since(start).Microseconds())/1000,
+				"remote_ip", remoteIP,
+				"user_agent", r.UserAgent(),
+			)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"produtos-api/src/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogWritesOneLinePerRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logging.Setup(&buf)
+
+	router := mux.NewRouter()
+	router.Use(RequestID(), AccessLog())
+	router.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/products/7", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.Header.Set("User-Agent", "pedidos-api")
+	req.RemoteAddr = "10.0.0.8:51234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "request completed", line["msg"])
+	assert.Equal(t, "http", line["package"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/products/{id}", line["route"])
+	assert.Equal(t, "/products/7", line["path"])
+	assert.Equal(t, float64(http.StatusCreated), line["status"])
+	assert.Equal(t, float64(5), line["bytes"])
+	assert.Equal(t, "10.0.0.8", line["remote_ip"])
+	assert.Equal(t, "pedidos-api", line["user_agent"])
+}
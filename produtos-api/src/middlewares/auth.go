@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ErrorWriter responde um erro no formato padrão da API
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// Auth autentica o header Authorization: Bearer (usuários) ou X-API-Key (clientes de máquina)
+// e aplica a política de acesso da rota. Sem esses headers, as rotas que aceitam tickets autenticam
+// o parâmetro ticket da query, usado pelos navegadores em EventSource e WebSocket, que não enviam headers.
+// Credenciais inválidas são rejeitadas mesmo em rotas públicas.
+func Auth(authService services.AuthService, writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var principal *auth.Principal
+
+			if key := r.Header.Get(auth.APIKeyHeader); key != "" {
+				var err error
+				principal, err = authService.AuthenticateAPIKey(r.Context(), key)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+			} else if token, ok := bearerToken(r); ok {
+				var err error
+				principal, err = authService.AuthenticateBearer(r.Context(), token)
+				if err != nil {
+					challenge(w, "invalid_token")
+					writeError(w, r, err)
+					return
+				}
+			} else if ticket := r.URL.Query().Get(auth.TicketParam); ticket != "" {
+				var err error
+				principal, err = authService.AuthenticateTicket(r.Context(), ticket, r.Method, RouteTemplate(r))
+				if err != nil {
+					challenge(w, "invalid_token")
+					writeError(w, r, err)
+					return
+				}
+			}
+
+			if err := authService.Authorize(principal, r.Method, RouteTemplate(r)); err != nil {
+				if principal == nil {
+					challenge(w, "")
+				}
+				writeError(w, r, err)
+				return
+			}
+
+			if principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// challenge informa ao cliente o esquema de autenticação esperado (RFC 6750)
+func challenge(w http.ResponseWriter, errorCode string) {
+	value := `Bearer realm="produtos-api"`
+	if errorCode != "" {
+		value += `, error="` + errorCode + `"`
+	}
+	w.Header().Set("WWW-Authenticate", value)
+}
@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthAcceptsTicketsOnTicketRoutes(t *testing.T) {
+	tickets := auth.Tickets{Secret: []byte("test-secret"), TTL: time.Minute}
+	authService := services.NewAuthService(&auth.KeySet{HMACSecret: []byte("test-secret")}, auth.Policy{
+		Default:      auth.RoleViewer,
+		TicketRoutes: map[string]bool{"GET /products/stream": true},
+	}, nil).WithTickets(tickets)
+
+	var subject string
+	router := mux.NewRouter()
+	router.Use(Auth(authService, writeStatus))
+	handler := func(w http.ResponseWriter, r *http.Request) { subject = auth.PrincipalFrom(r.Context()).Subject }
+	router.HandleFunc("/products/stream", handler).Methods(http.MethodGet)
+	router.HandleFunc("/products", handler).Methods(http.MethodGet)
+
+	viewer := &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleViewer}, Method: auth.MethodJWT}
+	ticket, _, err := tickets.Issue(viewer, time.Now())
+	require.NoError(t, err)
+	expired, _, err := tickets.Issue(viewer, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	// Um token da API não é aceito como ticket
+	token, err := auth.Mint([]byte("test-secret"), "maria", []auth.Role{auth.RoleViewer}, time.Minute, "", "")
+	require.NoError(t, err)
+
+	get := func(target string) int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("/products/stream?ticket="+ticket))
+	assert.Equal(t, "maria", subject)
+	assert.Equal(t, http.StatusUnauthorized, get("/products/stream"))
+	assert.Equal(t, http.StatusUnauthorized, get("/products/stream?ticket="+expired))
+	assert.Equal(t, http.StatusUnauthorized, get("/products/stream?ticket="+token))
+	assert.Equal(t, http.StatusUnauthorized, get("/products?ticket="+ticket))
+}
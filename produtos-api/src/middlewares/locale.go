@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"produtos-api/src/i18n"
+
+	"github.com/gorilla/mux"
+)
+
+// ContentLocale identifica o idioma pedido para o conteúdo dos produtos.
+// O parâmetro ?locale= tem prioridade sobre o header Accept-Language.
+func ContentLocale() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.URL.Query().Get("locale")
+			if value == "" {
+				value = r.Header.Get("Accept-Language")
+			}
+
+			if candidates := i18n.ContentCandidates(value); len(candidates) > 0 {
+				r = r.WithContext(i18n.WithContentLocales(r.Context(), candidates))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"produtos-api/src/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute é o rótulo das requisições que não correspondem a nenhuma rota
+const unmatchedRoute = "unmatched"
+
+// knownMethods limita os valores do label method para manter a cardinalidade das métricas
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodHead: true, http.MethodOptions: true,
+}
+
+// matchedRouteKey guarda no contexto o template da rota encontrado pelo roteador
+type matchedRouteKey struct{}
+
+// Metrics envolve o roteador e registra contadores e latência de cada requisição, rotulados pelo template da
+// rota do mux. Fica fora do router.Use para medir também as requisições sem rota (404 e 405), rotuladas "unmatched".
+// O template é lido da requisição já roteada por um middleware registrado aqui; por isso Metrics deve ser chamado
+// antes dos demais router.Use, para que as respostas dadas por eles (ex: 401 e 429) também tenham a rota.
+func Metrics(m *metrics.Metrics, router *mux.Router) http.Handler {
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route, ok := r.Context().Value(matchedRouteKey{}).(*string); ok {
+				*route = RouteTemplate(r)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+
+		m.InFlight().Inc()
+		defer m.InFlight().Dec()
+
+		route := unmatchedRoute
+		router.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), matchedRouteKey{}, &route)))
+
+		m.ObserveRequest(methodLabel(r.Method), route, strconv.Itoa(recorder.status), time.Since(start))
+	})
+}
+
+// RouteTemplate retorna o template da rota (ex: /products/{id}) em vez do caminho bruto
+func RouteTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return unmatchedRoute
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+
+	return template
+}
+
+func methodLabel(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+
+	return "OTHER"
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/metrics"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCountsMatchedAndUnmatchedRoutes(t *testing.T) {
+	m := metrics.New()
+	router := mux.NewRouter()
+	router.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodGet)
+	handler := Metrics(m, router)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/products/1", nil),
+		httptest.NewRequest(http.MethodGet, "/products/2", nil),
+		httptest.NewRequest(http.MethodGet, "/nope", nil),
+		httptest.NewRequest(http.MethodDelete, "/products/1", nil),
+		httptest.NewRequest("PURGE", "/products/1", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+
+	// Rotas são rotuladas pelo template; requisições sem rota e métodos desconhecidos não aumentam a cardinalidade
+	assert.Contains(t, body, `produtos_api_http_requests_total{code="204",method="GET",route="/products/{id}"} 2`)
+	assert.Contains(t, body, `produtos_api_http_requests_total{code="404",method="GET",route="unmatched"} 1`)
+	assert.Contains(t, body, `produtos_api_http_requests_total{code="405",method="DELETE",route="unmatched"} 1`)
+	assert.Contains(t, body, `produtos_api_http_requests_total{code="405",method="OTHER",route="unmatched"} 1`)
+	assert.Contains(t, body, "produtos_api_http_requests_in_flight 0")
+	assert.False(t, strings.Contains(body, "/products/1"))
+}
+
+func TestMetricsLabelsRoutesAnsweredByMiddlewares(t *testing.T) {
+	m := metrics.New()
+	router := mux.NewRouter()
+	handler := Metrics(m, router)
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	})
+	router.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/1", nil))
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `produtos_api_http_requests_total{code="401",method="GET",route="/products/{id}"} 1`)
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+)
+
+var rateLimitLogger = logging.Logger("ratelimit")
+
+// RateLimit aplica o limite de requisições da rota por chave de API, usuário autenticado ou IP,
+// nessa ordem. Responde com os headers RateLimit-* e, quando o limite é atingido, 429 com
+// Retry-After. Com trustedProxies > 0, o IP é lido de X-Forwarded-For (ver clientIP).
+// Falhas do backend liberam a requisição.
+func RateLimit(rateLimitService services.RateLimitService, trustedProxies int, writeError ErrorWriter) mux.MiddlewareFunc {
+	return rateLimit(rateLimitService, func(r *http.Request) string {
+		return RateLimitIdentity(r, trustedProxies)
+	}, writeError)
+}
+
+// RateLimitByIP aplica o limite de requisições apenas pelo IP do cliente. Deve ser registrado antes do
+// Auth, para que as requisições que falham na autenticação também sejam limitadas (força bruta de
+// chaves de API e tokens).
+func RateLimitByIP(rateLimitService services.RateLimitService, trustedProxies int, writeError ErrorWriter) mux.MiddlewareFunc {
+	return rateLimit(rateLimitService, func(r *http.Request) string {
+		return "ip:" + clientIP(r, trustedProxies)
+	}, writeError)
+}
+
+func rateLimit(rateLimitService services.RateLimitService, identity func(r *http.Request) string, writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := rateLimitService.Allow(r.Context(), identity(r), r.Method, RouteTemplate(r))
+			if domainErr, ok := services.AsDomainError(err); ok && domainErr.Kind == services.KindRateLimited {
+				setRateLimitHeaders(w, result)
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				writeError(w, r, err)
+				return
+			}
+			if err != nil {
+				rateLimitLogger.WarnContext(r.Context(), "rate limit backend failed, allowing request", "error", err)
+			} else {
+				setRateLimitHeaders(w, result)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitIdentity identifica o cliente para o bucket de limite
+func RateLimitIdentity(r *http.Request, trustedProxies int) string {
+	if principal := auth.PrincipalFrom(r.Context()); principal != nil {
+		if principal.Method == auth.MethodAPIKey {
+			return "key:" + principal.Subject
+		}
+		return "user:" + principal.Subject
+	}
+
+	return "ip:" + clientIP(r, trustedProxies)
+}
+
+// clientIP retorna o IP do cliente. Atrás de trustedProxies proxies confiáveis, cada um acrescenta ao fim de
+// X-Forwarded-For o endereço de quem o chamou, então o cliente é o item na posição trustedProxies a partir da
+// direita; os itens à esquerda dele vêm do próprio cliente e não são considerados.
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var forwarded []string
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			for _, item := range strings.Split(value, ",") {
+				forwarded = append(forwarded, strings.TrimSpace(item))
+			}
+		}
+		if len(forwarded) > 0 {
+			if ip := forwarded[max(len(forwarded)-trustedProxies, 0)]; ip != "" {
+				return ip
+			}
+		}
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return ip
+}
+
+// setRateLimitHeaders escreve os headers do draft IETF "RateLimit header fields for HTTP"
+func setRateLimitHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	if result.Limit.Unlimited() {
+		return
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit.Requests))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+	w.Header().Set("RateLimit-Policy", strconv.Itoa(result.Limit.Requests)+";w="+ceilSeconds(result.Limit.Period))
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,180 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/services"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuthService aceita apenas a chave de API "valid"
+type fakeAuthService struct{}
+
+func (fakeAuthService) AuthenticateBearer(ctx context.Context, token string) (*auth.Principal, error) {
+	return nil, services.UnauthorizedError("Invalid token", nil)
+}
+
+func (fakeAuthService) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	if key != "valid" {
+		return nil, services.UnauthorizedError("Invalid API key", nil)
+	}
+	return &auth.Principal{Subject: "orders", Method: auth.MethodAPIKey}, nil
+}
+
+func (fakeAuthService) AuthenticateTicket(ctx context.Context, ticket, method, route string) (*auth.Principal, error) {
+	return nil, services.UnauthorizedError("Invalid or expired ticket", nil)
+}
+
+func (fakeAuthService) Authorize(principal *auth.Principal, method, route string) error {
+	if principal == nil {
+		return services.UnauthorizedError("Authentication required", nil)
+	}
+	return nil
+}
+
+// failingRateLimitService simula um backend indisponível
+type failingRateLimitService struct{}
+
+func (failingRateLimitService) Allow(ctx context.Context, identity, method, route string) (ratelimit.Result, error) {
+	return ratelimit.Result{Allowed: true}, errors.New("connection refused")
+}
+
+// writeStatus responde apenas o status do erro de domínio
+func writeStatus(w http.ResponseWriter, r *http.Request, err error) {
+	domainErr, _ := services.AsDomainError(err)
+	switch domainErr.Kind {
+	case services.KindRateLimited:
+		w.WriteHeader(http.StatusTooManyRequests)
+	case services.KindUnauthorized:
+		w.WriteHeader(http.StatusUnauthorized)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func newRateLimitRouter(middlewares ...mux.MiddlewareFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middlewares...)
+	router.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+
+	return router
+}
+
+func newMemoryRateLimitService(limit string, entries ...string) services.RateLimitService {
+	defaultLimit, _ := ratelimit.ParseLimit(limit)
+	return services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(defaultLimit, entries))
+}
+
+func serve(router http.Handler, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestRateLimitHeadersAndRetryAfter(t *testing.T) {
+	router := newRateLimitRouter(RateLimit(newMemoryRateLimitService("2/m"), 0, writeStatus))
+
+	rr := serve(router, "10.0.0.1:5000", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	serve(router, "10.0.0.1:5000", nil)
+	rr = serve(router, "10.0.0.1:5000", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+	// Outro IP tem bucket próprio
+	assert.Equal(t, http.StatusOK, serve(router, "10.0.0.2:5000", nil).Code)
+}
+
+func TestRateLimitIdentity(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7, 10.0.0.2")
+
+	// O primeiro item é enviado pelo cliente; valem os itens acrescentados pelos proxies confiáveis
+	assert.Equal(t, "ip:10.0.0.1", RateLimitIdentity(req, 0))
+	assert.Equal(t, "ip:10.0.0.2", RateLimitIdentity(req, 1))
+	assert.Equal(t, "ip:203.0.113.7", RateLimitIdentity(req, 2))
+	assert.Equal(t, "ip:198.51.100.9", RateLimitIdentity(req, 5))
+
+	keyReq := req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "orders", Method: auth.MethodAPIKey}))
+	assert.Equal(t, "key:orders", RateLimitIdentity(keyReq, 1))
+
+	userReq := req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "maria", Method: auth.MethodJWT}))
+	assert.Equal(t, "user:maria", RateLimitIdentity(userReq, 1))
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	router := newRateLimitRouter(RateLimitByIP(newMemoryRateLimitService("2/m"), 1, writeStatus))
+
+	// Um valor diferente a cada requisição não gera buckets novos: o proxy acrescenta o IP real ao fim
+	for i, spoofed := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		rr := serve(router, "10.0.0.9:5000", map[string]string{"X-Forwarded-For": spoofed + ", 203.0.113.7"})
+		if i < 2 {
+			assert.Equal(t, http.StatusOK, rr.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		}
+	}
+}
+
+func TestRateLimitBackendFailureAllowsRequest(t *testing.T) {
+	router := newRateLimitRouter(RateLimit(failingRateLimitService{}, 0, writeStatus))
+
+	rr := serve(router, "10.0.0.1:5000", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitByIPThrottlesFailedAuthentication(t *testing.T) {
+	router := newRateLimitRouter(
+		RateLimitByIP(newMemoryRateLimitService("3/m", "GET /healthz=off"), 0, writeStatus),
+		Auth(fakeAuthService{}, writeStatus),
+		RateLimit(newMemoryRateLimitService("100/m"), 0, writeStatus),
+	)
+
+	// Chaves inválidas consomem o bucket do IP antes de chegar ao Auth
+	for i := 0; i < 3; i++ {
+		rr := serve(router, "10.0.0.1:5000", map[string]string{auth.APIKeyHeader: "guess"})
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	}
+	rr := serve(router, "10.0.0.1:5000", map[string]string{auth.APIKeyHeader: "guess"})
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// O bloqueio vale para o IP, mesmo com uma chave válida, mas não para outros IPs
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "10.0.0.1:5000", map[string]string{auth.APIKeyHeader: "valid"}).Code)
+	rr = serve(router, "10.0.0.2:5000", map[string]string{auth.APIKeyHeader: "valid"})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	// Depois do Auth, os headers são os do limite por cliente
+	assert.Equal(t, "100", rr.Header().Get("RateLimit-Limit"))
+
+	// Rotas isentas não são limitadas por IP
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set(auth.APIKeyHeader, "valid")
+	healthz := httptest.NewRecorder()
+	router.ServeHTTP(healthz, req)
+	assert.Equal(t, http.StatusOK, healthz.Code)
+}
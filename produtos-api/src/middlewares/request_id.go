@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"produtos-api/src/logging"
+
+	"github.com/gorilla/mux"
+)
+
+// RequestIDHeader é o header usado para receber e devolver o identificador da requisição
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID reaproveita o X-Request-ID recebido, quando válido, ou gera um novo,
+// disponibilizando-o no contexto da requisição e no header da resposta
+func RequestID() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+			next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), requestID)))
+		})
+	}
+}
+
+// validRequestID aceita apenas IDs curtos com caracteres imprimíveis, evitando injeção nos logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// randRead é a fonte dos IDs, substituída nos testes
+var randRead = rand.Read
+
+// requestSequence diferencia os IDs de fallback gerados no mesmo instante
+var requestSequence atomic.Uint64
+
+// newRequestID gera 16 bytes aleatórios em hexadecimal. Se o gerador do sistema falhar, usa o instante e um
+// contador, que ainda distinguem as requisições da instância, em vez de repetir um ID zerado.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := randRead(b); err != nil {
+		return fmt.Sprintf("%x-%x", time.Now().UnixNano(), requestSequence.Add(1))
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"produtos-api/src/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveRequestID(header string) (string, string) {
+	var fromContext string
+	router := mux.NewRouter()
+	router.Use(RequestID())
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fromContext = logging.RequestID(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(RequestIDHeader, header)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr.Header().Get(RequestIDHeader), fromContext
+}
+
+func TestRequestIDReusesValidHeader(t *testing.T) {
+	response, fromContext := serveRequestID("pedidos-api-123")
+
+	assert.Equal(t, "pedidos-api-123", response)
+	assert.Equal(t, "pedidos-api-123", fromContext)
+}
+
+func TestRequestIDReplacesInvalidHeader(t *testing.T) {
+	// IDs com espaços, quebras de linha ou longos demais seriam um vetor de injeção nos logs
+	for _, header := range []string{"", "a b", "id\nforged=1", strings.Repeat("a", 129)} {
+		response, fromContext := serveRequestID(header)
+
+		assert.Len(t, response, 32, header)
+		assert.NotEqual(t, header, response)
+		assert.Equal(t, response, fromContext)
+	}
+}
+
+func TestNewRequestIDFallsBackWhenRandomFails(t *testing.T) {
+	defer func(original func([]byte) (int, error)) { randRead = original }(randRead)
+	randRead = func([]byte) (int, error) { return 0, errors.New("entropy unavailable") }
+
+	first, second := newRequestID(), newRequestID()
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, validRequestID(first))
+	assert.NotEqual(t, strings.Repeat("0", 32), first)
+}
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteTimeouts define o prazo padrão das requisições e os prazos específicos por rota.
+// As chaves de PerRoute seguem o formato "MÉTODO /template", ex: "GET /products".
+type RouteTimeouts struct {
+	Default  time.Duration
+	PerRoute map[string]time.Duration
+}
+
+// ParseRouteTimeouts interpreta uma lista no formato "GET /products=2s" ignorando itens inválidos
+func ParseRouteTimeouts(defaultTimeout time.Duration, entries []string) RouteTimeouts {
+	timeouts := RouteTimeouts{Default: defaultTimeout, PerRoute: map[string]time.Duration{}}
+
+	for _, entry := range entries {
+		route, value, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		timeouts.PerRoute[strings.Join(strings.Fields(route), " ")] = duration
+	}
+
+	return timeouts
+}
+
+// For retorna o prazo configurado para o método e template da rota
+func (rt RouteTimeouts) For(method, route string) time.Duration {
+	if timeout, ok := rt.PerRoute[method+" "+route]; ok {
+		return timeout
+	}
+
+	return rt.Default
+}
+
+// Timeout aplica ao contexto da requisição o prazo configurado para a rota.
+// Quando o prazo expira, as consultas em andamento são canceladas pelo db.WithContext.
+func Timeout(timeouts RouteTimeouts) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := timeouts.For(r.Method, RouteTemplate(r))
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRouteTimeouts(t *testing.T) {
+	timeouts := ParseRouteTimeouts(10*time.Second, []string{
+		"GET /products=2s",
+		"  POST   /products = 500ms ",
+		"GET /products/stream=0s",
+		"GET /audit",           // sem prazo: ignorado
+		"PUT /products/{id}=x", // prazo inválido: ignorado
+	})
+
+	assert.Equal(t, 2*time.Second, timeouts.For("GET", "/products"))
+	assert.Equal(t, 500*time.Millisecond, timeouts.For("POST", "/products"))
+	assert.Equal(t, time.Duration(0), timeouts.For("GET", "/products/stream"))
+	assert.Equal(t, 10*time.Second, timeouts.For("GET", "/audit"))
+	assert.Equal(t, 10*time.Second, timeouts.For("PUT", "/products/{id}"))
+	assert.Len(t, timeouts.PerRoute, 3)
+}
+
+func TestTimeoutAppliesRouteDeadline(t *testing.T) {
+	deadlines := map[string]time.Duration{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if ok {
+			deadlines[r.URL.Path] = time.Until(deadline)
+		} else {
+			deadlines[r.URL.Path] = 0
+		}
+	}
+
+	router := mux.NewRouter()
+	router.Use(Timeout(ParseRouteTimeouts(time.Minute, []string{"GET /products/{id}=2s", "GET /stream=0s"})))
+	router.HandleFunc("/products/{id}", handler)
+	router.HandleFunc("/products", handler)
+	router.HandleFunc("/stream", handler)
+
+	for _, path := range []string{"/products/1", "/products", "/stream"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// O prazo é escolhido pelo template da rota; 0s deixa a requisição sem prazo
+	assert.InDelta(t, 2*time.Second, deadlines["/products/1"], float64(time.Second))
+	assert.InDelta(t, time.Minute, deadlines["/products"], float64(time.Second))
+	assert.Equal(t, time.Duration(0), deadlines["/stream"])
+}
+
+func TestTimeoutCancelsSlowHandler(t *testing.T) {
+	var ctxErr error
+	router := mux.NewRouter()
+	router.Use(Timeout(RouteTimeouts{Default: 10 * time.Millisecond}))
+	router.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		ctxErr = r.Context().Err()
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	assert.ErrorIs(t, ctxErr, context.DeadlineExceeded)
+}
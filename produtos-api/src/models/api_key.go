@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// APIKey represents a credential used by machine clients; only the hash of the key is stored.
+// @Description API key metadata; the key itself is only returned when created or rotated
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`                                   // API key ID
+	Name       string     `json:"name" validate:"required,notblank,min=2,max=80"`         // Client name, e.g. orders-service
+	Prefix     string     `json:"prefix" gorm:"not null;uniqueIndex:idx_api_keys_prefix"` // Public key prefix, used to identify the key
+	Hash       string     `json:"-" gorm:"not null"`                                      // SHA-256 hash of the key
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;not null"`                 // Granted scopes, e.g. products:write
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`                                   // Expiration time; empty means no expiration
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`                                 // Last successful authentication
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`                                   // Last rotation of the key
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`                                   // Revocation time
+	CreatedBy  string     `json:"created_by"`                                             // Subject that created the key
+	CreatedAt  time.Time  `json:"created_at"`                                             // Creation time
+}
+
+// IssuedAPIKey is returned when a key is created or rotated and carries the plain key.
+// @Description API key metadata and the plain key, shown only once
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // Plain API key, send it in the X-API-Key header
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+	AuditActionRevert  = "revert"
+)
+
+// AuditRecord represents a catalog mutation. Records are chained by hash: each record stores
+// the hash of the previous one, so altering or removing a record breaks the chain.
+// @Description Audit record of a catalog mutation
+type AuditRecord struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`                                                  // Audit record ID
+	EntityType string        `json:"entity_type" gorm:"not null;index:idx_audit_records_entity,priority:1"` // Entity type, e.g. product
+	EntityID   uint          `json:"entity_id" gorm:"not null;index:idx_audit_records_entity,priority:2"`   // Entity ID
+	Action     string        `json:"action" gorm:"not null"`                                                // create, update, delete, restore, purge or revert
+	Actor      string        `json:"actor" gorm:"not null;index:idx_audit_records_actor"`                   // Subject that made the change
+	RequestID  string        `json:"request_id"`                                                            // X-Request-ID of the change
+	Changes    []FieldChange `json:"changes" gorm:"serializer:json"`                                        // Field-level diff
+	OccurredAt time.Time     `json:"occurred_at" gorm:"not null;index:idx_audit_records_occurred_at"`       // Time of the change
+	PrevHash   string        `json:"prev_hash" gorm:"not null;uniqueIndex:idx_audit_records_prev_hash"`     // Hash of the previous record
+	Hash       string        `json:"hash" gorm:"not null"`                                                  // Hash of this record
+}
+
+// FieldChange represents the value of a field before and after a change
+// @Description Value of a field before and after a change
+type FieldChange struct {
+	Field  string      `json:"field"`            // Field name
+	Before interface{} `json:"before,omitempty"` // Previous value; empty on create
+	After  interface{} `json:"after,omitempty"`  // New value; empty on delete
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// Document types of a customer
+const (
+	DocumentCPF  = "cpf"
+	DocumentCNPJ = "cnpj"
+)
+
+// Consent purposes under the LGPD
+const (
+	ConsentMarketing   = "marketing"
+	ConsentDataSharing = "data_sharing"
+)
+
+// Customer represents a customer of the clients service. The subject that registered the customer is
+// the data subject and can read, correct, export and erase its own data; editors can access every customer.
+// A document is unique per subject, so a customer registered by someone else never blocks the data subject,
+// and a data subject who registers itself has a single customer.
+// @Description Customer, its addresses and consents
+type Customer struct {
+	ID                 uint              `json:"id" gorm:"primaryKey"`                                                                                                                                                                                                   // Customer ID
+	Name               string            `json:"name" gorm:"not null"`                                                                                                                                                                                                   // Full name or company name
+	Document           string            `json:"document" gorm:"not null;uniqueIndex:idx_customers_subject_document,priority:2"`                                                                                                                                         // CPF or CNPJ digits
+	DocumentType       string            `json:"document_type" gorm:"not null"`                                                                                                                                                                                          // cpf or cnpj
+	Email              string            `json:"email,omitempty"`                                                                                                                                                                                                        // Contact email
+	Phone              string            `json:"phone,omitempty"`                                                                                                                                                                                                        // Contact phone
+	Subject            string            `json:"subject" gorm:"index:idx_customers_subject;uniqueIndex:idx_customers_subject_document,priority:1,where:erased_at IS NULL;uniqueIndex:idx_customers_self_registered_subject,where:erased_at IS NULL AND self_registered"` // Subject (JWT sub) of the data subject
+	SelfRegistered     bool              `json:"-" gorm:"not null;default:false"`                                                                                                                                                                                        // Registered by the data subject, who can have only one customer
+	MarketingConsent   bool              `json:"marketing_consent" gorm:"not null;default:false"`                                                                                                                                                                        // Consent to receive marketing communications
+	DataSharingConsent bool              `json:"data_sharing_consent" gorm:"not null;default:false"`                                                                                                                                                                     // Consent to share data with partners
+	ConsentUpdatedAt   *time.Time        `json:"consent_updated_at,omitempty"`                                                                                                                                                                                           // Last change of a consent
+	Addresses          []CustomerAddress `json:"addresses" gorm:"constraint:OnDelete:CASCADE"`                                                                                                                                                                           // Customer addresses
+	ErasedAt           *time.Time        `json:"-"`                                                                                                                                                                                                                      // When the personal data was erased
+	CreatedAt          time.Time         `json:"created_at"`                                                                                                                                                                                                             // Registration time
+	UpdatedAt          time.Time         `json:"updated_at"`                                                                                                                                                                                                             // Last update
+}
+
+// CustomerAddress is an address of a customer; at most one address of each customer is the default
+// @Description Customer address
+type CustomerAddress struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`                                                 // Address ID
+	CustomerID uint      `json:"customer_id" gorm:"not null;index:idx_customer_addresses_customer_id"` // Customer ID
+	Label      string    `json:"label,omitempty"`                                                      // Name of the address, ex: home, office
+	Street     string    `json:"street" gorm:"not null"`                                               // Street
+	Number     string    `json:"number" gorm:"not null"`                                               // Number
+	Complement string    `json:"complement,omitempty"`                                                 // Complement
+	District   string    `json:"district,omitempty"`                                                   // District (bairro)
+	City       string    `json:"city" gorm:"not null"`                                                 // City
+	State      string    `json:"state" gorm:"not null"`                                                // State (UF)
+	PostalCode string    `json:"postal_code" gorm:"not null"`                                          // CEP digits
+	Default    bool      `json:"default" gorm:"column:is_default;not null;default:false"`              // Whether it is the default address
+	CreatedAt  time.Time `json:"created_at"`                                                           // Creation time
+	UpdatedAt  time.Time `json:"updated_at"`                                                           // Last update
+}
+
+// ConsentRecord is an entry of the consent history of a customer, kept as proof of each grant and withdrawal
+// @Description Grant or withdrawal of a consent
+type ConsentRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`                                              // Record ID
+	CustomerID uint      `json:"customer_id" gorm:"not null;index:idx_consent_records_customer_id"` // Customer ID
+	Purpose    string    `json:"purpose" gorm:"not null"`                                           // marketing or data_sharing
+	Granted    bool      `json:"granted" gorm:"not null"`                                           // Whether the consent was granted or withdrawn
+	RecordedBy string    `json:"recorded_by"`                                                       // Subject that changed the consent
+	CreatedAt  time.Time `json:"created_at"`                                                        // When the consent changed
+}
+
+// CustomerRequest is the registration or correction of a customer; the document accepts punctuation
+// @Description Customer data
+type CustomerRequest struct {
+	Name     string `json:"name" validate:"notblank,max=200"`         // Full name or company name
+	Document string `json:"document" validate:"required,cpf_cnpj"`    // CPF or CNPJ
+	Email    string `json:"email" validate:"omitempty,email,max=254"` // Contact email
+	Phone    string `json:"phone" validate:"max=20"`                  // Contact phone
+}
+
+// ConsentRequest grants or withdraws consents; omitted consents are not changed
+// @Description Consents of the customer
+type ConsentRequest struct {
+	MarketingConsent   *bool `json:"marketing_consent"`    // Consent to receive marketing communications
+	DataSharingConsent *bool `json:"data_sharing_consent"` // Consent to share data with partners
+}
+
+// AddressRequest is the creation or update of an address; the postal code accepts punctuation
+// @Description Customer address
+type AddressRequest struct {
+	Label      string `json:"label" validate:"max=50"`             // Name of the address, ex: home, office
+	Street     string `json:"street" validate:"notblank,max=200"`  // Street
+	Number     string `json:"number" validate:"notblank,max=20"`   // Number
+	Complement string `json:"complement" validate:"max=100"`       // Complement
+	District   string `json:"district" validate:"max=100"`         // District (bairro)
+	City       string `json:"city" validate:"notblank,max=100"`    // City
+	State      string `json:"state" validate:"required,uf"`        // State (UF)
+	PostalCode string `json:"postal_code" validate:"required,cep"` // CEP
+	Default    bool   `json:"default"`                             // Whether it becomes the default address
+}
+
+// CustomerExport is the copy of the personal data of a customer handed to the data subject
+// @Description Personal data of the customer
+type CustomerExport struct {
+	Customer   Customer        `json:"customer"`    // Customer and addresses
+	Consents   []ConsentRecord `json:"consents"`    // Consent history
+	ExportedAt time.Time       `json:"exported_at"` // Export time
+}
+
+// CustomerFilter filters the customer listing
+type CustomerFilter struct {
+	Document string
+	Email    string
+	Subject  string
+	Limit    int
+}
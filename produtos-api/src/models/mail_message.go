@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Mail message statuses
+const (
+	MailPending = "pending"
+	MailSent    = "sent"
+	MailFailed  = "failed"
+)
+
+// MailMessage is a rendered email waiting in the mail queue. Messages are rendered when queued, so
+// a retry sends exactly the same content, and the key makes queueing the same notification twice a no-op.
+type MailMessage struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`                                                 // Queue sequence
+	Key           string     `json:"key" gorm:"not null;uniqueIndex"`                                      // Idempotency key, e.g. the event ID and the recipient
+	Template      string     `json:"template" gorm:"not null;index:idx_mail_messages_template_created_at"` // Template used to render the message
+	Locale        string     `json:"locale" gorm:"not null"`                                               // Locale the message was rendered in
+	To            string     `json:"to" gorm:"not null"`                                                   // Recipient address
+	Subject       string     `json:"subject" gorm:"not null"`                                              // Rendered subject
+	Text          string     `json:"text" gorm:"not null"`                                                 // Rendered plain text body
+	HTML          string     `json:"html" gorm:"not null"`                                                 // Rendered HTML body
+	Status        string     `json:"status" gorm:"not null;index:idx_mail_messages_status"`                // pending, sent or failed
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`                                   // Sending attempts
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null"`                                      // Earliest time of the next attempt
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`                                            // Time of the last attempt
+	LastError     string     `json:"last_error,omitempty"`                                                 // Error of the last failed attempt
+	SentAt        *time.Time `json:"sent_at,omitempty"`                                                    // When the transport accepted the message
+	CreatedAt     time.Time  `json:"created_at" gorm:"index:idx_mail_messages_template_created_at"`        // When the message was queued
+}
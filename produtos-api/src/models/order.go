@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Order statuses
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderCancelled = "cancelled"
+)
+
+// Order represents an order of the orders service. The stock of its items is reserved in the products
+// API at checkout; cancelling the order releases it and shipping the order makes it final.
+// @Description Order and its items
+type Order struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`                           // Order ID
+	CustomerID    uint        `json:"customer_id,omitempty"`                          // Customer who placed the order
+	Email         string      `json:"email,omitempty"`                                // Contact email
+	Locale        string      `json:"locale"`                                         // Language negotiated at checkout, used in notifications
+	Status        string      `json:"status" gorm:"not null;index:idx_orders_status"` // pending, paid, shipped or cancelled
+	Total         float64     `json:"total" gorm:"not null"`                          // Sum of the items at checkout prices
+	CancelReason  string      `json:"cancel_reason,omitempty"`                        // Why the order was cancelled
+	StockReserved bool        `json:"stock_reserved" gorm:"not null;default:false"`   // Whether the products API confirmed the reservation
+	StockReleased bool        `json:"stock_released" gorm:"not null;default:false"`   // Whether the stock of a cancelled order was given back
+	CreatedBy     string      `json:"created_by"`                                     // Subject that placed the order
+	Items         []OrderItem `json:"items" gorm:"constraint:OnDelete:CASCADE"`       // Ordered items
+	PaidAt        *time.Time  `json:"paid_at,omitempty"`                              // When the payment was confirmed
+	ShippedAt     *time.Time  `json:"shipped_at,omitempty"`                           // When the order was shipped
+	CancelledAt   *time.Time  `json:"cancelled_at,omitempty"`                         // When the order was cancelled
+	CreatedAt     time.Time   `json:"created_at"`                                     // Checkout time
+	UpdatedAt     time.Time   `json:"updated_at" gorm:"index:idx_orders_updated_at"`  // Last update
+}
+
+// OrderItem is a product of an order, with the name and price it had at checkout
+// @Description Ordered product
+type OrderItem struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`                                    // Item ID
+	OrderID   uint    `json:"order_id" gorm:"not null;index:idx_order_items_order_id"` // Order ID
+	ProductID uint    `json:"product_id" gorm:"not null"`                              // Product ID in the products API
+	Name      string  `json:"name" gorm:"not null"`                                    // Product name at checkout
+	UnitPrice float64 `json:"unit_price" gorm:"not null"`                              // Product price at checkout
+	Quantity  int     `json:"quantity" gorm:"not null"`                                // Ordered units
+}
+
+// OrderRequest is the checkout of an order; repeated products are summed
+// @Description Checkout of an order
+type OrderRequest struct {
+	CustomerID uint               `json:"customer_id"`                      // Customer who places the order
+	Email      string             `json:"email" validate:"omitempty,email"` // Contact email
+	Items      []OrderItemRequest `json:"items" validate:"dive"`            // Ordered items
+}
+
+// OrderItemRequest is a product and the units ordered
+type OrderItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required"` // Product ID in the products API
+	Quantity  int  `json:"quantity" validate:"gt=0"`       // Ordered units
+}
+
+// OrderCancelRequest carries the reason of a cancellation
+// @Description Cancellation of an order
+type OrderCancelRequest struct {
+	Reason string `json:"reason" validate:"max=200"` // Why the order was cancelled
+}
+
+// OrderFilter filters the order listing
+type OrderFilter struct {
+	Status     string
+	CustomerID uint
+	Limit      int
+}
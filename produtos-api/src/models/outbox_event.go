@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OutboxEvent represents a domain event waiting to be relayed to the broker. Events are written
+// in the same transaction as the change and published in ID order by the outbox dispatcher.
+// @Description Domain event stored in the transactional outbox
+type OutboxEvent struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`                                                        // Outbox sequence, defines the publication order
+	EventID       string     `json:"event_id" gorm:"not null;uniqueIndex"`                                        // Unique event ID, used by consumers to discard duplicates
+	Type          string     `json:"type" gorm:"not null"`                                                        // Event type, e.g. ProductCreated
+	AggregateType string     `json:"aggregate_type" gorm:"not null;index:idx_outbox_events_aggregate,priority:1"` // Aggregate type, e.g. product
+	AggregateID   uint       `json:"aggregate_id" gorm:"not null;index:idx_outbox_events_aggregate,priority:2"`   // Aggregate ID; events of the same aggregate are published in order
+	Payload       string     `json:"payload" gorm:"not null"`                                                     // JSON payload
+	SchemaVersion int        `json:"schema_version" gorm:"not null;default:1"`                                    // Version of the payload schema
+	TraceContext  string     `json:"trace_context,omitempty"`                                                     // W3C trace context of the change, as JSON
+	RequestID     string     `json:"request_id"`                                                                  // X-Request-ID of the change
+	OccurredAt    time.Time  `json:"occurred_at" gorm:"not null"`                                                 // When the change happened
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`                                          // Failed publication attempts
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null"`                                             // Earliest time of the next attempt
+	LastError     string     `json:"last_error,omitempty"`                                                        // Error of the last failed attempt
+	PublishedAt   *time.Time `json:"published_at,omitempty" gorm:"index:idx_outbox_events_published_at"`          // When the broker acknowledged the event
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ProcessedEvent records a consumed event. It is written in the same transaction as the event's
+// effects, so a redelivered event is recognized and skipped.
+type ProcessedEvent struct {
+	EventID     string    `json:"event_id" gorm:"primaryKey"` // ID of the consumed event
+	Type        string    `json:"type" gorm:"not null"`       // Event type, e.g. OrderPlaced
+	ProcessedAt time.Time `json:"processed_at" gorm:"not null"`
+}
@@ -1,11 +1,21 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // Product represents a product entity in the database.
 // @Description A product model
 type Product struct {
-	ID          uint    `json:"id" gorm:"primaryKey"` // Product ID
-	Name        string  `json:"name"`                 // Product Name
-	Description string  `json:"description"`          // Product Description
-	Price       float64 `json:"price"`                // Product Price
-	Stock       int     `json:"stock"`                // Product Stock
+	ID          uint           `json:"id" gorm:"primaryKey"`                                                                               // Product ID
+	Name        string         `json:"name" validate:"required,notblank,min=2,max=120" gorm:"index:idx_products_category_name,priority:2"` // Product Name
+	Description string         `json:"description" validate:"max=2000"`                                                                    // Product Description
+	Category    string         `json:"category" validate:"max=60" gorm:"index:idx_products_category_name,priority:1"`                      // Product Category
+	Price       float64        `json:"price" validate:"gte=0,precision=2"`                                                                 // Product Price
+	Stock       int            `json:"stock" validate:"gte=0"`                                                                             // Product Stock
+	CreatedAt   time.Time      `json:"created_at"`                                                                                         // Creation time
+	UpdatedAt   time.Time      `json:"updated_at"`                                                                                         // Last update time
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index:idx_products_deleted_at" swaggertype:"string"`                               // Deletion time; set only for products in the trash
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProductChange is a catalog change pushed to the change stream clients. It is built from the
+// product events of the outbox, whose sequence is the stream position.
+// @Description Catalog change sent by GET /products/stream
+type ProductChange struct {
+	Position   uint            `json:"position"`                  // Stream position, sent as the SSE id; resume after it with Last-Event-ID
+	EventID    string          `json:"event_id"`                  // Unique event ID
+	Type       string          `json:"type"`                      // ProductCreated, ProductUpdated, ProductDeleted or StockChanged
+	ProductID  uint            `json:"product_id"`                // Changed product
+	Category   string          `json:"category"`                  // Category of the product when it changed
+	OccurredAt time.Time       `json:"occurred_at"`               // When the change happened
+	Data       json.RawMessage `json:"data" swaggertype:"object"` // Event payload, as published to the broker
+}
+
+// StreamTicket is a short-lived credential for the change stream connections opened by browsers,
+// which cannot send the Authorization or X-API-Key headers
+// @Description Ticket for GET /products/stream and GET /products/stream/ws
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`     // Send it in the ticket query parameter
+	ExpiresAt time.Time `json:"expires_at"` // The connection must be opened before this instant
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ProductRevision represents a version of a product, kept in the history table.
+// @Description A past or current version of a product
+type ProductRevision struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	ProductID   uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_product_revisions_product_revision,priority:1"` // Product ID
+	Revision    uint      `json:"revision" gorm:"not null;uniqueIndex:idx_product_revisions_product_revision,priority:2"`   // Sequential revision number, starting at 1
+	Name        string    `json:"name"`                                                                                     // Product Name
+	Description string    `json:"description"`                                                                              // Product Description
+	Category    string    `json:"category"`                                                                                 // Product Category
+	Price       float64   `json:"price"`                                                                                    // Product Price
+	Stock       int       `json:"stock"`                                                                                    // Product Stock
+	Deleted     bool      `json:"deleted"`                                                                                  // Whether the product was in the trash in this revision
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime:false"`                                                   // Creation time of the product
+	ValidFrom   time.Time `json:"valid_from" gorm:"not null"`                                                               // When this revision became current
+}
+
+// NewProductRevision cria a revisão com o estado atual do produto
+func NewProductRevision(product *Product, revision uint, validFrom time.Time) *ProductRevision {
+	return &ProductRevision{
+		ProductID:   product.ID,
+		Revision:    revision,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Deleted:     product.DeletedAt.Valid,
+		CreatedAt:   product.CreatedAt,
+		ValidFrom:   validFrom,
+	}
+}
+
+// Product reconstrói o produto como era nesta revisão
+func (r *ProductRevision) Product() *Product {
+	return &Product{
+		ID:          r.ProductID,
+		Name:        r.Name,
+		Description: r.Description,
+		Category:    r.Category,
+		Price:       r.Price,
+		Stock:       r.Stock,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.ValidFrom,
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ProductIDParams identifies a product in the JSON-RPC methods
+type ProductIDParams struct {
+	ID uint `json:"id"` // Product ID
+}
+
+// ProductGetParams identifies a product and, optionally, the instant of its history to read
+type ProductGetParams struct {
+	ID   uint       `json:"id"`              // Product ID
+	AsOf *time.Time `json:"as_of,omitempty"` // Read the product as it was at this instant
+}
+
+// ProductListParams filters the product list; an empty name lists every product
+type ProductListParams struct {
+	Name string `json:"name,omitempty"` // Exact product name
+}
+
+// ProductRevertParams identifies the revision a product is reverted to
+type ProductRevertParams struct {
+	ID       uint `json:"id"`       // Product ID
+	Revision uint `json:"revision"` // Revision to restore
+}
+
+// ProductCount is the number of products in the catalog
+type ProductCount struct {
+	Count int64 `json:"count"` // Number of products
+}
@@ -0,0 +1,11 @@
+package models
+
+// ProductTranslation represents the localized content of a product for a locale.
+// @Description Localized name and description of a product
+type ProductTranslation struct {
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	ProductID   uint   `json:"product_id" gorm:"not null;uniqueIndex:idx_product_translations_product_locale,priority:1"` // Product ID
+	Locale      string `json:"locale" gorm:"not null;uniqueIndex:idx_product_translations_product_locale,priority:2"`     // BCP 47 locale, e.g. pt-BR
+	Name        string `json:"name" validate:"required,notblank,min=2,max=120"`                                           // Localized Name
+	Description string `json:"description" validate:"max=2000"`                                                           // Localized Description
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Stock reservation statuses
+const (
+	ReservationReserved = "reserved"
+	ReservationReleased = "released"
+	ReservationShipped  = "shipped"
+)
+
+// StockReservation represents the stock of a product held for an order. The stock is decremented
+// when the order is placed; cancelling the order gives it back and shipping it makes it final.
+// Reservations are created from OrderPlaced events or by the orders service checkout.
+// @Description Stock held for an order
+type StockReservation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`                                                                   // Reservation ID
+	OrderID   uint      `json:"order_id" gorm:"not null;uniqueIndex:idx_stock_reservations_order_product,priority:1"`   // Order ID in the orders service
+	ProductID uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_stock_reservations_order_product,priority:2"` // Reserved product
+	Quantity  int       `json:"quantity" gorm:"not null"`                                                               // Reserved units
+	Status    string    `json:"status" gorm:"not null"`                                                                 // reserved, released or shipped
+	EventID   string    `json:"event_id" gorm:"not null"`                                                               // OrderPlaced event, or X-Request-ID of the checkout, that created the reservation
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockReservationRequest holds the stock of every item of an order, or of none of them
+// @Description Stock reservation of an order; repeated products are summed
+type StockReservationRequest struct {
+	OrderID uint                   `json:"order_id" validate:"required"` // Order ID in the orders service
+	Items   []StockReservationItem `json:"items" validate:"dive"`        // Reserved items
+}
+
+// StockReservationItem is a product and the units reserved for the order
+type StockReservationItem struct {
+	ProductID uint `json:"product_id" validate:"required"` // Reserved product
+	Quantity  int  `json:"quantity" validate:"gt=0"`       // Reserved units
+}
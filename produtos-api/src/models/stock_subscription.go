@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// StockSubscription is a request to be emailed once when an out of stock product is replenished.
+// The recipient is either an email address or a customer of the customers service, whose email is
+// looked up when the notification is sent. A restock marks the subscription as due and the notification is
+// sent in the background; the subscription is removed after the notification.
+// @Description Back-in-stock subscription
+type StockSubscription struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`                                                                                                              // Subscription ID
+	ProductID  uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_stock_subscriptions_product_email;uniqueIndex:idx_stock_subscriptions_product_customer"` // Product awaited
+	Email      string     `json:"email,omitempty" gorm:"not null;default:'';uniqueIndex:idx_stock_subscriptions_product_email,where:email <> ''"`                    // Recipient address
+	CustomerID uint       `json:"customer_id,omitempty" gorm:"not null;default:0;uniqueIndex:idx_stock_subscriptions_product_customer,where:customer_id <> 0"`       // Recipient customer
+	Locale     string     `json:"locale"`                                                                                                                            // Language of the notification
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`                                                                                                     // SHA-256 of the unsubscribe token
+	NotifyAt   *time.Time `json:"-" gorm:"index"`                                                                                                                    // When the notification is due; nil while waiting for a restock
+	CreatedBy  string     `json:"created_by"`                                                                                                                        // Subject that subscribed
+	CreatedAt  time.Time  `json:"created_at"`                                                                                                                        // Subscription time
+}
+
+// StockSubscriptionRecipient keeps the unsubscribe token of the latest back-in-stock email sent to an
+// address or a customer. The link in the email cancels every pending subscription of the recipient, since
+// the token returned by the subscription only reaches whoever subscribed.
+type StockSubscriptionRecipient struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`                                                                          // Recipient ID
+	Email      string    `json:"email" gorm:"not null;default:'';uniqueIndex:idx_stock_subscription_recipients_recipient"`      // Lowercase address of email subscriptions
+	CustomerID uint      `json:"customer_id" gorm:"not null;default:0;uniqueIndex:idx_stock_subscription_recipients_recipient"` // Customer of customer subscriptions
+	TokenHash  string    `json:"-" gorm:"not null;uniqueIndex"`                                                                 // SHA-256 of the unsubscribe token of the latest email
+	UpdatedAt  time.Time `json:"updated_at"`                                                                                    // When the latest email was queued
+}
+
+// StockSubscriptionRequest subscribes an email or a customer, never both
+// @Description Back-in-stock subscription of an email or a customer
+type StockSubscriptionRequest struct {
+	Email      string `json:"email" validate:"omitempty,email,max=254"` // Recipient address
+	CustomerID uint   `json:"customer_id"`                              // Recipient customer
+}
+
+// IssuedStockSubscription is returned once, when the subscription is created, with the plain unsubscribe token
+// @Description Subscription and its unsubscribe token, shown only once
+type IssuedStockSubscription struct {
+	StockSubscription
+	UnsubscribeToken string `json:"unsubscribe_token"` // Token for DELETE /stock-subscriptions/{token}
+}
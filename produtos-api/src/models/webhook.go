@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook represents a partner endpoint that receives catalog events over HTTP. Deliveries are
+// signed with the secret, which is only returned when the webhook is created or its secret rotated.
+// @Description Webhook subscription; the secret is only returned when created or rotated
+type Webhook struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`                                      // Webhook ID
+	URL                 string     `json:"url" gorm:"not null" validate:"required,http_url,max=2048"` // Endpoint that receives the deliveries
+	EventTypes          []string   `json:"event_types" gorm:"serializer:json"`                        // Delivered event types; empty delivers every event
+	Secret              string     `json:"-" gorm:"not null"`                                         // HMAC signing secret
+	Active              bool       `json:"active" gorm:"not null"`                                    // Inactive webhooks receive no deliveries
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`            // Failed attempts since the last success
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`                                     // When the webhook was disabled after repeated failures
+	DisabledReason      string     `json:"disabled_reason,omitempty"`                                 // Why the webhook was disabled
+	CreatedBy           string     `json:"created_by"`                                                // Subject that created the webhook
+	CreatedAt           time.Time  `json:"created_at"`                                                // Creation time
+	UpdatedAt           time.Time  `json:"updated_at"`                                                // Last update
+}
+
+// Accepts reports whether the webhook subscribes to the event type
+func (w *Webhook) Accepts(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, accepted := range w.EventTypes {
+		if accepted == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IssuedWebhook is returned when a webhook is created or its secret rotated and carries the secret.
+// @Description Webhook subscription and its signing secret, shown only once
+type IssuedWebhook struct {
+	Webhook
+	Secret string `json:"secret"` // Secret used to verify the X-Webhook-Signature header
+}
+
+// WebhookDelivery represents an event sent, or to be sent, to a webhook. Each event is delivered
+// once per webhook; replaying a delivery queues it again.
+// @Description Delivery of an event to a webhook
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`                                                                   // Delivery ID
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_webhook_event,priority:1"` // Target webhook
+	EventID        string     `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_webhook_event,priority:2"`   // Delivered event ID
+	EventType      string     `json:"event_type" gorm:"not null"`                                                             // Delivered event type
+	Payload        string     `json:"payload" gorm:"not null"`                                                                // JSON request body
+	Status         string     `json:"status" gorm:"not null;index:idx_webhook_deliveries_status"`                             // pending, succeeded or failed
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`                                                     // Attempts since the delivery was queued
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null"`                                                        // Earliest time of the next attempt
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`                                                              // Time of the last attempt
+	ResponseStatus int        `json:"response_status,omitempty"`                                                              // HTTP status of the last attempt
+	LastError      string     `json:"last_error,omitempty"`                                                                   // Error of the last failed attempt
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`                                                                 // When the endpoint accepted the delivery
+	ReplayedAt     *time.Time `json:"replayed_at,omitempty"`                                                                  // Last manual replay
+	CreatedAt      time.Time  `json:"created_at"`                                                                             // When the delivery was queued
+}
@@ -0,0 +1,39 @@
+package productsrpc
+
+import (
+	"errors"
+
+	"produtos-api/src/jsonrpc"
+)
+
+//go:generate go run ../../cmd/rpcgen -o client_gen.go
+
+// Client chama os métodos JSON-RPC do catálogo (POST /rpc) com parâmetros e resultados tipados. Os métodos
+// são gerados pelo cmd/rpcgen a partir de controllers.ProductRPCMethods; chamadas em lote e notificações
+// usam os métodos do jsonrpc.Client embutido.
+type Client struct {
+	*jsonrpc.Client
+}
+
+// NewClient cria o cliente; config.URL é o endpoint completo, ex: http://produtos-api:8080/rpc
+func NewClient(config jsonrpc.ClientConfig) *Client {
+	return &Client{Client: jsonrpc.NewClient(config)}
+}
+
+// ErrorCode retorna o código do problema (ex: product_not_found) de um erro dos métodos, ou "" quando o
+// erro não veio do servidor
+func ErrorCode(err error) string {
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		return ""
+	}
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if rpcErr.DecodeData(&problem) != nil {
+		return ""
+	}
+
+	return problem.Code
+}
@@ -0,0 +1,95 @@
+// Code generated by rpcgen; DO NOT EDIT.
+
+package productsrpc
+
+import (
+	"context"
+
+	"produtos-api/src/models"
+)
+
+// CreateProduct chama products.create: cadastra um produto
+func (c *Client) CreateProduct(ctx context.Context, params models.Product) (*models.Product, error) {
+	var result models.Product
+	if err := c.Call(ctx, "products.create", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetProduct chama products.get: retorna um produto pelo ID; com as_of, a versão vigente naquele instante
+func (c *Client) GetProduct(ctx context.Context, params models.ProductGetParams) (*models.Product, error) {
+	var result models.Product
+	if err := c.Call(ctx, "products.get", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListProducts chama products.list: lista os produtos; com name, apenas os produtos com esse nome
+func (c *Client) ListProducts(ctx context.Context, params models.ProductListParams) ([]models.Product, error) {
+	var result []models.Product
+	if err := c.Call(ctx, "products.list", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CountProducts chama products.count: retorna a quantidade de produtos do catálogo
+func (c *Client) CountProducts(ctx context.Context) (*models.ProductCount, error) {
+	var result models.ProductCount
+	if err := c.Call(ctx, "products.count", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateProduct chama products.update: atualiza o produto identificado por id
+func (c *Client) UpdateProduct(ctx context.Context, params models.Product) (*models.Product, error) {
+	var result models.Product
+	if err := c.Call(ctx, "products.update", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteProduct chama products.delete: move o produto para a lixeira
+func (c *Client) DeleteProduct(ctx context.Context, params models.ProductIDParams) error {
+	return c.Call(ctx, "products.delete", params, nil)
+}
+
+// GetTrash chama products.trash: lista os produtos na lixeira
+func (c *Client) GetTrash(ctx context.Context) ([]models.Product, error) {
+	var result []models.Product
+	if err := c.Call(ctx, "products.trash", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreProduct chama products.restore: restaura um produto da lixeira
+func (c *Client) RestoreProduct(ctx context.Context, params models.ProductIDParams) (*models.Product, error) {
+	var result models.Product
+	if err := c.Call(ctx, "products.restore", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRevisions chama products.revisions: lista o histórico de versões do produto
+func (c *Client) GetRevisions(ctx context.Context, params models.ProductIDParams) ([]models.ProductRevision, error) {
+	var result []models.ProductRevision
+	if err := c.Call(ctx, "products.revisions", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RevertProduct chama products.revert: cria uma nova revisão do produto com o conteúdo da revisão informada
+func (c *Client) RevertProduct(ctx context.Context, params models.ProductRevertParams) (*models.Product, error) {
+	var result models.Product
+	if err := c.Call(ctx, "products.revert", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval define a frequência da remoção dos buckets ociosos
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// MemoryBackend guarda os buckets em memória; os limites valem apenas para esta instância
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryBackend cria o backend em memória
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{buckets: map[string]*bucket{}}
+}
+
+// Take consome um token do bucket da chave, criando-o cheio no primeiro uso
+func (b *MemoryBackend) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+
+	current, ok := b.buckets[key]
+	if !ok || current.limit != limit {
+		current = &bucket{tokens: float64(limit.Requests), updated: now, limit: limit}
+		b.buckets[key] = current
+	}
+
+	tokens, result := take(current.tokens, current.updated, limit, now)
+	current.tokens, current.updated = tokens, now
+
+	return result, nil
+}
+
+// sweep remove os buckets que já teriam voltado a ficar cheios, equivalentes a um bucket novo
+func (b *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+	b.lastSweep = now
+
+	for key, current := range b.buckets {
+		if now.Sub(current.updated) >= current.limit.Period {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit define um token bucket: Requests requisições por Period, com rajada de até Requests.
+// Requests <= 0 desativa o limite.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited indica se o limite está desativado
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Rate retorna a taxa de reposição de tokens por segundo
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formata o limite como em ParseLimit, ex: "60/m"
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+var units = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit interpreta limites no formato "<requisições>/<s|m|h>" (ex: "100/m") ou "off"
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return Limit{}, nil
+	}
+
+	count, unit, found := strings.Cut(value, "/")
+	period, ok := units[strings.TrimSpace(unit)]
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if !found || !ok || err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected e.g. 100/m or off", value)
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Result é a decisão do limitador para uma requisição
+type Result struct {
+	Limit      Limit
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration // tempo até o bucket voltar a ficar cheio
+	RetryAfter time.Duration // tempo até o próximo token, quando a requisição é negada
+}
+
+// Backend guarda os buckets e decide atomicamente se a requisição identificada por key pode
+// consumir um token. O MemoryBackend atende uma única instância; implementações distribuídas
+// (ex: Redis com um script Lua) permitem compartilhar os limites entre réplicas.
+type Backend interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Policies define o limite padrão e os limites específicos por rota.
+// As chaves de PerRoute seguem o formato "MÉTODO /template", ex: "GET /products".
+type Policies struct {
+	Default  Limit
+	PerRoute map[string]Limit
+}
+
+// ParsePolicies interpreta uma lista no formato "GET /products=60/m" ignorando itens inválidos
+func ParsePolicies(defaultLimit Limit, entries []string) Policies {
+	policies := Policies{Default: defaultLimit, PerRoute: map[string]Limit{}}
+
+	for _, entry := range entries {
+		route, value, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			continue
+		}
+		policies.PerRoute[strings.Join(strings.Fields(route), " ")] = limit
+	}
+
+	return policies
+}
+
+// For retorna o limite da rota e o nome do bucket: rotas com política própria têm bucket
+// próprio, as demais compartilham o bucket do limite padrão
+func (p Policies) For(method, route string) (Limit, string) {
+	name := method + " " + route
+	if limit, ok := p.PerRoute[name]; ok {
+		return limit, name
+	}
+
+	return p.Default, "*"
+}
+
+// take aplica o algoritmo de token bucket ao estado (tokens, updated) e retorna o novo estado
+func take(tokens float64, updated time.Time, limit Limit, now time.Time) (float64, Result) {
+	burst := float64(limit.Requests)
+	rate := limit.Rate()
+
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(burst, tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: limit}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = seconds((burst - tokens) / rate)
+
+	return tokens, result
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseLimit(" 100/m ")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 100, Period: time.Minute}, limit)
+
+	limit, err = ParseLimit("off")
+	assert.NoError(t, err)
+	assert.True(t, limit.Unlimited())
+
+	for _, value := range []string{"100", "0/s", "10/d", "x/m"} {
+		_, err := ParseLimit(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies := ParsePolicies(Limit{Requests: 3, Period: time.Minute}, []string{"  POST   /products = 2/s ", "GET /healthz=off", "invalid", "GET /products=abc"})
+
+	limit, bucket := policies.For("POST", "/products")
+	assert.Equal(t, Limit{Requests: 2, Period: time.Second}, limit)
+	assert.Equal(t, "POST /products", bucket)
+
+	limit, _ = policies.For("GET", "/healthz")
+	assert.True(t, limit.Unlimited())
+
+	// Itens inválidos são ignorados e a rota usa o bucket padrão
+	limit, bucket = policies.For("GET", "/products")
+	assert.Equal(t, Limit{Requests: 3, Period: time.Minute}, limit)
+	assert.Equal(t, "*", bucket)
+	assert.Len(t, policies.PerRoute, 2)
+}
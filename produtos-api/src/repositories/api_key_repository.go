@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository define a interface para o repositório de chaves de API
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	SaveAPIKey(ctx context.Context, key *models.APIKey) error
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type APIKeyRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository cria uma nova instância do repositório de chaves de API
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepositoryDB {
+	return &APIKeyRepositoryDB{db}
+}
+
+func (repo *APIKeyRepositoryDB) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.CreateAPIKey")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(key).Error)
+}
+
+func (repo *APIKeyRepositoryDB) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.GetAPIKeys")
+	defer span.End()
+
+	var keys []models.APIKey
+	err := conn(ctx, repo.db).Order("id").Find(&keys).Error
+	return keys, endSpan(span, err)
+}
+
+func (repo *APIKeyRepositoryDB) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.GetAPIKeyByID")
+	defer span.End()
+
+	var key models.APIKey
+	err := conn(ctx, repo.db).First(&key, id).Error
+	return &key, endSpan(span, err)
+}
+
+func (repo *APIKeyRepositoryDB) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.GetAPIKeyByPrefix")
+	defer span.End()
+
+	var key models.APIKey
+	err := conn(ctx, repo.db).Where("prefix = ?", prefix).First(&key).Error
+	return &key, endSpan(span, err)
+}
+
+func (repo *APIKeyRepositoryDB) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.SaveAPIKey")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(key).Error)
+}
+
+// TouchAPIKey atualiza apenas o último uso da chave, sem sobrescrever alterações concorrentes
+func (repo *APIKeyRepositoryDB) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "APIKeyRepository.TouchAPIKey")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	return endSpan(span, err)
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter define os filtros da consulta de registros de auditoria; campos vazios não filtram
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+	Actor      string
+	From       time.Time
+	To         time.Time
+	AfterID    uint
+	Limit      int
+}
+
+// AuditRepository define a interface para o repositório de registros de auditoria
+type AuditRepository interface {
+	LastAuditHash(ctx context.Context) (string, error)
+	CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error
+	FindAuditRecords(ctx context.Context, filter AuditFilter) ([]models.AuditRecord, error)
+	GetAuditChain(ctx context.Context, afterID uint, limit int) ([]models.AuditRecord, error)
+}
+
+type AuditRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository cria uma nova instância do repositório de auditoria
+func NewAuditRepository(db *gorm.DB) *AuditRepositoryDB {
+	return &AuditRepositoryDB{db}
+}
+
+// LastAuditHash retorna o hash do último registro da cadeia, ou vazio quando ela está vazia
+func (repo *AuditRepositoryDB) LastAuditHash(ctx context.Context) (string, error) {
+	ctx, span := tracer.Start(ctx, "AuditRepository.LastAuditHash")
+	defer span.End()
+
+	var record models.AuditRecord
+	err := conn(ctx, repo.db).Select("hash").Order("id DESC").Take(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+
+	return record.Hash, endSpan(span, err)
+}
+
+// CreateAuditRecord grava o registro; o índice único em prev_hash impede que duas
+// transações concorrentes encadeiem registros no mesmo antecessor (ErrDuplicated)
+func (repo *AuditRepositoryDB) CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	ctx, span := tracer.Start(ctx, "AuditRepository.CreateAuditRecord")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(record).Error)
+}
+
+func (repo *AuditRepositoryDB) FindAuditRecords(ctx context.Context, filter AuditFilter) ([]models.AuditRecord, error) {
+	ctx, span := tracer.Start(ctx, "AuditRepository.FindAuditRecords")
+	defer span.End()
+
+	query := conn(ctx, repo.db).Order("id").Limit(filter.Limit)
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("occurred_at < ?", filter.To)
+	}
+	if filter.AfterID != 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+
+	var records []models.AuditRecord
+	err := query.Find(&records).Error
+	return records, endSpan(span, err)
+}
+
+// GetAuditChain retorna um lote da cadeia em ordem de ID, a partir do registro seguinte a afterID
+func (repo *AuditRepositoryDB) GetAuditChain(ctx context.Context, afterID uint, limit int) ([]models.AuditRecord, error) {
+	ctx, span := tracer.Start(ctx, "AuditRepository.GetAuditChain")
+	defer span.End()
+
+	var records []models.AuditRecord
+	err := conn(ctx, repo.db).Where("id > ?", afterID).Order("id").Limit(limit).Find(&records).Error
+	return records, endSpan(span, err)
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// CustomerRepository define a interface para o repositório de clientes, dos seus endereços e do histórico de consentimentos.
+// Clientes com os dados apagados não são encontrados.
+type CustomerRepository interface {
+	CreateCustomer(ctx context.Context, customer *models.Customer) error
+	GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error)
+	FindCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error)
+	UpdateCustomer(ctx context.Context, customer *models.Customer) error
+	UpdateConsents(ctx context.Context, customer *models.Customer, consents []models.ConsentRecord) error
+	SaveAddress(ctx context.Context, address *models.CustomerAddress) error
+	DeleteAddress(ctx context.Context, customerID, addressID uint) error
+	GetConsentRecords(ctx context.Context, customerID uint) ([]models.ConsentRecord, error)
+	EraseCustomer(ctx context.Context, customer *models.Customer) error
+}
+
+type CustomerRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewCustomerRepository cria uma nova instância do repositório de clientes
+func NewCustomerRepository(db *gorm.DB) *CustomerRepositoryDB {
+	return &CustomerRepositoryDB{db}
+}
+
+// CreateCustomer grava o cliente com os seus endereços
+func (repo *CustomerRepositoryDB) CreateCustomer(ctx context.Context, customer *models.Customer) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.CreateCustomer")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(customer).Error)
+}
+
+func (repo *CustomerRepositoryDB) GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error) {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.GetCustomerByID")
+	defer span.End()
+
+	var customer models.Customer
+	err := conn(ctx, repo.db).Preload("Addresses", customerAddresses).
+		Where("erased_at IS NULL").First(&customer, id).Error
+	return &customer, endSpan(span, err)
+}
+
+// FindCustomers lista os clientes pela ordem de cadastro; campos vazios do filtro não filtram
+func (repo *CustomerRepositoryDB) FindCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error) {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.FindCustomers")
+	defer span.End()
+
+	query := conn(ctx, repo.db).Preload("Addresses", customerAddresses).
+		Where("erased_at IS NULL").Order("id").Limit(filter.Limit)
+	if filter.Document != "" {
+		query = query.Where("document = ?", filter.Document)
+	}
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+	if filter.Subject != "" {
+		query = query.Where("subject = ?", filter.Subject)
+	}
+
+	var customers []models.Customer
+	err := query.Find(&customers).Error
+	return customers, endSpan(span, err)
+}
+
+// UpdateCustomer grava os dados cadastrais do cliente, sem os endereços e sem os consentimentos, que só mudam
+// por UpdateConsents
+func (repo *CustomerRepositoryDB) UpdateCustomer(ctx context.Context, customer *models.Customer) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.UpdateCustomer")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Model(customer).Where("erased_at IS NULL").
+		Select("*").Omit("Addresses", "ID", "Subject", "SelfRegistered", "CreatedAt", "MarketingConsent", "DataSharingConsent", "ConsentUpdatedAt", "ErasedAt").
+		Updates(customer)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+	return endSpan(span, result.Error)
+}
+
+// UpdateConsents grava os consentimentos do cliente e os registros das mudanças na mesma transação. A gravação
+// só acontece se cada consentimento alterado ainda estiver no valor anterior ao registro; caso contrário, ou se o
+// cliente não existir, retorna ErrNotFound e nada é gravado.
+func (repo *CustomerRepositoryDB) UpdateConsents(ctx context.Context, customer *models.Customer, consents []models.ConsentRecord) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.UpdateConsents")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(customer).Where("erased_at IS NULL")
+		for _, consent := range consents {
+			query = query.Where(consentColumns[consent.Purpose]+" = ?", !consent.Granted)
+		}
+
+		result := query.Select("MarketingConsent", "DataSharingConsent", "ConsentUpdatedAt", "UpdatedAt").Updates(customer)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if len(consents) > 0 {
+			return tx.Create(&consents).Error
+		}
+		return nil
+	})
+	return endSpan(span, err)
+}
+
+// SaveAddress cria ou atualiza o endereço; quando ele é o padrão, os outros endereços do cliente deixam de ser
+func (repo *CustomerRepositoryDB) SaveAddress(ctx context.Context, address *models.CustomerAddress) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.SaveAddress")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if address.Default {
+			err := tx.Model(&models.CustomerAddress{}).
+				Where("customer_id = ? AND id <> ? AND is_default = ?", address.CustomerID, address.ID, true).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(address).Error
+	})
+	return endSpan(span, err)
+}
+
+// DeleteAddress remove o endereço; se ele era o padrão, o endereço mais antigo restante passa a ser
+func (repo *CustomerRepositoryDB) DeleteAddress(ctx context.Context, customerID, addressID uint) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.DeleteAddress")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		var address models.CustomerAddress
+		if err := tx.Where("customer_id = ?", customerID).First(&address, addressID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.Default {
+			return nil
+		}
+
+		var next models.CustomerAddress
+		err := tx.Where("customer_id = ?", customerID).Order("id").First(&next).Error
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	return endSpan(span, err)
+}
+
+// GetConsentRecords lista o histórico de consentimentos do cliente, do mais antigo para o mais recente
+func (repo *CustomerRepositoryDB) GetConsentRecords(ctx context.Context, customerID uint) ([]models.ConsentRecord, error) {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.GetConsentRecords")
+	defer span.End()
+
+	var records []models.ConsentRecord
+	err := conn(ctx, repo.db).Where("customer_id = ?", customerID).Order("id").Find(&records).Error
+	return records, endSpan(span, err)
+}
+
+// EraseCustomer apaga os endereços e grava o cliente anonimizado, mantendo o ID referenciado pelos pedidos.
+// O histórico de consentimentos é mantido como prova das concessões e revogações.
+func (repo *CustomerRepositoryDB) EraseCustomer(ctx context.Context, customer *models.Customer) error {
+	ctx, span := tracer.Start(ctx, "CustomerRepository.EraseCustomer")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", customer.ID).Delete(&models.CustomerAddress{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(customer).Where("erased_at IS NULL").
+			Select("*").Omit("Addresses", "ID", "CreatedAt").Updates(customer)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	return endSpan(span, err)
+}
+
+// consentColumns são as colunas de cada finalidade de consentimento
+var consentColumns = map[string]string{
+	models.ConsentMarketing:   "marketing_consent",
+	models.ConsentDataSharing: "data_sharing_consent",
+}
+
+// customerAddresses carrega os endereços pela ordem de cadastro
+func customerAddresses(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
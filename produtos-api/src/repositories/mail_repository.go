@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MailRepository define a interface para a fila de e-mails
+type MailRepository interface {
+	CreateMailMessage(ctx context.Context, message *models.MailMessage) error
+	GetPendingMailMessages(ctx context.Context, afterID uint, limit int) ([]models.MailMessage, error)
+	SaveMailMessage(ctx context.Context, message *models.MailMessage) error
+	DeleteMailMessages(ctx context.Context, attemptedBefore time.Time) (int64, error)
+	CountMailMessages(ctx context.Context, template, to string, since time.Time) (int64, error)
+}
+
+type MailRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewMailRepository cria uma nova instância do repositório da fila de e-mails
+func NewMailRepository(db *gorm.DB) *MailRepositoryDB {
+	return &MailRepositoryDB{db}
+}
+
+// CreateMailMessage enfileira a mensagem; retorna ErrDuplicated se uma mensagem com a mesma chave já foi enfileirada
+func (repo *MailRepositoryDB) CreateMailMessage(ctx context.Context, message *models.MailMessage) error {
+	ctx, span := tracer.Start(ctx, "MailRepository.CreateMailMessage")
+	defer span.End()
+
+	// O mesmo evento pode ser entregue de novo: o conflito é esperado e não é registrado como erro
+	result := conn(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(message)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrDuplicated)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+// GetPendingMailMessages lista as mensagens pendentes com ID maior que afterID, na ordem em que foram enfileiradas
+func (repo *MailRepositoryDB) GetPendingMailMessages(ctx context.Context, afterID uint, limit int) ([]models.MailMessage, error) {
+	ctx, span := tracer.Start(ctx, "MailRepository.GetPendingMailMessages")
+	defer span.End()
+
+	var messages []models.MailMessage
+	err := conn(ctx, repo.db).Where("status = ? AND id > ?", models.MailPending, afterID).Order("id").Limit(limit).Find(&messages).Error
+	return messages, endSpan(span, err)
+}
+
+func (repo *MailRepositoryDB) SaveMailMessage(ctx context.Context, message *models.MailMessage) error {
+	ctx, span := tracer.Start(ctx, "MailRepository.SaveMailMessage")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(message).Error)
+}
+
+// DeleteMailMessages remove as mensagens concluídas cuja última tentativa foi antes de attemptedBefore
+func (repo *MailRepositoryDB) DeleteMailMessages(ctx context.Context, attemptedBefore time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "MailRepository.DeleteMailMessages")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Where("status <> ? AND last_attempt_at < ?", models.MailPending, attemptedBefore).
+		Delete(&models.MailMessage{})
+	return result.RowsAffected, endSpan(span, result.Error)
+}
+
+// CountMailMessages conta as mensagens do template enfileiradas para o endereço, sem diferenciar maiúsculas, desde since
+func (repo *MailRepositoryDB) CountMailMessages(ctx context.Context, template, to string, since time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "MailRepository.CountMailMessages")
+	defer span.End()
+
+	var count int64
+	err := conn(ctx, repo.db).Model(&models.MailMessage{}).
+		Where(`template = ? AND created_at >= ? AND LOWER("to") = ?`, template, since, strings.ToLower(to)).
+		Count(&count).Error
+	return count, endSpan(span, err)
+}
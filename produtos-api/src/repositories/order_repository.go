@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderRepository define a interface para o repositório de pedidos e dos seus itens
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *models.Order) error
+	GetOrderByID(ctx context.Context, id uint) (*models.Order, error)
+	FindOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+	GetUnreleasedOrders(ctx context.Context, checkoutBefore time.Time, limit int) ([]models.Order, error)
+	UpdateOrder(ctx context.Context, order *models.Order, status string) error
+	DeleteOrder(ctx context.Context, id uint) error
+}
+
+type OrderRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository cria uma nova instância do repositório de pedidos
+func NewOrderRepository(db *gorm.DB) *OrderRepositoryDB {
+	return &OrderRepositoryDB{db}
+}
+
+// CreateOrder grava o pedido com os seus itens
+func (repo *OrderRepositoryDB) CreateOrder(ctx context.Context, order *models.Order) error {
+	ctx, span := tracer.Start(ctx, "OrderRepository.CreateOrder")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(order).Error)
+}
+
+func (repo *OrderRepositoryDB) GetOrderByID(ctx context.Context, id uint) (*models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.GetOrderByID")
+	defer span.End()
+
+	var order models.Order
+	err := conn(ctx, repo.db).Preload("Items", orderItems).First(&order, id).Error
+	return &order, endSpan(span, err)
+}
+
+// FindOrders lista os pedidos, dos mais recentes para os mais antigos; campos vazios do filtro não filtram
+func (repo *OrderRepositoryDB) FindOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.FindOrders")
+	defer span.End()
+
+	query := conn(ctx, repo.db).Preload("Items", orderItems).Order("id DESC").Limit(filter.Limit)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CustomerID != 0 {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+
+	var orders []models.Order
+	err := query.Find(&orders).Error
+	return orders, endSpan(span, err)
+}
+
+// GetUnreleasedOrders lista os pedidos cujo estoque precisa ser devolvido: os cancelados que ainda não
+// devolveram as reservas e os pendentes sem reserva confirmada cujo checkout começou antes de checkoutBefore
+func (repo *OrderRepositoryDB) GetUnreleasedOrders(ctx context.Context, checkoutBefore time.Time, limit int) ([]models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderRepository.GetUnreleasedOrders")
+	defer span.End()
+
+	var orders []models.Order
+	err := conn(ctx, repo.db).Preload("Items", orderItems).
+		Where("(status = ? AND stock_released = ?) OR (status = ? AND stock_reserved = ? AND created_at < ?)",
+			models.OrderCancelled, false, models.OrderPending, false, checkoutBefore).
+		Order("id").Limit(limit).Find(&orders).Error
+	return orders, endSpan(span, err)
+}
+
+// UpdateOrder grava o pedido se o status gravado ainda for status; retorna ErrNotFound se o pedido
+// mudou desde a leitura, para que transições concorrentes não se sobreponham. Os itens não mudam depois do checkout.
+func (repo *OrderRepositoryDB) UpdateOrder(ctx context.Context, order *models.Order, status string) error {
+	ctx, span := tracer.Start(ctx, "OrderRepository.UpdateOrder")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Model(order).Where("status = ?", status).
+		Select("*").Omit("Items", "ID", "CreatedAt").Updates(order)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+	return endSpan(span, result.Error)
+}
+
+// DeleteOrder remove o pedido e os seus itens
+func (repo *OrderRepositoryDB) DeleteOrder(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "OrderRepository.DeleteOrder")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("order_id = ?", id).Delete(&models.OrderItem{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Order{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	return endSpan(span, err)
+}
+
+// orderItems carrega os itens na ordem do checkout
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository define a interface para o repositório da outbox de eventos
+type OutboxRepository interface {
+	CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+	GetPendingOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error)
+	SaveOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+	GetOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error)
+	GetOutboxBounds(ctx context.Context) (oldest, newest uint, err error)
+}
+
+type OutboxRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository cria uma nova instância do repositório da outbox
+func NewOutboxRepository(db *gorm.DB) *OutboxRepositoryDB {
+	return &OutboxRepositoryDB{db}
+}
+
+// CreateOutboxEvent grava o evento; deve participar da transação da mudança que o originou
+func (repo *OutboxRepositoryDB) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.CreateOutboxEvent")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(event).Error)
+}
+
+// GetPendingOutboxEvents lista os eventos ainda não publicados com ID maior que afterID, na ordem em que foram gravados
+func (repo *OutboxRepositoryDB) GetPendingOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.GetPendingOutboxEvents")
+	defer span.End()
+
+	var events []models.OutboxEvent
+	err := conn(ctx, repo.db).Where("published_at IS NULL AND id > ?", afterID).Order("id").Limit(limit).Find(&events).Error
+	return events, endSpan(span, err)
+}
+
+// SaveOutboxEvent atualiza o resultado da tentativa de publicação
+func (repo *OutboxRepositoryDB) SaveOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.SaveOutboxEvent")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(event).Error)
+}
+
+// DeletePublishedOutboxEvents remove os eventos publicados antes de publishedBefore
+func (repo *OutboxRepositoryDB) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.DeletePublishedOutboxEvents")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Where("published_at < ?", publishedBefore).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, endSpan(span, result.Error)
+}
+
+// GetOutboxEvents lista os eventos com ID maior que afterID, publicados ou não, na ordem em que foram gravados
+func (repo *OutboxRepositoryDB) GetOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.GetOutboxEvents")
+	defer span.End()
+
+	var events []models.OutboxEvent
+	err := conn(ctx, repo.db).Where("id > ?", afterID).Order("id").Limit(limit).Find(&events).Error
+	return events, endSpan(span, err)
+}
+
+// GetOutboxBounds retorna o menor e o maior ID da outbox; zero quando ela está vazia
+func (repo *OutboxRepositoryDB) GetOutboxBounds(ctx context.Context) (oldest, newest uint, err error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepository.GetOutboxBounds")
+	defer span.End()
+
+	var bounds struct{ Oldest, Newest uint }
+	err = conn(ctx, repo.db).Model(&models.OutboxEvent{}).Select("COALESCE(MIN(id), 0) AS oldest, COALESCE(MAX(id), 0) AS newest").
+		Scan(&bounds).Error
+	return bounds.Oldest, bounds.Newest, endSpan(span, err)
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedEventRepository define a interface para o registro dos eventos já consumidos
+type ProcessedEventRepository interface {
+	MarkEventProcessed(ctx context.Context, event *models.ProcessedEvent) error
+}
+
+type ProcessedEventRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewProcessedEventRepository cria uma nova instância do repositório de eventos consumidos
+func NewProcessedEventRepository(db *gorm.DB) *ProcessedEventRepositoryDB {
+	return &ProcessedEventRepositoryDB{db}
+}
+
+// MarkEventProcessed registra o evento; retorna ErrDuplicated se ele já tiver sido processado.
+// Deve participar da transação que aplica os efeitos do evento.
+func (repo *ProcessedEventRepositoryDB) MarkEventProcessed(ctx context.Context, event *models.ProcessedEvent) error {
+	ctx, span := tracer.Start(ctx, "ProcessedEventRepository.MarkEventProcessed")
+	defer span.End()
+
+	// Entregas repetidas são esperadas: o conflito é ignorado em vez de registrado como erro da consulta
+	result := conn(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrDuplicated)
+	}
+
+	return endSpan(span, result.Error)
+}
@@ -1,20 +1,42 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"produtos-api/src/models"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+var tracer = otel.Tracer("produtos-api/src/repositories")
+
+// Erros retornados pelo repositório; o GORM os traduz a partir dos erros do driver (TranslateError)
+var (
+	ErrNotFound   = gorm.ErrRecordNotFound
+	ErrDuplicated = gorm.ErrDuplicatedKey
+)
+
 // ProductRepository define a interface para o repositório de produtos
 type ProductRepository interface {
-	CreateProduct(product *models.Product) error
-	GetAllProducts() ([]models.Product, error)
-	GetProductByID(id uint) (*models.Product, error)
-	GetProductByName(name string) ([]models.Product, error)
-	GetProductsCount() int64
-	UpdateProduct(product *models.Product) error
-	DeleteProduct(id uint) error
+	CreateProduct(ctx context.Context, product *models.Product) error
+	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	GetProductByID(ctx context.Context, id uint) (*models.Product, error)
+	GetProductByName(ctx context.Context, name string) ([]models.Product, error)
+	GetProductsCount(ctx context.Context) int64
+	ExistsByNameAndCategory(ctx context.Context, name, category string, excludeID uint) (bool, error)
+	UpdateProduct(ctx context.Context, product *models.Product) error
+	DeleteProduct(ctx context.Context, id uint) error
+	GetDeletedProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]models.Product, error)
+	GetDeletedProductByID(ctx context.Context, id uint) (*models.Product, error)
+	RestoreProduct(ctx context.Context, id uint) error
+	PurgeProduct(ctx context.Context, id uint) error
+	GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error)
+	GetProductRevision(ctx context.Context, id, revision uint) (*models.ProductRevision, error)
+	GetProductRevisionAsOf(ctx context.Context, id uint, asOf time.Time) (*models.ProductRevision, error)
 }
 
 type ProductRepositoryDB struct {
@@ -26,44 +48,247 @@ func NewProductRepository(db *gorm.DB) *ProductRepositoryDB {
 	return &ProductRepositoryDB{db}
 }
 
-func (repo *ProductRepositoryDB) CreateProduct(product *models.Product) error {
-	return repo.db.Create(product).Error
+func (repo *ProductRepositoryDB) CreateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductRepository.CreateProduct")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+
+		return saveRevision(tx, product.ID)
+	})
+
+	return endSpan(span, err)
 }
 
-func (repo *ProductRepositoryDB) GetAllProducts() ([]models.Product, error) {
+func (repo *ProductRepositoryDB) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetAllProducts")
+	defer span.End()
+
 	var products []models.Product
-	err := repo.db.Find(&products).Error
-	return products, err
+	err := conn(ctx, repo.db).Find(&products).Error
+	return products, endSpan(span, err)
 }
 
-func (repo *ProductRepositoryDB) GetProductByID(id uint) (*models.Product, error) {
+func (repo *ProductRepositoryDB) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductByID")
+	defer span.End()
+
 	var product models.Product
-	err := repo.db.First(&product, id).Error
-	return &product, err
+	err := conn(ctx, repo.db).First(&product, id).Error
+	return &product, endSpan(span, err)
 }
 
-func (repo *ProductRepositoryDB) GetProductByName(name string) ([]models.Product, error) {
+func (repo *ProductRepositoryDB) GetProductByName(ctx context.Context, name string) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductByName")
+	defer span.End()
+
 	var products []models.Product
-	err := repo.db.Where("name = ?", name).Find(&products).Error
+	err := conn(ctx, repo.db).Where("name = ?", name).Find(&products).Error
 
-	return products, err
+	return products, endSpan(span, err)
 }
 
-func (repo *ProductRepositoryDB) GetProductsCount() int64 {
+func (repo *ProductRepositoryDB) GetProductsCount(ctx context.Context) int64 {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductsCount")
+	defer span.End()
+
 	var count int64
-	err := repo.db.Model(&models.Product{}).Count(&count).Error
+	err := conn(ctx, repo.db).Model(&models.Product{}).Count(&count).Error
 
-	if err != nil {
+	if endSpan(span, err) != nil {
 		return 0
 	}
 
 	return count
 }
 
-func (repo *ProductRepositoryDB) UpdateProduct(product *models.Product) error {
-	return repo.db.Save(product).Error
+// ExistsByNameAndCategory indica se já existe outro produto com o mesmo nome (sem diferenciar maiúsculas) na categoria.
+// A verificação antecipa o erro de validação; gravações concorrentes são barradas pelo índice único
+// idx_products_category_name_unique, e Create, Update e Restore retornam ErrDuplicated.
+func (repo *ProductRepositoryDB) ExistsByNameAndCategory(ctx context.Context, name, category string, excludeID uint) (bool, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.ExistsByNameAndCategory")
+	defer span.End()
+
+	var count int64
+	query := conn(ctx, repo.db).Model(&models.Product{}).
+		Where("LOWER(name) = LOWER(?) AND category = ?", name, category)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+
+	return count > 0, endSpan(span, err)
+}
+
+func (repo *ProductRepositoryDB) UpdateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductRepository.UpdateProduct")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(product).Error; err != nil {
+			return err
+		}
+
+		return saveRevision(tx, product.ID)
+	})
+
+	return endSpan(span, err)
+}
+
+// DeleteProduct move o produto para a lixeira (soft delete); as traduções são mantidas para a restauração
+func (repo *ProductRepositoryDB) DeleteProduct(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "ProductRepository.DeleteProduct")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Product{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return saveRevision(tx, id)
+	})
+
+	return endSpan(span, err)
+}
+
+// GetDeletedProducts lista os produtos da lixeira removidos antes de deletedBefore (zero para todos),
+// dos mais antigos para os mais recentes
+func (repo *ProductRepositoryDB) GetDeletedProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetDeletedProducts")
+	defer span.End()
+
+	query := conn(ctx, repo.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at")
+	if !deletedBefore.IsZero() {
+		query = query.Where("deleted_at < ?", deletedBefore)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var products []models.Product
+	err := query.Find(&products).Error
+	return products, endSpan(span, err)
+}
+
+func (repo *ProductRepositoryDB) GetDeletedProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetDeletedProductByID")
+	defer span.End()
+
+	var product models.Product
+	err := conn(ctx, repo.db).Unscoped().Where("deleted_at IS NOT NULL").First(&product, id).Error
+	return &product, endSpan(span, err)
+}
+
+// RestoreProduct tira o produto da lixeira
+func (repo *ProductRepositoryDB) RestoreProduct(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "ProductRepository.RestoreProduct")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Product{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return saveRevision(tx, id)
+	})
+
+	return endSpan(span, err)
+}
+
+// PurgeProduct remove definitivamente um produto da lixeira, as suas traduções e o seu histórico
+func (repo *ProductRepositoryDB) PurgeProduct(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "ProductRepository.PurgeProduct")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Product{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		// Traduções e revisões não fazem sentido sem o produto
+		if err := tx.Where("product_id = ?", id).Delete(&models.ProductTranslation{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("product_id = ?", id).Delete(&models.ProductRevision{}).Error
+	})
+
+	return endSpan(span, err)
+}
+
+// GetProductRevisions lista o histórico do produto, da revisão mais antiga para a mais recente
+func (repo *ProductRepositoryDB) GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductRevisions")
+	defer span.End()
+
+	var revisions []models.ProductRevision
+	err := conn(ctx, repo.db).Where("product_id = ?", id).Order("revision").Find(&revisions).Error
+	return revisions, endSpan(span, err)
+}
+
+func (repo *ProductRepositoryDB) GetProductRevision(ctx context.Context, id, revision uint) (*models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductRevision")
+	defer span.End()
+
+	var found models.ProductRevision
+	err := conn(ctx, repo.db).Where("product_id = ? AND revision = ?", id, revision).First(&found).Error
+	return &found, endSpan(span, err)
 }
 
-func (repo *ProductRepositoryDB) DeleteProduct(id uint) error {
-	return repo.db.Delete(&models.Product{}, id).Error
+// GetProductRevisionAsOf retorna a revisão vigente no instante asOf
+func (repo *ProductRepositoryDB) GetProductRevisionAsOf(ctx context.Context, id uint, asOf time.Time) (*models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "ProductRepository.GetProductRevisionAsOf")
+	defer span.End()
+
+	var found models.ProductRevision
+	err := conn(ctx, repo.db).Where("product_id = ? AND valid_from <= ?", id, asOf.UTC()).
+		Order("revision DESC").
+		First(&found).Error
+	return &found, endSpan(span, err)
+}
+
+// saveRevision grava no histórico o estado do produto na transação, como a próxima revisão
+func saveRevision(tx *gorm.DB, id uint) error {
+	var product models.Product
+	if err := tx.Unscoped().First(&product, id).Error; err != nil {
+		return err
+	}
+
+	var last uint
+	err := tx.Model(&models.ProductRevision{}).
+		Where("product_id = ?", id).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(models.NewProductRevision(&product, last+1, time.Now().UTC())).Error
+}
+
+// endSpan registra o erro no span, quando houver, e o devolve sem alterações
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
 }
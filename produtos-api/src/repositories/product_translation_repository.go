@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductTranslationRepository define a interface para o repositório de traduções de produtos
+type ProductTranslationRepository interface {
+	SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error
+	GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error)
+	GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error)
+	FindTranslations(ctx context.Context, productIDs []uint, locales []string) ([]models.ProductTranslation, error)
+	DeleteTranslation(ctx context.Context, productID uint, locale string) error
+}
+
+type ProductTranslationRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewProductTranslationRepository cria uma nova instância do repositório de traduções
+func NewProductTranslationRepository(db *gorm.DB) *ProductTranslationRepositoryDB {
+	return &ProductTranslationRepositoryDB{db}
+}
+
+// SaveTranslation cria ou substitui a tradução do produto para o locale
+func (repo *ProductTranslationRepositoryDB) SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error {
+	ctx, span := tracer.Start(ctx, "ProductTranslationRepository.SaveTranslation")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description"}),
+	}).Create(translation).Error
+
+	return endSpan(span, err)
+}
+
+func (repo *ProductTranslationRepositoryDB) GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "ProductTranslationRepository.GetTranslation")
+	defer span.End()
+
+	var translation models.ProductTranslation
+	err := conn(ctx, repo.db).Where("product_id = ? AND locale = ?", productID, locale).First(&translation).Error
+	return &translation, endSpan(span, err)
+}
+
+func (repo *ProductTranslationRepositoryDB) GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "ProductTranslationRepository.GetTranslations")
+	defer span.End()
+
+	var translations []models.ProductTranslation
+	err := conn(ctx, repo.db).Where("product_id = ?", productID).Order("locale").Find(&translations).Error
+	return translations, endSpan(span, err)
+}
+
+// FindTranslations busca, em uma única consulta, as traduções dos produtos nos locales informados
+func (repo *ProductTranslationRepositoryDB) FindTranslations(ctx context.Context, productIDs []uint, locales []string) ([]models.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "ProductTranslationRepository.FindTranslations")
+	defer span.End()
+
+	var translations []models.ProductTranslation
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+
+	err := conn(ctx, repo.db).Where("product_id IN ? AND locale IN ?", productIDs, locales).Find(&translations).Error
+	return translations, endSpan(span, err)
+}
+
+func (repo *ProductTranslationRepositoryDB) DeleteTranslation(ctx context.Context, productID uint, locale string) error {
+	ctx, span := tracer.Start(ctx, "ProductTranslationRepository.DeleteTranslation")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Where("product_id = ? AND locale = ?", productID, locale).Delete(&models.ProductTranslation{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+
+	return endSpan(span, result.Error)
+}
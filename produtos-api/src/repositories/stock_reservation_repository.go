@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+)
+
+// StockReservationRepository define a interface para o repositório das reservas de estoque dos pedidos
+type StockReservationRepository interface {
+	CreateStockReservation(ctx context.Context, reservation *models.StockReservation) error
+	GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error)
+	SaveStockReservation(ctx context.Context, reservation *models.StockReservation) error
+}
+
+type StockReservationRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewStockReservationRepository cria uma nova instância do repositório de reservas de estoque
+func NewStockReservationRepository(db *gorm.DB) *StockReservationRepositoryDB {
+	return &StockReservationRepositoryDB{db}
+}
+
+func (repo *StockReservationRepositoryDB) CreateStockReservation(ctx context.Context, reservation *models.StockReservation) error {
+	ctx, span := tracer.Start(ctx, "StockReservationRepository.CreateStockReservation")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(reservation).Error)
+}
+
+// GetStockReservations lista as reservas do pedido, ordenadas por produto
+func (repo *StockReservationRepositoryDB) GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	ctx, span := tracer.Start(ctx, "StockReservationRepository.GetStockReservations")
+	defer span.End()
+
+	var reservations []models.StockReservation
+	err := conn(ctx, repo.db).Where("order_id = ?", orderID).Order("product_id").Find(&reservations).Error
+	return reservations, endSpan(span, err)
+}
+
+func (repo *StockReservationRepositoryDB) SaveStockReservation(ctx context.Context, reservation *models.StockReservation) error {
+	ctx, span := tracer.Start(ctx, "StockReservationRepository.SaveStockReservation")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(reservation).Error)
+}
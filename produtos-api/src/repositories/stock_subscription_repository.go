@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockSubscriptionRepository define a interface para o repositório dos avisos de volta ao estoque
+type StockSubscriptionRepository interface {
+	CreateStockSubscription(ctx context.Context, subscription *models.StockSubscription) error
+	MarkStockSubscriptionsDue(ctx context.Context, productID uint, at time.Time) (int64, error)
+	GetDueStockSubscriptions(ctx context.Context, now time.Time, limit int) ([]models.StockSubscription, error)
+	SetStockSubscriptionNotifyAt(ctx context.Context, id uint, at *time.Time) error
+	DeleteStockSubscription(ctx context.Context, id uint) error
+	DeleteStockSubscriptionByToken(ctx context.Context, tokenHash string) error
+	SaveRecipientToken(ctx context.Context, recipient *models.StockSubscriptionRecipient) error
+	DeleteRecipientSubscriptions(ctx context.Context, tokenHash string) error
+}
+
+type StockSubscriptionRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewStockSubscriptionRepository cria uma nova instância do repositório dos avisos de volta ao estoque
+func NewStockSubscriptionRepository(db *gorm.DB) *StockSubscriptionRepositoryDB {
+	return &StockSubscriptionRepositoryDB{db}
+}
+
+// CreateStockSubscription grava o aviso; retorna ErrDuplicated se o e-mail ou o cliente já aguarda o produto
+func (repo *StockSubscriptionRepositoryDB) CreateStockSubscription(ctx context.Context, subscription *models.StockSubscription) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.CreateStockSubscription")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(subscription)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrDuplicated)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+// MarkStockSubscriptionsDue marca para at os avisos do produto que aguardam a reposição e retorna quantos foram marcados
+func (repo *StockSubscriptionRepositoryDB) MarkStockSubscriptionsDue(ctx context.Context, productID uint, at time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.MarkStockSubscriptionsDue")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Model(&models.StockSubscription{}).
+		Where("product_id = ? AND notify_at IS NULL", productID).
+		Update("notify_at", at)
+	return result.RowsAffected, endSpan(span, result.Error)
+}
+
+// GetDueStockSubscriptions lista até limit avisos com envio marcado até now, dos mais antigos para os mais novos
+func (repo *StockSubscriptionRepositoryDB) GetDueStockSubscriptions(ctx context.Context, now time.Time, limit int) ([]models.StockSubscription, error) {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.GetDueStockSubscriptions")
+	defer span.End()
+
+	var subscriptions []models.StockSubscription
+	err := conn(ctx, repo.db).Where("notify_at <= ?", now).Order("notify_at, id").Limit(limit).Find(&subscriptions).Error
+	return subscriptions, endSpan(span, err)
+}
+
+// SetStockSubscriptionNotifyAt remarca o envio do aviso; nil volta a aguardar a próxima reposição
+func (repo *StockSubscriptionRepositoryDB) SetStockSubscriptionNotifyAt(ctx context.Context, id uint, at *time.Time) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.SetStockSubscriptionNotifyAt")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Model(&models.StockSubscription{ID: id}).Update("notify_at", at).Error
+	return endSpan(span, err)
+}
+
+func (repo *StockSubscriptionRepositoryDB) DeleteStockSubscription(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.DeleteStockSubscription")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Delete(&models.StockSubscription{}, id).Error)
+}
+
+// DeleteStockSubscriptionByToken remove o aviso do token; retorna ErrNotFound se ele não existir ou já tiver sido enviado
+func (repo *StockSubscriptionRepositoryDB) DeleteStockSubscriptionByToken(ctx context.Context, tokenHash string) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.DeleteStockSubscriptionByToken")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Where("token_hash = ?", tokenHash).Delete(&models.StockSubscription{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+// SaveRecipientToken grava o token de cancelamento do último aviso do destinatário, substituindo o anterior
+func (repo *StockSubscriptionRepositoryDB) SaveRecipientToken(ctx context.Context, recipient *models.StockSubscriptionRecipient) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.SaveRecipientToken")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}, {Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "updated_at"}),
+	}).Create(recipient).Error
+	return endSpan(span, err)
+}
+
+// DeleteRecipientSubscriptions remove todos os avisos pendentes do destinatário do token e o próprio token;
+// retorna ErrNotFound se o token não existir ou já tiver sido usado
+func (repo *StockSubscriptionRepositoryDB) DeleteRecipientSubscriptions(ctx context.Context, tokenHash string) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionRepository.DeleteRecipientSubscriptions")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		var recipient models.StockSubscriptionRecipient
+		if err := tx.Where("token_hash = ?", tokenHash).First(&recipient).Error; err != nil {
+			return err
+		}
+
+		query := tx.Where("LOWER(email) = ? AND email <> ''", recipient.Email)
+		if recipient.CustomerID != 0 {
+			query = tx.Where("customer_id = ?", recipient.CustomerID)
+		}
+		if err := query.Delete(&models.StockSubscription{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&recipient).Error
+	})
+	return endSpan(span, err)
+}
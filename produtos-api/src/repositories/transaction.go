@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor executa um bloco em uma transação; os repositórios chamados com o contexto
+// recebido por fn participam da mesma transação
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type TransactorDB struct {
+	db *gorm.DB
+}
+
+// NewTransactor cria o Transactor sobre a conexão do GORM
+func NewTransactor(db *gorm.DB) *TransactorDB {
+	return &TransactorDB{db}
+}
+
+type txKey struct{}
+
+// WithinTransaction abre uma transação, ou reaproveita a que já estiver no contexto,
+// e faz rollback quando fn retorna erro
+func (t *TransactorDB) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn retorna a transação do contexto, quando houver, ou a conexão do repositório
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
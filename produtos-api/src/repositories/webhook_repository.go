@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDeliveryFilter define os filtros da consulta de entregas de um webhook; campos vazios não filtram
+type WebhookDeliveryFilter struct {
+	WebhookID uint
+	Status    string
+	EventType string
+	BeforeID  uint
+	Limit     int
+}
+
+// WebhookRepository define a interface para o repositório de webhooks e das suas entregas
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetActiveWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhookByID(ctx context.Context, id uint) (*models.Webhook, error)
+	SaveWebhook(ctx context.Context, webhook *models.Webhook) error
+	UpdateWebhookHealth(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id uint) error
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, webhookID, id uint) (*models.WebhookDelivery, error)
+	FindWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	GetPendingWebhookDeliveries(ctx context.Context, afterID uint, limit int) ([]models.WebhookDelivery, error)
+	SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery, previousAttempts int) error
+	DeleteWebhookDeliveries(ctx context.Context, attemptedBefore time.Time) (int64, error)
+}
+
+type WebhookRepositoryDB struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository cria uma nova instância do repositório de webhooks
+func NewWebhookRepository(db *gorm.DB) *WebhookRepositoryDB {
+	return &WebhookRepositoryDB{db}
+}
+
+func (repo *WebhookRepositoryDB) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.CreateWebhook")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Create(webhook).Error)
+}
+
+func (repo *WebhookRepositoryDB) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.GetWebhooks")
+	defer span.End()
+
+	var webhooks []models.Webhook
+	err := conn(ctx, repo.db).Order("id").Find(&webhooks).Error
+	return webhooks, endSpan(span, err)
+}
+
+func (repo *WebhookRepositoryDB) GetActiveWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.GetActiveWebhooks")
+	defer span.End()
+
+	var webhooks []models.Webhook
+	err := conn(ctx, repo.db).Where("active = ?", true).Order("id").Find(&webhooks).Error
+	return webhooks, endSpan(span, err)
+}
+
+func (repo *WebhookRepositoryDB) GetWebhookByID(ctx context.Context, id uint) (*models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.GetWebhookByID")
+	defer span.End()
+
+	var webhook models.Webhook
+	err := conn(ctx, repo.db).First(&webhook, id).Error
+	return &webhook, endSpan(span, err)
+}
+
+func (repo *WebhookRepositoryDB) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.SaveWebhook")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(webhook).Error)
+}
+
+// UpdateWebhookHealth grava apenas a contagem de falhas e a desativação do webhook, sem sobrescrever
+// alterações concorrentes dos demais campos, como a rotação do segredo. Retorna ErrNotFound se o webhook foi removido.
+func (repo *WebhookRepositoryDB) UpdateWebhookHealth(ctx context.Context, webhook *models.Webhook) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.UpdateWebhookHealth")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Model(&models.Webhook{}).Where("id = ?", webhook.ID).
+		Select("consecutive_failures", "active", "disabled_at", "disabled_reason").
+		Updates(webhook)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+// DeleteWebhook remove o webhook junto com o seu registro de entregas
+func (repo *WebhookRepositoryDB) DeleteWebhook(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.DeleteWebhook")
+	defer span.End()
+
+	err := conn(ctx, repo.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+	return endSpan(span, err)
+}
+
+// CreateWebhookDelivery enfileira a entrega; retorna ErrDuplicated se o evento já foi enfileirado para o webhook
+func (repo *WebhookRepositoryDB) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.CreateWebhookDelivery")
+	defer span.End()
+
+	// A outbox pode publicar o mesmo evento de novo: o conflito é esperado e não é registrado como erro
+	result := conn(ctx, repo.db).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrDuplicated)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+func (repo *WebhookRepositoryDB) GetWebhookDelivery(ctx context.Context, webhookID, id uint) (*models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.GetWebhookDelivery")
+	defer span.End()
+
+	var delivery models.WebhookDelivery
+	err := conn(ctx, repo.db).Where("webhook_id = ?", webhookID).First(&delivery, id).Error
+	return &delivery, endSpan(span, err)
+}
+
+// FindWebhookDeliveries lista as entregas que atendem ao filtro, da mais recente para a mais antiga
+func (repo *WebhookRepositoryDB) FindWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.FindWebhookDeliveries")
+	defer span.End()
+
+	query := conn(ctx, repo.db).Where("webhook_id = ?", filter.WebhookID).Order("id DESC").Limit(filter.Limit)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Find(&deliveries).Error
+	return deliveries, endSpan(span, err)
+}
+
+// GetPendingWebhookDeliveries lista as entregas pendentes com ID maior que afterID, na ordem em que foram enfileiradas
+func (repo *WebhookRepositoryDB) GetPendingWebhookDeliveries(ctx context.Context, afterID uint, limit int) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.GetPendingWebhookDeliveries")
+	defer span.End()
+
+	var deliveries []models.WebhookDelivery
+	err := conn(ctx, repo.db).Where("status = ? AND id > ?", models.DeliveryPending, afterID).Order("id").Limit(limit).Find(&deliveries).Error
+	return deliveries, endSpan(span, err)
+}
+
+func (repo *WebhookRepositoryDB) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.SaveWebhookDelivery")
+	defer span.End()
+
+	return endSpan(span, conn(ctx, repo.db).Save(delivery).Error)
+}
+
+// UpdateWebhookDeliveryAttempt grava o resultado de uma tentativa de envio se a entrega continua pendente
+// com previousAttempts tentativas. Retorna ErrNotFound se ela foi removida ou reenviada durante o envio.
+func (repo *WebhookRepositoryDB) UpdateWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery, previousAttempts int) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.UpdateWebhookDeliveryAttempt")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.DeliveryPending, previousAttempts).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error", "delivered_at").
+		Updates(delivery)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return endSpan(span, ErrNotFound)
+	}
+
+	return endSpan(span, result.Error)
+}
+
+// DeleteWebhookDeliveries remove as entregas concluídas cuja última tentativa foi antes de attemptedBefore
+func (repo *WebhookRepositoryDB) DeleteWebhookDeliveries(ctx context.Context, attemptedBefore time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepository.DeleteWebhookDeliveries")
+	defer span.End()
+
+	result := conn(ctx, repo.db).Where("status <> ? AND last_attempt_at < ?", models.DeliveryPending, attemptedBefore).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, endSpan(span, result.Error)
+}
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/config"
+	"produtos-api/src/controllers"
+	"produtos-api/src/database"
+	"produtos-api/src/middlewares"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/repositories"
+	"produtos-api/src/services"
+	"produtos-api/src/tracing"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	_ "produtos-api/docs/clientes"
+
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+// SetupCustomerRoutes monta as dependências e as rotas do microsserviço de clientes. O serviço não tem
+// workers em segundo plano; ctx e workers seguem a assinatura dos outros microsserviços.
+func SetupCustomerRoutes(ctx context.Context, db *gorm.DB, healthService services.HealthService, workers *sync.WaitGroup) *mux.Router {
+	// Registra as dependências verificadas pela prontidão
+	healthService.RegisterCheck("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthService.RegisterCheck("migrations", func(ctx context.Context) error {
+		pending, err := database.PendingMigrations(ctx, db, database.Customers)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	})
+
+	// Inicializar dependências
+	customerService := services.NewCustomerService(repositories.NewCustomerRepository(db))
+	customerController := controllers.NewCustomerController(customerService)
+	healthController := controllers.NewHealthController(healthService)
+
+	// Spans do OpenTelemetry para cada consulta do GORM
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		fatal("Failed to register tracing plugin", err)
+	}
+
+	// Autenticação apenas por JWT, com as mesmas chaves e papéis da API de produtos. O titular dos dados
+	// acessa o próprio cadastro com o papel viewer; editores acessam todos os clientes.
+	keys, err := auth.LoadKeySet()
+	if err != nil {
+		fatal("Failed to load authentication keys", err)
+	}
+	if keys.Empty() {
+		logger.Warn("No AUTH_HMAC_SECRET or AUTH_JWKS_FILE configured: only public routes are accessible")
+	}
+	authPolicy := auth.Policy{
+		Routes:  map[string]auth.Role{},
+		Default: auth.RoleAdmin,
+	}
+	authService := services.NewAuthService(keys, authPolicy, nil)
+
+	defaultRateLimit, err := ratelimit.ParseLimit(config.GetString("RATE_LIMIT", "600/m"))
+	if err != nil {
+		fatal("Failed to parse RATE_LIMIT", err)
+	}
+	rateLimitService := services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(
+		defaultRateLimit,
+		append([]string{"GET /healthz=off", "GET /readyz=off"}, config.GetList("ROUTE_RATE_LIMITS", nil)...),
+	))
+
+	// Cria um novo roteador
+	router := mux.NewRouter()
+	router.Use(
+		middlewares.RequestID(),
+		middlewares.Tracing(),
+		middlewares.AccessLog(),
+		ipRateLimit("GET /healthz=off", "GET /readyz=off"),
+		middlewares.Auth(authService, controllers.WriteProblem),
+		middlewares.RateLimit(rateLimitService, trustedProxies(), controllers.WriteProblem),
+		middlewares.ContentLocale(),
+	)
+	router.Use(middlewares.Timeout(middlewares.ParseRouteTimeouts(
+		config.GetDuration("REQUEST_TIMEOUT", 10*time.Second),
+		config.GetList("ROUTE_TIMEOUTS", nil),
+	)))
+
+	// Definir rotas, cada uma com o papel mínimo exigido
+	handle := func(path, method string, role auth.Role, handler http.HandlerFunc) {
+		router.HandleFunc(path, handler).Methods(method)
+		authPolicy.Routes[method+" "+path] = role
+	}
+
+	handle("/healthz", "GET", auth.RolePublic, healthController.Liveness)
+	handle("/readyz", "GET", auth.RolePublic, healthController.Readiness)
+	handle("/customers", "POST", auth.RoleViewer, customerController.CreateCustomer)
+	handle("/customers", "GET", auth.RoleEditor, customerController.GetCustomers)
+	handle("/customers/{id}", "GET", auth.RoleViewer, customerController.GetCustomerByID)
+	handle("/customers/{id}", "PUT", auth.RoleViewer, customerController.UpdateCustomer)
+	handle("/customers/{id}", "DELETE", auth.RoleViewer, customerController.EraseCustomer)
+	handle("/customers/{id}/export", "GET", auth.RoleViewer, customerController.ExportCustomer)
+	handle("/customers/{id}/consents", "PUT", auth.RoleViewer, customerController.UpdateConsents)
+	handle("/customers/{id}/addresses", "POST", auth.RoleViewer, customerController.AddAddress)
+	handle("/customers/{id}/addresses/{address}", "PUT", auth.RoleViewer, customerController.UpdateAddress)
+	handle("/customers/{id}/addresses/{address}", "DELETE", auth.RoleViewer, customerController.DeleteAddress)
+
+	// Define a rota para a documentação Swagger
+	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(httpSwagger.InstanceName("clientes")))
+	authPolicy.Routes["GET /swagger/"] = auth.RolePublic
+
+	return router
+}
@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/catalog"
+	"produtos-api/src/config"
+	"produtos-api/src/controllers"
+	"produtos-api/src/customers"
+	"produtos-api/src/database"
+	"produtos-api/src/middlewares"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/repositories"
+	"produtos-api/src/services"
+	"produtos-api/src/tracing"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	_ "produtos-api/docs/pedidos"
+
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+// SetupOrderRoutes monta as dependências e as rotas do microsserviço de pedidos; os workers em segundo
+// plano rodam até o cancelamento de ctx e são acompanhados por workers, que o main aguarda ao desligar
+func SetupOrderRoutes(ctx context.Context, db *gorm.DB, healthService services.HealthService, workers *sync.WaitGroup) *mux.Router {
+	background := func(fn func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			fn()
+		}()
+	}
+
+	// Registra as dependências verificadas pela prontidão
+	healthService.RegisterCheck("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthService.RegisterCheck("migrations", func(ctx context.Context) error {
+		pending, err := database.PendingMigrations(ctx, db, database.Orders)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	})
+
+	// Spans do OpenTelemetry para cada consulta do GORM, registrados antes de qualquer worker, pois db.Use
+	// altera os callbacks compartilhados e os workers consultam o banco assim que iniciam
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		fatal("Failed to register tracing plugin", err)
+	}
+
+	// API de produtos, onde o checkout reserva o estoque. A chave de API (PRODUCTS_API_KEY) precisa dos
+	// escopos products:read e stock-reservations:write; sem ela, PRODUCTS_API_TOKEN é enviado como Bearer.
+	catalogClient := catalog.NewClient(catalog.Config{
+		BaseURL: config.GetString("PRODUCTS_API_URL", "http://localhost:8080"),
+		APIKey:  config.GetString("PRODUCTS_API_KEY", ""),
+		Token:   config.GetString("PRODUCTS_API_TOKEN", ""),
+		Timeout: config.GetDuration("PRODUCTS_API_TIMEOUT", 5*time.Second),
+	})
+
+	// API de clientes, onde o checkout verifica se o customer_id é do titular autenticado
+	customersClient := customers.NewClient(customers.Config{
+		BaseURL: config.GetString("CUSTOMERS_API_URL", "http://localhost:8082"),
+		Token:   config.GetString("CUSTOMERS_API_TOKEN", ""),
+		Timeout: config.GetDuration("CUSTOMERS_API_TIMEOUT", 5*time.Second),
+	})
+
+	// Inicializar dependências
+	outboxRepository := repositories.NewOutboxRepository(db)
+	orderService := services.NewOrderService(repositories.NewOrderRepository(db), outboxRepository, repositories.NewTransactor(db),
+		catalogClient, customersClient, config.GetDuration("CHECKOUT_TIMEOUT", 5*time.Minute))
+	orderController := controllers.NewOrderController(orderService)
+	healthController := controllers.NewHealthController(healthService)
+
+	// Compensação do checkout: devolve o estoque dos pedidos cancelados enquanto a API de produtos
+	// estava indisponível e cancela os checkouts interrompidos há mais de CHECKOUT_TIMEOUT
+	compensationWorker := healthService.RegisterWorker("compensation")
+	background(func() {
+		services.RunPeriodic(ctx, "compensation", compensationWorker, config.GetDuration("COMPENSATION_INTERVAL", 30*time.Second),
+			func(ctx context.Context) error {
+				_, err := orderService.Compensate(ctx)
+				return err
+			})
+	})
+
+	// Notificações: OrderConfirmed é gravado na outbox do banco de pedidos junto com o pagamento e repassado
+	// ao serviço de notificações, que enfileira o e-mail de confirmação; o worker mail envia a fila
+	notificationService := newNotificationService(db)
+	mailWorker := healthService.RegisterWorker("mail")
+	background(func() {
+		services.RunPeriodic(ctx, "mail", mailWorker, config.GetDuration("MAIL_INTERVAL", 5*time.Second),
+			func(ctx context.Context) error {
+				_, err := notificationService.Deliver(ctx)
+				return err
+			})
+	})
+
+	// Com KAFKA_BROKERS definido, os eventos dos pedidos também são publicados no tópico OUTBOX_TOPIC, consumido
+	// pelas reservas de estoque da API de produtos (ORDERS_TOPIC)
+	outboxTopic := config.GetString("OUTBOX_TOPIC", "orders")
+	outboxPublisher := broker.Fanout{broker.PublisherFunc(notificationService.HandleEvent)}
+	if kafkaConfig, kafkaEnabled := kafkaConfigFromEnv(); kafkaEnabled {
+		kafkaBroker, err := broker.NewKafkaBroker(kafkaConfig)
+		if err != nil {
+			fatal("Failed to create Kafka producer", err)
+		}
+		// Sem o tópico a publicação falha e é repetida; não impede a API de subir
+		if err := kafkaBroker.EnsureTopic(ctx, outboxTopic); err != nil {
+			logger.Warn("Failed to ensure Kafka topic", "topic", outboxTopic, "error", err)
+		}
+		background(func() {
+			<-ctx.Done()
+			kafkaBroker.Close()
+		})
+		outboxPublisher = append(outboxPublisher, kafkaBroker)
+	}
+
+	outboxService := services.NewOutboxService(outboxRepository, outboxPublisher,
+		outboxTopic, config.GetDuration("OUTBOX_RETENTION", 7*24*time.Hour))
+	outboxWorker := healthService.RegisterWorker("outbox")
+	background(func() {
+		services.RunPeriodic(ctx, "outbox", outboxWorker, config.GetDuration("OUTBOX_INTERVAL", time.Second),
+			func(ctx context.Context) error {
+				_, err := outboxService.Dispatch(ctx)
+				return err
+			})
+	})
+
+	// Autenticação apenas por JWT, com as mesmas chaves e papéis da API de produtos; os pedidos não têm leituras públicas
+	keys, err := auth.LoadKeySet()
+	if err != nil {
+		fatal("Failed to load authentication keys", err)
+	}
+	if keys.Empty() {
+		logger.Warn("No AUTH_HMAC_SECRET or AUTH_JWKS_FILE configured: only public routes are accessible")
+	}
+	authPolicy := auth.Policy{
+		Routes:  map[string]auth.Role{},
+		Default: auth.RoleAdmin,
+	}
+	authService := services.NewAuthService(keys, authPolicy, nil)
+
+	defaultRateLimit, err := ratelimit.ParseLimit(config.GetString("RATE_LIMIT", "600/m"))
+	if err != nil {
+		fatal("Failed to parse RATE_LIMIT", err)
+	}
+	rateLimitService := services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(
+		defaultRateLimit,
+		append([]string{"GET /healthz=off", "GET /readyz=off"}, config.GetList("ROUTE_RATE_LIMITS", nil)...),
+	))
+
+	// Cria um novo roteador
+	router := mux.NewRouter()
+	router.Use(
+		middlewares.RequestID(),
+		middlewares.Tracing(),
+		middlewares.AccessLog(),
+		ipRateLimit("GET /healthz=off", "GET /readyz=off"),
+		middlewares.Auth(authService, controllers.WriteProblem),
+		middlewares.RateLimit(rateLimitService, trustedProxies(), controllers.WriteProblem),
+		middlewares.ContentLocale(),
+	)
+	router.Use(middlewares.Timeout(middlewares.ParseRouteTimeouts(
+		config.GetDuration("REQUEST_TIMEOUT", 10*time.Second),
+		config.GetList("ROUTE_TIMEOUTS", nil),
+	)))
+
+	// Definir rotas, cada uma com o papel mínimo exigido
+	handle := func(path, method string, role auth.Role, handler http.HandlerFunc) {
+		router.HandleFunc(path, handler).Methods(method)
+		authPolicy.Routes[method+" "+path] = role
+	}
+
+	handle("/healthz", "GET", auth.RolePublic, healthController.Liveness)
+	handle("/readyz", "GET", auth.RolePublic, healthController.Readiness)
+	handle("/orders", "POST", auth.RoleViewer, orderController.CreateOrder)
+	handle("/orders", "GET", auth.RoleEditor, orderController.GetOrders)
+	handle("/orders/{id}", "GET", auth.RoleViewer, orderController.GetOrderByID)
+	handle("/orders/{id}/pay", "POST", auth.RoleEditor, orderController.PayOrder)
+	handle("/orders/{id}/ship", "POST", auth.RoleEditor, orderController.ShipOrder)
+	handle("/orders/{id}/cancel", "POST", auth.RoleEditor, orderController.CancelOrder)
+
+	// Define a rota para a documentação Swagger
+	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(httpSwagger.InstanceName("pedidos")))
+	authPolicy.Routes["GET /swagger/"] = auth.RolePublic
+
+	return router
+}
@@ -1,43 +1,445 @@
 package routes
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"os"
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/config"
 	"produtos-api/src/controllers"
+	"produtos-api/src/customers"
 	"produtos-api/src/database"
+	"produtos-api/src/logging"
+	"produtos-api/src/mail"
+	"produtos-api/src/metrics"
+	"produtos-api/src/middlewares"
+	"produtos-api/src/ratelimit"
 	"produtos-api/src/repositories"
 	"produtos-api/src/services"
+	"produtos-api/src/tracing"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 
 	_ "produtos-api/docs"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func SetupRoutes() *mux.Router {
-	// Inicializa o banco de dados (real ou de testes, dependendo do ambiente)
-	db, err := database.SetupDatabase()
+var logger = logging.Logger("routes")
+
+// fatal registra a falha de configuração e encerra o processo, como o main faz nas falhas de inicialização
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// ipRateLimit limita as requisições por IP antes da autenticação, de modo que tentativas com credenciais
+// inválidas também sejam limitadas. IP_RATE_LIMIT define um único bucket por IP para todas as rotas, exceto
+// as informadas em exempt; os limites por cliente continuam no RateLimit, depois do Auth.
+func ipRateLimit(exempt ...string) mux.MiddlewareFunc {
+	limit, err := ratelimit.ParseLimit(config.GetString("IP_RATE_LIMIT", "1200/m"))
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal("Failed to parse IP_RATE_LIMIT", err)
+	}
+	rateLimitService := services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(limit, exempt))
+
+	return middlewares.RateLimitByIP(rateLimitService, trustedProxies(), controllers.WriteProblem)
+}
+
+// trustedProxies retorna quantos proxies confiáveis acrescentam o IP do cliente a X-Forwarded-For:
+// RATE_LIMIT_TRUSTED_PROXIES, ou um quando apenas RATE_LIMIT_TRUST_PROXY está habilitado
+func trustedProxies() int {
+	if !config.GetBool("RATE_LIMIT_TRUST_PROXY", false) {
+		return 0
+	}
+
+	return max(config.GetInt("RATE_LIMIT_TRUSTED_PROXIES", 1), 1)
+}
+
+// SetupRoutes monta as dependências e as rotas da API; os workers em segundo plano
+// rodam até o cancelamento de ctx e são acompanhados por workers, que o main aguarda ao desligar
+func SetupRoutes(ctx context.Context, db *gorm.DB, healthService services.HealthService, workers *sync.WaitGroup) http.Handler {
+	background := func(fn func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			fn()
+		}()
+	}
+
+	// Registra as dependências verificadas pela prontidão
+	healthService.RegisterCheck("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthService.RegisterCheck("migrations", func(ctx context.Context) error {
+		pending, err := database.PendingMigrations(ctx, db, database.Products)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	})
+
+	// Spans do OpenTelemetry e métricas do Prometheus (HTTP, consultas do GORM, pool de conexões e catálogo).
+	// Os plugins do GORM são registrados antes de qualquer worker, pois db.Use altera os callbacks
+	// compartilhados e os workers consultam o banco assim que iniciam.
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		fatal("Failed to register tracing plugin", err)
+	}
+	appMetrics := metrics.New()
+	if err := db.Use(metrics.NewGormPlugin(appMetrics)); err != nil {
+		fatal("Failed to register metrics plugin", err)
+	}
+	if err := appMetrics.RegisterDBStats(db); err != nil {
+		fatal("Failed to register database metrics", err)
 	}
 
 	// Inicializar dependências
 	productRepository := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepository)
+	productTranslationRepository := repositories.NewProductTranslationRepository(db)
+	apiKeyRepository := repositories.NewAPIKeyRepository(db)
+	auditRepository := repositories.NewAuditRepository(db)
+	transactor := repositories.NewTransactor(db)
+	outboxRepository := repositories.NewOutboxRepository(db)
+	productService := services.NewProductService(productRepository, productTranslationRepository, auditRepository, outboxRepository, transactor)
+	productTranslationService := services.NewProductTranslationService(productRepository, productTranslationRepository)
 	productController := controllers.NewProductController(productService)
+	productTranslationController := controllers.NewProductTranslationController(productTranslationService)
+	healthController := controllers.NewHealthController(healthService)
+	auditController := controllers.NewAuditController(services.NewAuditService(auditRepository))
+
+	// Limpeza da lixeira: remove definitivamente os produtos excluídos há mais de PURGE_RETENTION
+	purgeRetention := config.GetDuration("PURGE_RETENTION", 30*24*time.Hour)
+	purgeWorker := healthService.RegisterWorker("purge")
+	background(func() {
+		services.RunPeriodic(ctx, "purge", purgeWorker, config.GetDuration("PURGE_INTERVAL", time.Hour),
+			func(ctx context.Context) error {
+				_, err := productService.PurgeDeletedProducts(ctx, purgeRetention)
+				return err
+			})
+	})
+
+	// Publicação dos eventos de domínio gravados na outbox: no Kafka quando KAFKA_BROKERS estiver definido
+	outboxTopic := config.GetString("OUTBOX_TOPIC", "products")
+	ordersTopic := config.GetString("ORDERS_TOPIC", "orders")
+	ordersDLQTopic := config.GetString("ORDERS_DLQ_TOPIC", ordersTopic+".dlq")
+	kafkaConfig, kafkaEnabled := kafkaConfigFromEnv()
+	var publisher broker.Broker = broker.NewMemoryBroker()
+	if kafkaEnabled {
+		kafkaBroker, err := broker.NewKafkaBroker(kafkaConfig)
+		if err != nil {
+			fatal("Failed to create Kafka producer", err)
+		}
+		// Sem o tópico a publicação falha e é repetida; não impede a API de subir
+		for _, topic := range []string{outboxTopic, ordersDLQTopic} {
+			if err := kafkaBroker.EnsureTopic(ctx, topic); err != nil {
+				logger.Warn("Failed to ensure Kafka topic", "topic", topic, "error", err)
+			}
+		}
+		background(func() {
+			<-ctx.Done()
+			kafkaBroker.Close()
+		})
+		publisher = kafkaBroker
+	}
+
+	// Webhooks: os eventos publicados pela outbox também são enfileirados para os endpoints dos parceiros
+	// e entregues pelo worker webhooks, com assinatura HMAC e novas tentativas com backoff
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), services.WebhookConfig{
+		Timeout:      config.GetDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+		MaxAttempts:  config.GetInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		DisableAfter: config.GetInt("WEBHOOK_DISABLE_AFTER", 20),
+		Retention:    config.GetDuration("WEBHOOK_RETENTION", 30*24*time.Hour),
+	})
+	webhookController := controllers.NewWebhookController(webhookService)
+	webhookWorker := healthService.RegisterWorker("webhooks")
+	background(func() {
+		services.RunPeriodic(ctx, "webhooks", webhookWorker, config.GetDuration("WEBHOOK_INTERVAL", time.Second),
+			func(ctx context.Context) error {
+				_, err := webhookService.Deliver(ctx)
+				return err
+			})
+	})
+
+	// Avisos de volta ao estoque: quando o estoque de um produto sai de zero, a outbox entrega o StockChanged ao
+	// serviço, que só marca as inscrições do produto. O worker stock-alerts enfileira a cada STOCK_ALERTS_INTERVAL
+	// um e-mail por inscrição marcada, limitado por destinatário a BACK_IN_STOCK_RATE_LIMIT. O e-mail dos clientes
+	// inscritos é lido da API de clientes com CUSTOMERS_API_TOKEN, que precisa do papel editor.
+	backInStockLimit, err := ratelimit.ParseLimit(config.GetString("BACK_IN_STOCK_RATE_LIMIT", "5/h"))
+	if err != nil {
+		fatal("Failed to parse BACK_IN_STOCK_RATE_LIMIT", err)
+	}
+	customersClient := customers.NewClient(customers.Config{
+		BaseURL: config.GetString("CUSTOMERS_API_URL", "http://localhost:8082"),
+		Token:   config.GetString("CUSTOMERS_API_TOKEN", ""),
+		Timeout: config.GetDuration("CUSTOMERS_API_TIMEOUT", 5*time.Second),
+	})
+	notificationService := newNotificationService(db)
+	stockSubscriptionService := services.NewStockSubscriptionService(repositories.NewStockSubscriptionRepository(db), productRepository,
+		productTranslationRepository, transactor, customersClient, notificationService, backInStockLimit,
+		config.GetString("STOCK_UNSUBSCRIBE_URL", "http://localhost:8080/stock-subscriptions/{token}"))
+	stockSubscriptionController := controllers.NewStockSubscriptionController(stockSubscriptionService)
+	mailWorker := healthService.RegisterWorker("mail")
+	background(func() {
+		services.RunPeriodic(ctx, "mail", mailWorker, config.GetDuration("MAIL_INTERVAL", 5*time.Second),
+			func(ctx context.Context) error {
+				_, err := notificationService.Deliver(ctx)
+				return err
+			})
+	})
+
+	stockAlertsWorker := healthService.RegisterWorker("stock-alerts")
+	background(func() {
+		services.RunPeriodic(ctx, "stock-alerts", stockAlertsWorker, config.GetDuration("STOCK_ALERTS_INTERVAL", 5*time.Second),
+			func(ctx context.Context) error {
+				_, err := stockSubscriptionService.NotifyDue(ctx)
+				return err
+			})
+	})
+
+	outboxPublisher := broker.Fanout{publisher, broker.PublisherFunc(webhookService.Enqueue), broker.PublisherFunc(stockSubscriptionService.HandleEvent)}
+	outboxService := services.NewOutboxService(outboxRepository, outboxPublisher, outboxTopic,
+		config.GetDuration("OUTBOX_RETENTION", 7*24*time.Hour))
+	outboxWorker := healthService.RegisterWorker("outbox")
+	background(func() {
+		services.RunPeriodic(ctx, "outbox", outboxWorker, config.GetDuration("OUTBOX_INTERVAL", time.Second),
+			func(ctx context.Context) error {
+				_, err := outboxService.Dispatch(ctx)
+				return err
+			})
+	})
+
+	// Stream de mudanças do catálogo (SSE e WebSocket): o worker stream lê a outbox a cada STREAM_INTERVAL e
+	// mantém as últimas STREAM_BUFFER mudanças em memória; as conexões são encerradas no início da drenagem
+	changeStream := services.NewChangeStreamService(outboxRepository, productRepository, config.GetInt("STREAM_BUFFER", 1024))
+	streamWorker := healthService.RegisterWorker("stream")
+	background(func() {
+		services.RunPeriodic(ctx, "stream", streamWorker, config.GetDuration("STREAM_INTERVAL", 500*time.Millisecond),
+			func(ctx context.Context) error {
+				_, err := changeStream.Poll(ctx)
+				return err
+			})
+	})
+	background(func() {
+		select {
+		case <-healthService.Drained():
+		case <-ctx.Done():
+		}
+		changeStream.Close()
+	})
+
+	// Reservas de estoque dos pedidos: feitas pelo checkout do microsserviço de pedidos em /stock-reservations
+	// ou pelos eventos do tópico de pedidos, que só são consumidos com o Kafka. Eventos inválidos vão para ORDERS_DLQ_TOPIC,
+	// assim como os que falham ORDERS_MAX_ATTEMPTS vezes seguidas, para não segurar a partição indefinidamente.
+	inventoryService := services.NewInventoryService(productRepository, repositories.NewStockReservationRepository(db),
+		repositories.NewProcessedEventRepository(db), auditRepository, outboxRepository, transactor, publisher, ordersDLQTopic)
+	stockReservationController := controllers.NewStockReservationController(inventoryService)
+	if kafkaEnabled {
+		ordersConsumer, err := broker.NewKafkaConsumer(kafkaConfig, config.GetString("ORDERS_CONSUMER_GROUP", "produtos-api"), ordersTopic)
+		if err != nil {
+			fatal("Failed to create Kafka consumer", err)
+		}
+		ordersConsumer.WithDeadLetter(publisher, ordersDLQTopic, config.GetInt("ORDERS_MAX_ATTEMPTS", 10))
+		ordersWorker := healthService.RegisterWorker("orders")
+		// Fechar o consumer sai do grupo, e as partições são redistribuídas sem esperar o timeout da sessão
+		background(func() {
+			services.RunConsumer(ctx, "orders", ordersWorker, ordersConsumer, inventoryService.HandleOrderEvent)
+			ordersConsumer.Close()
+		})
+	}
+
+	// Quantidade de produtos do catálogo, recalculada no máximo a cada METRICS_PRODUCTS_INTERVAL
+	if err := appMetrics.RegisterProductsCount(func() int64 {
+		return productService.GetProductsCount(context.Background())
+	}, config.GetDuration("METRICS_PRODUCTS_INTERVAL", 30*time.Second)); err != nil {
+		fatal("Failed to register products metrics", err)
+	}
+
+	// Autenticação JWT (HMAC e/ou JWKS) com papéis por rota e chaves de API com escopos;
+	// AUTH_PUBLIC_READS libera as leituras
+	keys, err := auth.LoadKeySet()
+	if err != nil {
+		fatal("Failed to load authentication keys", err)
+	}
+	if keys.Empty() {
+		logger.Warn("No AUTH_HMAC_SECRET or AUTH_JWKS_FILE configured: only public routes are accessible")
+	}
+	authPolicy := auth.Policy{
+		Routes:       map[string]auth.Role{},
+		Default:      auth.RoleAdmin,
+		PublicReads:  config.GetBool("AUTH_PUBLIC_READS", true),
+		TicketRoutes: map[string]bool{"GET /products/stream": true, "GET /products/stream/ws": true},
+	}
+	authService := services.NewAuthService(keys, authPolicy, apiKeyRepository).WithTickets(streamTickets(keys))
+
+	// Os navegadores autenticam os streams com um ticket de POST /products/stream/tickets na query
+	productStreamController := controllers.NewProductStreamController(changeStream, authService,
+		config.GetDuration("STREAM_HEARTBEAT", 15*time.Second), config.GetDuration("STREAM_WRITE_TIMEOUT", 10*time.Second))
+	apiKeyController := controllers.NewAPIKeyController(services.NewAPIKeyService(apiKeyRepository, authPolicy))
+
+	// Limite de requisições por chave de API, usuário ou IP: RATE_LIMIT define o padrão e
+	// ROUTE_RATE_LIMITS os limites por rota (ex: ROUTE_RATE_LIMITS="GET /products=60/m,POST /products=10/s").
+	// Health checks e métricas não são limitados, salvo configuração explícita.
+	defaultRateLimit, err := ratelimit.ParseLimit(config.GetString("RATE_LIMIT", "600/m"))
+	if err != nil {
+		fatal("Failed to parse RATE_LIMIT", err)
+	}
+	rateLimitService := services.NewRateLimitService(ratelimit.NewMemoryBackend(), ratelimit.ParsePolicies(
+		defaultRateLimit,
+		append([]string{"GET /healthz=off", "GET /readyz=off", "GET /metrics=off"}, config.GetList("ROUTE_RATE_LIMITS", nil)...),
+	))
+	trustedProxies := trustedProxies()
+
+	// Prazo por requisição: REQUEST_TIMEOUT define o padrão e ROUTE_TIMEOUTS os prazos por rota
+	// (ex: ROUTE_TIMEOUTS="GET /products=2s,POST /products=5s"). Os streams não têm prazo, salvo configuração explícita,
+	// e o lote JSON-RPC também não: cada chamada tem o prazo da sua rota REST.
+	routeTimeouts := middlewares.ParseRouteTimeouts(
+		config.GetDuration("REQUEST_TIMEOUT", 10*time.Second),
+		append([]string{"GET /products/stream=0s", "GET /products/stream/ws=0s", "POST /rpc=0s"}, config.GetList("ROUTE_TIMEOUTS", nil)...),
+	)
+
+	// JSON-RPC 2.0 para os outros microsserviços, com a mesma instância do ProductService dos controllers REST.
+	// Cada chamada é autorizada, limitada e tem o prazo da rota REST equivalente; RPC_MAX_BATCH limita as chamadas por lote.
+	productRPCController := controllers.NewProductRPCController(productService, authService, controllers.RPCLimits{
+		RateLimits: rateLimitService,
+		Identity: func(r *http.Request) string {
+			return middlewares.RateLimitIdentity(r, trustedProxies)
+		},
+		Timeout: routeTimeouts.For,
+	}, config.GetInt("RPC_MAX_BATCH", 100))
 
 	// Cria um novo roteador
 	router := mux.NewRouter()
+	// As métricas envolvem o roteador para contar também as requisições sem rota; são registradas antes dos
+	// demais middlewares para rotular pela rota também as respostas dadas por eles
+	handler := middlewares.Metrics(appMetrics, router)
+	router.Use(
+		middlewares.RequestID(),
+		middlewares.Tracing(),
+		middlewares.AccessLog(),
+		ipRateLimit("GET /healthz=off", "GET /readyz=off", "GET /metrics=off"),
+		middlewares.Auth(authService, controllers.WriteProblem),
+		middlewares.RateLimit(rateLimitService, trustedProxies, controllers.WriteProblem),
+		middlewares.ContentLocale(),
+	)
+
+	router.Use(middlewares.Timeout(routeTimeouts))
+
+	// Definir rotas, cada uma com o papel mínimo exigido
+	handle := func(path, method string, role auth.Role, handler http.HandlerFunc) {
+		router.HandleFunc(path, handler).Methods(method)
+		authPolicy.Routes[method+" "+path] = role
+	}
 
-	// Definir rotas
-	router.HandleFunc("/products", productController.CreateProduct).Methods("POST")
-	router.HandleFunc("/products/{id}", productController.GetProductByID).Methods("GET")
-	router.HandleFunc("/products", productController.GetAllProducts).Methods("GET")
-	router.HandleFunc("/products/{id}", productController.UpdateProduct).Methods("PUT")
-	router.HandleFunc("/products/{id}", productController.DeleteProduct).Methods("DELETE")
+	handle("/healthz", "GET", auth.RolePublic, healthController.Liveness)
+	handle("/readyz", "GET", auth.RolePublic, healthController.Readiness)
+	handle("/metrics", "GET", auth.RolePublic, appMetrics.Handler().ServeHTTP)
+	handle("/products", "POST", auth.RoleEditor, productController.CreateProduct)
+	handle("/products/trash", "GET", auth.RoleAdmin, productController.GetTrash)
+	handle("/products/stream/tickets", "POST", auth.RoleViewer, productStreamController.CreateStreamTicket)
+	handle("/products/stream", "GET", auth.RoleViewer, productStreamController.StreamProducts)
+	handle("/products/stream/ws", "GET", auth.RoleViewer, productStreamController.StreamProductsWebSocket)
+	handle("/products/{id}", "GET", auth.RoleViewer, productController.GetProductByID)
+	handle("/products", "GET", auth.RoleViewer, productController.GetAllProducts)
+	handle("/products/{id}", "PUT", auth.RoleEditor, productController.UpdateProduct)
+	handle("/products/{id}", "DELETE", auth.RoleAdmin, productController.DeleteProduct)
+	handle("/products/{id}/restore", "POST", auth.RoleAdmin, productController.RestoreProduct)
+	handle("/products/{id}/revisions", "GET", auth.RoleViewer, productController.GetRevisions)
+	handle("/products/{id}/revisions/{revision}/revert", "POST", auth.RoleEditor, productController.RevertProduct)
+	handle("/products/{id}/translations", "GET", auth.RoleViewer, productTranslationController.GetTranslations)
+	handle("/products/{id}/translations/{locale}", "GET", auth.RoleViewer, productTranslationController.GetTranslation)
+	handle("/products/{id}/translations/{locale}", "PUT", auth.RoleEditor, productTranslationController.PutTranslation)
+	handle("/products/{id}/translations/{locale}", "DELETE", auth.RoleEditor, productTranslationController.DeleteTranslation)
+	handle("/products/{id}/subscriptions", "POST", auth.RoleViewer, stockSubscriptionController.Subscribe)
+	handle("/stock-subscriptions/{token}", "GET", auth.RolePublic, stockSubscriptionController.ConfirmUnsubscribe)
+	handle("/stock-subscriptions/{token}", "POST", auth.RolePublic, stockSubscriptionController.UnsubscribeFromPage)
+	handle("/stock-subscriptions/{token}", "DELETE", auth.RolePublic, stockSubscriptionController.Unsubscribe)
+	handle("/rpc", "POST", auth.RolePublic, productRPCController.ServeRPC)
+	handle("/audit", "GET", auth.RoleAdmin, auditController.GetAuditRecords)
+	handle("/audit/verify", "GET", auth.RoleAdmin, auditController.VerifyAuditChain)
+	handle("/api-keys", "POST", auth.RoleAdmin, apiKeyController.CreateAPIKey)
+	handle("/api-keys", "GET", auth.RoleAdmin, apiKeyController.GetAPIKeys)
+	handle("/api-keys/{id}/rotate", "POST", auth.RoleAdmin, apiKeyController.RotateAPIKey)
+	handle("/api-keys/{id}", "DELETE", auth.RoleAdmin, apiKeyController.RevokeAPIKey)
+	handle("/stock-reservations", "POST", auth.RoleEditor, stockReservationController.CreateStockReservation)
+	handle("/stock-reservations/{id}", "GET", auth.RoleEditor, stockReservationController.GetStockReservations)
+	handle("/stock-reservations/{id}", "DELETE", auth.RoleEditor, stockReservationController.ReleaseStockReservation)
+	handle("/stock-reservations/{id}/commit", "POST", auth.RoleEditor, stockReservationController.CommitStockReservation)
+	handle("/webhooks", "POST", auth.RoleAdmin, webhookController.CreateWebhook)
+	handle("/webhooks", "GET", auth.RoleAdmin, webhookController.GetWebhooks)
+	handle("/webhooks/{id}", "GET", auth.RoleAdmin, webhookController.GetWebhook)
+	handle("/webhooks/{id}", "PUT", auth.RoleAdmin, webhookController.UpdateWebhook)
+	handle("/webhooks/{id}", "DELETE", auth.RoleAdmin, webhookController.DeleteWebhook)
+	handle("/webhooks/{id}/rotate", "POST", auth.RoleAdmin, webhookController.RotateWebhookSecret)
+	handle("/webhooks/{id}/deliveries", "GET", auth.RoleAdmin, webhookController.GetWebhookDeliveries)
+	handle("/webhooks/{id}/deliveries/{delivery}/replay", "POST", auth.RoleAdmin, webhookController.ReplayWebhookDelivery)
 
 	// Define a rota para a documentação Swagger
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	authPolicy.Routes["GET /swagger/"] = auth.RolePublic
+
+	return handler
+}
+
+// streamTickets configura os tickets dos streams: STREAM_TICKET_SECRET, ou AUTH_HMAC_SECRET, assina os tickets e
+// deve ser o mesmo em todas as instâncias; sem nenhum dos dois, um segredo aleatório vale apenas nesta instância
+func streamTickets(keys *auth.KeySet) auth.Tickets {
+	tickets := auth.Tickets{
+		Secret: []byte(config.GetString("STREAM_TICKET_SECRET", string(keys.HMACSecret))),
+		TTL:    config.GetDuration("STREAM_TICKET_TTL", 30*time.Second),
+	}
+	if len(tickets.Secret) == 0 {
+		tickets.Secret = make([]byte, 32)
+		if _, err := rand.Read(tickets.Secret); err != nil {
+			fatal("Failed to generate the stream ticket secret", err)
+		}
+		logger.Warn("No STREAM_TICKET_SECRET or AUTH_HMAC_SECRET configured: stream tickets are only valid on this instance")
+	}
+
+	return tickets
+}
+
+// kafkaConfigFromEnv lê a configuração do Kafka; ok é falso quando KAFKA_BROKERS não está definido
+func kafkaConfigFromEnv() (broker.KafkaConfig, bool) {
+	brokers := config.GetList("KAFKA_BROKERS", nil)
+
+	return broker.KafkaConfig{
+		Brokers:           brokers,
+		ClientID:          config.GetString("KAFKA_CLIENT_ID", "produtos-api"),
+		Partitions:        int32(config.GetInt("KAFKA_TOPIC_PARTITIONS", 6)),
+		ReplicationFactor: int16(config.GetInt("KAFKA_TOPIC_REPLICATION_FACTOR", 1)),
+	}, len(brokers) > 0
+}
+
+// newNotificationService cria o serviço de notificações com a fila de e-mails no banco informado. O transporte
+// é definido por MAIL_TRANSPORT: smtp (SMTP_HOST, SMTP_PORT, SMTP_USERNAME e SMTP_PASSWORD), file, que grava
+// os e-mails como arquivos .eml em MAIL_DIR, ou memory
+func newNotificationService(db *gorm.DB) *services.NotificationServiceRepo {
+	transport, err := mail.NewTransport(mail.Config{
+		Transport: config.GetString("MAIL_TRANSPORT", mail.TransportFile),
+		Host:      config.GetString("SMTP_HOST", ""),
+		Port:      config.GetInt("SMTP_PORT", 587),
+		Username:  config.GetString("SMTP_USERNAME", ""),
+		Password:  config.GetString("SMTP_PASSWORD", ""),
+		Dir:       config.GetString("MAIL_DIR", "mail"),
+		Timeout:   config.GetDuration("SMTP_TIMEOUT", 10*time.Second),
+	})
+	if err != nil {
+		fatal("Failed to create mail transport", err)
+	}
 
-	return router
+	return services.NewNotificationService(repositories.NewMailRepository(db), transport, services.NotificationConfig{
+		From:        config.GetString("MAIL_FROM", "Loja <no-reply@example.com>"),
+		MaxAttempts: config.GetInt("MAIL_MAX_ATTEMPTS", 10),
+		Retention:   config.GetDuration("MAIL_RETENTION", 30*24*time.Hour),
+	})
 }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"produtos-api/src/config"
+	"produtos-api/src/logging"
+	"produtos-api/src/services"
+)
+
+var logger = logging.Logger("server")
+
+// Setup monta o handler da aplicação; os workers em segundo plano rodam até o cancelamento de ctx
+// e são acompanhados por workers, que Run aguarda ao desligar
+type Setup func(ctx context.Context, healthService services.HealthService, workers *sync.WaitGroup) http.Handler
+
+// Run sobe o servidor HTTP em addr e o desliga no SIGINT/SIGTERM: drena por SHUTDOWN_DRAIN_DELAY,
+// termina as requisições em andamento, para os workers e chama shutdown, tudo dentro de SHUTDOWN_TIMEOUT.
+// Só retorna erro quando o servidor não consegue subir.
+func Run(addr string, setup Setup, shutdown ...func(context.Context) error) error {
+	// Os workers em segundo plano param depois que o servidor termina as requisições em andamento
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	healthService := services.NewHealthService(config.GetDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second))
+	var workers sync.WaitGroup
+	server := &http.Server{Addr: addr, Handler: setup(workersCtx, healthService, &workers)}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, 1)
+	go func() {
+		logger.Info("Server is running", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-failed:
+		return err
+	}
+
+	// Durante a drenagem o /readyz passa a falhar para que o orquestrador retire a instância do balanceador
+	healthService.Drain()
+	logger.Info("Draining connections...")
+	time.Sleep(config.GetDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.GetDuration("SHUTDOWN_TIMEOUT", 15*time.Second))
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server shutdown failed", "error", err)
+	}
+	stopWorkers()
+	if err := waitWorkers(shutdownCtx, &workers); err != nil {
+		logger.Error("Workers shutdown failed", "error", err)
+	}
+	for _, fn := range shutdown {
+		if err := fn(shutdownCtx); err != nil {
+			logger.Error("Shutdown failed", "error", err)
+		}
+	}
+	logger.Info("Server stopped")
+	return nil
+}
+
+// waitWorkers espera os workers terminarem, por exemplo saindo do grupo de consumidores do Kafka, até o fim de ctx
+func waitWorkers(ctx context.Context, workers *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+)
+
+// Códigos dos erros de chaves de API
+const (
+	CodeAPIKeyNotFound = "api_key_not_found"
+	CodeAPIKeyRevoked  = "api_key_revoked"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.IssuedAPIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uint) (*models.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+}
+
+type APIKeyServiceRepo struct {
+	repo   repositories.APIKeyRepository
+	policy auth.Policy
+}
+
+// NewAPIKeyService cria o serviço de chaves de API; os escopos concedidos são validados contra a política de rotas
+func NewAPIKeyService(repo repositories.APIKeyRepository, policy auth.Policy) *APIKeyServiceRepo {
+	return &APIKeyServiceRepo{repo: repo, policy: policy}
+}
+
+// CreateAPIKey valida e cadastra a chave; a chave em texto puro só é retornada nesta chamada
+func (s *APIKeyServiceRepo) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.IssuedAPIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer span.End()
+
+	if err := s.validateAPIKey(key); err != nil {
+		return nil, err
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key.ID = 0
+	key.Prefix = prefix
+	key.Hash = hash
+	key.LastUsedAt, key.RotatedAt, key.RevokedAt = nil, nil, nil
+	key.CreatedBy = ""
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		key.CreatedBy = principal.Subject
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, translateAPIKeyError(err)
+	}
+
+	return &models.IssuedAPIKey{APIKey: *key, Key: plain}, nil
+}
+
+func (s *APIKeyServiceRepo) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.GetAPIKeys")
+	defer span.End()
+
+	keys, err := s.repo.GetAPIKeys(ctx)
+	return keys, translateAPIKeyError(err)
+}
+
+// RotateAPIKey gera uma nova chave para o mesmo cadastro; a chave anterior deixa de ser aceita imediatamente
+func (s *APIKeyServiceRepo) RotateAPIKey(ctx context.Context, id uint) (*models.IssuedAPIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.RotateAPIKey")
+	defer span.End()
+
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, translateAPIKeyError(err)
+	}
+	if key.RevokedAt != nil {
+		return nil, ConflictError(CodeAPIKeyRevoked, "API key has been revoked", nil)
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key.Prefix = prefix
+	key.Hash = hash
+	key.RotatedAt = &now
+
+	if err := s.repo.SaveAPIKey(ctx, key); err != nil {
+		return nil, translateAPIKeyError(err)
+	}
+
+	return &models.IssuedAPIKey{APIKey: *key, Key: plain}, nil
+}
+
+// RevokeAPIKey revoga a chave; revogar uma chave já revogada não altera a data original
+func (s *APIKeyServiceRepo) RevokeAPIKey(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer span.End()
+
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return translateAPIKeyError(err)
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+
+	return translateAPIKeyError(s.repo.SaveAPIKey(ctx, key))
+}
+
+// validateAPIKey aplica as regras declaradas no modelo, os escopos concedíveis e a expiração futura
+func (s *APIKeyServiceRepo) validateAPIKey(key *models.APIKey) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(key) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	allowed := s.policy.APIKeyScopes()
+	if len(key.Scopes) == 0 {
+		fields = append(fields, fieldError("scopes", "required", ""))
+	}
+	for _, scope := range key.Scopes {
+		if !slices.Contains(allowed, scope) {
+			fields = append(fields, fieldError("scopes", "scope", scope))
+		}
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		fields = append(fields, fieldError("expires_at", "future", ""))
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+func translateAPIKeyError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return NotFoundError(CodeAPIKeyNotFound, "API key not found", err)
+	}
+
+	return translateProductError(err)
+}
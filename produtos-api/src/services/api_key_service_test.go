@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+func TestServiceCreateAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, testPolicy())
+
+	mockRepo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []auth.Role{auth.RoleAdmin}})
+	issued, err := service.CreateAPIKey(ctx, &models.APIKey{Name: "orders-service", Scopes: []string{"products:write"}, Hash: "ignored"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "root", issued.CreatedBy)
+	prefix, ok := auth.APIKeyPrefix(issued.Key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, issued.Prefix)
+	assert.True(t, auth.MatchAPIKey(issued.Key, issued.Hash))
+	assert.NotContains(t, issued.Hash, issued.Key)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceCreateAPIKeyValidation(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, testPolicy())
+
+	past := time.Now().Add(-time.Minute)
+	_, err := service.CreateAPIKey(context.Background(), &models.APIKey{
+		Name:      "orders-service",
+		Scopes:    []string{"products:write", "api-keys:write"},
+		ExpiresAt: &past,
+	})
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Equal(t, []FieldError{
+		{Field: "scopes", Code: "scope", Param: "api-keys:write", Message: "scopes contains an unknown scope: api-keys:write"},
+		{Field: "expires_at", Code: "future", Message: "expires_at must be in the future"},
+	}, domainErr.Fields)
+	mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestServiceRotateAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, testPolicy())
+
+	stored := &models.APIKey{ID: 1, Name: "orders-service", Prefix: "aaaaaaaaaaaa", Hash: "old"}
+	mockRepo.On("GetAPIKeyByID", mock.Anything, uint(1)).Return(stored, nil)
+	mockRepo.On("SaveAPIKey", mock.Anything, stored).Return(nil)
+
+	issued, err := service.RotateAPIKey(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "aaaaaaaaaaaa", issued.Prefix)
+	assert.True(t, auth.MatchAPIKey(issued.Key, stored.Hash))
+	assert.NotNil(t, stored.RotatedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceRotateRevokedAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, testPolicy())
+
+	revokedAt := time.Now()
+	mockRepo.On("GetAPIKeyByID", mock.Anything, uint(1)).Return(&models.APIKey{ID: 1, RevokedAt: &revokedAt}, nil)
+
+	_, err := service.RotateAPIKey(context.Background(), 1)
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeAPIKeyRevoked, domainErr.Code)
+	mockRepo.AssertNotCalled(t, "SaveAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestServiceRevokeAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, testPolicy())
+
+	stored := &models.APIKey{ID: 1}
+	mockRepo.On("GetAPIKeyByID", mock.Anything, uint(1)).Return(stored, nil)
+	mockRepo.On("SaveAPIKey", mock.Anything, stored).Return(nil).Once()
+	mockRepo.On("GetAPIKeyByID", mock.Anything, uint(2)).Return(&models.APIKey{}, repositories.ErrNotFound)
+
+	assert.NoError(t, service.RevokeAPIKey(context.Background(), 1))
+	assert.NotNil(t, stored.RevokedAt)
+
+	// Revogar novamente não altera a chave
+	assert.NoError(t, service.RevokeAPIKey(context.Background(), 1))
+
+	domainErr, _ := AsDomainError(service.RevokeAPIKey(context.Background(), 2))
+	assert.Equal(t, CodeAPIKeyNotFound, domainErr.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"produtos-api/src/audit"
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+)
+
+// Códigos dos erros de auditoria
+const CodeConcurrentModification = "concurrent_modification"
+
+// Limites da consulta de auditoria e tamanho do lote da verificação da cadeia
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+	auditChainBatch   = 500
+)
+
+// Tipos de entidade auditados
+const AuditEntityProduct = "product"
+
+// anonymousActor identifica mudanças feitas sem principal autenticado
+const anonymousActor = "anonymous"
+
+type AuditService interface {
+	FindAuditRecords(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditRecord, error)
+	VerifyAuditChain(ctx context.Context) (audit.Verification, error)
+}
+
+type AuditServiceRepo struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) *AuditServiceRepo {
+	return &AuditServiceRepo{repo: repo}
+}
+
+// FindAuditRecords consulta os registros por entidade, ator e intervalo [From, To)
+func (s *AuditServiceRepo) FindAuditRecords(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditRecord, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.FindAuditRecords")
+	defer span.End()
+
+	var fields []FieldError
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAuditLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxAuditLimit {
+		fields = append(fields, fieldError("limit", "invalid", ""))
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		fields = append(fields, fieldError("to", "invalid", ""))
+	}
+	if len(fields) > 0 {
+		return nil, ValidationError(fields...)
+	}
+
+	// Os horários são gravados em UTC e comparados como texto pelo SQLite
+	filter.From, filter.To = filter.From.UTC(), filter.To.UTC()
+
+	records, err := s.repo.FindAuditRecords(ctx, filter)
+	return records, translateAuditError(err)
+}
+
+// VerifyAuditChain percorre toda a cadeia em lotes e informa o primeiro registro adulterado
+func (s *AuditServiceRepo) VerifyAuditChain(ctx context.Context) (audit.Verification, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.VerifyAuditChain")
+	defer span.End()
+
+	verifier := audit.NewVerifier()
+	var afterID uint
+	for {
+		records, err := s.repo.GetAuditChain(ctx, afterID, auditChainBatch)
+		if err != nil {
+			return verifier.Result, translateAuditError(err)
+		}
+
+		valid, err := verifier.Add(records)
+		if err != nil {
+			return verifier.Result, err
+		}
+		if !valid || len(records) < auditChainBatch {
+			return verifier.Result, nil
+		}
+		afterID = records[len(records)-1].ID
+	}
+}
+
+// auditIgnoredFields são campos que mudam a cada gravação e não precisam constar no diff
+var auditIgnoredFields = []string{"updated_at"}
+
+// recordAudit encadeia um registro de auditoria da mudança; deve ser chamado dentro da
+// transação da mudança para que ambos sejam gravados ou descartados juntos
+func recordAudit(ctx context.Context, repo repositories.AuditRepository, entityType string, entityID uint, action string, before, after interface{}) error {
+	changes, err := audit.Diff(before, after, auditIgnoredFields...)
+	if err != nil {
+		return err
+	}
+
+	actor := anonymousActor
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		actor = principal.Subject
+	}
+
+	prevHash, err := repo.LastAuditHash(ctx)
+	if err != nil {
+		return translateAuditError(err)
+	}
+
+	record := &models.AuditRecord{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      actor,
+		RequestID:  logging.RequestID(ctx),
+		Changes:    changes,
+		OccurredAt: time.Now().UTC().Truncate(audit.Precision),
+		PrevHash:   prevHash,
+	}
+	if record.Hash, err = audit.Hash(record); err != nil {
+		return err
+	}
+
+	return translateAuditError(repo.CreateAuditRecord(ctx, record))
+}
+
+func translateAuditError(err error) error {
+	if errors.Is(err, repositories.ErrDuplicated) {
+		return ConflictError(CodeConcurrentModification, "The catalog was modified concurrently, retry the request", err)
+	}
+
+	return translateProductError(err)
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"produtos-api/src/audit"
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) LastAuditHash(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuditRepository) CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) FindAuditRecords(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditRecord, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditRecord), args.Error(1)
+}
+
+func (m *MockAuditRepository) GetAuditChain(ctx context.Context, afterID uint, limit int) ([]models.AuditRecord, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]models.AuditRecord), args.Error(1)
+}
+
+func TestServiceUpdateProductRecordsAudit(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	product := &models.Product{ID: 1, Name: "Caneta", Category: "papelaria", Price: 3.5, Stock: 10}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Category: "papelaria", Price: 2.5, Stock: 10}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "papelaria", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, product).Return(nil)
+
+	var recorded *models.AuditRecord
+	auditRepo.On("LastAuditHash", mock.Anything).Return("previous-hash", nil)
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.AnythingOfType("*models.AuditRecord")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.AuditRecord) }).Return(nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleEditor}})
+	ctx = logging.WithRequestID(ctx, "req-1")
+	err := productService.UpdateProduct(ctx, product)
+
+	assert.NoError(t, err)
+	assert.Equal(t, AuditEntityProduct, recorded.EntityType)
+	assert.Equal(t, uint(1), recorded.EntityID)
+	assert.Equal(t, models.AuditActionUpdate, recorded.Action)
+	assert.Equal(t, "maria", recorded.Actor)
+	assert.Equal(t, "req-1", recorded.RequestID)
+	assert.Equal(t, []models.FieldChange{{Field: "price", Before: 2.5, After: 3.5}}, recorded.Changes)
+	assert.Equal(t, "previous-hash", recorded.PrevHash)
+	hash, _ := audit.Hash(recorded)
+	assert.Equal(t, hash, recorded.Hash)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestServiceFailedMutationIsNotAudited(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	product := &models.Product{Name: "Caneta", Price: 2.5}
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(0)).Return(false, nil)
+	mockRepo.On("CreateProduct", mock.Anything, product).Return(repositories.ErrDuplicated)
+
+	err := productService.CreateProduct(context.Background(), product)
+
+	assert.Error(t, err)
+	auditRepo.AssertNotCalled(t, "CreateAuditRecord", mock.Anything, mock.Anything)
+}
+
+func TestServiceAuditChainConflict(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta"}, nil)
+	mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+	auditRepo.On("LastAuditHash", mock.Anything).Return("previous-hash", nil)
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.Anything).Return(repositories.ErrDuplicated)
+
+	err := productService.DeleteProduct(context.Background(), 1)
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeConcurrentModification, domainErr.Code)
+}
+
+func TestServiceFindAuditRecordsValidation(t *testing.T) {
+	auditRepo := new(MockAuditRepository)
+	auditService := NewAuditService(auditRepo)
+
+	now := time.Now()
+	_, err := auditService.FindAuditRecords(context.Background(), repositories.AuditFilter{From: now, To: now.Add(-time.Hour), Limit: 5000})
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Len(t, domainErr.Fields, 2)
+
+	auditRepo.On("FindAuditRecords", mock.Anything, mock.MatchedBy(func(filter repositories.AuditFilter) bool {
+		return filter.Limit == DefaultAuditLimit && filter.Actor == "maria"
+	})).Return([]models.AuditRecord{{ID: 1}}, nil)
+
+	records, err := auditService.FindAuditRecords(context.Background(), repositories.AuditFilter{Actor: "maria"})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestServiceVerifyAuditChain(t *testing.T) {
+	chain := make([]models.AuditRecord, 3)
+	prevHash := ""
+	for i := range chain {
+		chain[i] = models.AuditRecord{
+			ID:         uint(i + 1),
+			EntityType: AuditEntityProduct,
+			EntityID:   1,
+			Action:     models.AuditActionUpdate,
+			Actor:      "maria",
+			Changes:    []models.FieldChange{{Field: "price", Before: float64(i), After: float64(i + 1)}},
+			OccurredAt: time.Date(2026, 10, 19, 12, i, 0, 0, time.UTC),
+			PrevHash:   prevHash,
+		}
+		chain[i].Hash, _ = audit.Hash(&chain[i])
+		prevHash = chain[i].Hash
+	}
+
+	auditRepo := new(MockAuditRepository)
+	auditService := NewAuditService(auditRepo)
+	auditRepo.On("GetAuditChain", mock.Anything, uint(0), auditChainBatch).Return(chain, nil).Once()
+
+	result, err := auditService.VerifyAuditChain(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 3, result.Checked)
+
+	// Alterar o conteúdo de um registro quebra a cadeia a partir dele
+	chain[1].Changes[0].After = 100.0
+	auditRepo.On("GetAuditChain", mock.Anything, uint(0), auditChainBatch).Return(chain, nil).Once()
+
+	result, err = auditService.VerifyAuditChain(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint(2), *result.FirstInvalid)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/repositories"
+)
+
+// Códigos dos erros de autenticação e autorização
+const (
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeTicketsUnavailable = "tickets_unavailable"
+)
+
+// lastUsedResolution limita a frequência de gravação do último uso das chaves de API
+const lastUsedResolution = time.Minute
+
+type AuthService interface {
+	AuthenticateBearer(ctx context.Context, token string) (*auth.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+	AuthenticateTicket(ctx context.Context, ticket, method, route string) (*auth.Principal, error)
+	Authorize(principal *auth.Principal, method, route string) error
+}
+
+type AuthServiceRepo struct {
+	keys    *auth.KeySet
+	policy  auth.Policy
+	apiKeys repositories.APIKeyRepository
+	tickets *auth.Tickets
+	now     func() time.Time
+}
+
+// NewAuthService cria o serviço que valida tokens JWT e chaves de API e aplica a política de acesso por rota.
+// Sem apiKeys, como nos microsserviços que não emitem chaves, apenas tokens JWT são aceitos.
+func NewAuthService(keys *auth.KeySet, policy auth.Policy, apiKeys repositories.APIKeyRepository) *AuthServiceRepo {
+	return &AuthServiceRepo{keys: keys, policy: policy, apiKeys: apiKeys, now: time.Now}
+}
+
+// WithTickets habilita a emissão de tickets e a sua validação nas rotas de Policy.TicketRoutes
+func (s *AuthServiceRepo) WithTickets(tickets auth.Tickets) *AuthServiceRepo {
+	s.tickets = &tickets
+	return s
+}
+
+// AuthenticateBearer valida o token e retorna o principal com os papéis reconhecidos
+func (s *AuthServiceRepo) AuthenticateBearer(ctx context.Context, token string) (*auth.Principal, error) {
+	_, span := tracer.Start(ctx, "AuthService.AuthenticateBearer")
+	defer span.End()
+
+	claims, err := s.keys.Parse(token)
+	if err != nil {
+		return nil, UnauthorizedError("Invalid or expired token", err)
+	}
+
+	principal := &auth.Principal{Subject: claims.Subject, Method: auth.MethodJWT}
+	for _, role := range claims.Roles {
+		if role.Valid() {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+
+	return principal, nil
+}
+
+// IssueTicket emite um ticket com o principal da requisição, para as conexões que não enviam headers
+func (s *AuthServiceRepo) IssueTicket(ctx context.Context, principal *auth.Principal) (string, time.Time, error) {
+	_, span := tracer.Start(ctx, "AuthService.IssueTicket")
+	defer span.End()
+
+	if principal == nil {
+		return "", time.Time{}, UnauthorizedError("Authentication required", nil)
+	}
+	if s.tickets == nil {
+		return "", time.Time{}, UnavailableError(CodeTicketsUnavailable, "Tickets are not enabled", nil)
+	}
+
+	return s.tickets.Issue(principal, s.now())
+}
+
+// AuthenticateTicket valida o ticket recebido na query de uma rota de Policy.TicketRoutes e retorna o
+// principal que o emitiu; nas demais rotas, o ticket é rejeitado
+func (s *AuthServiceRepo) AuthenticateTicket(ctx context.Context, ticket, method, route string) (*auth.Principal, error) {
+	_, span := tracer.Start(ctx, "AuthService.AuthenticateTicket")
+	defer span.End()
+
+	if s.tickets == nil || !s.policy.TicketRoutes[method+" "+route] {
+		return nil, UnauthorizedError("Tickets are not accepted on this route", nil)
+	}
+
+	principal, err := s.tickets.Parse(ticket)
+	if err != nil {
+		return nil, UnauthorizedError("Invalid or expired ticket", err)
+	}
+
+	return principal, nil
+}
+
+// AuthenticateAPIKey valida a chave de API e retorna o principal com os escopos da chave.
+// O último uso é gravado no máximo uma vez por lastUsedResolution.
+func (s *AuthServiceRepo) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.AuthenticateAPIKey")
+	defer span.End()
+
+	prefix, ok := auth.APIKeyPrefix(key)
+	if !ok || s.apiKeys == nil {
+		return nil, UnauthorizedError("Invalid API key", nil)
+	}
+
+	stored, err := s.apiKeys.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, UnauthorizedError("Invalid API key", err)
+	}
+	if err != nil {
+		return nil, translateAPIKeyError(err)
+	}
+
+	now := time.Now()
+	switch {
+	case !auth.MatchAPIKey(key, stored.Hash):
+		return nil, UnauthorizedError("Invalid API key", nil)
+	case stored.RevokedAt != nil:
+		return nil, UnauthorizedError("API key has been revoked", nil)
+	case stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt):
+		return nil, UnauthorizedError("API key has expired", nil)
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= lastUsedResolution {
+		// Falha ao registrar o uso não impede a requisição
+		if err := s.apiKeys.TouchAPIKey(ctx, stored.ID, now); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	return &auth.Principal{Subject: "api_key:" + stored.Name, Scopes: stored.Scopes, Method: auth.MethodAPIKey}, nil
+}
+
+// Authorize verifica se o principal (nil para anônimo) pode acessar a rota: usuários pelo papel
+// exigido e chaves de API pelo escopo da rota. Rotas de admin não aceitam chaves de API.
+func (s *AuthServiceRepo) Authorize(principal *auth.Principal, method, route string) error {
+	required := s.policy.Required(method, route)
+	if required == auth.RolePublic {
+		return nil
+	}
+	if principal == nil {
+		return UnauthorizedError("Authentication required", nil)
+	}
+
+	if principal.Method == auth.MethodAPIKey {
+		if auth.RoleEditor.Includes(required) && principal.HasScope(auth.ScopeFor(method, route)) {
+			return nil
+		}
+	} else if principal.HasRole(required) {
+		return nil
+	}
+
+	return ForbiddenError("Insufficient permissions", nil)
+}
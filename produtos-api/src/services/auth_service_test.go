@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testSecret = []byte("test-secret")
+
+func newTestAuthService(keys *auth.KeySet, apiKeys repositories.APIKeyRepository) *AuthServiceRepo {
+	return NewAuthService(keys, testPolicy(), apiKeys)
+}
+
+func testPolicy() auth.Policy {
+	return auth.Policy{
+		Default:     auth.RoleAdmin,
+		PublicReads: true,
+		Routes: map[string]auth.Role{
+			"GET /products":         auth.RoleViewer,
+			"POST /products":        auth.RoleEditor,
+			"PUT /products/{id}":    auth.RoleEditor,
+			"DELETE /products/{id}": auth.RoleAdmin,
+			"GET /api-keys":         auth.RoleAdmin,
+		},
+	}
+}
+
+func TestServiceAuthenticateBearerHMAC(t *testing.T) {
+	authService := newTestAuthService(&auth.KeySet{HMACSecret: testSecret}, nil)
+
+	token, err := auth.Mint(testSecret, "maria", []auth.Role{auth.RoleEditor, "unknown"}, time.Hour, "", "")
+	assert.NoError(t, err)
+
+	principal, err := authService.AuthenticateBearer(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, "maria", principal.Subject)
+	assert.Equal(t, []auth.Role{auth.RoleEditor}, principal.Roles)
+}
+
+func TestServiceAuthenticateBearerRejectsInvalidTokens(t *testing.T) {
+	authService := newTestAuthService(&auth.KeySet{HMACSecret: testSecret, Issuer: "produtos-api"}, nil)
+
+	expired, _ := auth.Mint(testSecret, "maria", []auth.Role{auth.RoleAdmin}, -time.Hour, "produtos-api", "")
+	wrongSecret, _ := auth.Mint([]byte("other"), "maria", []auth.Role{auth.RoleAdmin}, time.Hour, "produtos-api", "")
+	wrongIssuer, _ := auth.Mint(testSecret, "maria", []auth.Role{auth.RoleAdmin}, time.Hour, "someone-else", "")
+
+	for name, token := range map[string]string{"expired": expired, "wrong secret": wrongSecret, "wrong issuer": wrongIssuer, "garbage": "abc"} {
+		_, err := authService.AuthenticateBearer(context.Background(), token)
+		domainErr, ok := AsDomainError(err)
+		assert.True(t, ok, name)
+		assert.Equal(t, KindUnauthorized, domainErr.Kind, name)
+	}
+}
+
+func TestServiceAuthenticateBearerJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","n":%q,"e":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+
+	publicKeys, err := auth.LoadJWKS(path)
+	assert.NoError(t, err)
+	authService := newTestAuthService(&auth.KeySet{PublicKeys: publicKeys}, nil)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.Claims{
+		Roles:            []auth.Role{auth.RoleViewer},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "orders", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(privateKey)
+	assert.NoError(t, err)
+
+	principal, err := authService.AuthenticateBearer(context.Background(), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", principal.Subject)
+
+	// Tokens HMAC não são aceitos quando apenas o JWKS está configurado
+	hmacToken, _ := auth.Mint(testSecret, "maria", []auth.Role{auth.RoleAdmin}, time.Hour, "", "")
+	_, err = authService.AuthenticateBearer(context.Background(), hmacToken)
+	assert.Error(t, err)
+}
+
+func TestServiceAuthorize(t *testing.T) {
+	authService := newTestAuthService(&auth.KeySet{HMACSecret: testSecret}, nil)
+
+	viewer := &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleViewer}}
+	editor := &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleEditor}}
+	admin := &auth.Principal{Subject: "root", Roles: []auth.Role{auth.RoleAdmin}}
+
+	assert.NoError(t, authService.Authorize(nil, "GET", "/products"))
+	assert.NoError(t, authService.Authorize(editor, "POST", "/products"))
+	assert.NoError(t, authService.Authorize(admin, "POST", "/products"))
+	assert.NoError(t, authService.Authorize(admin, "DELETE", "/products/{id}"))
+
+	domainErr, _ := AsDomainError(authService.Authorize(nil, "POST", "/products"))
+	assert.Equal(t, KindUnauthorized, domainErr.Kind)
+
+	domainErr, _ = AsDomainError(authService.Authorize(viewer, "POST", "/products"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+
+	domainErr, _ = AsDomainError(authService.Authorize(editor, "DELETE", "/products/{id}"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+
+	// Rotas sem política exigem o papel padrão
+	domainErr, _ = AsDomainError(authService.Authorize(editor, "GET", "/unmapped"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+}
+
+func TestServiceAuthenticateAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	authService := newTestAuthService(&auth.KeySet{}, mockRepo)
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+	stored := &models.APIKey{ID: 1, Name: "orders-service", Prefix: prefix, Hash: hash, Scopes: []string{"products:write"}}
+
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, prefix).Return(stored, nil)
+	mockRepo.On("TouchAPIKey", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	principal, err := authService.AuthenticateAPIKey(context.Background(), plain)
+	assert.NoError(t, err)
+	assert.Equal(t, "api_key:orders-service", principal.Subject)
+	assert.Equal(t, auth.MethodAPIKey, principal.Method)
+	assert.Equal(t, []string{"products:write"}, principal.Scopes)
+
+	// O último uso recente não é gravado novamente
+	recently := time.Now().Add(-time.Second)
+	stored.LastUsedAt = &recently
+	_, err = authService.AuthenticateAPIKey(context.Background(), plain)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceAuthenticateAPIKeyRejectsInvalidKeys(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	authService := newTestAuthService(&auth.KeySet{}, mockRepo)
+
+	past := time.Now().Add(-time.Hour)
+	revoked, revokedPrefix, revokedHash, _ := auth.GenerateAPIKey()
+	expired, expiredPrefix, expiredHash, _ := auth.GenerateAPIKey()
+	unknown, unknownPrefix, _, _ := auth.GenerateAPIKey()
+	_, otherPrefix, otherHash, _ := auth.GenerateAPIKey()
+	forged := "pak_" + otherPrefix + revoked[len("pak_")+len(revokedPrefix):]
+
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, revokedPrefix).Return(&models.APIKey{Hash: revokedHash, RevokedAt: &past}, nil)
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, expiredPrefix).Return(&models.APIKey{Hash: expiredHash, ExpiresAt: &past}, nil)
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, unknownPrefix).Return(&models.APIKey{}, repositories.ErrNotFound)
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, otherPrefix).Return(&models.APIKey{Hash: otherHash}, nil)
+
+	for name, key := range map[string]string{"revoked": revoked, "expired": expired, "unknown": unknown, "forged": forged, "malformed": "pak_123"} {
+		_, err := authService.AuthenticateAPIKey(context.Background(), key)
+		domainErr, ok := AsDomainError(err)
+		assert.True(t, ok, name)
+		assert.Equal(t, KindUnauthorized, domainErr.Kind, name)
+	}
+
+	mockRepo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceAuthorizeAPIKeyScopes(t *testing.T) {
+	authService := newTestAuthService(&auth.KeySet{}, nil)
+
+	writer := &auth.Principal{Subject: "api_key:orders", Scopes: []string{"products:write"}, Method: auth.MethodAPIKey}
+
+	assert.NoError(t, authService.Authorize(writer, "POST", "/products"))
+	assert.NoError(t, authService.Authorize(writer, "PUT", "/products/{id}"))
+
+	// Rotas de admin nunca aceitam chaves de API
+	domainErr, _ := AsDomainError(authService.Authorize(writer, "DELETE", "/products/{id}"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+	domainErr, _ = AsDomainError(authService.Authorize(writer, "GET", "/api-keys"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+
+	reader := &auth.Principal{Subject: "api_key:reports", Scopes: []string{"products:read"}, Method: auth.MethodAPIKey}
+	domainErr, _ = AsDomainError(authService.Authorize(reader, "POST", "/products"))
+	assert.Equal(t, KindForbidden, domainErr.Kind)
+
+	assert.Equal(t, []string{"products:read", "products:write"}, testPolicy().APIKeyScopes())
+}
+
+func TestServiceIssueTicket(t *testing.T) {
+	policy := testPolicy()
+	policy.TicketRoutes = map[string]bool{"GET /products/stream": true}
+	authService := NewAuthService(&auth.KeySet{HMACSecret: testSecret}, policy, nil).
+		WithTickets(auth.Tickets{Secret: testSecret, TTL: 30 * time.Second})
+	now := time.Now()
+	authService.now = func() time.Time { return now }
+
+	key := &auth.Principal{Subject: "api_key:painel", Scopes: []string{"products:read"}, Method: auth.MethodAPIKey}
+	ticket, expiresAt, err := authService.IssueTicket(context.Background(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(30*time.Second), expiresAt)
+
+	// O ticket leva o principal completo, autorizado como a requisição que o emitiu
+	principal, err := authService.AuthenticateTicket(context.Background(), ticket, "GET", "/products/stream")
+	assert.NoError(t, err)
+	assert.Equal(t, key, principal)
+
+	// O ticket não é um token da API
+	_, err = authService.AuthenticateBearer(context.Background(), ticket)
+	assert.Error(t, err)
+
+	_, _, err = authService.IssueTicket(context.Background(), nil)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindUnauthorized, domainErr.Kind)
+
+	_, _, err = newTestAuthService(&auth.KeySet{HMACSecret: testSecret}, nil).IssueTicket(context.Background(), key)
+	domainErr, ok = AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeTicketsUnavailable, domainErr.Code)
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"produtos-api/src/events"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrStreamClosed indica que o stream foi encerrado porque a aplicação está sendo desligada
+var ErrStreamClosed = errors.New("change stream closed")
+
+// changeStreamBatchSize limita os eventos lidos da outbox por consulta e as mudanças entregues por chamada de Next
+const changeStreamBatchSize = 100
+
+// ChangeFilter seleciona as mudanças entregues a uma assinatura; campos vazios não filtram
+type ChangeFilter struct {
+	ProductIDs []uint
+	Category   string
+}
+
+// Matches informa se a mudança atende ao filtro
+func (f ChangeFilter) Matches(change *models.ProductChange) bool {
+	if len(f.ProductIDs) > 0 && !slices.Contains(f.ProductIDs, change.ProductID) {
+		return false
+	}
+
+	return f.Category == "" || f.Category == change.Category
+}
+
+type ChangeStreamService interface {
+	Subscribe(ctx context.Context, filter ChangeFilter, lastEventID *uint) (ChangeSubscription, error)
+}
+
+// ChangeSubscription entrega as mudanças do catálogo a um cliente, a partir da sua posição no stream
+type ChangeSubscription interface {
+	// Next aguarda e retorna as próximas mudanças que atendem ao filtro
+	Next(ctx context.Context) ([]models.ProductChange, error)
+	// Expired informa que a posição pedida já saiu da outbox; a assinatura começa nas mudanças novas
+	Expired() bool
+}
+
+// ChangeStreamServiceRepo lê as mudanças de produtos gravadas na outbox, cuja sequência é a posição do stream.
+// As mudanças recentes ficam num buffer em memória compartilhado pelas assinaturas; cada assinatura avança no
+// seu ritmo, e as que ficam para trás do buffer leem da outbox. Assim um cliente lento não acumula memória
+// nem atrasa os demais.
+type ChangeStreamServiceRepo struct {
+	outbox   repositories.OutboxRepository
+	products repositories.ProductRepository
+	capacity int
+
+	mu         sync.RWMutex
+	started    bool
+	head       uint                   // maior posição lida da outbox
+	bufferFrom uint                   // o buffer contém todas as mudanças com posição maior que bufferFrom até head
+	buffer     []models.ProductChange // mudanças recentes, em ordem de posição
+	notify     chan struct{}          // fechado e substituído a cada leitura da outbox
+	categories map[uint]string        // categoria mais recente de cada produto, usada em StockChanged
+	closed     chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewChangeStreamService cria o serviço do stream de mudanças; capacity é a quantidade de mudanças mantidas em memória
+func NewChangeStreamService(outbox repositories.OutboxRepository, products repositories.ProductRepository, capacity int) *ChangeStreamServiceRepo {
+	return &ChangeStreamServiceRepo{
+		outbox:     outbox,
+		products:   products,
+		capacity:   max(capacity, 1),
+		notify:     make(chan struct{}),
+		categories: map[uint]string{},
+		closed:     make(chan struct{}),
+	}
+}
+
+// Subscribe cria uma assinatura a partir de lastEventID, ou das próximas mudanças quando ele não é informado
+func (s *ChangeStreamServiceRepo) Subscribe(ctx context.Context, filter ChangeFilter, lastEventID *uint) (ChangeSubscription, error) {
+	ctx, span := tracer.Start(ctx, "ChangeStreamService.Subscribe")
+	defer span.End()
+
+	if err := s.start(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	head, bufferFrom := s.head, s.bufferFrom
+	s.mu.RUnlock()
+
+	subscription := &changeSubscription{service: s, filter: filter, position: head, categories: map[uint]string{}}
+	if lastEventID == nil {
+		return subscription, nil
+	}
+	span.SetAttributes(attribute.Int("stream.last_event_id", int(*lastEventID)))
+
+	if *lastEventID >= bufferFrom {
+		subscription.position = *lastEventID
+		return subscription, nil
+	}
+
+	// Fora do buffer, a posição só pode ser retomada se os eventos seguintes ainda estiverem na outbox
+	oldest, _, err := s.outbox.GetOutboxBounds(ctx)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+	if oldest == 0 || *lastEventID+1 < oldest {
+		subscription.expired = true
+		return subscription, nil
+	}
+	subscription.position = *lastEventID
+
+	return subscription, nil
+}
+
+// Poll lê os eventos gravados na outbox desde a leitura anterior, guarda as mudanças de produtos no buffer
+// e acorda as assinaturas; retorna quantas mudanças foram lidas
+func (s *ChangeStreamServiceRepo) Poll(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "ChangeStreamService.Poll")
+	defer span.End()
+
+	if err := s.start(ctx); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	head := s.head
+	s.mu.RUnlock()
+
+	read := 0
+	defer func() { span.SetAttributes(attribute.Int("stream.changes", read)) }()
+	for {
+		outboxEvents, err := s.outbox.GetOutboxEvents(ctx, head, changeStreamBatchSize)
+		if err != nil {
+			return read, translateProductError(err)
+		}
+		if len(outboxEvents) == 0 {
+			return read, nil
+		}
+
+		// Poll roda num único worker, o único que usa s.categories
+		changes, err := s.toChanges(ctx, outboxEvents, s.categories)
+		if err != nil {
+			return read, err
+		}
+		head = outboxEvents[len(outboxEvents)-1].ID
+		s.append(changes, head)
+		read += len(changes)
+
+		if len(outboxEvents) < changeStreamBatchSize {
+			return read, nil
+		}
+	}
+}
+
+// Close encerra todas as assinaturas; chamado no início do desligamento para que os clientes reconectem em
+// outra instância e o servidor não espere as conexões de longa duração
+func (s *ChangeStreamServiceRepo) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+// start posiciona o stream no fim da outbox na primeira leitura
+func (s *ChangeStreamServiceRepo) start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	_, newest, err := s.outbox.GetOutboxBounds(ctx)
+	if err != nil {
+		return translateProductError(err)
+	}
+	s.head, s.bufferFrom, s.started = newest, newest, true
+
+	return nil
+}
+
+func (s *ChangeStreamServiceRepo) append(changes []models.ProductChange, head uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buffer = append(s.buffer, changes...)
+	if excess := len(s.buffer) - s.capacity; excess > 0 {
+		s.bufferFrom = s.buffer[excess-1].Position
+		s.buffer = slices.Clone(s.buffer[excess:])
+	}
+	s.head = head
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// read retorna as próximas mudanças da assinatura e avança a sua posição. Sem mudanças novas, retorna o canal
+// que será fechado na próxima leitura da outbox; um canal nil indica que há mais a ler imediatamente.
+func (s *ChangeStreamServiceRepo) read(ctx context.Context, sub *changeSubscription) ([]models.ProductChange, <-chan struct{}, error) {
+	s.mu.RLock()
+	if sub.position >= s.bufferFrom {
+		defer s.mu.RUnlock()
+
+		var changes []models.ProductChange
+		start, _ := slices.BinarySearchFunc(s.buffer, sub.position+1, func(change models.ProductChange, position uint) int {
+			return cmp.Compare(change.Position, position)
+		})
+		for _, change := range s.buffer[start:] {
+			if sub.filter.Matches(&change) {
+				changes = append(changes, change)
+			}
+			if len(changes) == changeStreamBatchSize {
+				sub.position = change.Position
+				return changes, nil, nil
+			}
+		}
+		sub.position = max(sub.position, s.head)
+
+		return changes, s.notify, nil
+	}
+	bufferFrom := s.bufferFrom
+	s.mu.RUnlock()
+
+	// A assinatura está atrás do buffer: lê diretamente da outbox
+	outboxEvents, err := s.outbox.GetOutboxEvents(ctx, sub.position, changeStreamBatchSize)
+	if err != nil {
+		return nil, nil, translateProductError(err)
+	}
+	if len(outboxEvents) == 0 {
+		sub.position = bufferFrom
+		return nil, nil, nil
+	}
+
+	changes, err := s.toChanges(ctx, outboxEvents, sub.categories)
+	if err != nil {
+		return nil, nil, err
+	}
+	sub.position = outboxEvents[len(outboxEvents)-1].ID
+
+	return slices.DeleteFunc(changes, func(change models.ProductChange) bool { return !sub.filter.Matches(&change) }), nil, nil
+}
+
+// toChanges converte os eventos de produtos da outbox nas mudanças do stream. O payload de StockChanged não
+// tem a categoria, que vem do ProductUpdated gravado antes dele na mesma transação ou, na falta dele, do produto.
+func (s *ChangeStreamServiceRepo) toChanges(ctx context.Context, outboxEvents []models.OutboxEvent, categories map[uint]string) ([]models.ProductChange, error) {
+	changes := make([]models.ProductChange, 0, len(outboxEvents))
+	for _, event := range outboxEvents {
+		if event.AggregateType != events.AggregateProduct {
+			continue
+		}
+
+		switch event.Type {
+		case events.ProductCreated, events.ProductUpdated, events.ProductDeleted:
+			var product events.Product
+			if err := json.Unmarshal([]byte(event.Payload), &product); err == nil {
+				categories[event.AggregateID] = product.Category
+			}
+		default:
+			if _, ok := categories[event.AggregateID]; !ok {
+				category, err := s.productCategory(ctx, event.AggregateID)
+				if err != nil {
+					return nil, err
+				}
+				categories[event.AggregateID] = category
+			}
+		}
+
+		changes = append(changes, models.ProductChange{
+			Position:   event.ID,
+			EventID:    event.EventID,
+			Type:       event.Type,
+			ProductID:  event.AggregateID,
+			Category:   categories[event.AggregateID],
+			OccurredAt: event.OccurredAt,
+			Data:       json.RawMessage(event.Payload),
+		})
+	}
+
+	return changes, nil
+}
+
+// productCategory busca a categoria atual do produto, inclusive na lixeira; vazia se ele já foi removido
+func (s *ChangeStreamServiceRepo) productCategory(ctx context.Context, id uint) (string, error) {
+	product, err := s.products.GetProductByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		product, err = s.products.GetDeletedProductByID(ctx, id)
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", translateProductError(err)
+	}
+
+	return product.Category, nil
+}
+
+type changeSubscription struct {
+	service    *ChangeStreamServiceRepo
+	filter     ChangeFilter
+	position   uint
+	expired    bool
+	categories map[uint]string
+}
+
+func (sub *changeSubscription) Next(ctx context.Context) ([]models.ProductChange, error) {
+	for {
+		select {
+		case <-sub.service.closed:
+			return nil, ErrStreamClosed
+		default:
+		}
+
+		changes, wait, err := sub.service.read(ctx, sub)
+		if err != nil || len(changes) > 0 {
+			return changes, err
+		}
+		if wait == nil {
+			continue
+		}
+
+		select {
+		case <-wait:
+		case <-sub.service.closed:
+			return nil, ErrStreamClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (sub *changeSubscription) Expired() bool {
+	return sub.expired
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"produtos-api/src/events"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox guarda os eventos da outbox em memória; as demais operações ficam com o mock e falham se chamadas
+type memoryOutbox struct {
+	*MockOutboxRepository
+	mu     sync.Mutex
+	events []models.OutboxEvent
+	reads  int
+}
+
+func (m *memoryOutbox) GetOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reads++
+	var list []models.OutboxEvent
+	for _, event := range m.events {
+		if event.ID > afterID && len(list) < limit {
+			list = append(list, event)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryOutbox) GetOutboxBounds(ctx context.Context) (uint, uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.events) == 0 {
+		return 0, 0, nil
+	}
+	return m.events[0].ID, m.events[len(m.events)-1].ID, nil
+}
+
+// add grava um evento com a próxima posição da outbox
+func (m *memoryOutbox) add(eventType, aggregateType string, aggregateID uint, payload interface{}) uint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uint(1)
+	if len(m.events) > 0 {
+		id = m.events[len(m.events)-1].ID + 1
+	}
+	data, _ := json.Marshal(payload)
+	m.events = append(m.events, models.OutboxEvent{
+		ID:            id,
+		EventID:       fmt.Sprintf("event-%d", id),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		OccurredAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	return id
+}
+
+// addProduct grava um evento de produto com o payload completo
+func (m *memoryOutbox) addProduct(eventType string, id uint, category string) uint {
+	return m.add(eventType, events.AggregateProduct, id, events.Product{ID: id, Name: "Produto", Category: category, Price: 10, Stock: 5})
+}
+
+func (m *memoryOutbox) addStock(id uint, previous, current int) uint {
+	return m.add(events.StockChanged, events.AggregateProduct, id, events.StockChange{ProductID: id, Previous: previous, Current: current})
+}
+
+func newChangeStreamTest(capacity int) (*ChangeStreamServiceRepo, *memoryOutbox, *MockProductRepository) {
+	outbox := &memoryOutbox{MockOutboxRepository: new(MockOutboxRepository)}
+	products := new(MockProductRepository)
+
+	return NewChangeStreamService(outbox, products, capacity), outbox, products
+}
+
+// nextChanges lê as mudanças disponíveis sem esperar pelas próximas
+func nextChanges(t *testing.T, subscription ChangeSubscription) []models.ProductChange {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	changes, err := subscription.Next(ctx)
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	require.NoError(t, err)
+	return changes
+}
+
+func positions(changes []models.ProductChange) []uint {
+	list := make([]uint, 0, len(changes))
+	for _, change := range changes {
+		list = append(list, change.Position)
+	}
+	return list
+}
+
+func TestServiceChangeStreamDeliversNewChanges(t *testing.T) {
+	service, outbox, _ := newChangeStreamTest(10)
+	outbox.addProduct(events.ProductCreated, 1, "Eletrônicos")
+
+	// Sem Last-Event-ID, a assinatura começa no fim da outbox
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{}, nil)
+	require.NoError(t, err)
+	assert.False(t, subscription.Expired())
+
+	updated := outbox.addProduct(events.ProductUpdated, 1, "Eletrônicos")
+	outbox.add(events.OrderPlaced, events.AggregateOrder, 7, events.Order{OrderID: 7})
+
+	received := make(chan []models.ProductChange)
+	go func() {
+		changes, _ := subscription.Next(context.Background())
+		received <- changes
+	}()
+
+	read, err := service.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, read)
+
+	select {
+	case changes := <-received:
+		require.Len(t, changes, 1)
+		assert.Equal(t, updated, changes[0].Position)
+		assert.Equal(t, "event-2", changes[0].EventID)
+		assert.Equal(t, events.ProductUpdated, changes[0].Type)
+		assert.Equal(t, uint(1), changes[0].ProductID)
+		assert.Equal(t, "Eletrônicos", changes[0].Category)
+		assert.JSONEq(t, outbox.events[1].Payload, string(changes[0].Data))
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not woken up by Poll")
+	}
+}
+
+func TestServiceChangeStreamFilters(t *testing.T) {
+	service, outbox, products := newChangeStreamTest(10)
+	_, err := service.Poll(context.Background())
+	require.NoError(t, err)
+
+	all, _ := service.Subscribe(context.Background(), ChangeFilter{}, nil)
+	byID, _ := service.Subscribe(context.Background(), ChangeFilter{ProductIDs: []uint{1, 3}}, nil)
+	byCategory, _ := service.Subscribe(context.Background(), ChangeFilter{Category: "Livros"}, nil)
+
+	outbox.addProduct(events.ProductCreated, 1, "Eletrônicos")
+	outbox.addProduct(events.ProductUpdated, 2, "Livros")
+	// O StockChanged gravado com o ProductUpdated herda a categoria dele; sem ele, a categoria vem do produto
+	outbox.addStock(2, 0, 3)
+	outbox.addStock(3, 4, 2)
+	products.On("GetProductByID", mock.Anything, uint(3)).Return(&models.Product{ID: 3, Category: "Livros"}, nil).Once()
+
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint{1, 2, 3, 4}, positions(nextChanges(t, all)))
+	assert.Equal(t, []uint{1, 4}, positions(nextChanges(t, byID)))
+
+	changes := nextChanges(t, byCategory)
+	assert.Equal(t, []uint{2, 3, 4}, positions(changes))
+	assert.Equal(t, events.StockChanged, changes[1].Type)
+	assert.Equal(t, "Livros", changes[1].Category)
+	products.AssertExpectations(t)
+}
+
+func TestServiceChangeStreamCategoryOfRemovedProduct(t *testing.T) {
+	service, outbox, products := newChangeStreamTest(10)
+	_, err := service.Poll(context.Background())
+	require.NoError(t, err)
+	subscription, _ := service.Subscribe(context.Background(), ChangeFilter{}, nil)
+
+	outbox.addStock(5, 1, 0)
+	outbox.addStock(6, 1, 0)
+	products.On("GetProductByID", mock.Anything, uint(5)).Return((*models.Product)(nil), repositories.ErrNotFound).Once()
+	products.On("GetDeletedProductByID", mock.Anything, uint(5)).Return(&models.Product{ID: 5, Category: "Livros"}, nil).Once()
+	products.On("GetProductByID", mock.Anything, uint(6)).Return((*models.Product)(nil), repositories.ErrNotFound).Once()
+	products.On("GetDeletedProductByID", mock.Anything, uint(6)).Return((*models.Product)(nil), repositories.ErrNotFound).Once()
+
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+
+	changes := nextChanges(t, subscription)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "Livros", changes[0].Category)
+	assert.Equal(t, "", changes[1].Category)
+	products.AssertExpectations(t)
+}
+
+func TestServiceChangeStreamResumesFromBuffer(t *testing.T) {
+	service, outbox, _ := newChangeStreamTest(10)
+	_, err := service.Poll(context.Background())
+	require.NoError(t, err)
+
+	for id := uint(1); id <= 4; id++ {
+		outbox.addProduct(events.ProductCreated, id, "Livros")
+	}
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+	reads := outbox.reads
+
+	lastEventID := uint(2)
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{}, &lastEventID)
+	require.NoError(t, err)
+
+	assert.False(t, subscription.Expired())
+	assert.Equal(t, []uint{3, 4}, positions(nextChanges(t, subscription)))
+	assert.Equal(t, reads, outbox.reads, "changes in the buffer must not be read from the outbox")
+}
+
+func TestServiceChangeStreamResumesFromOutbox(t *testing.T) {
+	service, outbox, _ := newChangeStreamTest(2)
+	_, err := service.Poll(context.Background())
+	require.NoError(t, err)
+
+	for id := uint(1); id <= 5; id++ {
+		outbox.addProduct(events.ProductCreated, id, "Livros")
+	}
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+
+	// O buffer guarda apenas as posições 4 e 5: as anteriores são lidas da outbox
+	lastEventID := uint(1)
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{ProductIDs: []uint{2, 5}}, &lastEventID)
+	require.NoError(t, err)
+
+	assert.False(t, subscription.Expired())
+	assert.Equal(t, []uint{2, 5}, positions(nextChanges(t, subscription)))
+	assert.Empty(t, nextChanges(t, subscription))
+
+	outbox.addProduct(events.ProductUpdated, 5, "Livros")
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []uint{6}, positions(nextChanges(t, subscription)))
+}
+
+func TestServiceChangeStreamExpiredPosition(t *testing.T) {
+	service, outbox, _ := newChangeStreamTest(10)
+	for id := uint(1); id <= 3; id++ {
+		outbox.addProduct(events.ProductCreated, id, "Livros")
+	}
+	// Os eventos mais antigos já foram removidos pela retenção da outbox
+	outbox.events = outbox.events[2:]
+
+	lastEventID := uint(1)
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{}, &lastEventID)
+	require.NoError(t, err)
+	assert.True(t, subscription.Expired())
+
+	outbox.addProduct(events.ProductUpdated, 3, "Livros")
+	_, err = service.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4}, positions(nextChanges(t, subscription)))
+}
+
+func TestServiceChangeStreamNextHonorsContext(t *testing.T) {
+	service, _, _ := newChangeStreamTest(10)
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = subscription.Next(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServiceChangeStreamClose(t *testing.T) {
+	service, _, _ := newChangeStreamTest(10)
+	subscription, err := service.Subscribe(context.Background(), ChangeFilter{}, nil)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := subscription.Next(context.Background())
+		done <- err
+	}()
+
+	service.Close()
+	service.Close()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrStreamClosed)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
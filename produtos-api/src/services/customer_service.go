@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Códigos dos erros do microsserviço de clientes
+const (
+	CodeCustomerNotFound           = "customer_not_found"
+	CodeCustomerConflict           = "customer_conflict"
+	CodeCustomerAlreadyRegistered  = "customer_already_registered"
+	CodeConsentConflict            = "consent_conflict"
+	CodeAddressNotFound            = "address_not_found"
+	CodeCustomerStorageUnavailable = "customer_storage_unavailable"
+)
+
+// Limites da listagem de clientes e da quantidade de endereços de cada cliente
+const (
+	DefaultCustomerLimit = 50
+	MaxCustomerLimit     = 500
+	MaxCustomerAddresses = 20
+)
+
+// maxConsentAttempts limita as releituras de UpdateConsents quando os consentimentos mudam ao mesmo tempo
+const maxConsentAttempts = 3
+
+var customerLogger = logging.Logger("customers")
+
+// CustomerService define o cadastro de clientes e os direitos do titular dos dados (LGPD): acesso,
+// correção, consentimentos, portabilidade e eliminação
+type CustomerService interface {
+	CreateCustomer(ctx context.Context, request *models.CustomerRequest) (*models.Customer, error)
+	GetCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error)
+	GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error)
+	UpdateCustomer(ctx context.Context, id uint, request *models.CustomerRequest) (*models.Customer, error)
+	UpdateConsents(ctx context.Context, id uint, request *models.ConsentRequest) (*models.Customer, error)
+	AddAddress(ctx context.Context, customerID uint, request *models.AddressRequest) (*models.CustomerAddress, error)
+	UpdateAddress(ctx context.Context, customerID, addressID uint, request *models.AddressRequest) (*models.CustomerAddress, error)
+	DeleteAddress(ctx context.Context, customerID, addressID uint) error
+	ExportCustomer(ctx context.Context, id uint) (*models.CustomerExport, error)
+	EraseCustomer(ctx context.Context, id uint) error
+}
+
+type CustomerServiceRepo struct {
+	repo repositories.CustomerRepository
+	now  func() time.Time
+}
+
+// NewCustomerService cria o serviço de clientes
+func NewCustomerService(repo repositories.CustomerRepository) *CustomerServiceRepo {
+	return &CustomerServiceRepo{repo: repo, now: time.Now}
+}
+
+// CreateCustomer cadastra o cliente; quem o cadastra é o titular dos dados e, exceto editores, só pode
+// cadastrar um cliente. Os consentimentos começam negados e só mudam por UpdateConsents.
+func (s *CustomerServiceRepo) CreateCustomer(ctx context.Context, request *models.CustomerRequest) (*models.Customer, error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.CreateCustomer")
+	defer span.End()
+
+	if err := validateCustomer(request); err != nil {
+		return nil, err
+	}
+
+	customer := &models.Customer{Addresses: []models.CustomerAddress{}}
+	applyCustomerRequest(customer, request)
+	principal := auth.PrincipalFrom(ctx)
+	if principal != nil {
+		customer.Subject = principal.Subject
+	}
+	customer.SelfRegistered = !principal.HasRole(auth.RoleEditor)
+
+	// O documento é único por titular, então o conflito do repositório só acontece entre cadastros do mesmo
+	// titular e não revela os documentos cadastrados por outros. Para quem cadastra a si mesmo, a consulta
+	// responde o caso comum e o índice idx_customers_self_registered_subject barra os cadastros simultâneos.
+	if customer.SelfRegistered {
+		registered, err := s.repo.FindCustomers(ctx, models.CustomerFilter{Subject: customer.Subject, Limit: 1})
+		if err != nil {
+			return nil, translateCustomerError(err)
+		}
+		if len(registered) > 0 {
+			return nil, ConflictError(CodeCustomerAlreadyRegistered, "The authenticated user already has a customer", nil)
+		}
+	}
+
+	if err := s.repo.CreateCustomer(ctx, customer); err != nil {
+		if customer.SelfRegistered && errors.Is(err, repositories.ErrDuplicated) {
+			return nil, ConflictError(CodeCustomerAlreadyRegistered, "The authenticated user already has a customer", err)
+		}
+		return nil, translateCustomerError(err)
+	}
+	span.SetAttributes(attribute.Int("customer.id", int(customer.ID)))
+
+	return customer, nil
+}
+
+// GetCustomers lista os clientes, filtrando pelo documento (com ou sem pontuação) ou pelo email
+func (s *CustomerServiceRepo) GetCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error) {
+	filter.Document = validation.Digits(filter.Document)
+	if filter.Limit == 0 {
+		filter.Limit = DefaultCustomerLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxCustomerLimit {
+		return nil, ValidationError(fieldError("limit", "invalid", ""))
+	}
+
+	customers, err := s.repo.FindCustomers(ctx, filter)
+	if err != nil {
+		return nil, translateCustomerError(err)
+	}
+
+	return customers, nil
+}
+
+// GetCustomerByID busca o cliente; clientes de outros titulares são reportados como inexistentes
+func (s *CustomerServiceRepo) GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error) {
+	customer, err := s.repo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, translateCustomerError(err)
+	}
+	if err := authorizeCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// UpdateCustomer corrige os dados cadastrais do cliente
+func (s *CustomerServiceRepo) UpdateCustomer(ctx context.Context, id uint, request *models.CustomerRequest) (*models.Customer, error) {
+	if err := validateCustomer(request); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyCustomerRequest(customer, request)
+	if err := s.repo.UpdateCustomer(ctx, customer); err != nil {
+		return nil, translateCustomerError(err)
+	}
+
+	return customer, nil
+}
+
+// UpdateConsents concede ou revoga os consentimentos informados. Cada mudança é registrada no histórico,
+// com quem a fez, na mesma transação; consentimentos que não mudam não geram registro. A gravação só acontece
+// se os consentimentos não mudaram desde a leitura; se mudaram, o cliente é lido de novo.
+func (s *CustomerServiceRepo) UpdateConsents(ctx context.Context, id uint, request *models.ConsentRequest) (*models.Customer, error) {
+	var recordedBy string
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		recordedBy = principal.Subject
+	}
+
+	for attempt := 1; ; attempt++ {
+		customer, err := s.GetCustomerByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		var records []models.ConsentRecord
+		change := func(purpose string, current *bool, granted *bool) {
+			if granted == nil || *granted == *current {
+				return
+			}
+			*current = *granted
+			records = append(records, models.ConsentRecord{CustomerID: customer.ID, Purpose: purpose, Granted: *granted, RecordedBy: recordedBy})
+		}
+		change(models.ConsentMarketing, &customer.MarketingConsent, request.MarketingConsent)
+		change(models.ConsentDataSharing, &customer.DataSharingConsent, request.DataSharingConsent)
+		if len(records) == 0 {
+			return customer, nil
+		}
+
+		now := s.now()
+		customer.ConsentUpdatedAt = &now
+		err = s.repo.UpdateConsents(ctx, customer, records)
+		if err == nil {
+			return customer, nil
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, translateCustomerError(err)
+		}
+		if attempt == maxConsentAttempts {
+			return nil, ConflictError(CodeConsentConflict, "Consents changed concurrently, try again", err)
+		}
+	}
+}
+
+// AddAddress cadastra um endereço do cliente. O primeiro endereço, ou o enviado com default, passa a ser o padrão.
+func (s *CustomerServiceRepo) AddAddress(ctx context.Context, customerID uint, request *models.AddressRequest) (*models.CustomerAddress, error) {
+	if err := validateAddress(request); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(customer.Addresses) >= MaxCustomerAddresses {
+		return nil, ValidationError(fieldError("addresses", "max_items", strconv.Itoa(MaxCustomerAddresses)))
+	}
+
+	address := &models.CustomerAddress{CustomerID: customer.ID}
+	applyAddressRequest(address, request)
+	address.Default = request.Default || len(customer.Addresses) == 0
+	if err := s.repo.SaveAddress(ctx, address); err != nil {
+		return nil, translateCustomerError(err)
+	}
+
+	return address, nil
+}
+
+// UpdateAddress atualiza um endereço do cliente. O endereço padrão só deixa de ser quando outro é marcado como padrão.
+func (s *CustomerServiceRepo) UpdateAddress(ctx context.Context, customerID, addressID uint, request *models.AddressRequest) (*models.CustomerAddress, error) {
+	if err := validateAddress(request); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range customer.Addresses {
+		address := &customer.Addresses[i]
+		if address.ID != addressID {
+			continue
+		}
+
+		wasDefault := address.Default
+		applyAddressRequest(address, request)
+		address.Default = wasDefault || request.Default
+		if err := s.repo.SaveAddress(ctx, address); err != nil {
+			return nil, translateCustomerError(err)
+		}
+		return address, nil
+	}
+
+	return nil, NotFoundError(CodeAddressNotFound, "Address not found", nil)
+}
+
+// DeleteAddress remove um endereço do cliente
+func (s *CustomerServiceRepo) DeleteAddress(ctx context.Context, customerID, addressID uint) error {
+	customer, err := s.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteAddress(ctx, customer.ID, addressID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return NotFoundError(CodeAddressNotFound, "Address not found", err)
+	}
+
+	return translateCustomerError(err)
+}
+
+// ExportCustomer reúne os dados pessoais do cliente, os endereços e o histórico de consentimentos
+// para entrega ao titular (portabilidade)
+func (s *CustomerServiceRepo) ExportCustomer(ctx context.Context, id uint) (*models.CustomerExport, error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.ExportCustomer")
+	defer span.End()
+
+	customer, err := s.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.repo.GetConsentRecords(ctx, customer.ID)
+	if err != nil {
+		return nil, translateCustomerError(err)
+	}
+	if records == nil {
+		records = []models.ConsentRecord{}
+	}
+
+	return &models.CustomerExport{Customer: *customer, Consents: records, ExportedAt: s.now()}, nil
+}
+
+// EraseCustomer elimina os dados pessoais do cliente: os endereços são apagados e o cadastro é anonimizado,
+// mantendo apenas o ID referenciado pelos pedidos. O documento fica livre para um novo cadastro, e o cliente
+// deixa de ser encontrado.
+//
+// Ficam de fora, de propósito:
+//   - o histórico de consentimentos, que só tem o ID do cliente e quem registrou cada mudança e é a prova
+//     das concessões e revogações exigida do controlador (LGPD, art. 8º, § 2º);
+//   - o email gravado nos pedidos, parte do registro da venda, que é mantido para o cumprimento de obrigações
+//     legais e fiscais (LGPD, art. 16, I);
+//   - a fila mail_messages, cujas mensagens concluídas são removidas após MAIL_RETENTION;
+//   - as inscrições de estoque do cliente, que guardam apenas o ID e são removidas no próximo aviso, quando o
+//     cliente não é mais encontrado. As inscrições por email não são ligadas ao cadastro e são removidas pelo
+//     cancelamento da inscrição.
+func (s *CustomerServiceRepo) EraseCustomer(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "CustomerService.EraseCustomer")
+	defer span.End()
+
+	customer, err := s.GetCustomerByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	erased := &models.Customer{ID: customer.ID, ErasedAt: &now}
+	if err := s.repo.EraseCustomer(ctx, erased); err != nil {
+		return translateCustomerError(err)
+	}
+
+	var erasedBy string
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		erasedBy = principal.Subject
+	}
+	customerLogger.InfoContext(ctx, "Customer personal data erased", "customer_id", customer.ID, "erased_by", erasedBy)
+
+	return nil
+}
+
+// authorizeCustomer permite o acesso ao próprio titular e a editores. Para não revelar quais clientes
+// existem, os de outros titulares são reportados como inexistentes.
+func authorizeCustomer(ctx context.Context, customer *models.Customer) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal.HasRole(auth.RoleEditor) || (principal != nil && customer.Subject != "" && principal.Subject == customer.Subject) {
+		return nil
+	}
+
+	return NotFoundError(CodeCustomerNotFound, "Customer not found", nil)
+}
+
+// validateCustomer normaliza o documento para apenas dígitos antes de validar os dígitos verificadores
+func validateCustomer(request *models.CustomerRequest) error {
+	request.Document = validation.Digits(strings.TrimSpace(request.Document))
+
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+// validateAddress normaliza o CEP para apenas dígitos e a UF para maiúsculas antes de validar
+func validateAddress(request *models.AddressRequest) error {
+	request.PostalCode = validation.Digits(strings.TrimSpace(request.PostalCode))
+	request.State = strings.ToUpper(strings.TrimSpace(request.State))
+
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+func applyCustomerRequest(customer *models.Customer, request *models.CustomerRequest) {
+	customer.Name = strings.TrimSpace(request.Name)
+	customer.Document = request.Document
+	customer.DocumentType = models.DocumentCPF
+	if len(request.Document) == 14 {
+		customer.DocumentType = models.DocumentCNPJ
+	}
+	customer.Email = request.Email
+	customer.Phone = strings.TrimSpace(request.Phone)
+}
+
+func applyAddressRequest(address *models.CustomerAddress, request *models.AddressRequest) {
+	address.Label = strings.TrimSpace(request.Label)
+	address.Street = strings.TrimSpace(request.Street)
+	address.Number = strings.TrimSpace(request.Number)
+	address.Complement = strings.TrimSpace(request.Complement)
+	address.District = strings.TrimSpace(request.District)
+	address.City = strings.TrimSpace(request.City)
+	address.State = request.State
+	address.PostalCode = request.PostalCode
+}
+
+func translateCustomerError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, repositories.ErrNotFound):
+		return NotFoundError(CodeCustomerNotFound, "Customer not found", err)
+	case errors.Is(err, repositories.ErrDuplicated):
+		return ConflictError(CodeCustomerConflict, "A customer with this document already exists", err)
+	default:
+		return UnavailableError(CodeCustomerStorageUnavailable, "Customer storage is unavailable", err)
+	}
+}
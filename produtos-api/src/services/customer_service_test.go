@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/database"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomerRepository) CreateCustomer(ctx context.Context, customer *models.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) GetCustomerByID(ctx context.Context, id uint) (*models.Customer, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) FindCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) UpdateCustomer(ctx context.Context, customer *models.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) UpdateConsents(ctx context.Context, customer *models.Customer, consents []models.ConsentRecord) error {
+	args := m.Called(ctx, customer, consents)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) SaveAddress(ctx context.Context, address *models.CustomerAddress) error {
+	args := m.Called(ctx, address)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) DeleteAddress(ctx context.Context, customerID, addressID uint) error {
+	args := m.Called(ctx, customerID, addressID)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) GetConsentRecords(ctx context.Context, customerID uint) ([]models.ConsentRecord, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).([]models.ConsentRecord), args.Error(1)
+}
+
+func (m *MockCustomerRepository) EraseCustomer(ctx context.Context, customer *models.Customer) error {
+	args := m.Called(ctx, customer)
+	return args.Error(0)
+}
+
+var customerTestNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newCustomerTest() (*CustomerServiceRepo, *MockCustomerRepository) {
+	repo := new(MockCustomerRepository)
+	service := NewCustomerService(repo)
+	service.now = func() time.Time { return customerTestNow }
+
+	return service, repo
+}
+
+// asSubject autentica o titular maria com o papel viewer
+func asSubject() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleViewer}})
+}
+
+// expectCustomer cadastra o cliente 7, da titular maria, com um endereço padrão
+func expectCustomer(repo *MockCustomerRepository) {
+	repo.On("GetCustomerByID", mock.Anything, uint(7)).Return(&models.Customer{
+		ID: 7, Name: "Maria Souza", Document: "52998224725", DocumentType: models.DocumentCPF, Subject: "maria",
+		Addresses: []models.CustomerAddress{{ID: 3, CustomerID: 7, Street: "Av. Paulista", Number: "1000", City: "São Paulo", State: "SP", PostalCode: "01310100", Default: true}},
+	}, nil)
+}
+
+func TestServiceCreateCustomer(t *testing.T) {
+	service, repo := newCustomerTest()
+	repo.On("FindCustomers", mock.Anything, models.CustomerFilter{Subject: "maria", Limit: 1}).Return([]models.Customer{}, nil)
+	repo.On("CreateCustomer", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Customer).ID = 7
+	}).Return(nil)
+
+	customer, err := service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: " Maria Souza ", Document: "529.982.247-25", Email: "maria@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), customer.ID)
+	assert.Equal(t, "Maria Souza", customer.Name)
+	assert.Equal(t, "52998224725", customer.Document)
+	assert.Equal(t, models.DocumentCPF, customer.DocumentType)
+	assert.Equal(t, "maria", customer.Subject)
+	assert.False(t, customer.MarketingConsent)
+
+	// Editores cadastram vários clientes
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	customer, err = service.CreateCustomer(editor, &models.CustomerRequest{Name: "Loja LTDA", Document: "11.222.333/0001-81"})
+	require.NoError(t, err)
+	assert.Equal(t, models.DocumentCNPJ, customer.DocumentType)
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "FindCustomers", 1)
+}
+
+func TestServiceCreateCustomerTwice(t *testing.T) {
+	service, repo := newCustomerTest()
+	repo.On("FindCustomers", mock.Anything, models.CustomerFilter{Subject: "maria", Limit: 1}).Return([]models.Customer{{ID: 7}}, nil)
+
+	_, err := service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: "Maria", Document: "52998224725"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	assert.Equal(t, CodeCustomerAlreadyRegistered, domainErr.Code)
+	repo.AssertNotCalled(t, "CreateCustomer", mock.Anything, mock.Anything)
+}
+
+// unseenCustomerRepository não encontra os clientes já cadastrados, como quando outro cadastro do mesmo
+// titular ainda não terminou no momento da consulta
+type unseenCustomerRepository struct {
+	repositories.CustomerRepository
+}
+
+func (unseenCustomerRepository) FindCustomers(ctx context.Context, filter models.CustomerFilter) ([]models.Customer, error) {
+	return nil, nil
+}
+
+func TestServiceCreateCustomerConcurrently(t *testing.T) {
+	db, err := database.SetupTestDatabase()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(database.Customers.Models...))
+	service := NewCustomerService(unseenCustomerRepository{repositories.NewCustomerRepository(db)})
+
+	_, err = service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: "Maria", Document: "52998224725"})
+	require.NoError(t, err)
+
+	// O índice barra o segundo cadastro do titular mesmo com outro documento
+	_, err = service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: "Maria", Document: "11222333000181"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeCustomerAlreadyRegistered, domainErr.Code)
+
+	// Editores continuam cadastrando vários clientes
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	for _, document := range []string{"52998224725", "11222333000181"} {
+		_, err = service.CreateCustomer(editor, &models.CustomerRequest{Name: "Cliente", Document: document})
+		require.NoError(t, err)
+	}
+}
+
+func TestServiceCreateCustomerValidation(t *testing.T) {
+	service, repo := newCustomerTest()
+
+	for _, document := range []string{"529.982.247-24", "111.111.111-11", "11.222.333/0001-82", "1234567890", "abc.982.247-25"} {
+		_, err := service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: "Maria", Document: document})
+		domainErr, ok := AsDomainError(err)
+		require.True(t, ok, document)
+		assert.Equal(t, KindValidation, domainErr.Kind)
+		assert.Equal(t, "document", domainErr.Fields[0].Field)
+		assert.Equal(t, "cpf_cnpj", domainErr.Fields[0].Code, document)
+	}
+
+	_, err := service.CreateCustomer(asSubject(), &models.CustomerRequest{Name: " ", Document: "52998224725", Email: "maria"})
+	domainErr, _ := AsDomainError(err)
+	require.NotNil(t, domainErr)
+	assert.Len(t, domainErr.Fields, 2)
+	repo.AssertNotCalled(t, "CreateCustomer", mock.Anything, mock.Anything)
+}
+
+func TestServiceCreateCustomerWithDuplicatedDocument(t *testing.T) {
+	service, repo := newCustomerTest()
+	repo.On("CreateCustomer", mock.Anything, mock.Anything).Return(repositories.ErrDuplicated)
+
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	_, err := service.CreateCustomer(editor, &models.CustomerRequest{Name: "Maria", Document: "52998224725"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	assert.Equal(t, CodeCustomerConflict, domainErr.Code)
+}
+
+func TestServiceGetCustomerOfAnotherSubject(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+
+	joao := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "joao", Roles: []auth.Role{auth.RoleViewer}})
+	_, err := service.GetCustomerByID(joao, 7)
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeCustomerNotFound, domainErr.Code)
+
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	customer, err := service.GetCustomerByID(editor, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "maria", customer.Subject)
+
+	customer, err = service.GetCustomerByID(asSubject(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), customer.ID)
+}
+
+func TestServiceUpdateConsents(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("UpdateConsents", mock.Anything, mock.Anything, []models.ConsentRecord{
+		{CustomerID: 7, Purpose: models.ConsentMarketing, Granted: true, RecordedBy: "maria"},
+	}).Return(nil)
+
+	granted, withdrawn := true, false
+	customer, err := service.UpdateConsents(asSubject(), 7, &models.ConsentRequest{MarketingConsent: &granted, DataSharingConsent: &withdrawn})
+	require.NoError(t, err)
+	assert.True(t, customer.MarketingConsent)
+	assert.False(t, customer.DataSharingConsent)
+	assert.Equal(t, customerTestNow, *customer.ConsentUpdatedAt)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceUpdateConsentsWithoutChanges(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+
+	withdrawn := false
+	customer, err := service.UpdateConsents(asSubject(), 7, &models.ConsentRequest{MarketingConsent: &withdrawn})
+	require.NoError(t, err)
+	assert.Nil(t, customer.ConsentUpdatedAt)
+	repo.AssertNotCalled(t, "UpdateConsents", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceUpdateConsentsChangedConcurrently(t *testing.T) {
+	service, repo := newCustomerTest()
+	// Entre a leitura e a gravação, outra requisição concedeu o consentimento de marketing
+	repo.On("GetCustomerByID", mock.Anything, uint(7)).Return(&models.Customer{ID: 7, Subject: "maria"}, nil).Once()
+	repo.On("GetCustomerByID", mock.Anything, uint(7)).Return(&models.Customer{ID: 7, Subject: "maria", MarketingConsent: true}, nil).Once()
+	repo.On("UpdateConsents", mock.Anything, mock.Anything, []models.ConsentRecord{
+		{CustomerID: 7, Purpose: models.ConsentMarketing, Granted: true, RecordedBy: "maria"},
+	}).Return(repositories.ErrNotFound).Once()
+
+	granted := true
+	customer, err := service.UpdateConsents(asSubject(), 7, &models.ConsentRequest{MarketingConsent: &granted})
+	require.NoError(t, err)
+	assert.True(t, customer.MarketingConsent)
+	repo.AssertExpectations(t)
+
+	// Mudanças concorrentes seguidas desistem depois de maxConsentAttempts leituras
+	service, repo = newCustomerTest()
+	for attempt := 0; attempt < maxConsentAttempts; attempt++ {
+		repo.On("GetCustomerByID", mock.Anything, uint(7)).Return(&models.Customer{ID: 7, Subject: "maria"}, nil).Once()
+	}
+	repo.On("UpdateConsents", mock.Anything, mock.Anything, mock.Anything).Return(repositories.ErrNotFound)
+	_, err = service.UpdateConsents(asSubject(), 7, &models.ConsentRequest{MarketingConsent: &granted})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeConsentConflict, domainErr.Code)
+	repo.AssertNumberOfCalls(t, "UpdateConsents", maxConsentAttempts)
+}
+
+func TestServiceAddAddress(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("SaveAddress", mock.Anything, mock.Anything).Return(nil)
+
+	address, err := service.AddAddress(asSubject(), 7, &models.AddressRequest{
+		Label: "trabalho", Street: "Rua Augusta", Number: "500", City: "São Paulo", State: "sp", PostalCode: "01305-000",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), address.CustomerID)
+	assert.Equal(t, "SP", address.State)
+	assert.Equal(t, "01305000", address.PostalCode)
+	assert.False(t, address.Default)
+
+	_, err = service.AddAddress(asSubject(), 7, &models.AddressRequest{Street: "Rua Augusta", Number: "500", City: "São Paulo", State: "XX", PostalCode: "0130"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	if assert.Len(t, domainErr.Fields, 2) {
+		assert.Equal(t, "uf", domainErr.Fields[0].Code)
+		assert.Equal(t, "cep", domainErr.Fields[1].Code)
+	}
+	repo.AssertNumberOfCalls(t, "SaveAddress", 1)
+}
+
+func TestServiceFirstAddressIsDefault(t *testing.T) {
+	service, repo := newCustomerTest()
+	repo.On("GetCustomerByID", mock.Anything, uint(8)).Return(&models.Customer{ID: 8, Subject: "maria"}, nil)
+	repo.On("SaveAddress", mock.Anything, mock.Anything).Return(nil)
+
+	address, err := service.AddAddress(asSubject(), 8, &models.AddressRequest{Street: "Rua A", Number: "1", City: "Recife", State: "PE", PostalCode: "50000000"})
+	require.NoError(t, err)
+	assert.True(t, address.Default)
+}
+
+func TestServiceUpdateAddress(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("SaveAddress", mock.Anything, mock.Anything).Return(nil)
+
+	// O endereço padrão continua padrão sem default no corpo
+	address, err := service.UpdateAddress(asSubject(), 7, 3, &models.AddressRequest{Street: "Av. Paulista", Number: "1001", City: "São Paulo", State: "SP", PostalCode: "01310100"})
+	require.NoError(t, err)
+	assert.Equal(t, "1001", address.Number)
+	assert.True(t, address.Default)
+
+	_, err = service.UpdateAddress(asSubject(), 7, 4, &models.AddressRequest{Street: "Av. Paulista", Number: "1001", City: "São Paulo", State: "SP", PostalCode: "01310100"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeAddressNotFound, domainErr.Code)
+}
+
+func TestServiceDeleteAddress(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("DeleteAddress", mock.Anything, uint(7), uint(3)).Return(nil)
+	repo.On("DeleteAddress", mock.Anything, uint(7), uint(4)).Return(repositories.ErrNotFound)
+
+	require.NoError(t, service.DeleteAddress(asSubject(), 7, 3))
+
+	domainErr, ok := AsDomainError(service.DeleteAddress(asSubject(), 7, 4))
+	require.True(t, ok)
+	assert.Equal(t, CodeAddressNotFound, domainErr.Code)
+}
+
+func TestServiceExportCustomer(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("GetConsentRecords", mock.Anything, uint(7)).Return([]models.ConsentRecord{
+		{ID: 1, CustomerID: 7, Purpose: models.ConsentMarketing, Granted: true, RecordedBy: "maria"},
+	}, nil)
+
+	export, err := service.ExportCustomer(asSubject(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "52998224725", export.Customer.Document)
+	assert.Len(t, export.Customer.Addresses, 1)
+	assert.Len(t, export.Consents, 1)
+	assert.Equal(t, customerTestNow, export.ExportedAt)
+}
+
+func TestServiceEraseCustomer(t *testing.T) {
+	service, repo := newCustomerTest()
+	expectCustomer(repo)
+	repo.On("EraseCustomer", mock.Anything, mock.MatchedBy(func(customer *models.Customer) bool {
+		return customer.ID == 7 && customer.Name == "" && customer.Document == "" && customer.Subject == "" &&
+			customer.ErasedAt != nil && customer.ErasedAt.Equal(customerTestNow)
+	})).Return(nil)
+
+	require.NoError(t, service.EraseCustomer(asSubject(), 7))
+	repo.AssertExpectations(t)
+}
+
+func TestServiceGetCustomers(t *testing.T) {
+	service, repo := newCustomerTest()
+	repo.On("FindCustomers", mock.Anything, models.CustomerFilter{Document: "52998224725", Limit: DefaultCustomerLimit}).
+		Return([]models.Customer{{ID: 7}}, nil)
+
+	customers, err := service.GetCustomers(context.Background(), models.CustomerFilter{Document: "529.982.247-25"})
+	require.NoError(t, err)
+	assert.Len(t, customers, 1)
+
+	_, err = service.GetCustomers(context.Background(), models.CustomerFilter{Limit: MaxCustomerLimit + 1})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+}
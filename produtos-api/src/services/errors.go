@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"produtos-api/src/i18n"
+	"produtos-api/src/repositories"
+)
+
+// ErrorKind classifica os erros de domínio; o controller decide o status HTTP a partir dele
+type ErrorKind string
+
+const (
+	KindNotFound     ErrorKind = "not_found"
+	KindValidation   ErrorKind = "validation"
+	KindConflict     ErrorKind = "conflict"
+	KindUnavailable  ErrorKind = "unavailable"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindRateLimited  ErrorKind = "rate_limited"
+)
+
+// Códigos estáveis dos erros de domínio, expostos no campo "code" das respostas de erro
+const (
+	CodeProductNotFound     = "product_not_found"
+	CodeValidationFailed    = "validation_failed"
+	CodeProductConflict     = "product_conflict"
+	CodeRevisionNotFound    = "revision_not_found"
+	CodeDatabaseUnavailable = "database_unavailable"
+)
+
+// FieldError descreve uma violação de validação em um campo específico
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// DomainError é o erro tipado retornado pela camada de serviço
+type DomainError struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError cria um erro de recurso inexistente
+func NotFoundError(code, message string, err error) *DomainError {
+	return &DomainError{Kind: KindNotFound, Code: code, Message: message, Err: err}
+}
+
+// ValidationError cria um erro de validação com os detalhes de cada campo inválido
+func ValidationError(fields ...FieldError) *DomainError {
+	return &DomainError{Kind: KindValidation, Code: CodeValidationFailed, Message: "Validation failed", Fields: fields}
+}
+
+// ConflictError cria um erro de conflito com o estado atual do recurso
+func ConflictError(code, message string, err error) *DomainError {
+	return &DomainError{Kind: KindConflict, Code: code, Message: message, Err: err}
+}
+
+// UnavailableError cria um erro de dependência indisponível
+func UnavailableError(code, message string, err error) *DomainError {
+	return &DomainError{Kind: KindUnavailable, Code: code, Message: message, Err: err}
+}
+
+// UnauthorizedError cria um erro de credenciais ausentes ou inválidas
+func UnauthorizedError(message string, err error) *DomainError {
+	return &DomainError{Kind: KindUnauthorized, Code: CodeUnauthorized, Message: message, Err: err}
+}
+
+// ForbiddenError cria um erro de permissão insuficiente
+func ForbiddenError(message string, err error) *DomainError {
+	return &DomainError{Kind: KindForbidden, Code: CodeForbidden, Message: message, Err: err}
+}
+
+// RateLimitedError cria um erro de limite de requisições excedido
+func RateLimitedError(message string, err error) *DomainError {
+	return &DomainError{Kind: KindRateLimited, Code: CodeRateLimited, Message: message, Err: err}
+}
+
+// fieldError cria a violação de um campo com a mensagem da regra no locale padrão
+func fieldError(field, rule, param string) FieldError {
+	return FieldError{
+		Field: field,
+		Code:  rule,
+		Param: param,
+		Message: i18n.T(i18n.DefaultLocale, "validation."+rule, map[string]string{
+			"field": field,
+			"param": param,
+		}),
+	}
+}
+
+// AsDomainError extrai o DomainError da cadeia de erros
+func AsDomainError(err error) (*DomainError, bool) {
+	var domainErr *DomainError
+	ok := errors.As(err, &domainErr)
+	return domainErr, ok
+}
+
+// translateProductError converte os erros do repositório em erros de domínio.
+// Erros de contexto são mantidos para que o controller responda 504/499.
+func translateProductError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, repositories.ErrNotFound):
+		return NotFoundError(CodeProductNotFound, "Product not found", err)
+	case errors.Is(err, repositories.ErrDuplicated):
+		return ConflictError(CodeProductConflict, "Product conflicts with an existing product", err)
+	default:
+		return UnavailableError(CodeDatabaseUnavailable, "Product storage is unavailable", err)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status possíveis de uma dependência ou da aplicação
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDraining = "draining"
+)
+
+// HealthCheck verifica uma dependência e retorna erro caso ela esteja indisponível
+type HealthCheck func(ctx context.Context) error
+
+// DependencyStatus representa o resultado da verificação de uma dependência
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport representa o estado de prontidão da aplicação e de cada dependência
+type HealthReport struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Healthy indica se a aplicação está pronta para receber tráfego
+func (r HealthReport) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+type HealthService interface {
+	Readiness(ctx context.Context) HealthReport
+	RegisterCheck(name string, check HealthCheck)
+	RegisterWorker(name string) *WorkerState
+	Drain()
+	Draining() bool
+	Drained() <-chan struct{}
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+type HealthServiceChecks struct {
+	mu        sync.RWMutex
+	checks    []namedCheck
+	timeout   time.Duration
+	draining  atomic.Bool
+	drained   chan struct{}
+	drainOnce sync.Once
+}
+
+// NewHealthService cria o serviço de saúde; timeout limita a duração de cada verificação
+func NewHealthService(timeout time.Duration) *HealthServiceChecks {
+	return &HealthServiceChecks{timeout: timeout, drained: make(chan struct{})}
+}
+
+// RegisterCheck adiciona uma dependência à verificação de prontidão
+func (s *HealthServiceChecks) RegisterCheck(name string, check HealthCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// RegisterWorker registra um worker em segundo plano e retorna o estado que ele deve atualizar
+func (s *HealthServiceChecks) RegisterWorker(name string) *WorkerState {
+	worker := &WorkerState{}
+	s.RegisterCheck("worker:"+name, worker.Check)
+
+	return worker
+}
+
+// Drain marca a aplicação como em drenagem, fazendo a prontidão falhar durante o desligamento
+func (s *HealthServiceChecks) Drain() {
+	s.draining.Store(true)
+	s.drainOnce.Do(func() { close(s.drained) })
+}
+
+func (s *HealthServiceChecks) Draining() bool {
+	return s.draining.Load()
+}
+
+// Drained retorna um canal fechado quando a drenagem começa, para encerrar as conexões de longa duração
+func (s *HealthServiceChecks) Drained() <-chan struct{} {
+	return s.drained
+}
+
+// Readiness executa todas as verificações em paralelo e consolida o resultado
+func (s *HealthServiceChecks) Readiness(ctx context.Context) HealthReport {
+	s.mu.RLock()
+	checks := append([]namedCheck(nil), s.checks...)
+	s.mu.RUnlock()
+
+	report := HealthReport{Status: StatusUp, Dependencies: make(map[string]DependencyStatus, len(checks))}
+	results := make([]DependencyStatus, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = s.run(ctx, c.check)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Dependencies[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	if s.Draining() {
+		report.Status = StatusDraining
+	}
+
+	return report
+}
+
+func (s *HealthServiceChecks) run(ctx context.Context, check HealthCheck) DependencyStatus {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	status := DependencyStatus{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+// WorkerState guarda o estado de um worker em segundo plano para a verificação de prontidão
+type WorkerState struct {
+	mu      sync.Mutex
+	running bool
+	lastErr error
+}
+
+var errWorkerStopped = errors.New("worker is not running")
+
+// Start marca o worker como em execução
+func (w *WorkerState) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running = true
+	w.lastErr = nil
+}
+
+// Stop marca o worker como parado
+func (w *WorkerState) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running = false
+}
+
+// Report registra o resultado da última execução do worker; nil limpa o erro anterior
+func (w *WorkerState) Report(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastErr = err
+}
+
+// Check implementa HealthCheck para o worker
+func (w *WorkerState) Check(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return errWorkerStopped
+	}
+
+	return w.lastErr
+}
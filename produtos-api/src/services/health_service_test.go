@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceReadinessAllUp(t *testing.T) {
+	healthService := NewHealthService(time.Second)
+	healthService.RegisterCheck("database", func(ctx context.Context) error { return nil })
+
+	report := healthService.Readiness(context.Background())
+	assert.True(t, report.Healthy())
+	assert.Equal(t, StatusUp, report.Dependencies["database"].Status)
+}
+
+func TestServiceReadinessDependencyDown(t *testing.T) {
+	healthService := NewHealthService(time.Second)
+	healthService.RegisterCheck("database", func(ctx context.Context) error { return nil })
+	healthService.RegisterCheck("migrations", func(ctx context.Context) error { return errors.New("pending") })
+
+	report := healthService.Readiness(context.Background())
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "pending", report.Dependencies["migrations"].Error)
+}
+
+func TestServiceReadinessCheckTimeout(t *testing.T) {
+	healthService := NewHealthService(10 * time.Millisecond)
+	healthService.RegisterCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := healthService.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Dependencies["slow"].Status)
+}
+
+func TestServiceReadinessWorkerState(t *testing.T) {
+	healthService := NewHealthService(time.Second)
+	worker := healthService.RegisterWorker("purge")
+
+	assert.False(t, healthService.Readiness(context.Background()).Healthy())
+
+	worker.Start()
+	assert.True(t, healthService.Readiness(context.Background()).Healthy())
+
+	worker.Report(errors.New("boom"))
+	assert.False(t, healthService.Readiness(context.Background()).Healthy())
+}
+
+func TestServiceReadinessDraining(t *testing.T) {
+	healthService := NewHealthService(time.Second)
+	healthService.Drain()
+
+	report := healthService.Readiness(context.Background())
+	assert.Equal(t, StatusDraining, report.Status)
+	assert.False(t, report.Healthy())
+}
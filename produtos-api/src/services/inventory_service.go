@@ -0,0 +1,539 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var inventoryLogger = logging.Logger("inventory")
+
+// ordersActor é o ator dos registros de auditoria das mudanças de estoque feitas pelos eventos de pedidos
+var ordersActor = &auth.Principal{Subject: "system:orders"}
+
+// errInvalidEvent marca os eventos que nunca poderão ser processados; eles vão para o dead letter
+var errInvalidEvent = errors.New("invalid event")
+
+// errEventAlreadyProcessed desfaz a transação de um evento entregue de novo
+var errEventAlreadyProcessed = errors.New("event already processed")
+
+// Códigos dos erros das reservas de estoque
+const (
+	CodeInsufficientStock        = "insufficient_stock"
+	CodeStockReservationNotFound = "stock_reservation_not_found"
+	CodeStockReservationClosed   = "stock_reservation_closed"
+	CodeStockReservationMismatch = "stock_reservation_mismatch"
+)
+
+// MaxReservationItems limita os itens de uma reserva de estoque
+const MaxReservationItems = 100
+
+// InventoryService aplica ao estoque os pedidos do microsserviço de pedidos: pelos eventos do tópico de
+// pedidos ou pelas reservas feitas no checkout
+type InventoryService interface {
+	HandleOrderEvent(ctx context.Context, msg broker.Message) error
+	ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error)
+	GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error)
+	ReleaseStock(ctx context.Context, orderID uint) error
+	CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error)
+}
+
+type InventoryServiceRepo struct {
+	products     repositories.ProductRepository
+	reservations repositories.StockReservationRepository
+	processed    repositories.ProcessedEventRepository
+	audit        repositories.AuditRepository
+	outbox       repositories.OutboxRepository
+	transactor   repositories.Transactor
+	deadLetters  broker.Broker
+	dlqTopic     string
+}
+
+// NewInventoryService cria o serviço de estoque. Eventos inválidos são publicados em dlqTopic por deadLetters.
+func NewInventoryService(products repositories.ProductRepository, reservations repositories.StockReservationRepository, processed repositories.ProcessedEventRepository, audit repositories.AuditRepository, outbox repositories.OutboxRepository, transactor repositories.Transactor, deadLetters broker.Broker, dlqTopic string) *InventoryServiceRepo {
+	return &InventoryServiceRepo{
+		products:     products,
+		reservations: reservations,
+		processed:    processed,
+		audit:        audit,
+		outbox:       outbox,
+		transactor:   transactor,
+		deadLetters:  deadLetters,
+		dlqTopic:     dlqTopic,
+	}
+}
+
+// HandleOrderEvent processa um evento do tópico de pedidos. Cada evento é aplicado uma única vez: o seu ID
+// é gravado na mesma transação que os seus efeitos, e entregas repetidas são ignoradas. Eventos inválidos
+// vão para o tópico de dead letter; falhas temporárias, como o banco indisponível, retornam erro para que
+// o evento seja entregue de novo. Tipos que não afetam o estoque são ignorados.
+func (s *InventoryServiceRepo) HandleOrderEvent(ctx context.Context, msg broker.Message) error {
+	ctx, span := tracer.Start(ctx, "InventoryService.HandleOrderEvent")
+	defer span.End()
+	span.SetAttributes(attribute.String("event.id", msg.ID), attribute.String("event.type", msg.Type))
+
+	ctx = auth.WithPrincipal(ctx, ordersActor)
+	if requestID := msg.Headers["request_id"]; requestID != "" {
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
+
+	err := s.handle(ctx, msg)
+	if errors.Is(err, errEventAlreadyProcessed) {
+		inventoryLogger.DebugContext(ctx, "duplicate event skipped", "event_id", msg.ID, "type", msg.Type)
+		return nil
+	}
+	if err == nil || !isPermanent(err) {
+		return err
+	}
+
+	return s.deadLetter(ctx, msg, err)
+}
+
+func (s *InventoryServiceRepo) handle(ctx context.Context, msg broker.Message) error {
+	var payload interface{}
+	switch msg.Type {
+	case events.OrderPlaced:
+		payload = &events.Order{}
+	case events.OrderCancelled:
+		payload = &events.OrderCancellation{}
+	case events.OrderShipped:
+		payload = &events.OrderShipment{}
+	default:
+		// O tópico de pedidos também carrega eventos que não afetam o estoque
+		return nil
+	}
+
+	if msg.ID == "" {
+		return fmt.Errorf("%w: missing event ID", errInvalidEvent)
+	}
+	if err := decodeEvent(msg, payload); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.processed.MarkEventProcessed(ctx, &models.ProcessedEvent{EventID: msg.ID, Type: msg.Type, ProcessedAt: time.Now().UTC()})
+		if errors.Is(err, repositories.ErrDuplicated) {
+			return errEventAlreadyProcessed
+		}
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		switch payload := payload.(type) {
+		case *events.Order:
+			return s.placeOrder(ctx, msg.ID, payload)
+		case *events.OrderCancellation:
+			return s.cancelOrder(ctx, payload.OrderID)
+		default:
+			return s.shipOrder(ctx, payload.(*events.OrderShipment).OrderID)
+		}
+	})
+}
+
+// placeOrder reserva o estoque de todos os itens do pedido, ou de nenhum: se algum item não tiver estoque
+// suficiente, publica StockInsufficient para que o microsserviço de pedidos cancele o pedido
+func (s *InventoryServiceRepo) placeOrder(ctx context.Context, eventID string, order *events.Order) error {
+	if len(order.Items) == 0 {
+		return fmt.Errorf("%w: order %d has no items", errInvalidEvent, order.OrderID)
+	}
+	for _, item := range order.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: invalid quantity %d for product %d", errInvalidEvent, item.Quantity, item.ProductID)
+		}
+	}
+
+	// O checkout do microsserviço de pedidos reserva o estoque antes de publicar o OrderPlaced, e um segundo
+	// OrderPlaced do mesmo pedido, com outro ID de evento, não reserva de novo; itens diferentes dos reservados
+	// indicam um evento inconsistente
+	existing, err := s.reservations.GetStockReservations(ctx, order.OrderID)
+	if err != nil {
+		return translateProductError(err)
+	}
+	if len(existing) > 0 {
+		if !sameReservation(existing, order.Items) {
+			return fmt.Errorf("%w: order %d is already reserved with other items", errInvalidEvent, order.OrderID)
+		}
+		inventoryLogger.DebugContext(ctx, "order already has reservations", "order_id", order.OrderID, "event_id", eventID)
+		return nil
+	}
+
+	_, missing, err := s.reserve(ctx, order.OrderID, eventID, order.Items)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	inventoryLogger.InfoContext(ctx, "insufficient stock for order", "order_id", order.OrderID, "event_id", eventID)
+	shortage := events.StockShortage{OrderID: order.OrderID, EventID: eventID, Items: missing}
+	return translateProductError(enqueueEvent(ctx, s.outbox, events.StockInsufficient, events.AggregateOrder, order.OrderID, shortage))
+}
+
+// reserve baixa o estoque e cria as reservas de todos os itens, ou de nenhum: quando falta estoque de algum
+// item, retorna os itens em falta sem alterar nada. Itens repetidos do mesmo produto são somados.
+func (s *InventoryServiceRepo) reserve(ctx context.Context, orderID uint, source string, items []events.OrderItem) ([]models.StockReservation, []events.MissingStock, error) {
+	quantities := map[uint]int{}
+	var ids []uint
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			ids = append(ids, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	products := make([]*models.Product, len(ids))
+	var missing []events.MissingStock
+	for i, id := range ids {
+		product, err := s.products.GetProductByID(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			missing = append(missing, events.MissingStock{ProductID: id, Requested: quantities[id]})
+			continue
+		}
+		if err != nil {
+			return nil, nil, translateProductError(err)
+		}
+		if product.Stock < quantities[id] {
+			missing = append(missing, events.MissingStock{ProductID: id, Requested: quantities[id], Available: product.Stock})
+		}
+		products[i] = product
+	}
+	if len(missing) > 0 {
+		return nil, missing, nil
+	}
+
+	reservations := make([]models.StockReservation, 0, len(products))
+	for _, product := range products {
+		if err := s.changeStock(ctx, product, -quantities[product.ID]); err != nil {
+			return nil, nil, err
+		}
+
+		reservation := models.StockReservation{
+			OrderID:   orderID,
+			ProductID: product.ID,
+			Quantity:  quantities[product.ID],
+			Status:    models.ReservationReserved,
+			EventID:   source,
+		}
+		if err := s.reservations.CreateStockReservation(ctx, &reservation); err != nil {
+			return nil, nil, translateProductError(err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil, nil
+}
+
+// cancelOrder devolve ao estoque as reservas ainda não enviadas do pedido
+func (s *InventoryServiceRepo) cancelOrder(ctx context.Context, orderID uint) error {
+	reservations, err := s.reservations.GetStockReservations(ctx, orderID)
+	if err != nil {
+		return translateProductError(err)
+	}
+
+	for i := range reservations {
+		reservation := &reservations[i]
+		if reservation.Status != models.ReservationReserved {
+			continue
+		}
+
+		product, err := s.products.GetProductByID(ctx, reservation.ProductID)
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			// Produto na lixeira ou removido: não há estoque para onde devolver
+			inventoryLogger.WarnContext(ctx, "reserved product no longer exists", "order_id", orderID, "product_id", reservation.ProductID)
+		case err != nil:
+			return translateProductError(err)
+		default:
+			if err := s.changeStock(ctx, product, reservation.Quantity); err != nil {
+				return err
+			}
+		}
+
+		reservation.Status = models.ReservationReleased
+		if err := s.reservations.SaveStockReservation(ctx, reservation); err != nil {
+			return translateProductError(err)
+		}
+	}
+
+	return nil
+}
+
+// shipOrder torna definitivas as reservas do pedido; o estoque já foi baixado quando o pedido foi feito
+func (s *InventoryServiceRepo) shipOrder(ctx context.Context, orderID uint) error {
+	reservations, err := s.reservations.GetStockReservations(ctx, orderID)
+	if err != nil {
+		return translateProductError(err)
+	}
+
+	for i := range reservations {
+		reservation := &reservations[i]
+		if reservation.Status == models.ReservationShipped {
+			// Já confirmada pelo envio do microsserviço de pedidos
+			continue
+		}
+		if reservation.Status != models.ReservationReserved {
+			inventoryLogger.WarnContext(ctx, "shipped order reservation is not reserved", "order_id", orderID,
+				"product_id", reservation.ProductID, "status", reservation.Status)
+			continue
+		}
+
+		reservation.Status = models.ReservationShipped
+		if err := s.reservations.SaveStockReservation(ctx, reservation); err != nil {
+			return translateProductError(err)
+		}
+	}
+
+	return nil
+}
+
+// ReserveStock reserva o estoque dos itens do pedido no checkout, com as mesmas regras do OrderPlaced: todos os
+// itens ou nenhum. A falta de estoque é um conflito que lista cada produto em falta. Repetir a reserva de um
+// pedido com os mesmos itens retorna as reservas existentes, o que permite ao microsserviço de pedidos repetir a
+// requisição; com outros itens, a repetição é um conflito.
+func (s *InventoryServiceRepo) ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error) {
+	ctx, span := tracer.Start(ctx, "InventoryService.ReserveStock")
+	defer span.End()
+	span.SetAttributes(attribute.Int("order.id", int(request.OrderID)))
+
+	if err := validateStockReservation(request); err != nil {
+		return nil, err
+	}
+
+	items := make([]events.OrderItem, len(request.Items))
+	for i, item := range request.Items {
+		items[i] = events.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	var reservations []models.StockReservation
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.reservations.GetStockReservations(ctx, request.OrderID)
+		if err != nil {
+			return translateProductError(err)
+		}
+		if len(existing) > 0 {
+			reservations = existing
+			for _, reservation := range existing {
+				if reservation.Status == models.ReservationReleased {
+					return ConflictError(CodeStockReservationClosed, "Stock reservation of the order was already released", nil)
+				}
+			}
+			if !sameReservation(existing, items) {
+				return ConflictError(CodeStockReservationMismatch, "Order already has a stock reservation with other items", nil)
+			}
+			return nil
+		}
+
+		created, missing, err := s.reserve(ctx, request.OrderID, logging.RequestID(ctx), items)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			productIDs := make([]uint, len(missing))
+			for i, item := range missing {
+				productIDs[i] = item.ProductID
+			}
+			return insufficientStockError(productIDs)
+		}
+		reservations = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetStockReservations retorna as reservas de estoque do pedido
+func (s *InventoryServiceRepo) GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	ctx, span := tracer.Start(ctx, "InventoryService.GetStockReservations")
+	defer span.End()
+
+	reservations, err := s.reservations.GetStockReservations(ctx, orderID)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+	if len(reservations) == 0 {
+		return nil, NotFoundError(CodeStockReservationNotFound, "Stock reservation not found", nil)
+	}
+
+	return reservations, nil
+}
+
+// ReleaseStock devolve ao estoque as reservas do pedido. É a compensação do checkout: liberar um pedido sem
+// reservas ou já liberado não é um erro, já que a reserva pode não ter chegado a ser feita.
+func (s *InventoryServiceRepo) ReleaseStock(ctx context.Context, orderID uint) error {
+	ctx, span := tracer.Start(ctx, "InventoryService.ReleaseStock")
+	defer span.End()
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		reservations, err := s.reservations.GetStockReservations(ctx, orderID)
+		if err != nil {
+			return translateProductError(err)
+		}
+		for _, reservation := range reservations {
+			if reservation.Status == models.ReservationShipped {
+				return ConflictError(CodeStockReservationClosed, "Stock reservation of the order was already shipped", nil)
+			}
+		}
+
+		return s.cancelOrder(ctx, orderID)
+	})
+}
+
+// CommitStock torna definitivas as reservas do pedido quando ele é enviado
+func (s *InventoryServiceRepo) CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	ctx, span := tracer.Start(ctx, "InventoryService.CommitStock")
+	defer span.End()
+
+	var reservations []models.StockReservation
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		reservations, err = s.reservations.GetStockReservations(ctx, orderID)
+		if err != nil {
+			return translateProductError(err)
+		}
+		if len(reservations) == 0 {
+			return NotFoundError(CodeStockReservationNotFound, "Stock reservation not found", nil)
+		}
+
+		for i := range reservations {
+			reservation := &reservations[i]
+			switch reservation.Status {
+			case models.ReservationReleased:
+				return ConflictError(CodeStockReservationClosed, "Stock reservation of the order was already released", nil)
+			case models.ReservationReserved:
+				reservation.Status = models.ReservationShipped
+				if err := s.reservations.SaveStockReservation(ctx, reservation); err != nil {
+					return translateProductError(err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// validateStockReservation aplica as regras declaradas no modelo e limita a quantidade de itens
+func validateStockReservation(request *models.StockReservationRequest) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	switch {
+	case len(request.Items) == 0:
+		fields = append(fields, fieldError("items", "required", ""))
+	case len(request.Items) > MaxReservationItems:
+		fields = append(fields, fieldError("items", "max_items", strconv.Itoa(MaxReservationItems)))
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+// insufficientStockError lista os produtos em falta, com o ID do produto em param de cada item
+func insufficientStockError(productIDs []uint) error {
+	fields := make([]FieldError, len(productIDs))
+	for i, id := range productIDs {
+		fields[i] = fieldError("items", CodeInsufficientStock, strconv.FormatUint(uint64(id), 10))
+	}
+
+	err := ConflictError(CodeInsufficientStock, "Insufficient stock for the order", nil)
+	err.Fields = fields
+	return err
+}
+
+// changeStock soma delta ao estoque do produto, com auditoria e eventos como em uma atualização pela API
+func (s *InventoryServiceRepo) changeStock(ctx context.Context, product *models.Product, delta int) error {
+	before := *product
+	product.Stock += delta
+	if err := s.products.UpdateProduct(ctx, product); err != nil {
+		return translateProductError(err)
+	}
+
+	if err := recordAudit(ctx, s.audit, AuditEntityProduct, product.ID, models.AuditActionUpdate, &before, product); err != nil {
+		return err
+	}
+
+	return enqueueProductEvents(ctx, s.outbox, events.ProductUpdated, &before, product)
+}
+
+// sameReservation indica se as reservas do pedido têm as mesmas quantidades por produto que os itens,
+// somando os itens repetidos do mesmo produto como em reserve
+func sameReservation(reservations []models.StockReservation, items []events.OrderItem) bool {
+	quantities := map[uint]int{}
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	if len(quantities) != len(reservations) {
+		return false
+	}
+	for _, reservation := range reservations {
+		if quantities[reservation.ProductID] != reservation.Quantity {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deadLetter publica o evento no tópico de dead letter com o motivo da falha, para análise e reenvio manual
+func (s *InventoryServiceRepo) deadLetter(ctx context.Context, msg broker.Message, cause error) error {
+	dead := broker.DeadLetter(msg, s.dlqTopic, cause)
+	if err := s.deadLetters.Publish(ctx, dead); err != nil {
+		// Sem o dead letter o evento não pode ser descartado; ele será entregue de novo
+		return fmt.Errorf("dead letter publication failed: %w", err)
+	}
+
+	inventoryLogger.WarnContext(ctx, "event sent to dead letter topic", "event_id", msg.ID, "type", msg.Type,
+		"topic", s.dlqTopic, "error", cause)
+	return nil
+}
+
+// decodeEvent valida o payload contra o schema do tipo de evento e o decodifica em payload.
+// Versões mais novas também são aceitas, já que os schemas só evoluem de forma compatível.
+func decodeEvent(msg broker.Message, payload interface{}) error {
+	schema, err := events.LoadSchema(msg.Type, events.CurrentVersions[msg.Type])
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(msg.Value); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidEvent, err)
+	}
+	if err := json.Unmarshal(msg.Value, payload); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidEvent, err)
+	}
+
+	return nil
+}
+
+// isPermanent indica se reprocessar o evento daria o mesmo erro
+func isPermanent(err error) bool {
+	if errors.Is(err, errInvalidEvent) {
+		return true
+	}
+	domainErr, ok := AsDomainError(err)
+
+	return ok && domainErr.Kind != KindUnavailable
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockReservationRepository) CreateStockReservation(ctx context.Context, reservation *models.StockReservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+func (m *MockStockReservationRepository) GetStockReservations(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+func (m *MockStockReservationRepository) SaveStockReservation(ctx context.Context, reservation *models.StockReservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+type MockProcessedEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockProcessedEventRepository) MarkEventProcessed(ctx context.Context, event *models.ProcessedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+type inventoryTest struct {
+	service      *InventoryServiceRepo
+	products     *MockProductRepository
+	reservations *MockStockReservationRepository
+	processed    *MockProcessedEventRepository
+	audit        *MockAuditRepository
+	outbox       *MockOutboxRepository
+	broker       *broker.MemoryBroker
+	enqueued     []*models.OutboxEvent
+}
+
+func newInventoryTest() *inventoryTest {
+	test := &inventoryTest{
+		products:     new(MockProductRepository),
+		reservations: new(MockStockReservationRepository),
+		processed:    new(MockProcessedEventRepository),
+		audit:        newTestAuditRepository(),
+		outbox:       new(MockOutboxRepository),
+		broker:       broker.NewMemoryBroker(),
+	}
+	test.outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		test.enqueued = append(test.enqueued, args.Get(1).(*models.OutboxEvent))
+	}).Return(nil).Maybe()
+	test.service = NewInventoryService(test.products, test.reservations, test.processed, test.audit, test.outbox,
+		passthroughTransactor{}, test.broker, "orders.dlq")
+
+	return test
+}
+
+func (test *inventoryTest) enqueuedTypes() []string {
+	var types []string
+	for _, event := range test.enqueued {
+		types = append(types, event.Type)
+	}
+
+	return types
+}
+
+func orderMessage(id, eventType, payload string) broker.Message {
+	return broker.Message{Topic: "orders", Key: "42", ID: id, Type: eventType, SchemaVersion: 1, Value: []byte(payload),
+		Headers: map[string]string{"request_id": "req-1"}}
+}
+
+func TestInventoryOrderPlacedReservesStock(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.MatchedBy(func(event *models.ProcessedEvent) bool {
+		return event.EventID == "e1" && event.Type == events.OrderPlaced
+	})).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Stock: 10}, nil)
+	test.products.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(product *models.Product) bool {
+		return product.ID == 1 && product.Stock == 7
+	})).Return(nil)
+	test.reservations.On("CreateStockReservation", mock.Anything, mock.MatchedBy(func(reservation *models.StockReservation) bool {
+		return reservation.OrderID == 42 && reservation.ProductID == 1 && reservation.Quantity == 3 &&
+			reservation.Status == models.ReservationReserved && reservation.EventID == "e1"
+	})).Return(nil)
+
+	var record *models.AuditRecord
+	test.audit.ExpectedCalls = nil
+	test.audit.On("LastAuditHash", mock.Anything).Return("", nil)
+	test.audit.On("CreateAuditRecord", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		record = args.Get(1).(*models.AuditRecord)
+	}).Return(nil)
+
+	// Itens repetidos do mesmo produto são somados
+	msg := orderMessage("e1", events.OrderPlaced,
+		`{"order_id":42,"items":[{"product_id":1,"quantity":1},{"product_id":1,"quantity":2}],"placed_at":"2026-10-19T12:00:00Z"}`)
+	err := test.service.HandleOrderEvent(context.Background(), msg)
+	assert.NoError(t, err)
+	test.products.AssertExpectations(t)
+	test.reservations.AssertExpectations(t)
+	assert.Equal(t, []string{events.ProductUpdated, events.StockChanged}, test.enqueuedTypes())
+	if assert.NotNil(t, record) {
+		assert.Equal(t, "system:orders", record.Actor)
+		assert.Equal(t, "req-1", record.RequestID)
+	}
+	assert.Empty(t, test.broker.Messages())
+}
+
+func TestInventoryOrderPlacedWithInsufficientStock(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 10}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(2)).Return(&models.Product{ID: 2, Stock: 1}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(3)).Return((*models.Product)(nil), repositories.ErrNotFound)
+
+	msg := orderMessage("e1", events.OrderPlaced,
+		`{"order_id":42,"items":[{"product_id":3,"quantity":1},{"product_id":1,"quantity":2},{"product_id":2,"quantity":5}]}`)
+	err := test.service.HandleOrderEvent(context.Background(), msg)
+	assert.NoError(t, err)
+	// Nenhum item é reservado quando falta estoque de algum
+	test.products.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+	test.reservations.AssertNotCalled(t, "CreateStockReservation", mock.Anything, mock.Anything)
+	if assert.Len(t, test.enqueued, 1) {
+		event := test.enqueued[0]
+		assert.Equal(t, events.StockInsufficient, event.Type)
+		assert.Equal(t, events.AggregateOrder, event.AggregateType)
+		assert.Equal(t, uint(42), event.AggregateID)
+		assert.JSONEq(t, `{"order_id":42,"event_id":"e1","items":[
+			{"product_id":2,"requested":5,"available":1},
+			{"product_id":3,"requested":1,"available":0}]}`, event.Payload)
+	}
+}
+
+func TestInventoryOrderPlacedTwiceForTheSameOrder(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e2", events.OrderPlaced,
+		`{"order_id":42,"items":[{"product_id":1,"quantity":3}]}`))
+	assert.NoError(t, err)
+	test.products.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+	assert.Empty(t, test.enqueued)
+}
+
+func TestInventoryOrderPlacedWithOtherItemsThanReserved(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+
+	// O evento diverge da reserva do checkout: vai para o dead letter sem reservar de novo
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e2", events.OrderPlaced,
+		`{"order_id":42,"items":[{"product_id":1,"quantity":5}]}`))
+	assert.NoError(t, err)
+	test.products.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+	if assert.Len(t, test.broker.Messages(), 1) {
+		assert.Equal(t, "orders.dlq", test.broker.Messages()[0].Topic)
+	}
+}
+
+func TestInventorySkipsProcessedEvents(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(repositories.ErrDuplicated)
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e1", events.OrderPlaced,
+		`{"order_id":42,"items":[{"product_id":1,"quantity":3}]}`))
+	assert.NoError(t, err)
+	test.reservations.AssertNotCalled(t, "GetStockReservations", mock.Anything, mock.Anything)
+	assert.Empty(t, test.enqueued)
+	assert.Empty(t, test.broker.Messages())
+}
+
+func TestInventoryOrderCancelledReleasesStock(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+		{ID: 2, OrderID: 42, ProductID: 2, Quantity: 1, Status: models.ReservationReleased},
+	}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 7}, nil)
+	test.products.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(product *models.Product) bool {
+		return product.ID == 1 && product.Stock == 10
+	})).Return(nil)
+	test.reservations.On("SaveStockReservation", mock.Anything, mock.MatchedBy(func(reservation *models.StockReservation) bool {
+		return reservation.ID == 1 && reservation.Status == models.ReservationReleased
+	})).Return(nil).Once()
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e2", events.OrderCancelled, `{"order_id":42}`))
+	assert.NoError(t, err)
+	test.products.AssertExpectations(t)
+	test.reservations.AssertExpectations(t)
+	assert.Equal(t, []string{events.ProductUpdated, events.StockChanged}, test.enqueuedTypes())
+}
+
+func TestInventoryOrderShippedFinalizesReservations(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+	test.reservations.On("SaveStockReservation", mock.Anything, mock.MatchedBy(func(reservation *models.StockReservation) bool {
+		return reservation.ID == 1 && reservation.Status == models.ReservationShipped
+	})).Return(nil)
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e3", events.OrderShipped, `{"order_id":42}`))
+	assert.NoError(t, err)
+	test.reservations.AssertExpectations(t)
+	test.products.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+	assert.Empty(t, test.enqueued)
+}
+
+func TestInventoryOrderShippedAfterCheckoutCommit(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationShipped},
+	}, nil)
+
+	// O envio do microsserviço de pedidos já confirmou a reserva por /stock-reservations
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e3", events.OrderShipped, `{"order_id":42}`))
+	assert.NoError(t, err)
+	test.reservations.AssertNotCalled(t, "SaveStockReservation", mock.Anything, mock.Anything)
+}
+
+func TestInventorySendsInvalidEventsToDeadLetter(t *testing.T) {
+	cases := map[string]broker.Message{
+		"invalid payload":    orderMessage("e1", events.OrderPlaced, `{"order_id":"42","items":[]}`),
+		"not json":           orderMessage("e1", events.OrderCancelled, `order 42`),
+		"missing event id":   orderMessage("", events.OrderShipped, `{"order_id":42}`),
+		"invalid quantity":   orderMessage("e1", events.OrderPlaced, `{"order_id":42,"items":[{"product_id":1,"quantity":0}]}`),
+		"order without item": orderMessage("e1", events.OrderPlaced, `{"order_id":42,"items":[]}`),
+	}
+
+	for name, msg := range cases {
+		t.Run(name, func(t *testing.T) {
+			test := newInventoryTest()
+			test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(nil)
+			test.reservations.On("GetStockReservations", mock.Anything, mock.Anything).Return([]models.StockReservation{}, nil)
+
+			err := test.service.HandleOrderEvent(context.Background(), msg)
+			assert.NoError(t, err)
+			assert.Empty(t, test.enqueued)
+			messages := test.broker.Messages()
+			if assert.Len(t, messages, 1) {
+				dead := messages[0]
+				assert.Equal(t, "orders.dlq", dead.Topic)
+				assert.Equal(t, msg.ID, dead.ID)
+				assert.Equal(t, msg.Key, dead.Key)
+				assert.Equal(t, msg.Value, dead.Value)
+				assert.Equal(t, "orders", dead.Headers[broker.HeaderDeadLetterTopic])
+				assert.Contains(t, dead.Headers[broker.HeaderDeadLetterError], "invalid event")
+				assert.NotEmpty(t, dead.Headers[broker.HeaderDeadLetterFailedAt])
+				assert.Equal(t, "req-1", dead.Headers["request_id"])
+			}
+			assert.Equal(t, "", msg.Headers[broker.HeaderDeadLetterError], "original headers must not change")
+		})
+	}
+}
+
+func TestInventoryRetriesWhenDeadLetterFails(t *testing.T) {
+	test := newInventoryTest()
+	test.broker.SetError(errors.New("broker down"))
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e1", events.OrderPlaced, `{}`))
+	assert.Error(t, err)
+}
+
+func TestInventoryRetriesTransientFailures(t *testing.T) {
+	test := newInventoryTest()
+	test.processed.On("MarkEventProcessed", mock.Anything, mock.Anything).Return(errors.New("database is locked"))
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e1", events.OrderCancelled, `{"order_id":42}`))
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, KindUnavailable, domainErr.Kind)
+	}
+	assert.Empty(t, test.broker.Messages())
+}
+
+func TestInventoryIgnoresOtherOrderEvents(t *testing.T) {
+	test := newInventoryTest()
+
+	err := test.service.HandleOrderEvent(context.Background(), orderMessage("e1", "OrderPaid", `{"order_id":42}`))
+	assert.NoError(t, err)
+	test.processed.AssertNotCalled(t, "MarkEventProcessed", mock.Anything, mock.Anything)
+	assert.Empty(t, test.broker.Messages())
+}
+
+func TestInventoryReserveStock(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 10}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(2)).Return(&models.Product{ID: 2, Stock: 1}, nil)
+	test.products.On("UpdateProduct", mock.Anything, mock.Anything).Return(nil)
+	test.reservations.On("CreateStockReservation", mock.Anything, mock.Anything).Return(nil)
+
+	reservations, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		OrderID: 42,
+		Items:   []models.StockReservationItem{{ProductID: 2, Quantity: 1}, {ProductID: 1, Quantity: 2}, {ProductID: 1, Quantity: 1}},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, reservations, 2) {
+		assert.Equal(t, uint(1), reservations[0].ProductID)
+		assert.Equal(t, 3, reservations[0].Quantity)
+		assert.Equal(t, models.ReservationReserved, reservations[1].Status)
+	}
+	test.products.AssertNumberOfCalls(t, "UpdateProduct", 2)
+	assert.Equal(t, []string{events.ProductUpdated, events.StockChanged, events.ProductUpdated, events.StockChanged}, test.enqueuedTypes())
+}
+
+func TestInventoryReserveStockWithInsufficientStock(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 10}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(2)).Return(&models.Product{ID: 2, Stock: 1}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(3)).Return((*models.Product)(nil), repositories.ErrNotFound)
+
+	_, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		OrderID: 42,
+		Items:   []models.StockReservationItem{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 5}, {ProductID: 3, Quantity: 1}},
+	})
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, KindConflict, domainErr.Kind)
+		assert.Equal(t, CodeInsufficientStock, domainErr.Code)
+		if assert.Len(t, domainErr.Fields, 2) {
+			assert.Equal(t, "2", domainErr.Fields[0].Param)
+			assert.Equal(t, "3", domainErr.Fields[1].Param)
+		}
+	}
+	test.products.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+	test.reservations.AssertNotCalled(t, "CreateStockReservation", mock.Anything, mock.Anything)
+}
+
+func TestInventoryReserveStockIsIdempotent(t *testing.T) {
+	test := newInventoryTest()
+	existing := []models.StockReservation{{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved}}
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return(existing, nil)
+
+	reservations, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		OrderID: 42, Items: []models.StockReservationItem{{ProductID: 1, Quantity: 3}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, existing, reservations)
+	test.products.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+}
+
+func TestInventoryReserveStockWithOtherItems(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+
+	for _, items := range [][]models.StockReservationItem{
+		{{ProductID: 1, Quantity: 2}},
+		{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}},
+		{{ProductID: 2, Quantity: 3}},
+	} {
+		_, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{OrderID: 42, Items: items})
+		domainErr, ok := AsDomainError(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, KindConflict, domainErr.Kind)
+			assert.Equal(t, CodeStockReservationMismatch, domainErr.Code)
+		}
+	}
+
+	// Itens repetidos do mesmo produto são somados antes da comparação
+	_, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		OrderID: 42, Items: []models.StockReservationItem{{ProductID: 1, Quantity: 1}, {ProductID: 1, Quantity: 2}},
+	})
+	assert.NoError(t, err)
+	test.products.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+}
+
+func TestInventoryReserveStockOfReleasedOrder(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReleased},
+	}, nil)
+
+	_, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		OrderID: 42, Items: []models.StockReservationItem{{ProductID: 1, Quantity: 3}},
+	})
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CodeStockReservationClosed, domainErr.Code)
+	}
+}
+
+func TestInventoryReserveStockValidation(t *testing.T) {
+	test := newInventoryTest()
+
+	_, err := test.service.ReserveStock(context.Background(), &models.StockReservationRequest{
+		Items: []models.StockReservationItem{{ProductID: 1, Quantity: 0}},
+	})
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, KindValidation, domainErr.Kind)
+		var fields []string
+		for _, field := range domainErr.Fields {
+			fields = append(fields, field.Field+":"+field.Code)
+		}
+		assert.ElementsMatch(t, []string{"order_id:required", "quantity:gt"}, fields)
+	}
+
+	_, err = test.service.ReserveStock(context.Background(), &models.StockReservationRequest{OrderID: 42})
+	domainErr, ok = AsDomainError(err)
+	if assert.True(t, ok) && assert.Len(t, domainErr.Fields, 1) {
+		assert.Equal(t, "items", domainErr.Fields[0].Field)
+	}
+	test.reservations.AssertNotCalled(t, "GetStockReservations", mock.Anything, mock.Anything)
+}
+
+func TestInventoryReleaseStock(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+	}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 7}, nil)
+	test.products.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(product *models.Product) bool {
+		return product.Stock == 10
+	})).Return(nil)
+	test.reservations.On("SaveStockReservation", mock.Anything, mock.MatchedBy(func(reservation *models.StockReservation) bool {
+		return reservation.Status == models.ReservationReleased
+	})).Return(nil)
+
+	assert.NoError(t, test.service.ReleaseStock(context.Background(), 42))
+	test.products.AssertExpectations(t)
+	test.reservations.AssertExpectations(t)
+}
+
+func TestInventoryReleaseStockWithoutReservations(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+
+	// A compensação do checkout libera o pedido mesmo quando a reserva não chegou a ser feita
+	assert.NoError(t, test.service.ReleaseStock(context.Background(), 42))
+	test.products.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func TestInventoryReleaseStockOfShippedOrder(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationShipped},
+	}, nil)
+
+	err := test.service.ReleaseStock(context.Background(), 42)
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, KindConflict, domainErr.Kind)
+		assert.Equal(t, CodeStockReservationClosed, domainErr.Code)
+	}
+	test.reservations.AssertNotCalled(t, "SaveStockReservation", mock.Anything, mock.Anything)
+}
+
+func TestInventoryCommitStock(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReserved},
+		{ID: 2, OrderID: 42, ProductID: 2, Quantity: 1, Status: models.ReservationShipped},
+	}, nil)
+	test.reservations.On("SaveStockReservation", mock.Anything, mock.MatchedBy(func(reservation *models.StockReservation) bool {
+		return reservation.ID == 1 && reservation.Status == models.ReservationShipped
+	})).Return(nil).Once()
+
+	reservations, err := test.service.CommitStock(context.Background(), 42)
+	assert.NoError(t, err)
+	if assert.Len(t, reservations, 2) {
+		assert.Equal(t, models.ReservationShipped, reservations[0].Status)
+	}
+	test.reservations.AssertExpectations(t)
+	test.products.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func TestInventoryCommitStockErrors(t *testing.T) {
+	test := newInventoryTest()
+	test.reservations.On("GetStockReservations", mock.Anything, uint(41)).Return([]models.StockReservation{}, nil)
+	test.reservations.On("GetStockReservations", mock.Anything, uint(42)).Return([]models.StockReservation{
+		{ID: 1, OrderID: 42, ProductID: 1, Quantity: 3, Status: models.ReservationReleased},
+	}, nil)
+
+	_, err := test.service.CommitStock(context.Background(), 41)
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CodeStockReservationNotFound, domainErr.Code)
+	}
+
+	_, err = test.service.CommitStock(context.Background(), 42)
+	domainErr, ok = AsDomainError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CodeStockReservationClosed, domainErr.Code)
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
+	"produtos-api/src/logging"
+	"produtos-api/src/mail"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Lote de leitura e limites do backoff entre as tentativas de envio da fila de e-mails
+const (
+	mailBatchSize = 100
+	mailRetryBase = 30 * time.Second
+	mailRetryMax  = time.Hour
+)
+
+var notificationLogger = logging.Logger("notifications")
+
+// NotificationConfig define o remetente dos e-mails, as tentativas de envio de cada mensagem e por quanto
+// tempo as mensagens concluídas ficam na fila
+type NotificationConfig struct {
+	From        string
+	MaxAttempts int
+	Retention   time.Duration
+}
+
+// Notification é um e-mail a enviar. Key identifica a notificação: enfileirar de novo a mesma chave não
+// envia outro e-mail. Data são os dados do template, como mail.OrderConfirmed.
+type Notification struct {
+	Key      string
+	Template string
+	Locale   string
+	To       string
+	Data     interface{}
+}
+
+type NotificationService interface {
+	Notify(ctx context.Context, notification Notification) error
+	CountQueued(ctx context.Context, template, to string, since time.Time) (int, error)
+	HandleEvent(ctx context.Context, msg broker.Message) error
+	Deliver(ctx context.Context) (int, error)
+}
+
+type NotificationServiceRepo struct {
+	repo      repositories.MailRepository
+	transport mail.Transport
+	config    NotificationConfig
+	now       func() time.Time
+}
+
+// NewNotificationService cria o serviço de notificações, que transforma eventos de domínio em e-mails,
+// os renderiza no idioma do destinatário e os envia pela fila com o transporte informado
+func NewNotificationService(repo repositories.MailRepository, transport mail.Transport, config NotificationConfig) *NotificationServiceRepo {
+	return &NotificationServiceRepo{repo: repo, transport: transport, config: config, now: time.Now}
+}
+
+// Notify renderiza o e-mail e o coloca na fila. O conteúdo é gravado já renderizado, para que as novas
+// tentativas enviem exatamente a mesma mensagem.
+func (s *NotificationServiceRepo) Notify(ctx context.Context, notification Notification) error {
+	ctx, span := tracer.Start(ctx, "NotificationService.Notify")
+	defer span.End()
+	span.SetAttributes(attribute.String("mail.template", notification.Template))
+
+	locale := i18n.Negotiate(notification.Locale)
+	content, err := mail.Render(notification.Template, locale, notification.Data)
+	if err != nil {
+		return err
+	}
+
+	message := &models.MailMessage{
+		Key:           notification.Key,
+		Template:      notification.Template,
+		Locale:        locale,
+		To:            notification.To,
+		Subject:       content.Subject,
+		Text:          content.Text,
+		HTML:          content.HTML,
+		Status:        models.MailPending,
+		NextAttemptAt: s.now().UTC(),
+		CreatedAt:     s.now().UTC(),
+	}
+	if err := s.repo.CreateMailMessage(ctx, message); err != nil && !errors.Is(err, repositories.ErrDuplicated) {
+		return translateMailError(err)
+	}
+
+	return nil
+}
+
+// CountQueued conta os e-mails do template enfileirados para o endereço desde since, sem diferenciar
+// maiúsculas. Chamado na transação que enfileira o próximo e-mail, aplica limites por destinatário que
+// não são consumidos quando a transação falha.
+func (s *NotificationServiceRepo) CountQueued(ctx context.Context, template, to string, since time.Time) (int, error) {
+	count, err := s.repo.CountMailMessages(ctx, template, to, since.UTC())
+	return int(count), translateMailError(err)
+}
+
+// HandleEvent recebe os eventos publicados pela outbox e enfileira os e-mails correspondentes;
+// os demais tipos de evento são ignorados
+func (s *NotificationServiceRepo) HandleEvent(ctx context.Context, msg broker.Message) error {
+	switch msg.Type {
+	case events.OrderConfirmed:
+		var confirmation events.OrderConfirmation
+		if err := json.Unmarshal(msg.Value, &confirmation); err != nil {
+			// Um payload inválido nunca vai ser processado: reprocessar apenas bloquearia os eventos seguintes
+			notificationLogger.ErrorContext(ctx, "invalid event payload", "event_id", msg.ID, "type", msg.Type, "error", err)
+			return nil
+		}
+		if confirmation.Email == "" {
+			return nil
+		}
+
+		data := mail.OrderConfirmed{OrderID: confirmation.OrderID, Total: confirmation.Total}
+		for _, item := range confirmation.Items {
+			data.Items = append(data.Items, mail.OrderItem{Name: item.Name, Quantity: item.Quantity, UnitPrice: item.UnitPrice})
+		}
+		return s.Notify(ctx, Notification{
+			Key:      msg.ID + ":" + confirmation.Email,
+			Template: mail.TemplateOrderConfirmed,
+			Locale:   confirmation.Locale,
+			To:       confirmation.Email,
+			Data:     data,
+		})
+	default:
+		return nil
+	}
+}
+
+// Deliver envia as mensagens pendentes da fila e retorna quantas foram aceitas pelo transporte. Uma mensagem
+// que falha é reagendada com backoff até esgotar MaxAttempts. Falhas do transporte são registradas nas
+// mensagens; o erro retornado indica falhas da própria fila.
+func (s *NotificationServiceRepo) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.Deliver")
+	defer span.End()
+
+	now := s.now().UTC()
+	sent := 0
+	defer func() { span.SetAttributes(attribute.Int("mail.sent", sent)) }()
+
+	var afterID uint
+	for {
+		messages, err := s.repo.GetPendingMailMessages(ctx, afterID, mailBatchSize)
+		if err != nil {
+			return sent, translateMailError(err)
+		}
+
+		for i := range messages {
+			message := &messages[i]
+			if message.NextAttemptAt.After(now) {
+				continue
+			}
+
+			err := s.transport.Send(ctx, mail.Message{
+				From:    s.config.From,
+				To:      message.To,
+				Subject: message.Subject,
+				Text:    message.Text,
+				HTML:    message.HTML,
+			})
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			if s.record(ctx, message, err, now) {
+				sent++
+			}
+
+			if err := s.repo.SaveMailMessage(ctx, message); err != nil {
+				return sent, translateMailError(err)
+			}
+		}
+
+		if len(messages) < mailBatchSize {
+			break
+		}
+		afterID = messages[len(messages)-1].ID
+	}
+
+	if s.config.Retention > 0 {
+		if _, err := s.repo.DeleteMailMessages(ctx, now.Add(-s.config.Retention)); err != nil {
+			return sent, translateMailError(err)
+		}
+	}
+
+	return sent, nil
+}
+
+// record registra o resultado da tentativa na mensagem e retorna se ela foi enviada
+func (s *NotificationServiceRepo) record(ctx context.Context, message *models.MailMessage, err error, now time.Time) bool {
+	attemptedAt := now
+	message.Attempts++
+	message.LastAttemptAt = &attemptedAt
+
+	if err == nil {
+		message.Status = models.MailSent
+		message.SentAt = &attemptedAt
+		message.LastError = ""
+		return true
+	}
+
+	message.LastError = err.Error()
+	if message.Attempts >= s.config.MaxAttempts {
+		message.Status = models.MailFailed
+	} else {
+		message.NextAttemptAt = now.Add(retryBackoff(message.Attempts, mailRetryBase, mailRetryMax))
+	}
+	notificationLogger.WarnContext(ctx, "mail delivery failed", "message_id", message.ID, "template", message.Template,
+		"attempts", message.Attempts, "status", message.Status, "error", err)
+
+	return false
+}
+
+func translateMailError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	default:
+		return UnavailableError(CodeDatabaseUnavailable, "Mail queue is unavailable", err)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/database"
+	"produtos-api/src/events"
+	"produtos-api/src/mail"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockMailRepository struct {
+	mock.Mock
+}
+
+func (m *MockMailRepository) CreateMailMessage(ctx context.Context, message *models.MailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMailRepository) GetPendingMailMessages(ctx context.Context, afterID uint, limit int) ([]models.MailMessage, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]models.MailMessage), args.Error(1)
+}
+
+func (m *MockMailRepository) SaveMailMessage(ctx context.Context, message *models.MailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMailRepository) CountMailMessages(ctx context.Context, template, to string, since time.Time) (int64, error) {
+	args := m.Called(ctx, template, to, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMailRepository) DeleteMailMessages(ctx context.Context, attemptedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, attemptedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// failingTransport recusa todas as mensagens
+type failingTransport struct{}
+
+func (failingTransport) Send(ctx context.Context, msg mail.Message) error {
+	return errors.New("connection refused")
+}
+
+var notificationTestNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newNotificationTest(transport mail.Transport) (*NotificationServiceRepo, *MockMailRepository) {
+	repo := new(MockMailRepository)
+	service := NewNotificationService(repo, transport, NotificationConfig{From: "Loja <loja@example.com>", MaxAttempts: 3})
+	service.now = func() time.Time { return notificationTestNow }
+
+	return service, repo
+}
+
+func orderConfirmedMessage(t *testing.T, confirmation events.OrderConfirmation) broker.Message {
+	value, err := json.Marshal(confirmation)
+	require.NoError(t, err)
+
+	return broker.Message{ID: "e1", Type: events.OrderConfirmed, Value: value}
+}
+
+func TestServiceHandleOrderConfirmed(t *testing.T) {
+	service, repo := newNotificationTest(mail.NewMemoryTransport())
+	var queued *models.MailMessage
+	repo.On("CreateMailMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		queued = args.Get(1).(*models.MailMessage)
+	}).Return(nil)
+
+	err := service.HandleEvent(context.Background(), orderConfirmedMessage(t, events.OrderConfirmation{
+		OrderID: 42, Email: "maria@example.com", Locale: "pt-BR", Total: 5,
+		Items: []events.ConfirmedItem{{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}},
+	}))
+	require.NoError(t, err)
+
+	require.NotNil(t, queued)
+	assert.Equal(t, "e1:maria@example.com", queued.Key)
+	assert.Equal(t, mail.TemplateOrderConfirmed, queued.Template)
+	assert.Equal(t, "pt-BR", queued.Locale)
+	assert.Equal(t, "maria@example.com", queued.To)
+	assert.Equal(t, "Pedido nº 42 confirmado", queued.Subject)
+	assert.Contains(t, queued.Text, "- 2 x Caneta: R$ 2,50")
+	assert.Equal(t, models.MailPending, queued.Status)
+	assert.Equal(t, notificationTestNow, queued.NextAttemptAt)
+}
+
+func TestServiceHandleEventIgnoresOtherEvents(t *testing.T) {
+	service, repo := newNotificationTest(mail.NewMemoryTransport())
+
+	// Pedidos sem e-mail, outros tipos de evento e payloads inválidos não geram e-mails nem são reprocessados
+	require.NoError(t, service.HandleEvent(context.Background(), orderConfirmedMessage(t, events.OrderConfirmation{OrderID: 42})))
+	require.NoError(t, service.HandleEvent(context.Background(), broker.Message{ID: "e2", Type: events.ProductCreated, Value: []byte(`{}`)}))
+	require.NoError(t, service.HandleEvent(context.Background(), broker.Message{ID: "e3", Type: events.OrderConfirmed, Value: []byte(`{`)}))
+
+	repo.AssertNotCalled(t, "CreateMailMessage", mock.Anything, mock.Anything)
+}
+
+func TestServiceNotifyIgnoresDuplicates(t *testing.T) {
+	service, repo := newNotificationTest(mail.NewMemoryTransport())
+	repo.On("CreateMailMessage", mock.Anything, mock.Anything).Return(repositories.ErrDuplicated)
+
+	err := service.Notify(context.Background(), Notification{
+		Key: "k1", Template: mail.TemplateBackInStock, Locale: "en", To: "maria@example.com",
+		Data: mail.BackInStock{ProductID: 1, ProductName: "Caneta", Price: 2.5},
+	})
+	assert.NoError(t, err)
+}
+
+func TestServiceNotifyRejectsUnknownTemplate(t *testing.T) {
+	service, repo := newNotificationTest(mail.NewMemoryTransport())
+
+	err := service.Notify(context.Background(), Notification{Key: "k1", Template: "unknown", To: "maria@example.com"})
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "CreateMailMessage", mock.Anything, mock.Anything)
+}
+
+func TestServiceDeliverSendsPendingMail(t *testing.T) {
+	transport := mail.NewMemoryTransport()
+	service, repo := newNotificationTest(transport)
+	repo.On("GetPendingMailMessages", mock.Anything, uint(0), mailBatchSize).Return([]models.MailMessage{
+		{ID: 1, To: "maria@example.com", Subject: "Oi", Text: "texto", Status: models.MailPending, NextAttemptAt: notificationTestNow},
+		// Ainda aguardando o backoff
+		{ID: 2, To: "joao@example.com", Subject: "Oi", Status: models.MailPending, NextAttemptAt: notificationTestNow.Add(time.Minute)},
+	}, nil)
+	repo.On("SaveMailMessage", mock.Anything, mock.MatchedBy(func(message *models.MailMessage) bool {
+		return message.ID == 1 && message.Status == models.MailSent && message.Attempts == 1 && message.SentAt != nil
+	})).Return(nil)
+
+	sent, err := service.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	messages := transport.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Loja <loja@example.com>", messages[0].From)
+	assert.Equal(t, "maria@example.com", messages[0].To)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceDeliverRetriesWithBackoff(t *testing.T) {
+	service, repo := newNotificationTest(failingTransport{})
+	repo.On("GetPendingMailMessages", mock.Anything, uint(0), mailBatchSize).Return([]models.MailMessage{
+		{ID: 1, To: "maria@example.com", Status: models.MailPending, NextAttemptAt: notificationTestNow},
+		{ID: 2, To: "joao@example.com", Status: models.MailPending, Attempts: 2, NextAttemptAt: notificationTestNow},
+	}, nil)
+
+	var saved []models.MailMessage
+	repo.On("SaveMailMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, *args.Get(1).(*models.MailMessage))
+	}).Return(nil)
+
+	sent, err := service.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, models.MailPending, saved[0].Status)
+	assert.Equal(t, notificationTestNow.Add(mailRetryBase), saved[0].NextAttemptAt)
+	assert.Equal(t, "connection refused", saved[0].LastError)
+	// A terceira falha esgota as tentativas
+	assert.Equal(t, models.MailFailed, saved[1].Status)
+	assert.Equal(t, 3, saved[1].Attempts)
+}
+
+func TestOrderConfirmationEndToEnd(t *testing.T) {
+	db, err := database.SetupTestDatabase()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Cada conexão com :memory: abre um banco vazio
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(database.Orders.Models...))
+
+	orders := repositories.NewOrderRepository(db)
+	outbox := repositories.NewOutboxRepository(db)
+	order := &models.Order{
+		Email: "maria@example.com", Locale: "pt-BR", Status: models.OrderPending, StockReserved: true, Total: 5, CreatedBy: "maria",
+		Items: []models.OrderItem{{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}},
+	}
+	require.NoError(t, orders.CreateOrder(context.Background(), order))
+
+	transport := mail.NewMemoryTransport()
+	notifications := NewNotificationService(repositories.NewMailRepository(db), transport, NotificationConfig{From: "Loja <loja@example.com>", MaxAttempts: 3})
+	orderService := NewOrderService(orders, outbox, repositories.NewTransactor(db), new(MockCatalogClient), new(MockCustomerClient), 5*time.Minute)
+	dispatcher := NewOutboxService(outbox, broker.Fanout{broker.PublisherFunc(notifications.HandleEvent)}, "orders", 0)
+
+	_, err = orderService.PayOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+
+	// O worker outbox repassa OrderConfirmed às notificações, que enfileiram o e-mail
+	published, err := dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	published, err = dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	sent, err := notifications.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	messages := transport.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "maria@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Subject, "confirmado")
+	assert.Contains(t, messages[0].Text, "- 2 x Caneta: R$ 2,50")
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/catalog"
+	"produtos-api/src/customers"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Códigos dos erros do microsserviço de pedidos
+const (
+	CodeOrderNotFound           = "order_not_found"
+	CodeInvalidOrderTransition  = "invalid_order_transition"
+	CodeCatalogUnavailable      = "catalog_unavailable"
+	CodeOrderStorageUnavailable = "order_storage_unavailable"
+)
+
+// Motivo dos pedidos cancelados pelo próprio serviço quando o checkout não termina
+const CancelReasonCheckoutFailed = "checkout_failed"
+
+// Limites da listagem de pedidos e do lote de compensação
+const (
+	DefaultOrderLimit   = 50
+	MaxOrderLimit       = 500
+	compensateBatchSize = 100
+)
+
+var orderLogger = logging.Logger("orders")
+
+// CatalogClient é a API de produtos vista pelo checkout; implementado por *catalog.Client
+type CatalogClient interface {
+	GetProduct(ctx context.Context, id uint) (*models.Product, error)
+	ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error)
+	ReleaseStock(ctx context.Context, orderID uint) error
+	CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error)
+}
+
+// OrderService define o checkout e as transições de estado dos pedidos
+type OrderService interface {
+	Checkout(ctx context.Context, request *models.OrderRequest) (*models.Order, error)
+	GetOrderByID(ctx context.Context, id uint) (*models.Order, error)
+	GetOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+	PayOrder(ctx context.Context, id uint) (*models.Order, error)
+	ShipOrder(ctx context.Context, id uint) (*models.Order, error)
+	CancelOrder(ctx context.Context, id uint, request *models.OrderCancelRequest) (*models.Order, error)
+	Compensate(ctx context.Context) (int, error)
+}
+
+type OrderServiceRepo struct {
+	repo            repositories.OrderRepository
+	outbox          repositories.OutboxRepository
+	transactor      repositories.Transactor
+	catalog         CatalogClient
+	customers       CustomerClient
+	checkoutTimeout time.Duration
+	now             func() time.Time
+}
+
+// NewOrderService cria o serviço de pedidos. Pedidos pendentes sem reserva confirmada há mais de
+// checkoutTimeout são considerados checkouts interrompidos e têm as reservas devolvidas por Compensate.
+// Os eventos dos pedidos (OrderPlaced, OrderConfirmed, OrderShipped e OrderCancelled) são gravados na outbox
+// do banco de pedidos, na mesma transação da mudança de status. O cliente informado no checkout é verificado
+// na API de clientes.
+func NewOrderService(repo repositories.OrderRepository, outbox repositories.OutboxRepository, transactor repositories.Transactor,
+	catalog CatalogClient, customers CustomerClient, checkoutTimeout time.Duration) *OrderServiceRepo {
+	return &OrderServiceRepo{repo: repo, outbox: outbox, transactor: transactor, catalog: catalog, customers: customers,
+		checkoutTimeout: checkoutTimeout, now: time.Now}
+}
+
+// Checkout cria o pedido com os preços atuais dos produtos e reserva o estoque de todos os itens, ou de nenhum.
+// O pedido é gravado como pendente antes da reserva, para que o ID identifique as reservas na API de produtos.
+// Se a reserva falhar, o pedido é removido; se não for possível saber se o estoque foi reservado, a reserva é
+// devolvida e, quando nem isso for possível, o pedido fica cancelado até que Compensate devolva o estoque.
+func (s *OrderServiceRepo) Checkout(ctx context.Context, request *models.OrderRequest) (*models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.Checkout")
+	defer span.End()
+
+	if err := validateOrder(request); err != nil {
+		return nil, err
+	}
+	if request.CustomerID != 0 {
+		if err := s.checkCustomer(ctx, request.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := s.newOrder(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateOrder(ctx, order); err != nil {
+		return nil, translateOrderError(err)
+	}
+	span.SetAttributes(attribute.Int("order.id", int(order.ID)))
+
+	reservation := &models.StockReservationRequest{OrderID: order.ID}
+	for _, item := range order.Items {
+		reservation.Items = append(reservation.Items, models.StockReservationItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	_, err = s.catalog.ReserveStock(ctx, reservation)
+	var insufficient *catalog.InsufficientStockError
+	switch {
+	case errors.As(err, &insufficient):
+		// A reserva é tudo ou nada: nenhum estoque ficou preso e o pedido pode ser descartado
+		s.discard(ctx, order)
+		return nil, insufficientStockError(insufficient.ProductIDs)
+	case err != nil:
+		s.abortCheckout(ctx, order)
+		return nil, catalogError(err)
+	}
+
+	order.StockReserved = true
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateOrder(ctx, order, models.OrderPending); err != nil {
+			return err
+		}
+		return enqueueEvent(ctx, s.outbox, events.OrderPlaced, events.AggregateOrder, order.ID, events.NewOrder(order))
+	})
+	if err != nil {
+		// A reserva existe: o pedido segue pendente sem reserva confirmada e Compensate devolve o estoque
+		return nil, translateOrderError(err)
+	}
+
+	return order, nil
+}
+
+// checkCustomer verifica se o cliente existe e se é do titular autenticado; editores fazem pedidos para qualquer
+// cliente. Clientes de outros titulares são tratados como inexistentes, para não revelar quais IDs existem.
+func (s *OrderServiceRepo) checkCustomer(ctx context.Context, id uint) error {
+	customer, err := s.customers.GetCustomer(ctx, id)
+	if errors.Is(err, customers.ErrNotFound) {
+		return ValidationError(fieldError("customer_id", "exists", strconv.FormatUint(uint64(id), 10)))
+	}
+	if err != nil {
+		return customersError(err)
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil || (customer.Subject != principal.Subject && !principal.HasRole(auth.RoleEditor)) {
+		return ValidationError(fieldError("customer_id", "exists", strconv.FormatUint(uint64(id), 10)))
+	}
+
+	return nil
+}
+
+// newOrder monta o pedido pendente, somando os itens repetidos e usando o nome e o preço atuais de cada produto.
+// O idioma negociado na requisição fica no pedido, para as notificações enviadas depois.
+func (s *OrderServiceRepo) newOrder(ctx context.Context, request *models.OrderRequest) (*models.Order, error) {
+	quantities := make(map[uint]int)
+	var productIDs []uint
+	for _, item := range request.Items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+	order := &models.Order{
+		CustomerID: request.CustomerID,
+		Email:      request.Email,
+		Locale:     i18n.Negotiate(strings.Join(i18n.ContentLocales(ctx), ",")),
+		Status:     models.OrderPending,
+	}
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		order.CreatedBy = principal.Subject
+	}
+	var fields []FieldError
+	for _, id := range productIDs {
+		product, err := s.catalog.GetProduct(ctx, id)
+		if errors.Is(err, catalog.ErrNotFound) {
+			fields = append(fields, fieldError("product_id", "exists", strconv.FormatUint(uint64(id), 10)))
+			continue
+		}
+		if err != nil {
+			return nil, catalogError(err)
+		}
+
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID: id,
+			Name:      product.Name,
+			UnitPrice: product.Price,
+			Quantity:  quantities[id],
+		})
+		order.Total += product.Price * float64(quantities[id])
+	}
+	if len(fields) > 0 {
+		return nil, ValidationError(fields...)
+	}
+	order.Total = math.Round(order.Total*100) / 100
+
+	return order, nil
+}
+
+// abortCheckout desfaz o checkout cujo resultado da reserva é incerto: devolve o que possa ter sido
+// reservado e descarta o pedido. Se a devolução falhar, o pedido fica cancelado para Compensate.
+func (s *OrderServiceRepo) abortCheckout(ctx context.Context, order *models.Order) {
+	// A compensação não deve ser interrompida pelo cancelamento da requisição que falhou
+	ctx = context.WithoutCancel(ctx)
+
+	if err := s.catalog.ReleaseStock(ctx, order.ID); err != nil {
+		orderLogger.WarnContext(ctx, "Failed to release stock of aborted checkout", "order_id", order.ID, "error", err)
+
+		now := s.now()
+		order.Status = models.OrderCancelled
+		order.CancelReason = CancelReasonCheckoutFailed
+		order.CancelledAt = &now
+		if err := s.repo.UpdateOrder(ctx, order, models.OrderPending); err != nil {
+			orderLogger.ErrorContext(ctx, "Failed to cancel aborted checkout", "order_id", order.ID, "error", err)
+		}
+		return
+	}
+
+	s.discard(ctx, order)
+}
+
+// discard remove o pedido de um checkout sem estoque reservado; se falhar, Compensate o cancela depois
+func (s *OrderServiceRepo) discard(ctx context.Context, order *models.Order) {
+	if err := s.repo.DeleteOrder(context.WithoutCancel(ctx), order.ID); err != nil {
+		orderLogger.ErrorContext(ctx, "Failed to discard failed checkout", "order_id", order.ID, "error", err)
+	}
+}
+
+// GetOrderByID retorna o pedido a quem o criou e a editores. Para não revelar quais pedidos existem, os de
+// outros titulares são reportados como inexistentes.
+func (s *OrderServiceRepo) GetOrderByID(ctx context.Context, id uint) (*models.Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, translateOrderError(err)
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	if principal.HasRole(auth.RoleEditor) || (principal != nil && order.CreatedBy != "" && principal.Subject == order.CreatedBy) {
+		return order, nil
+	}
+
+	return nil, NotFoundError(CodeOrderNotFound, "Order not found", nil)
+}
+
+// GetOrders lista os pedidos, dos mais recentes para os mais antigos
+func (s *OrderServiceRepo) GetOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	var fields []FieldError
+	switch filter.Status {
+	case "", models.OrderPending, models.OrderPaid, models.OrderShipped, models.OrderCancelled:
+	default:
+		fields = append(fields, fieldError("status", "invalid", ""))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultOrderLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxOrderLimit {
+		fields = append(fields, fieldError("limit", "invalid", ""))
+	}
+	if len(fields) > 0 {
+		return nil, ValidationError(fields...)
+	}
+
+	orders, err := s.repo.FindOrders(ctx, filter)
+	if err != nil {
+		return nil, translateOrderError(err)
+	}
+
+	return orders, nil
+}
+
+// PayOrder confirma o pagamento de um pedido pendente e grava OrderConfirmed na mesma transação,
+// para que o cliente seja avisado exatamente quando o pagamento for confirmado
+func (s *OrderServiceRepo) PayOrder(ctx context.Context, id uint) (*models.Order, error) {
+	order, err := s.transitionable(ctx, id, models.OrderPaid, models.OrderPending)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	order.Status = models.OrderPaid
+	order.PaidAt = &now
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateOrder(ctx, order, models.OrderPending); err != nil {
+			return translateTransitionError(err)
+		}
+
+		confirmation := events.NewOrderConfirmation(order)
+		return translateOrderError(enqueueEvent(ctx, s.outbox, events.OrderConfirmed, events.AggregateOrder, order.ID, confirmation))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ShipOrder envia um pedido pago e grava OrderShipped; as reservas se tornam definitivas antes da mudança
+// de status, e repetir o envio depois de uma falha é seguro
+func (s *OrderServiceRepo) ShipOrder(ctx context.Context, id uint) (*models.Order, error) {
+	order, err := s.transitionable(ctx, id, models.OrderShipped, models.OrderPaid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.catalog.CommitStock(ctx, order.ID); err != nil {
+		return nil, catalogError(err)
+	}
+
+	now := s.now()
+	order.Status = models.OrderShipped
+	order.ShippedAt = &now
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateOrder(ctx, order, models.OrderPaid); err != nil {
+			return translateTransitionError(err)
+		}
+
+		shipment := events.OrderShipment{OrderID: order.ID, ShippedAt: now}
+		return translateOrderError(enqueueEvent(ctx, s.outbox, events.OrderShipped, events.AggregateOrder, order.ID, shipment))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CancelOrder cancela um pedido pendente ou pago, grava OrderCancelled e devolve o estoque reservado. O cancelamento
+// é gravado antes da devolução; se a API de produtos falhar, o pedido fica com stock_released=false até Compensate.
+func (s *OrderServiceRepo) CancelOrder(ctx context.Context, id uint, request *models.OrderCancelRequest) (*models.Order, error) {
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+	if len(fields) > 0 {
+		return nil, ValidationError(fields...)
+	}
+
+	order, err := s.transitionable(ctx, id, models.OrderCancelled, models.OrderPending, models.OrderPaid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	previous := order.Status
+	order.Status = models.OrderCancelled
+	order.CancelReason = request.Reason
+	order.CancelledAt = &now
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateOrder(ctx, order, previous); err != nil {
+			return translateTransitionError(err)
+		}
+
+		cancellation := events.OrderCancellation{OrderID: order.ID, CancelledAt: now}
+		return translateOrderError(enqueueEvent(ctx, s.outbox, events.OrderCancelled, events.AggregateOrder, order.ID, cancellation))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.release(ctx, order); err != nil {
+		orderLogger.WarnContext(ctx, "Failed to release stock of cancelled order", "order_id", order.ID, "error", err)
+	}
+
+	return order, nil
+}
+
+// Compensate devolve o estoque dos pedidos cancelados que ainda não o devolveram e cancela os checkouts
+// interrompidos há mais de checkoutTimeout. Retorna quantos pedidos foram compensados.
+func (s *OrderServiceRepo) Compensate(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.Compensate")
+	defer span.End()
+
+	orders, err := s.repo.GetUnreleasedOrders(ctx, s.now().Add(-s.checkoutTimeout), compensateBatchSize)
+	if err != nil {
+		return 0, translateOrderError(err)
+	}
+
+	compensated := 0
+	var firstErr error
+	for i := range orders {
+		order := &orders[i]
+		if order.Status == models.OrderPending {
+			// Checkout interrompido: cancelado antes da devolução, para que não possa mais ser pago
+			now := s.now()
+			order.Status = models.OrderCancelled
+			order.CancelReason = CancelReasonCheckoutFailed
+			order.CancelledAt = &now
+			if err := s.repo.UpdateOrder(ctx, order, models.OrderPending); err != nil {
+				if !errors.Is(err, repositories.ErrNotFound) && firstErr == nil {
+					firstErr = translateOrderError(err)
+				}
+				continue
+			}
+		}
+
+		if err := s.release(ctx, order); err != nil {
+			orderLogger.WarnContext(ctx, "Failed to release stock of cancelled order", "order_id", order.ID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		compensated++
+	}
+
+	return compensated, firstErr
+}
+
+// release devolve o estoque do pedido cancelado e grava stock_released
+func (s *OrderServiceRepo) release(ctx context.Context, order *models.Order) error {
+	if err := s.catalog.ReleaseStock(ctx, order.ID); err != nil {
+		return catalogError(err)
+	}
+
+	order.StockReleased = true
+	if err := s.repo.UpdateOrder(ctx, order, models.OrderCancelled); err != nil {
+		return translateOrderError(err)
+	}
+
+	return nil
+}
+
+// transitionable busca o pedido e verifica se ele pode passar para target a partir do status atual.
+// Pedidos com o checkout em andamento ainda não têm reserva confirmada e não mudam de status.
+func (s *OrderServiceRepo) transitionable(ctx context.Context, id uint, target string, from ...string) (*models.Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, translateOrderError(err)
+	}
+
+	if order.StockReserved {
+		for _, status := range from {
+			if order.Status == status {
+				return order, nil
+			}
+		}
+	}
+
+	return nil, ConflictError(CodeInvalidOrderTransition, "Order cannot be "+target+" from status "+order.Status, nil)
+}
+
+func validateOrder(request *models.OrderRequest) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	switch {
+	case len(request.Items) == 0:
+		fields = append(fields, fieldError("items", "required", ""))
+	case len(request.Items) > MaxReservationItems:
+		fields = append(fields, fieldError("items", "max_items", strconv.Itoa(MaxReservationItems)))
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+// catalogError converte as falhas da API de produtos; erros de contexto são mantidos para que o controller responda 504/499
+func catalogError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, catalog.ErrConflict):
+		return ConflictError(CodeInvalidOrderTransition, "Stock reservation of the order is closed", err)
+	default:
+		return UnavailableError(CodeCatalogUnavailable, "Products API is unavailable", err)
+	}
+}
+
+// translateTransitionError trata o pedido que mudou de status entre a leitura e a gravação como conflito
+func translateTransitionError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ConflictError(CodeInvalidOrderTransition, "Order was changed concurrently", err)
+	}
+
+	return translateOrderError(err)
+}
+
+func translateOrderError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, repositories.ErrNotFound):
+		return NotFoundError(CodeOrderNotFound, "Order not found", err)
+	default:
+		return UnavailableError(CodeOrderStorageUnavailable, "Order storage is unavailable", err)
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/catalog"
+	customersapi "produtos-api/src/customers"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetOrderByID(ctx context.Context, id uint) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetUnreleasedOrders(ctx context.Context, checkoutBefore time.Time, limit int) ([]models.Order, error) {
+	args := m.Called(ctx, checkoutBefore, limit)
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateOrder(ctx context.Context, order *models.Order, status string) error {
+	args := m.Called(ctx, order, status)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) DeleteOrder(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockCatalogClient struct {
+	mock.Mock
+}
+
+func (m *MockCatalogClient) GetProduct(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockCatalogClient) ReserveStock(ctx context.Context, request *models.StockReservationRequest) ([]models.StockReservation, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+func (m *MockCatalogClient) ReleaseStock(ctx context.Context, orderID uint) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *MockCatalogClient) CommitStock(ctx context.Context, orderID uint) ([]models.StockReservation, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]models.StockReservation), args.Error(1)
+}
+
+var orderTestNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newOrderTest() (*OrderServiceRepo, *MockOrderRepository, *MockCatalogClient) {
+	repo := new(MockOrderRepository)
+	client := new(MockCatalogClient)
+	customers := new(MockCustomerClient)
+	customers.On("GetCustomer", mock.Anything, uint(7)).Return(&models.Customer{ID: 7, Subject: "maria"}, nil).Maybe()
+	service := NewOrderService(repo, newTestOutbox(), passthroughTransactor{}, client, customers, 5*time.Minute)
+	service.now = func() time.Time { return orderTestNow }
+
+	return service, repo, client
+}
+
+// customerContext autentica maria, titular do cliente 7 com o papel viewer
+func customerContext() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "maria", Roles: []auth.Role{auth.RoleViewer}})
+}
+
+// captureOutbox troca a outbox do serviço por uma que guarda os eventos gravados
+func captureOutbox(service *OrderServiceRepo) *[]*models.OutboxEvent {
+	var enqueued []*models.OutboxEvent
+	outbox := new(MockOutboxRepository)
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		enqueued = append(enqueued, args.Get(1).(*models.OutboxEvent))
+	}).Return(nil)
+	service.outbox = outbox
+
+	return &enqueued
+}
+
+// expectProducts cadastra Caneta (ID 1, R$ 2,50) e Caderno (ID 2, R$ 19,90) na API de produtos
+func expectProducts(client *MockCatalogClient) {
+	client.On("GetProduct", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Price: 2.5}, nil)
+	client.On("GetProduct", mock.Anything, uint(2)).Return(&models.Product{ID: 2, Name: "Caderno", Price: 19.9}, nil)
+}
+
+// expectCreate atribui o ID 42 ao pedido criado
+func expectCreate(repo *MockOrderRepository) {
+	repo.On("CreateOrder", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Order).ID = 42
+	}).Return(nil)
+}
+
+func checkoutRequest() *models.OrderRequest {
+	return &models.OrderRequest{
+		CustomerID: 7,
+		Email:      "cliente@example.com",
+		Items: []models.OrderItemRequest{
+			{ProductID: 2, Quantity: 1},
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 1, Quantity: 1},
+		},
+	}
+}
+
+func TestServiceCheckout(t *testing.T) {
+	service, repo, client := newOrderTest()
+	expectProducts(client)
+	expectCreate(repo)
+	client.On("ReserveStock", mock.Anything, &models.StockReservationRequest{
+		OrderID: 42,
+		Items:   []models.StockReservationItem{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}},
+	}).Return([]models.StockReservation{}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.StockReserved && order.Status == models.OrderPending
+	}), models.OrderPending).Return(nil)
+
+	enqueued := captureOutbox(service)
+
+	ctx := i18n.WithContentLocales(customerContext(), i18n.ContentCandidates("pt-BR,pt;q=0.9"))
+	order, err := service.Checkout(ctx, checkoutRequest())
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), order.ID)
+	assert.Equal(t, models.OrderPending, order.Status)
+	assert.Equal(t, "maria", order.CreatedBy)
+	assert.Equal(t, "pt-BR", order.Locale)
+	assert.Equal(t, 27.4, order.Total)
+	if assert.Len(t, order.Items, 2) {
+		assert.Equal(t, models.OrderItem{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 3}, order.Items[0])
+	}
+	repo.AssertExpectations(t)
+	client.AssertExpectations(t)
+
+	// OrderPlaced é gravado com a reserva confirmada, para as reservas de estoque da API de produtos
+	require.Len(t, *enqueued, 1)
+	event := (*enqueued)[0]
+	assert.Equal(t, events.OrderPlaced, event.Type)
+	assert.Equal(t, uint(42), event.AggregateID)
+	var placed events.Order
+	require.NoError(t, json.Unmarshal([]byte(event.Payload), &placed))
+	assert.Equal(t, []events.OrderItem{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}}, placed.Items)
+}
+
+func TestServiceCheckoutChecksCustomer(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	customers := new(MockCustomerClient)
+	service.customers = customers
+	customers.On("GetCustomer", mock.Anything, uint(7)).Return(&models.Customer{ID: 7, Subject: "joao"}, nil)
+	customers.On("GetCustomer", mock.Anything, uint(8)).Return((*models.Customer)(nil), customersapi.ErrNotFound)
+	customers.On("GetCustomer", mock.Anything, uint(9)).Return((*models.Customer)(nil), errors.New("connection refused"))
+
+	// Cliente de outro titular e cliente inexistente têm o mesmo erro
+	for _, id := range []uint{7, 8} {
+		request := checkoutRequest()
+		request.CustomerID = id
+		_, err := service.Checkout(customerContext(), request)
+		domainErr, ok := AsDomainError(err)
+		require.True(t, ok)
+		assert.Equal(t, KindValidation, domainErr.Kind)
+		assert.Equal(t, []FieldError{fieldError("customer_id", "exists", strconv.FormatUint(uint64(id), 10))}, domainErr.Fields)
+	}
+
+	request := checkoutRequest()
+	request.CustomerID = 9
+	_, err := service.Checkout(customerContext(), request)
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeCustomersUnavailable, domainErr.Code)
+	repo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+
+	// Editores fazem pedidos para qualquer cliente
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	assert.NoError(t, service.checkCustomer(editor, 7))
+}
+
+func TestServiceGetOrderByIDOwnership(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, CreatedBy: "maria"}, nil)
+	repo.On("GetOrderByID", mock.Anything, uint(43)).Return(&models.Order{ID: 43}, nil)
+
+	order, err := service.GetOrderByID(customerContext(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), order.ID)
+
+	// Pedidos de outros titulares, ou sem titular, são reportados como inexistentes
+	joao := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "joao", Roles: []auth.Role{auth.RoleViewer}})
+	for _, call := range []struct {
+		ctx context.Context
+		id  uint
+	}{{joao, 42}, {customerContext(), 43}, {context.Background(), 42}} {
+		_, err := service.GetOrderByID(call.ctx, call.id)
+		domainErr, ok := AsDomainError(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeOrderNotFound, domainErr.Code)
+	}
+
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []auth.Role{auth.RoleEditor}})
+	order, err = service.GetOrderByID(editor, 43)
+	require.NoError(t, err)
+	assert.Equal(t, uint(43), order.ID)
+}
+
+func TestServiceCheckoutWithInsufficientStock(t *testing.T) {
+	service, repo, client := newOrderTest()
+	expectProducts(client)
+	expectCreate(repo)
+	client.On("ReserveStock", mock.Anything, mock.Anything).
+		Return([]models.StockReservation(nil), &catalog.InsufficientStockError{ProductIDs: []uint{2}})
+	repo.On("DeleteOrder", mock.Anything, uint(42)).Return(nil)
+
+	_, err := service.Checkout(customerContext(), checkoutRequest())
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	assert.Equal(t, CodeInsufficientStock, domainErr.Code)
+	if assert.Len(t, domainErr.Fields, 1) {
+		assert.Equal(t, "2", domainErr.Fields[0].Param)
+	}
+	// Nenhum estoque ficou reservado: não há o que devolver
+	client.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceCheckoutCompensatesUncertainReservation(t *testing.T) {
+	service, repo, client := newOrderTest()
+	expectProducts(client)
+	expectCreate(repo)
+	client.On("ReserveStock", mock.Anything, mock.Anything).Return([]models.StockReservation(nil), errors.New("connection reset"))
+	client.On("ReleaseStock", mock.Anything, uint(42)).Return(nil)
+	repo.On("DeleteOrder", mock.Anything, uint(42)).Return(nil)
+
+	_, err := service.Checkout(customerContext(), checkoutRequest())
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindUnavailable, domainErr.Kind)
+	assert.Equal(t, CodeCatalogUnavailable, domainErr.Code)
+	client.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceCheckoutKeepsOrderWhenCompensationFails(t *testing.T) {
+	service, repo, client := newOrderTest()
+	expectProducts(client)
+	expectCreate(repo)
+	client.On("ReserveStock", mock.Anything, mock.Anything).Return([]models.StockReservation(nil), errors.New("connection reset"))
+	client.On("ReleaseStock", mock.Anything, uint(42)).Return(errors.New("connection refused"))
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Status == models.OrderCancelled && order.CancelReason == CancelReasonCheckoutFailed && !order.StockReleased
+	}), models.OrderPending).Return(nil)
+
+	_, err := service.Checkout(customerContext(), checkoutRequest())
+	assert.Error(t, err)
+	// O pedido fica cancelado com stock_released=false para que Compensate devolva o estoque
+	repo.AssertNotCalled(t, "DeleteOrder", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestServiceCheckoutValidation(t *testing.T) {
+	service, repo, client := newOrderTest()
+	client.On("GetProduct", mock.Anything, uint(1)).Return((*models.Product)(nil), catalog.ErrNotFound)
+
+	_, err := service.Checkout(context.Background(), &models.OrderRequest{Email: "invalid"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Len(t, domainErr.Fields, 2)
+
+	_, err = service.Checkout(context.Background(), &models.OrderRequest{Items: []models.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	domainErr, ok = AsDomainError(err)
+	require.True(t, ok)
+	if assert.Len(t, domainErr.Fields, 1) {
+		assert.Equal(t, "exists", domainErr.Fields[0].Code)
+		assert.Equal(t, "1", domainErr.Fields[0].Param)
+	}
+	repo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func TestServicePayOrder(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	outbox := new(MockOutboxRepository)
+	service.outbox = outbox
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{
+		ID: 42, Email: "maria@example.com", Locale: "pt-BR", Status: models.OrderPending, StockReserved: true, Total: 5,
+		Items: []models.OrderItem{{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}},
+	}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.Anything, models.OrderPending).Return(nil)
+
+	var event *models.OutboxEvent
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		event = args.Get(1).(*models.OutboxEvent)
+	}).Return(nil)
+
+	order, err := service.PayOrder(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderPaid, order.Status)
+	assert.Equal(t, &orderTestNow, order.PaidAt)
+
+	require.NotNil(t, event)
+	assert.Equal(t, events.OrderConfirmed, event.Type)
+	assert.Equal(t, events.AggregateOrder, event.AggregateType)
+	var confirmation events.OrderConfirmation
+	require.NoError(t, json.Unmarshal([]byte(event.Payload), &confirmation))
+	assert.Equal(t, "maria@example.com", confirmation.Email)
+	assert.Equal(t, "pt-BR", confirmation.Locale)
+	assert.Equal(t, orderTestNow, confirmation.ConfirmedAt)
+	assert.Equal(t, []events.ConfirmedItem{{ProductID: 1, Name: "Caneta", UnitPrice: 2.5, Quantity: 2}}, confirmation.Items)
+}
+
+func TestServicePayOrderWhenOutboxFails(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	outbox := new(MockOutboxRepository)
+	service.outbox = outbox
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPending, StockReserved: true}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.Anything, models.OrderPending).Return(nil)
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(errors.New("disk I/O error"))
+
+	_, err := service.PayOrder(context.Background(), 42)
+
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok, "expected a domain error, got %v", err)
+	assert.Equal(t, CodeOrderStorageUnavailable, domainErr.Code)
+}
+
+func TestServiceOrderInvalidTransitions(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(1)).Return(&models.Order{ID: 1, Status: models.OrderShipped, StockReserved: true}, nil)
+	// Checkout em andamento: a reserva ainda não foi confirmada
+	repo.On("GetOrderByID", mock.Anything, uint(2)).Return(&models.Order{ID: 2, Status: models.OrderPending}, nil)
+	repo.On("GetOrderByID", mock.Anything, uint(3)).Return(&models.Order{ID: 3, Status: models.OrderPending, StockReserved: true}, nil)
+
+	_, err := service.PayOrder(context.Background(), 1)
+	assertConflict(t, err, CodeInvalidOrderTransition)
+	_, err = service.CancelOrder(context.Background(), 1, &models.OrderCancelRequest{})
+	assertConflict(t, err, CodeInvalidOrderTransition)
+	_, err = service.PayOrder(context.Background(), 2)
+	assertConflict(t, err, CodeInvalidOrderTransition)
+	_, err = service.ShipOrder(context.Background(), 3)
+	assertConflict(t, err, CodeInvalidOrderTransition)
+
+	repo.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "CommitStock", mock.Anything, mock.Anything)
+}
+
+func TestServiceOrderConcurrentTransition(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPending, StockReserved: true}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.Anything, models.OrderPending).Return(repositories.ErrNotFound)
+
+	_, err := service.PayOrder(context.Background(), 42)
+	assertConflict(t, err, CodeInvalidOrderTransition)
+}
+
+func TestServiceShipOrder(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPaid, StockReserved: true}, nil)
+	client.On("CommitStock", mock.Anything, uint(42)).Return([]models.StockReservation{}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Status == models.OrderShipped && order.ShippedAt != nil
+	}), models.OrderPaid).Return(nil)
+	enqueued := captureOutbox(service)
+
+	order, err := service.ShipOrder(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderShipped, order.Status)
+	repo.AssertExpectations(t)
+	require.Len(t, *enqueued, 1)
+	assert.Equal(t, events.OrderShipped, (*enqueued)[0].Type)
+	assert.JSONEq(t, `{"order_id":42,"shipped_at":"2026-10-19T12:00:00Z"}`, (*enqueued)[0].Payload)
+}
+
+func TestServiceShipOrderWhenCatalogFails(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPaid, StockReserved: true}, nil)
+	client.On("CommitStock", mock.Anything, uint(42)).Return([]models.StockReservation(nil), &catalog.StatusError{Status: 503})
+
+	_, err := service.ShipOrder(context.Background(), 42)
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeCatalogUnavailable, domainErr.Code)
+	// O pedido continua pago e o envio pode ser repetido
+	repo.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceCancelOrder(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPaid, StockReserved: true}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Status == models.OrderCancelled && order.CancelReason == "desistência" && !order.StockReleased
+	}), models.OrderPaid).Return(nil).Once()
+	client.On("ReleaseStock", mock.Anything, uint(42)).Return(nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.StockReleased
+	}), models.OrderCancelled).Return(nil).Once()
+
+	enqueued := captureOutbox(service)
+
+	order, err := service.CancelOrder(context.Background(), 42, &models.OrderCancelRequest{Reason: "desistência"})
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderCancelled, order.Status)
+	assert.True(t, order.StockReleased)
+	repo.AssertExpectations(t)
+	client.AssertExpectations(t)
+	require.Len(t, *enqueued, 1)
+	assert.Equal(t, events.OrderCancelled, (*enqueued)[0].Type)
+	assert.JSONEq(t, `{"order_id":42,"cancelled_at":"2026-10-19T12:00:00Z"}`, (*enqueued)[0].Payload)
+}
+
+func TestServiceCancelOrderWhenCatalogFails(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetOrderByID", mock.Anything, uint(42)).Return(&models.Order{ID: 42, Status: models.OrderPending, StockReserved: true}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.Anything, models.OrderPending).Return(nil).Once()
+	client.On("ReleaseStock", mock.Anything, uint(42)).Return(errors.New("connection refused"))
+
+	// O cancelamento vale mesmo com a API de produtos indisponível; o estoque é devolvido por Compensate
+	order, err := service.CancelOrder(context.Background(), 42, &models.OrderCancelRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderCancelled, order.Status)
+	assert.False(t, order.StockReleased)
+	repo.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything, models.OrderCancelled)
+}
+
+func TestServiceCompensate(t *testing.T) {
+	service, repo, client := newOrderTest()
+	repo.On("GetUnreleasedOrders", mock.Anything, orderTestNow.Add(-5*time.Minute), compensateBatchSize).Return([]models.Order{
+		{ID: 1, Status: models.OrderPending},
+		{ID: 2, Status: models.OrderCancelled, StockReserved: true},
+		{ID: 3, Status: models.OrderCancelled, StockReserved: true},
+	}, nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.ID == 1 && order.Status == models.OrderCancelled && order.CancelReason == CancelReasonCheckoutFailed
+	}), models.OrderPending).Return(nil)
+	repo.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.StockReleased
+	}), models.OrderCancelled).Return(nil)
+	client.On("ReleaseStock", mock.Anything, uint(1)).Return(nil)
+	client.On("ReleaseStock", mock.Anything, uint(2)).Return(nil)
+	client.On("ReleaseStock", mock.Anything, uint(3)).Return(errors.New("connection refused"))
+
+	compensated, err := service.Compensate(context.Background())
+	assert.Equal(t, 2, compensated)
+	assert.Error(t, err)
+	repo.AssertNumberOfCalls(t, "UpdateOrder", 3)
+	client.AssertExpectations(t)
+}
+
+func TestServiceGetOrdersValidation(t *testing.T) {
+	service, repo, _ := newOrderTest()
+	repo.On("FindOrders", mock.Anything, models.OrderFilter{Status: models.OrderPaid, Limit: DefaultOrderLimit}).Return([]models.Order{{ID: 1}}, nil)
+
+	orders, err := service.GetOrders(context.Background(), models.OrderFilter{Status: models.OrderPaid})
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	_, err = service.GetOrders(context.Background(), models.OrderFilter{Status: "lost", Limit: MaxOrderLimit + 1})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Len(t, domainErr.Fields, 2)
+}
+
+func assertConflict(t *testing.T, err error, code string) {
+	t.Helper()
+
+	domainErr, ok := AsDomainError(err)
+	if assert.True(t, ok, "expected a domain error, got %v", err) {
+		assert.Equal(t, KindConflict, domainErr.Kind)
+		assert.Equal(t, code, domainErr.Code)
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Lote de leitura da outbox e limites do backoff exponencial entre as tentativas de publicação
+const (
+	outboxBatchSize = 100
+	outboxRetryBase = time.Second
+	outboxRetryMax  = 5 * time.Minute
+)
+
+var outboxLogger = logging.Logger("outbox")
+
+type OutboxService interface {
+	Dispatch(ctx context.Context) (int, error)
+}
+
+type OutboxServiceRepo struct {
+	repo      repositories.OutboxRepository
+	broker    broker.Broker
+	topic     string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewOutboxService cria o dispatcher da outbox, que publica os eventos no tópico informado.
+// Eventos publicados há mais de retention são removidos da tabela; zero os mantém.
+func NewOutboxService(repo repositories.OutboxRepository, publisher broker.Broker, topic string, retention time.Duration) *OutboxServiceRepo {
+	return &OutboxServiceRepo{repo: repo, broker: publisher, topic: topic, retention: retention, now: time.Now}
+}
+
+// Dispatch publica os eventos pendentes na ordem em que foram gravados e retorna quantos foram publicados.
+// Um evento que falha é reagendado com backoff e bloqueia os eventos seguintes do mesmo agregado até ser
+// publicado, preservando a ordem por produto; os demais agregados seguem normalmente. Falhas do broker
+// são apenas registradas no log: o erro retornado indica falhas da própria outbox.
+func (s *OutboxServiceRepo) Dispatch(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "OutboxService.Dispatch")
+	defer span.End()
+
+	now := s.now().UTC()
+	published := 0
+	defer func() { span.SetAttributes(attribute.Int("outbox.published", published)) }()
+
+	blocked := map[string]bool{}
+	var afterID uint
+	for {
+		events, err := s.repo.GetPendingOutboxEvents(ctx, afterID, outboxBatchSize)
+		if err != nil {
+			return published, translateProductError(err)
+		}
+
+		for i := range events {
+			event := &events[i]
+			aggregate := fmt.Sprintf("%s:%d", event.AggregateType, event.AggregateID)
+			if blocked[aggregate] {
+				continue
+			}
+			if event.NextAttemptAt.After(now) {
+				blocked[aggregate] = true
+				continue
+			}
+
+			if err := s.broker.Publish(eventContext(ctx, event), s.message(event)); err != nil {
+				if ctx.Err() != nil {
+					return published, ctx.Err()
+				}
+				blocked[aggregate] = true
+				event.Attempts++
+				event.LastError = err.Error()
+				event.NextAttemptAt = now.Add(outboxBackoff(event.Attempts))
+				outboxLogger.WarnContext(ctx, "event publication failed", "event_id", event.EventID, "type", event.Type,
+					"attempts", event.Attempts, "next_attempt_at", event.NextAttemptAt, "error", err)
+			} else {
+				publishedAt := now
+				event.PublishedAt = &publishedAt
+				event.LastError = ""
+				published++
+			}
+
+			if err := s.repo.SaveOutboxEvent(ctx, event); err != nil {
+				return published, translateProductError(err)
+			}
+		}
+
+		if len(events) < outboxBatchSize {
+			break
+		}
+		afterID = events[len(events)-1].ID
+	}
+
+	if s.retention > 0 {
+		if _, err := s.repo.DeletePublishedOutboxEvents(ctx, now.Add(-s.retention)); err != nil {
+			return published, translateProductError(err)
+		}
+	}
+
+	return published, nil
+}
+
+func (s *OutboxServiceRepo) message(event *models.OutboxEvent) broker.Message {
+	return broker.Message{
+		Topic:         s.topic,
+		Key:           fmt.Sprint(event.AggregateID),
+		ID:            event.EventID,
+		Type:          event.Type,
+		SchemaVersion: event.SchemaVersion,
+		Headers:       map[string]string{"aggregate_type": event.AggregateType, "request_id": event.RequestID},
+		Value:         []byte(event.Payload),
+		Timestamp:     event.OccurredAt,
+	}
+}
+
+// eventContext retoma o trace da mudança que gravou o evento, para que a publicação apareça nele
+func eventContext(ctx context.Context, event *models.OutboxEvent) context.Context {
+	carrier := propagation.MapCarrier{}
+	if event.TraceContext == "" || json.Unmarshal([]byte(event.TraceContext), &carrier) != nil {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// outboxBackoff dobra o intervalo a cada falha, até outboxRetryMax
+func outboxBackoff(attempts int) time.Duration {
+	return retryBackoff(attempts, outboxRetryBase, outboxRetryMax)
+}
+
+// enqueueEvent grava o evento na outbox; deve ser chamado dentro da transação da mudança
+// para que o evento só exista se a mudança for confirmada. Os erros do repositório são retornados
+// sem tradução, para que cada serviço os converta nos seus próprios erros.
+func enqueueEvent(ctx context.Context, repo repositories.OutboxRepository, eventType, aggregateType string, aggregateID uint, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	traceContext, err := json.Marshal(carrier)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	event := &models.OutboxEvent{
+		EventID:       hex.EncodeToString(id),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		SchemaVersion: events.CurrentVersions[eventType],
+		TraceContext:  string(traceContext),
+		RequestID:     logging.RequestID(ctx),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}
+
+	return repo.CreateOutboxEvent(ctx, event)
+}
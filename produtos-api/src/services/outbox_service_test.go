@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) GetPendingOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]models.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) SaveOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, publishedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOutboxRepository) GetOutboxEvents(ctx context.Context, afterID uint, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]models.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) GetOutboxBounds(ctx context.Context) (uint, uint, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint), args.Get(1).(uint), args.Error(2)
+}
+
+// newTestOutbox aceita qualquer evento gravado pelos serviços
+func newTestOutbox() *MockOutboxRepository {
+	outbox := new(MockOutboxRepository)
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return outbox
+}
+
+func newTestOutboxService(repo *MockOutboxRepository, publisher broker.Broker, now time.Time) *OutboxServiceRepo {
+	service := NewOutboxService(repo, publisher, "products", 0)
+	service.now = func() time.Time { return now }
+
+	return service
+}
+
+func TestServiceUpdateProductEnqueuesStockChanged(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	outbox := new(MockOutboxRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), newTestAuditRepository(), outbox, passthroughTransactor{})
+
+	product := &models.Product{ID: 1, Name: "Caneta", Price: 2.5, Stock: 7}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Price: 2.5, Stock: 10}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, product).Return(nil)
+
+	var enqueued []*models.OutboxEvent
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		enqueued = append(enqueued, args.Get(1).(*models.OutboxEvent))
+	}).Return(nil)
+
+	err := productService.UpdateProduct(context.Background(), product)
+	assert.NoError(t, err)
+	if assert.Len(t, enqueued, 2) {
+		assert.Equal(t, events.ProductUpdated, enqueued[0].Type)
+		assert.Equal(t, events.StockChanged, enqueued[1].Type)
+		assert.Equal(t, uint(1), enqueued[1].AggregateID)
+		assert.JSONEq(t, `{"product_id":1,"previous":10,"current":7}`, enqueued[1].Payload)
+		assert.NotEqual(t, enqueued[0].EventID, enqueued[1].EventID)
+		assert.Equal(t, events.CurrentVersions[events.StockChanged], enqueued[1].SchemaVersion)
+	}
+}
+
+func TestServiceCreateProductFailsWhenOutboxFails(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	outbox := new(MockOutboxRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), newTestAuditRepository(), outbox, passthroughTransactor{})
+
+	product := &models.Product{Name: "Caneta", Price: 2.5}
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(0)).Return(false, nil)
+	mockRepo.On("CreateProduct", mock.Anything, product).Return(nil)
+	outbox.On("CreateOutboxEvent", mock.Anything, mock.Anything).Return(errors.New("disk I/O error"))
+
+	// O erro desfaz a transação: a mudança não é confirmada sem o seu evento
+	err := productService.CreateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindUnavailable, domainErr.Kind)
+}
+
+func TestServiceDispatchPublishesInOrder(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	publisher := broker.NewMemoryBroker()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service := newTestOutboxService(repo, publisher, now)
+
+	repo.On("GetPendingOutboxEvents", mock.Anything, uint(0), outboxBatchSize).Return([]models.OutboxEvent{
+		{ID: 1, EventID: "a", Type: events.ProductCreated, AggregateType: "product", AggregateID: 1, Payload: `{"id":1}`, SchemaVersion: 1, NextAttemptAt: now},
+		{ID: 2, EventID: "b", Type: events.ProductUpdated, AggregateType: "product", AggregateID: 1, Payload: `{"id":1}`, NextAttemptAt: now},
+	}, nil)
+	repo.On("SaveOutboxEvent", mock.Anything, mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		return event.PublishedAt != nil && event.PublishedAt.Equal(now)
+	})).Return(nil).Twice()
+
+	published, err := service.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	messages := publisher.Messages()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "a", messages[0].ID)
+		assert.Equal(t, "b", messages[1].ID)
+		assert.Equal(t, "products", messages[0].Topic)
+		assert.Equal(t, "1", messages[0].Key)
+		assert.Equal(t, 1, messages[0].SchemaVersion)
+		assert.True(t, json.Valid(messages[0].Value))
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestServiceDispatchFailureBlocksAggregate(t *testing.T) {
+	repo := new(MockOutboxRepository)
+	publisher := &failingBroker{MemoryBroker: broker.NewMemoryBroker(), failID: "a"}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service := newTestOutboxService(repo, publisher, now)
+
+	repo.On("GetPendingOutboxEvents", mock.Anything, uint(0), outboxBatchSize).Return([]models.OutboxEvent{
+		{ID: 1, EventID: "a", AggregateType: "product", AggregateID: 1, Attempts: 2, NextAttemptAt: now},
+		{ID: 2, EventID: "b", AggregateType: "product", AggregateID: 1, NextAttemptAt: now},
+		{ID: 3, EventID: "c", AggregateType: "product", AggregateID: 2, NextAttemptAt: now},
+		// Ainda aguardando o backoff
+		{ID: 4, EventID: "d", AggregateType: "product", AggregateID: 3, Attempts: 1, NextAttemptAt: now.Add(time.Second)},
+	}, nil)
+	repo.On("SaveOutboxEvent", mock.Anything, mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		return event.EventID == "a" && event.Attempts == 3 && event.PublishedAt == nil &&
+			event.NextAttemptAt.Equal(now.Add(4*time.Second)) && event.LastError == "broker down"
+	})).Return(nil).Once()
+	repo.On("SaveOutboxEvent", mock.Anything, mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		return event.EventID == "c" && event.PublishedAt != nil
+	})).Return(nil).Once()
+
+	published, err := service.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	// "b" espera "a", do mesmo produto; "c" é de outro produto e segue
+	messages := publisher.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "c", messages[0].ID)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, outboxBackoff(1))
+	assert.Equal(t, 8*time.Second, outboxBackoff(4))
+	assert.Equal(t, outboxRetryMax, outboxBackoff(30))
+}
+
+// failingBroker falha apenas a publicação do evento failID
+type failingBroker struct {
+	*broker.MemoryBroker
+	failID string
+}
+
+func (b *failingBroker) Publish(ctx context.Context, msg broker.Message) error {
+	if msg.ID == b.failID {
+		return errors.New("broker down")
+	}
+
+	return b.MemoryBroker.Publish(ctx, msg)
+}
@@ -1,52 +1,445 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
 	"produtos-api/src/models"
 	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
 )
 
+var tracer = otel.Tracer("produtos-api/src/services")
+
 type ProductService interface {
-	CreateProduct(product *models.Product) error
-	GetAllProducts() ([]models.Product, error)
-	GetProductByID(id uint) (*models.Product, error)
-	GetProductByName(name string) ([]models.Product, error)
-	GetProductsCount() int64
-	UpdateProduct(product *models.Product) error
-	DeleteProduct(id uint) error
+	CreateProduct(ctx context.Context, product *models.Product) error
+	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	GetProductByID(ctx context.Context, id uint) (*models.Product, error)
+	GetProductByName(ctx context.Context, name string) ([]models.Product, error)
+	GetProductsCount(ctx context.Context) int64
+	UpdateProduct(ctx context.Context, product *models.Product) error
+	DeleteProduct(ctx context.Context, id uint) error
+	GetDeletedProducts(ctx context.Context) ([]models.Product, error)
+	RestoreProduct(ctx context.Context, id uint) (*models.Product, error)
+	PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int, error)
+	GetProductAsOf(ctx context.Context, id uint, asOf time.Time) (*models.Product, error)
+	GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error)
+	RevertProduct(ctx context.Context, id, revision uint) (*models.Product, error)
 }
 
+// purgeBatchSize limita quantos produtos são lidos da lixeira por vez durante a limpeza
+const purgeBatchSize = 100
+
+// purgeActor é o ator dos registros de auditoria da limpeza automática da lixeira
+var purgeActor = &auth.Principal{Subject: "system:purge"}
+
 type ProductServiceRepo struct {
-	repository repositories.ProductRepository
+	repository   repositories.ProductRepository
+	translations repositories.ProductTranslationRepository
+	audit        repositories.AuditRepository
+	outbox       repositories.OutboxRepository
+	transactor   repositories.Transactor
+}
+
+// NewProductService cria o serviço de produtos; cada mudança é gravada na mesma transação que o seu
+// registro de auditoria e os seus eventos de domínio
+func NewProductService(repo repositories.ProductRepository, translations repositories.ProductTranslationRepository, audit repositories.AuditRepository, outbox repositories.OutboxRepository, transactor repositories.Transactor) *ProductServiceRepo {
+	return &ProductServiceRepo{repository: repo, translations: translations, audit: audit, outbox: outbox, transactor: transactor}
+}
+
+func (s *ProductServiceRepo) CreateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
+	// Timestamps e lixeira são controlados pelo GORM, não pelo cliente
+	product.CreatedAt, product.UpdatedAt, product.DeletedAt = time.Time{}, time.Time{}, gorm.DeletedAt{}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.validateProduct(ctx, product); err != nil {
+			return err
+		}
+
+		if err := s.repository.CreateProduct(ctx, product); err != nil {
+			return translateProductError(err)
+		}
+
+		if err := recordAudit(ctx, s.audit, AuditEntityProduct, product.ID, models.AuditActionCreate, nil, product); err != nil {
+			return err
+		}
+
+		return enqueueProductEvents(ctx, s.outbox, events.ProductCreated, nil, product)
+	})
+}
+
+func (s *ProductServiceRepo) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetAllProducts")
+	defer span.End()
+
+	products, err := s.repository.GetAllProducts(ctx)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+
+	return s.localize(ctx, products)
+}
+
+func (s *ProductServiceRepo) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductByID")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)))
+
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+
+	localized, err := s.localize(ctx, []models.Product{*product})
+	if err != nil {
+		return nil, err
+	}
+
+	return &localized[0], nil
+}
+
+func (s *ProductServiceRepo) GetProductByName(ctx context.Context, name string) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductByName")
+	defer span.End()
+
+	products, err := s.repository.GetProductByName(ctx, name)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+
+	return s.localize(ctx, products)
+}
+
+func (s *ProductServiceRepo) GetProductsCount(ctx context.Context) int64 {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductsCount")
+	defer span.End()
+
+	return s.repository.GetProductsCount(ctx)
+}
+
+func (s *ProductServiceRepo) UpdateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(product.ID)))
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Save faria um upsert; sem a verificação um ID inexistente criaria um novo produto
+		before, err := s.repository.GetProductByID(ctx, product.ID)
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		if err := s.validateProduct(ctx, product); err != nil {
+			return err
+		}
+
+		product.CreatedAt, product.DeletedAt = before.CreatedAt, gorm.DeletedAt{}
+		if err := s.repository.UpdateProduct(ctx, product); err != nil {
+			return translateProductError(err)
+		}
+
+		if err := recordAudit(ctx, s.audit, AuditEntityProduct, product.ID, models.AuditActionUpdate, before, product); err != nil {
+			return err
+		}
+
+		return enqueueProductEvents(ctx, s.outbox, events.ProductUpdated, before, product)
+	})
+}
+
+func (s *ProductServiceRepo) DeleteProduct(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)))
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repository.GetProductByID(ctx, id)
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		if err := s.repository.DeleteProduct(ctx, id); err != nil {
+			return translateProductError(err)
+		}
+
+		if err := recordAudit(ctx, s.audit, AuditEntityProduct, id, models.AuditActionDelete, before, nil); err != nil {
+			return err
+		}
+
+		return enqueueProductEvents(ctx, s.outbox, events.ProductDeleted, before, nil)
+	})
+}
+
+// GetDeletedProducts lista os produtos da lixeira
+func (s *ProductServiceRepo) GetDeletedProducts(ctx context.Context) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetDeletedProducts")
+	defer span.End()
+
+	products, err := s.repository.GetDeletedProducts(ctx, time.Time{}, 0)
+	return products, translateProductError(err)
 }
 
-func NewProductService(repo repositories.ProductRepository) *ProductServiceRepo {
-	return &ProductServiceRepo{repository: repo}
+// RestoreProduct tira o produto da lixeira; falha com conflito se outro produto ativo
+// já usa o mesmo nome na categoria
+func (s *ProductServiceRepo) RestoreProduct(ctx context.Context, id uint) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.RestoreProduct")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)))
+
+	var restored models.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repository.GetDeletedProductByID(ctx, id)
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		exists, err := s.repository.ExistsByNameAndCategory(ctx, before.Name, before.Category, id)
+		if err != nil {
+			return translateProductError(err)
+		}
+		if exists {
+			return ConflictError(CodeProductConflict, "Product conflicts with an existing product", nil)
+		}
+
+		if err := s.repository.RestoreProduct(ctx, id); err != nil {
+			return translateProductError(err)
+		}
+
+		restored = *before
+		restored.DeletedAt = gorm.DeletedAt{}
+		if err := recordAudit(ctx, s.audit, AuditEntityProduct, id, models.AuditActionRestore, before, &restored); err != nil {
+			return err
+		}
+
+		// Para os consumidores, o produto restaurado volta a existir com o estado atual
+		return enqueueProductEvents(ctx, s.outbox, events.ProductUpdated, nil, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &restored, nil
 }
 
-func (s *ProductServiceRepo) CreateProduct(product *models.Product) error {
-	return s.repository.CreateProduct(product)
+// PurgeDeletedProducts remove definitivamente os produtos que estão na lixeira há mais que retention.
+// Cada remoção é auditada na própria transação; retorna quantos produtos foram removidos.
+func (s *ProductServiceRepo) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.PurgeDeletedProducts")
+	defer span.End()
+
+	if auth.PrincipalFrom(ctx) == nil {
+		ctx = auth.WithPrincipal(ctx, purgeActor)
+	}
+	deletedBefore := time.Now().Add(-retention)
+
+	purged := 0
+	defer func() { span.SetAttributes(attribute.Int("products.purged", purged)) }()
+
+	for {
+		products, err := s.repository.GetDeletedProducts(ctx, deletedBefore, purgeBatchSize)
+		if err != nil {
+			return purged, translateProductError(err)
+		}
+
+		for i := range products {
+			product := &products[i]
+			err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				if err := s.repository.PurgeProduct(ctx, product.ID); err != nil {
+					return translateProductError(err)
+				}
+
+				return recordAudit(ctx, s.audit, AuditEntityProduct, product.ID, models.AuditActionPurge, product, nil)
+			})
+			// Um produto restaurado desde a leitura do lote não é mais removido
+			if domainErr, ok := AsDomainError(err); ok && domainErr.Kind == KindNotFound {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(products) < purgeBatchSize {
+			return purged, nil
+		}
+	}
 }
 
-func (s *ProductServiceRepo) GetAllProducts() ([]models.Product, error) {
-	return s.repository.GetAllProducts()
+// GetProductAsOf retorna o produto como era no instante asOf. As traduções não têm histórico,
+// então o conteúdo é sempre o base.
+func (s *ProductServiceRepo) GetProductAsOf(ctx context.Context, id uint, asOf time.Time) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductAsOf")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)))
+
+	revision, err := s.repository.GetProductRevisionAsOf(ctx, id, asOf)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+	// Na lixeira naquele instante, o produto não existia para os clientes
+	if revision.Deleted {
+		return nil, NotFoundError(CodeProductNotFound, "Product not found", nil)
+	}
+
+	return revision.Product(), nil
 }
 
-func (s *ProductServiceRepo) GetProductByID(id uint) (*models.Product, error) {
-	return s.repository.GetProductByID(id)
+// GetProductRevisions lista todas as versões do produto, inclusive as remoções para a lixeira
+func (s *ProductServiceRepo) GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductRevisions")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)))
+
+	revisions, err := s.repository.GetProductRevisions(ctx, id)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+	if len(revisions) == 0 {
+		return nil, NotFoundError(CodeProductNotFound, "Product not found", nil)
+	}
+
+	return revisions, nil
 }
 
-func (s *ProductServiceRepo) GetProductByName(name string) ([]models.Product, error) {
-	return s.repository.GetProductByName(name)
+// RevertProduct grava uma nova revisão com o conteúdo de uma revisão anterior.
+// Produtos na lixeira precisam ser restaurados antes.
+func (s *ProductServiceRepo) RevertProduct(ctx context.Context, id, revision uint) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.RevertProduct")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(id)), attribute.Int("product.revision", int(revision)))
+
+	var reverted models.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		target, err := s.repository.GetProductRevision(ctx, id, revision)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return NotFoundError(CodeRevisionNotFound, "Revision not found", err)
+		}
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		before, err := s.repository.GetProductByID(ctx, id)
+		if err != nil {
+			return translateProductError(err)
+		}
+
+		reverted = *before
+		reverted.Name, reverted.Description, reverted.Category = target.Name, target.Description, target.Category
+		reverted.Price, reverted.Stock = target.Price, target.Stock
+
+		// As regras podem ter mudado desde a revisão, e o nome pode ter sido ocupado por outro produto
+		if err := s.validateProduct(ctx, &reverted); err != nil {
+			return err
+		}
+
+		if err := s.repository.UpdateProduct(ctx, &reverted); err != nil {
+			return translateProductError(err)
+		}
+
+		if err := recordAudit(ctx, s.audit, AuditEntityProduct, id, models.AuditActionRevert, before, &reverted); err != nil {
+			return err
+		}
+
+		return enqueueProductEvents(ctx, s.outbox, events.ProductUpdated, before, &reverted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reverted, nil
 }
 
-func (s *ProductServiceRepo) GetProductsCount() int64 {
-	return s.repository.GetProductsCount()
+// enqueueProductEvents grava na outbox o evento da mudança e, quando o estoque muda, também o StockChanged
+func enqueueProductEvents(ctx context.Context, outbox repositories.OutboxRepository, eventType string, before, after *models.Product) error {
+	product := after
+	if product == nil {
+		product = before
+	}
+	if err := enqueueEvent(ctx, outbox, eventType, events.AggregateProduct, product.ID, events.NewProduct(product)); err != nil {
+		return translateProductError(err)
+	}
+
+	if before != nil && after != nil && before.Stock != after.Stock {
+		change := events.StockChange{ProductID: product.ID, Previous: before.Stock, Current: after.Stock}
+		return translateProductError(enqueueEvent(ctx, outbox, events.StockChanged, events.AggregateProduct, product.ID, change))
+	}
+
+	return nil
 }
 
-func (s *ProductServiceRepo) UpdateProduct(product *models.Product) error {
-	return s.repository.UpdateProduct(product)
+// validateProduct aplica as regras declaradas nas tags do modelo e as regras que dependem do banco,
+// reportando todas as violações de uma só vez
+func (s *ProductServiceRepo) validateProduct(ctx context.Context, product *models.Product) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(product) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	exists, err := s.repository.ExistsByNameAndCategory(ctx, product.Name, product.Category, product.ID)
+	if err != nil {
+		return translateProductError(err)
+	}
+	if exists {
+		fields = append(fields, FieldError{
+			Field:   "name",
+			Code:    "unique",
+			Message: i18n.T(i18n.DefaultLocale, "validation.unique", map[string]string{"field": "name"}),
+		})
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
 }
 
-func (s *ProductServiceRepo) DeleteProduct(id uint) error {
-	return s.repository.DeleteProduct(id)
+// localize substitui nome e descrição pela tradução no primeiro locale pedido que existir para cada produto.
+// Produtos sem tradução mantêm os campos base.
+func (s *ProductServiceRepo) localize(ctx context.Context, products []models.Product) ([]models.Product, error) {
+	locales := i18n.ContentLocales(ctx)
+	if len(locales) == 0 || len(products) == 0 {
+		return products, nil
+	}
+
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	translations, err := s.translations.FindTranslations(ctx, ids, locales)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+
+	byProduct := map[uint]map[string]models.ProductTranslation{}
+	for _, translation := range translations {
+		if byProduct[translation.ProductID] == nil {
+			byProduct[translation.ProductID] = map[string]models.ProductTranslation{}
+		}
+		byProduct[translation.ProductID][translation.Locale] = translation
+	}
+
+	for i := range products {
+		for _, locale := range locales {
+			if translation, ok := byProduct[products[i].ID][locale]; ok {
+				products[i].Name = translation.Name
+				products[i].Description = translation.Description
+				break
+			}
+		}
+	}
+
+	return products, nil
 }
@@ -1,75 +1,141 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"produtos-api/src/models"
+	"produtos-api/src/repositories"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) CreateProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *MockProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
+	args := m.Called(ctx, product)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) GetAllProducts() ([]models.Product, error) {
-	args := m.Called()
+func (m *MockProductRepository) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetProductByID(id uint) (*models.Product, error) {
-	args := m.Called(id)
+func (m *MockProductRepository) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetProductByName(name string) ([]models.Product, error) {
-	args := m.Called(name)
+func (m *MockProductRepository) GetProductByName(ctx context.Context, name string) ([]models.Product, error) {
+	args := m.Called(ctx, name)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetProductsCount() int64 {
-	args := m.Called()
+func (m *MockProductRepository) GetProductsCount(ctx context.Context) int64 {
+	args := m.Called(ctx)
 	return args.Get(0).(int64)
 }
 
-func (m *MockProductRepository) UpdateProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *MockProductRepository) ExistsByNameAndCategory(ctx context.Context, name, category string, excludeID uint) (bool, error) {
+	args := m.Called(ctx, name, category, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) DeleteProduct(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetDeletedProducts(ctx context.Context, deletedBefore time.Time, limit int) ([]models.Product, error) {
+	args := m.Called(ctx, deletedBefore, limit)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetDeletedProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RestoreProduct(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) DeleteProduct(id uint) error {
-	args := m.Called(id)
+func (m *MockProductRepository) PurgeProduct(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockProductRepository) GetProductRevisions(ctx context.Context, id uint) ([]models.ProductRevision, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.ProductRevision), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductRevision(ctx context.Context, id, revision uint) (*models.ProductRevision, error) {
+	args := m.Called(ctx, id, revision)
+	return args.Get(0).(*models.ProductRevision), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductRevisionAsOf(ctx context.Context, id uint, asOf time.Time) (*models.ProductRevision, error) {
+	args := m.Called(ctx, id, asOf)
+	return args.Get(0).(*models.ProductRevision), args.Error(1)
+}
+
+// passthroughTransactor executa o bloco diretamente, sem transação
+type passthroughTransactor struct{}
+
+func (passthroughTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newTestProductService(repo *MockProductRepository) *ProductServiceRepo {
+	return NewProductService(repo, new(MockProductTranslationRepository), newTestAuditRepository(), newTestOutbox(), passthroughTransactor{})
+}
+
+// newTestAuditRepository aceita qualquer registro de auditoria gravado pelos serviços
+func newTestAuditRepository() *MockAuditRepository {
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("LastAuditHash", mock.Anything).Return("", nil).Maybe()
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return auditRepo
+}
+
 func TestServiceCreateProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
 	product := &models.Product{Name: "Test Product", Price: 100.0}
-	mockRepo.On("CreateProduct", product).Return(nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Test Product", "", uint(0)).Return(false, nil)
+	mockRepo.On("CreateProduct", mock.Anything, product).Return(nil)
 
-	err := productService.CreateProduct(product)
+	err := productService.CreateProduct(context.Background(), product)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestServiceGetAllProducts(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
-	mockRepo.On("GetAllProducts").Return([]models.Product{
+	mockRepo.On("GetAllProducts", mock.Anything).Return([]models.Product{
 		{ID: 1, Name: "Product 1", Price: 100.0},
 		{ID: 2, Name: "Product 2", Price: 150.0},
 	}, nil)
 
-	products, err := productService.GetAllProducts()
+	products, err := productService.GetAllProducts(context.Background())
 	assert.NoError(t, err)
 	assert.Len(t, products, 2)
 	mockRepo.AssertExpectations(t)
@@ -77,12 +143,12 @@ func TestServiceGetAllProducts(t *testing.T) {
 
 func TestServiceGetProductByID(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
 	product := &models.Product{ID: 1, Name: "Product 1", Price: 100.0}
-	mockRepo.On("GetProductByID", uint(1)).Return(product, nil)
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(product, nil)
 
-	result, err := productService.GetProductByID(1)
+	result, err := productService.GetProductByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(1), result.ID)
 	mockRepo.AssertExpectations(t)
@@ -90,13 +156,13 @@ func TestServiceGetProductByID(t *testing.T) {
 
 func TestServiceGetProductByName(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
-	mockRepo.On("GetProductByName", "Product 1").Return([]models.Product{
+	mockRepo.On("GetProductByName", mock.Anything, "Product 1").Return([]models.Product{
 		{ID: 1, Name: "Product 1", Price: 100.0},
 	}, nil)
 
-	products, err := productService.GetProductByName("Product 1")
+	products, err := productService.GetProductByName(context.Background(), "Product 1")
 	assert.NoError(t, err)
 	assert.Len(t, products, 1)
 	mockRepo.AssertExpectations(t)
@@ -104,34 +170,343 @@ func TestServiceGetProductByName(t *testing.T) {
 
 func TestServiceGetProductsCount(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
-	mockRepo.On("GetProductsCount").Return(int64(2))
+	mockRepo.On("GetProductsCount", mock.Anything).Return(int64(2))
 
-	count := productService.GetProductsCount()
+	count := productService.GetProductsCount(context.Background())
 	assert.Equal(t, int64(2), count)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestServiceUpdateProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
 	product := &models.Product{ID: 1, Name: "Updated Product", Price: 120.0}
-	mockRepo.On("UpdateProduct", product).Return(nil)
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Product 1"}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Updated Product", "", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, product).Return(nil)
 
-	err := productService.UpdateProduct(product)
+	err := productService.UpdateProduct(context.Background(), product)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestServiceDeleteProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	productService := NewProductService(mockRepo)
+	productService := newTestProductService(mockRepo)
 
-	mockRepo.On("DeleteProduct", uint(1)).Return(nil)
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Product 1"}, nil)
+	mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
 
-	err := productService.DeleteProduct(1)
+	err := productService.DeleteProduct(context.Background(), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestServiceGetProductByIDNotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, repositories.ErrNotFound)
+
+	_, err := productService.GetProductByID(context.Background(), 1)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindNotFound, domainErr.Kind)
+	assert.Equal(t, CodeProductNotFound, domainErr.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceGetProductByIDUnavailable(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, errors.New("database is locked"))
+
+	_, err := productService.GetProductByID(context.Background(), 1)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindUnavailable, domainErr.Kind)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceGetProductByIDKeepsContextErrors(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, context.DeadlineExceeded)
+
+	_, err := productService.GetProductByID(context.Background(), 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, ok := AsDomainError(err)
+	assert.False(t, ok)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceUpdateProductNotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	product := &models.Product{ID: 1, Name: "Updated Product", Price: 120.0}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, repositories.ErrNotFound)
+
+	err := productService.UpdateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindNotFound, domainErr.Kind)
+	mockRepo.AssertNotCalled(t, "UpdateProduct", mock.Anything, product)
+}
+
+func TestServiceCreateProductConflict(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	product := &models.Product{ID: 1, Name: "Test Product", Price: 100.0}
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Test Product", "", uint(1)).Return(false, nil)
+	mockRepo.On("CreateProduct", mock.Anything, product).Return(repositories.ErrDuplicated)
+
+	err := productService.CreateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	mockRepo.AssertExpectations(t)
+}
+
+// Duas gravações simultâneas passam pela verificação prévia; o índice único barra a segunda
+func TestServiceUpdateProductNameTakenConcurrently(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	product := &models.Product{ID: 1, Name: "Caneta", Category: "Papelaria"}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Lápis", Category: "Papelaria"}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "Papelaria", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, product).Return(repositories.ErrDuplicated)
+
+	err := productService.UpdateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	assert.Equal(t, CodeProductConflict, domainErr.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceDeleteProductNotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, repositories.ErrNotFound)
+
+	err := productService.DeleteProduct(context.Background(), 1)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindNotFound, domainErr.Kind)
+	mockRepo.AssertNotCalled(t, "DeleteProduct", mock.Anything, uint(1))
+}
+
+func TestServiceCreateProductReportsAllViolations(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	product := &models.Product{Name: " ", Price: 10.999, Stock: -1}
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, " ", "", uint(0)).Return(false, nil)
+
+	err := productService.CreateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+
+	codes := map[string][]string{}
+	for _, field := range domainErr.Fields {
+		codes[field.Field] = append(codes[field.Field], field.Code)
+	}
+	assert.Equal(t, []string{"notblank"}, codes["name"])
+	assert.Equal(t, []string{"precision"}, codes["price"])
+	assert.Equal(t, []string{"gte"}, codes["stock"])
+	mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, product)
+}
+
+func TestServiceCreateProductDuplicateNameInCategory(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	product := &models.Product{Name: "Notebook", Category: "informatica", Price: 3500.5}
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Notebook", "informatica", uint(0)).Return(true, nil)
+
+	err := productService.CreateProduct(context.Background(), product)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, []FieldError{{Field: "name", Code: "unique", Message: "name must be unique within the category"}}, domainErr.Fields)
+	mockRepo.AssertNotCalled(t, "CreateProduct", mock.Anything, product)
+}
+
+func TestServiceUpdateProductKeepsCreatedAt(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	product := &models.Product{ID: 1, Name: "Caneta", Price: 2.5, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", CreatedAt: createdAt}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, product).Return(nil)
+
+	err := productService.UpdateProduct(context.Background(), product)
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, product.CreatedAt)
+	assert.False(t, product.DeletedAt.Valid)
+}
+
+func TestServiceRestoreProduct(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	deleted := &models.Product{ID: 1, Name: "Caneta", Category: "papelaria", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	mockRepo.On("GetDeletedProductByID", mock.Anything, uint(1)).Return(deleted, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "papelaria", uint(1)).Return(false, nil)
+	mockRepo.On("RestoreProduct", mock.Anything, uint(1)).Return(nil)
+	auditRepo.On("LastAuditHash", mock.Anything).Return("", nil)
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.MatchedBy(func(record *models.AuditRecord) bool {
+		return record.Action == models.AuditActionRestore && len(record.Changes) == 1 && record.Changes[0].Field == "deleted_at"
+	})).Return(nil)
+
+	product, err := productService.RestoreProduct(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Caneta", product.Name)
+	assert.False(t, product.DeletedAt.Valid)
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestServiceRestoreProductNameTaken(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetDeletedProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta"}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(1)).Return(true, nil)
+
+	_, err := productService.RestoreProduct(context.Background(), 1)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindConflict, domainErr.Kind)
+	mockRepo.AssertNotCalled(t, "RestoreProduct", mock.Anything, uint(1))
+}
+
+func TestServiceRestoreProductNotInTrash(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetDeletedProductByID", mock.Anything, uint(1)).Return(&models.Product{}, repositories.ErrNotFound)
+
+	_, err := productService.RestoreProduct(context.Background(), 1)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeProductNotFound, domainErr.Code)
+}
+
+func TestServicePurgeDeletedProducts(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	retention := 24 * time.Hour
+	expired := mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= retention && time.Since(before) < retention+time.Minute
+	})
+	mockRepo.On("GetDeletedProducts", mock.Anything, expired, purgeBatchSize).Return([]models.Product{{ID: 1}, {ID: 2}}, nil)
+	mockRepo.On("PurgeProduct", mock.Anything, uint(1)).Return(nil)
+	// Restaurado depois da leitura do lote
+	mockRepo.On("PurgeProduct", mock.Anything, uint(2)).Return(repositories.ErrNotFound)
+	auditRepo.On("LastAuditHash", mock.Anything).Return("", nil)
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.MatchedBy(func(record *models.AuditRecord) bool {
+		return record.Action == models.AuditActionPurge && record.EntityID == 1 && record.Actor == "system:purge"
+	})).Return(nil).Once()
+
+	purged, err := productService.PurgeDeletedProducts(context.Background(), retention)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestServiceGetProductAsOf(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	asOf := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	validFrom := asOf.Add(-time.Hour)
+	mockRepo.On("GetProductRevisionAsOf", mock.Anything, uint(1), asOf).Return(
+		&models.ProductRevision{ProductID: 1, Revision: 2, Name: "Caneta", Price: 2.5, ValidFrom: validFrom}, nil)
+
+	product, err := productService.GetProductAsOf(context.Background(), 1, asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), product.ID)
+	assert.Equal(t, "Caneta", product.Name)
+	assert.Equal(t, validFrom, product.UpdatedAt)
+}
+
+func TestServiceGetProductAsOfInTrash(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	asOf := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetProductRevisionAsOf", mock.Anything, uint(1), asOf).Return(
+		&models.ProductRevision{ProductID: 1, Revision: 3, Name: "Caneta", Deleted: true}, nil)
+
+	_, err := productService.GetProductAsOf(context.Background(), 1, asOf)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeProductNotFound, domainErr.Code)
+}
+
+func TestServiceGetProductRevisionsUnknownProduct(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductRevisions", mock.Anything, uint(9)).Return([]models.ProductRevision{}, nil)
+
+	_, err := productService.GetProductRevisions(context.Background(), 9)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindNotFound, domainErr.Kind)
+}
+
+func TestServiceRevertProduct(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	auditRepo := new(MockAuditRepository)
+	productService := NewProductService(mockRepo, new(MockProductTranslationRepository), auditRepo, newTestOutbox(), passthroughTransactor{})
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockRepo.On("GetProductRevision", mock.Anything, uint(1), uint(1)).Return(
+		&models.ProductRevision{ProductID: 1, Revision: 1, Name: "Caneta", Price: 2.5, Stock: 10}, nil)
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(
+		&models.Product{ID: 1, Name: "Caneta azul", Price: 3, Stock: 4, CreatedAt: createdAt}, nil)
+	mockRepo.On("ExistsByNameAndCategory", mock.Anything, "Caneta", "", uint(1)).Return(false, nil)
+	mockRepo.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(product *models.Product) bool {
+		return product.Name == "Caneta" && product.Price == 2.5 && product.Stock == 10 && product.CreatedAt.Equal(createdAt)
+	})).Return(nil)
+	auditRepo.On("LastAuditHash", mock.Anything).Return("", nil)
+	auditRepo.On("CreateAuditRecord", mock.Anything, mock.MatchedBy(func(record *models.AuditRecord) bool {
+		return record.Action == models.AuditActionRevert && len(record.Changes) == 3
+	})).Return(nil)
+
+	product, err := productService.RevertProduct(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Caneta", product.Name)
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestServiceRevertProductUnknownRevision(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	productService := newTestProductService(mockRepo)
+
+	mockRepo.On("GetProductRevision", mock.Anything, uint(1), uint(7)).Return(&models.ProductRevision{}, repositories.ErrNotFound)
+
+	_, err := productService.RevertProduct(context.Background(), 1, 7)
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeRevisionNotFound, domainErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+}
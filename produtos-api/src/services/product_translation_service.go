@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"produtos-api/src/i18n"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+)
+
+// CodeTranslationNotFound é o código do erro de tradução inexistente para o locale
+const CodeTranslationNotFound = "translation_not_found"
+
+type ProductTranslationService interface {
+	GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error)
+	GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error)
+	SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error
+	DeleteTranslation(ctx context.Context, productID uint, locale string) error
+}
+
+type ProductTranslationServiceRepo struct {
+	products     repositories.ProductRepository
+	translations repositories.ProductTranslationRepository
+}
+
+func NewProductTranslationService(products repositories.ProductRepository, translations repositories.ProductTranslationRepository) *ProductTranslationServiceRepo {
+	return &ProductTranslationServiceRepo{products: products, translations: translations}
+}
+
+func (s *ProductTranslationServiceRepo) GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "ProductTranslationService.GetTranslations")
+	defer span.End()
+
+	if err := s.ensureProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.translations.GetTranslations(ctx, productID)
+	return translations, translateProductError(err)
+}
+
+func (s *ProductTranslationServiceRepo) GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "ProductTranslationService.GetTranslation")
+	defer span.End()
+
+	locale, err := canonicalLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	translation, err := s.translations.GetTranslation(ctx, productID, locale)
+	if err != nil {
+		return nil, translateTranslationError(err)
+	}
+
+	return translation, nil
+}
+
+// SaveTranslation cria ou substitui a tradução do produto para o locale informado
+func (s *ProductTranslationServiceRepo) SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error {
+	ctx, span := tracer.Start(ctx, "ProductTranslationService.SaveTranslation")
+	defer span.End()
+
+	var fields []FieldError
+	locale, err := i18n.CanonicalLocale(translation.Locale)
+	if err != nil {
+		fields = append(fields, invalidLocaleField())
+	}
+	for _, fe := range validation.Struct(translation) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	if err := s.ensureProduct(ctx, translation.ProductID); err != nil {
+		return err
+	}
+
+	translation.Locale = locale
+	return translateProductError(s.translations.SaveTranslation(ctx, translation))
+}
+
+func (s *ProductTranslationServiceRepo) DeleteTranslation(ctx context.Context, productID uint, locale string) error {
+	ctx, span := tracer.Start(ctx, "ProductTranslationService.DeleteTranslation")
+	defer span.End()
+
+	locale, err := canonicalLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	return translateTranslationError(s.translations.DeleteTranslation(ctx, productID, locale))
+}
+
+func (s *ProductTranslationServiceRepo) ensureProduct(ctx context.Context, productID uint) error {
+	_, err := s.products.GetProductByID(ctx, productID)
+	return translateProductError(err)
+}
+
+func canonicalLocale(locale string) (string, error) {
+	canonical, err := i18n.CanonicalLocale(locale)
+	if err != nil {
+		return "", ValidationError(invalidLocaleField())
+	}
+
+	return canonical, nil
+}
+
+func invalidLocaleField() FieldError {
+	return FieldError{
+		Field:   "locale",
+		Code:    "locale",
+		Message: i18n.T(i18n.DefaultLocale, "validation.locale", map[string]string{"field": "locale"}),
+	}
+}
+
+func translateTranslationError(err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return NotFoundError(CodeTranslationNotFound, "Translation not found", err)
+	}
+
+	return translateProductError(err)
+}
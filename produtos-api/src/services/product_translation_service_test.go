@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"produtos-api/src/i18n"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductTranslationRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductTranslationRepository) SaveTranslation(ctx context.Context, translation *models.ProductTranslation) error {
+	args := m.Called(ctx, translation)
+	return args.Error(0)
+}
+
+func (m *MockProductTranslationRepository) GetTranslation(ctx context.Context, productID uint, locale string) (*models.ProductTranslation, error) {
+	args := m.Called(ctx, productID, locale)
+	return args.Get(0).(*models.ProductTranslation), args.Error(1)
+}
+
+func (m *MockProductTranslationRepository) GetTranslations(ctx context.Context, productID uint) ([]models.ProductTranslation, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]models.ProductTranslation), args.Error(1)
+}
+
+func (m *MockProductTranslationRepository) FindTranslations(ctx context.Context, productIDs []uint, locales []string) ([]models.ProductTranslation, error) {
+	args := m.Called(ctx, productIDs, locales)
+	return args.Get(0).([]models.ProductTranslation), args.Error(1)
+}
+
+func (m *MockProductTranslationRepository) DeleteTranslation(ctx context.Context, productID uint, locale string) error {
+	args := m.Called(ctx, productID, locale)
+	return args.Error(0)
+}
+
+func TestServiceSaveTranslationCanonicalizesLocale(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockTranslations := new(MockProductTranslationRepository)
+	translationService := NewProductTranslationService(mockRepo, mockTranslations)
+
+	translation := &models.ProductTranslation{ProductID: 1, Locale: "pt-pt", Name: "Caneta azul"}
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1}, nil)
+	mockTranslations.On("SaveTranslation", mock.Anything, translation).Return(nil)
+
+	err := translationService.SaveTranslation(context.Background(), translation)
+	assert.NoError(t, err)
+	assert.Equal(t, "pt-PT", translation.Locale)
+	mockTranslations.AssertExpectations(t)
+}
+
+func TestServiceSaveTranslationInvalid(t *testing.T) {
+	translationService := NewProductTranslationService(new(MockProductRepository), new(MockProductTranslationRepository))
+
+	err := translationService.SaveTranslation(context.Background(), &models.ProductTranslation{ProductID: 1, Locale: "not a locale!"})
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Len(t, domainErr.Fields, 2)
+}
+
+func TestServiceSaveTranslationProductNotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	translationService := NewProductTranslationService(mockRepo, new(MockProductTranslationRepository))
+
+	mockRepo.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{}, repositories.ErrNotFound)
+
+	err := translationService.SaveTranslation(context.Background(), &models.ProductTranslation{ProductID: 1, Locale: "es-ES", Name: "Bolígrafo"})
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeProductNotFound, domainErr.Code)
+}
+
+func TestServiceDeleteTranslationNotFound(t *testing.T) {
+	mockTranslations := new(MockProductTranslationRepository)
+	translationService := NewProductTranslationService(new(MockProductRepository), mockTranslations)
+
+	mockTranslations.On("DeleteTranslation", mock.Anything, uint(1), "es-ES").Return(repositories.ErrNotFound)
+
+	err := translationService.DeleteTranslation(context.Background(), 1, "es-es")
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeTranslationNotFound, domainErr.Code)
+	mockTranslations.AssertExpectations(t)
+}
+
+func TestServiceGetAllProductsLocalized(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockTranslations := new(MockProductTranslationRepository)
+	productService := NewProductService(mockRepo, mockTranslations, new(MockAuditRepository), newTestOutbox(), passthroughTransactor{})
+
+	mockRepo.On("GetAllProducts", mock.Anything).Return([]models.Product{
+		{ID: 1, Name: "Pen", Description: "Blue pen"},
+		{ID: 2, Name: "Pencil", Description: "HB pencil"},
+	}, nil)
+	locales := []string{"pt-PT", "pt"}
+	mockTranslations.On("FindTranslations", mock.Anything, []uint{1, 2}, locales).Return([]models.ProductTranslation{
+		{ProductID: 1, Locale: "pt", Name: "Caneta", Description: "Caneta azul"},
+		{ProductID: 1, Locale: "pt-PT", Name: "Esferográfica", Description: "Esferográfica azul"},
+	}, nil)
+
+	ctx := i18n.WithContentLocales(context.Background(), locales)
+	products, err := productService.GetAllProducts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Esferográfica", products[0].Name)
+	assert.Equal(t, "Esferográfica azul", products[0].Description)
+	// Sem tradução, o produto mantém os campos base
+	assert.Equal(t, "Pencil", products[1].Name)
+	mockTranslations.AssertExpectations(t)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/ratelimit"
+)
+
+// CodeRateLimited é o código do erro de limite de requisições excedido
+const CodeRateLimited = "rate_limited"
+
+type RateLimitService interface {
+	Allow(ctx context.Context, identity, method, route string) (ratelimit.Result, error)
+}
+
+type RateLimitServiceBackend struct {
+	backend  ratelimit.Backend
+	policies ratelimit.Policies
+	now      func() time.Time
+}
+
+// NewRateLimitService cria o serviço de limite de requisições sobre o backend informado
+func NewRateLimitService(backend ratelimit.Backend, policies ratelimit.Policies) *RateLimitServiceBackend {
+	return &RateLimitServiceBackend{backend: backend, policies: policies, now: time.Now}
+}
+
+// Allow consome um token do bucket da identidade (chave de API, usuário ou IP) para a rota.
+// Retorna um erro RateLimited quando o limite foi atingido; erros do backend são repassados
+// para que o chamador decida se libera a requisição.
+func (s *RateLimitServiceBackend) Allow(ctx context.Context, identity, method, route string) (ratelimit.Result, error) {
+	ctx, span := tracer.Start(ctx, "RateLimitService.Allow")
+	defer span.End()
+
+	limit, bucket := s.policies.For(method, route)
+	if limit.Unlimited() {
+		return ratelimit.Result{Limit: limit, Allowed: true}, nil
+	}
+
+	result, err := s.backend.Take(ctx, identity+"|"+bucket, limit, s.now())
+	if err != nil {
+		return ratelimit.Result{Limit: limit, Allowed: true}, err
+	}
+	if !result.Allowed {
+		return result, RateLimitedError("Rate limit exceeded", nil)
+	}
+
+	return result, nil
+}
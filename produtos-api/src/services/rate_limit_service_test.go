@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"produtos-api/src/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimitBackend struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitBackend) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	args := m.Called(ctx, key, limit, now)
+	return args.Get(0).(ratelimit.Result), args.Error(1)
+}
+
+func newTestRateLimitService(backend ratelimit.Backend, now *time.Time) *RateLimitServiceBackend {
+	service := NewRateLimitService(backend, ratelimit.ParsePolicies(
+		ratelimit.Limit{Requests: 3, Period: time.Minute},
+		[]string{"POST /products=2/s", "GET /healthz=off", "invalid", "GET /products=abc"},
+	))
+	service.now = func() time.Time { return *now }
+
+	return service
+}
+
+func TestServiceRateLimitTokenBucket(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service := newTestRateLimitService(ratelimit.NewMemoryBackend(), &now)
+	ctx := context.Background()
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := service.Allow(ctx, "ip:10.0.0.1", "GET", "/products")
+		assert.NoError(t, err)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := service.Allow(ctx, "ip:10.0.0.1", "GET", "/products")
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindRateLimited, domainErr.Kind)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	// Outras identidades têm buckets próprios
+	_, err = service.Allow(ctx, "key:api_key:orders", "GET", "/products")
+	assert.NoError(t, err)
+
+	// Um token é reposto a cada 20s
+	now = now.Add(20 * time.Second)
+	_, err = service.Allow(ctx, "ip:10.0.0.1", "GET", "/products")
+	assert.NoError(t, err)
+	_, err = service.Allow(ctx, "ip:10.0.0.1", "GET", "/products")
+	assert.Error(t, err)
+}
+
+func TestServiceRateLimitPerRoutePolicies(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service := newTestRateLimitService(ratelimit.NewMemoryBackend(), &now)
+	ctx := context.Background()
+
+	// A rota com política própria não consome o bucket padrão
+	for i := 0; i < 2; i++ {
+		result, err := service.Allow(ctx, "user:maria", "POST", "/products")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Limit.Requests)
+	}
+	_, err := service.Allow(ctx, "user:maria", "POST", "/products")
+	assert.Error(t, err)
+
+	result, err := service.Allow(ctx, "user:maria", "GET", "/products/{id}")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+
+	// Rotas desativadas não têm limite
+	for i := 0; i < 10; i++ {
+		result, err := service.Allow(ctx, "user:maria", "GET", "/healthz")
+		assert.NoError(t, err)
+		assert.True(t, result.Limit.Unlimited())
+	}
+}
+
+func TestServiceRateLimitBackendFailure(t *testing.T) {
+	now := time.Now()
+	backend := new(MockRateLimitBackend)
+	service := newTestRateLimitService(backend, &now)
+
+	backend.On("Take", mock.Anything, "ip:10.0.0.1|*", ratelimit.Limit{Requests: 3, Period: time.Minute}, now).
+		Return(ratelimit.Result{}, errors.New("connection refused"))
+
+	result, err := service.Allow(context.Background(), "ip:10.0.0.1", "GET", "/products")
+
+	assert.Error(t, err)
+	_, isDomainErr := AsDomainError(err)
+	assert.False(t, isDomainErr)
+	assert.True(t, result.Allowed)
+	backend.AssertExpectations(t)
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/customers"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
+	"produtos-api/src/logging"
+	"produtos-api/src/mail"
+	"produtos-api/src/models"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Códigos dos erros dos avisos de volta ao estoque
+const (
+	CodeStockSubscriptionNotFound = "stock_subscription_not_found"
+	CodeStockSubscriptionConflict = "stock_subscription_conflict"
+	CodeProductInStock            = "product_in_stock"
+	CodeCustomersUnavailable      = "customers_unavailable"
+)
+
+// Prefixo e tamanho dos tokens de cancelamento dos avisos
+const (
+	unsubscribeTokenTag   = "unsub_"
+	unsubscribeTokenBytes = 24
+)
+
+// Tamanho dos lotes do worker dos avisos e espera para tentar de novo a busca de um cliente que falhou
+const (
+	stockAlertBatchSize  = 100
+	stockAlertRetryDelay = time.Minute
+)
+
+// errBackInStockLimited desfaz a transação do aviso de um destinatário que atingiu o limite
+var errBackInStockLimited = errors.New("back in stock notifications limit reached")
+
+var stockSubscriptionLogger = logging.Logger("stock-subscriptions")
+
+// CustomerClient é a API de clientes vista pelos avisos de volta ao estoque; implementado por *customers.Client
+type CustomerClient interface {
+	GetCustomer(ctx context.Context, id uint) (*models.Customer, error)
+}
+
+// StockSubscriptionService define os avisos de volta ao estoque
+type StockSubscriptionService interface {
+	Subscribe(ctx context.Context, productID uint, request *models.StockSubscriptionRequest) (*models.IssuedStockSubscription, error)
+	Unsubscribe(ctx context.Context, token string) error
+	HandleEvent(ctx context.Context, msg broker.Message) error
+	NotifyDue(ctx context.Context) (int, error)
+}
+
+type StockSubscriptionServiceRepo struct {
+	repo           repositories.StockSubscriptionRepository
+	products       repositories.ProductRepository
+	translations   repositories.ProductTranslationRepository
+	transactor     repositories.Transactor
+	customers      CustomerClient
+	notifications  NotificationService
+	limit          ratelimit.Limit
+	unsubscribeURL string
+	now            func() time.Time
+}
+
+// NewStockSubscriptionService cria o serviço dos avisos de volta ao estoque. Cada endereço recebe no máximo
+// limit.Requests avisos a cada limit.Period, contados na fila de e-mails, já que o e-mail informado na inscrição
+// não é verificado. unsubscribeURL é o link de cancelamento incluído nos avisos, com {token} no lugar do token.
+func NewStockSubscriptionService(repo repositories.StockSubscriptionRepository, products repositories.ProductRepository,
+	translations repositories.ProductTranslationRepository, transactor repositories.Transactor, customers CustomerClient,
+	notifications NotificationService, limit ratelimit.Limit, unsubscribeURL string) *StockSubscriptionServiceRepo {
+	return &StockSubscriptionServiceRepo{
+		repo:           repo,
+		products:       products,
+		translations:   translations,
+		transactor:     transactor,
+		customers:      customers,
+		notifications:  notifications,
+		limit:          limit,
+		unsubscribeURL: unsubscribeURL,
+		now:            time.Now,
+	}
+}
+
+// Subscribe inscreve um e-mail ou um cliente no aviso de volta ao estoque do produto, que precisa estar
+// sem estoque. O aviso é enviado no idioma da requisição; o token de cancelamento só é retornado nesta chamada.
+func (s *StockSubscriptionServiceRepo) Subscribe(ctx context.Context, productID uint, request *models.StockSubscriptionRequest) (*models.IssuedStockSubscription, error) {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionService.Subscribe")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(productID)))
+
+	request.Email = strings.TrimSpace(request.Email)
+	if err := validateStockSubscription(request); err != nil {
+		return nil, err
+	}
+
+	product, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, translateProductError(err)
+	}
+	if product.Stock > 0 {
+		return nil, ConflictError(CodeProductInStock, "Product is in stock", nil)
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	if request.CustomerID != 0 {
+		if err := s.checkCustomer(ctx, principal, request.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	token, tokenHash, err := generateUnsubscribeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.StockSubscription{
+		ProductID:  productID,
+		Email:      request.Email,
+		CustomerID: request.CustomerID,
+		Locale:     i18n.Negotiate(strings.Join(i18n.ContentLocales(ctx), ",")),
+		TokenHash:  tokenHash,
+	}
+	if principal != nil {
+		subscription.CreatedBy = principal.Subject
+	}
+
+	if err := s.repo.CreateStockSubscription(ctx, subscription); err != nil {
+		return nil, translateStockSubscriptionError(err)
+	}
+
+	return &models.IssuedStockSubscription{StockSubscription: *subscription, UnsubscribeToken: token}, nil
+}
+
+// Unsubscribe cancela o aviso do token retornado na inscrição. O token do link de um aviso enviado cancela
+// todos os avisos pendentes do destinatário.
+func (s *StockSubscriptionServiceRepo) Unsubscribe(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionService.Unsubscribe")
+	defer span.End()
+
+	if !strings.HasPrefix(token, unsubscribeTokenTag) {
+		return NotFoundError(CodeStockSubscriptionNotFound, "Stock subscription not found", nil)
+	}
+
+	tokenHash := hashUnsubscribeToken(token)
+	err := s.repo.DeleteStockSubscriptionByToken(ctx, tokenHash)
+	if errors.Is(err, repositories.ErrNotFound) {
+		err = s.repo.DeleteRecipientSubscriptions(ctx, tokenHash)
+	}
+
+	return translateStockSubscriptionError(err)
+}
+
+// HandleEvent recebe os eventos publicados pela outbox: quando o estoque de um produto sai de zero, marca
+// as inscrições do produto para o worker de NotifyDue. Os demais eventos são ignorados. Como roda no Fanout
+// da outbox, com o publicador do Kafka, faz só uma escrita no banco: as buscas na API de clientes ficam no worker.
+func (s *StockSubscriptionServiceRepo) HandleEvent(ctx context.Context, msg broker.Message) error {
+	if msg.Type != events.StockChanged {
+		return nil
+	}
+
+	var change events.StockChange
+	if err := json.Unmarshal(msg.Value, &change); err != nil {
+		// Um payload inválido nunca vai ser processado: reprocessar apenas bloquearia os eventos seguintes
+		stockSubscriptionLogger.ErrorContext(ctx, "invalid event payload", "event_id", msg.ID, "type", msg.Type, "error", err)
+		return nil
+	}
+	if change.Previous > 0 || change.Current <= 0 {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "StockSubscriptionService.HandleEvent")
+	defer span.End()
+	span.SetAttributes(attribute.Int("product.id", int(change.ProductID)))
+
+	_, err := s.repo.MarkStockSubscriptionsDue(ctx, change.ProductID, s.now().UTC())
+	return translateProductError(err)
+}
+
+// NotifyDue enfileira os avisos das inscrições marcadas por HandleEvent e retorna quantos foram enfileirados.
+// As inscrições de um produto que foi excluído ou voltou a ficar sem estoque aguardam a próxima reposição.
+func (s *StockSubscriptionServiceRepo) NotifyDue(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "StockSubscriptionService.NotifyDue")
+	defer span.End()
+
+	queued := 0
+	defer func() { span.SetAttributes(attribute.Int("stock_subscriptions.notified", queued)) }()
+
+	products := map[uint]*models.Product{}
+	for {
+		// Cada inscrição processada é removida ou remarcada, então a próxima consulta já traz as seguintes
+		subscriptions, err := s.repo.GetDueStockSubscriptions(ctx, s.now().UTC(), stockAlertBatchSize)
+		if err != nil {
+			return queued, translateProductError(err)
+		}
+
+		for i := range subscriptions {
+			subscription := &subscriptions[i]
+			product, ok := products[subscription.ProductID]
+			if !ok {
+				product, err = s.products.GetProductByID(ctx, subscription.ProductID)
+				if errors.Is(err, repositories.ErrNotFound) {
+					product, err = nil, nil
+				}
+				if err != nil {
+					return queued, translateProductError(err)
+				}
+				products[subscription.ProductID] = product
+			}
+
+			if product == nil || product.Stock <= 0 {
+				if err := s.repo.SetStockSubscriptionNotifyAt(ctx, subscription.ID, nil); err != nil {
+					return queued, translateStockSubscriptionError(err)
+				}
+				continue
+			}
+
+			notified, err := s.notify(ctx, product, subscription)
+			if err != nil {
+				return queued, err
+			}
+			if notified {
+				queued++
+			}
+		}
+
+		if len(subscriptions) < stockAlertBatchSize {
+			return queued, nil
+		}
+	}
+}
+
+// notify enfileira o aviso da inscrição e a remove na mesma transação, e retorna se o aviso foi enfileirado.
+// A inscrição de um cliente que não existe mais é removida sem aviso; a de um cliente que a API de clientes
+// não conseguiu buscar é tentada de novo após stockAlertRetryDelay; a de um destinatário acima do limite
+// volta a aguardar a próxima reposição.
+func (s *StockSubscriptionServiceRepo) notify(ctx context.Context, product *models.Product, subscription *models.StockSubscription) (bool, error) {
+	to := subscription.Email
+	recipient := &models.StockSubscriptionRecipient{Email: strings.ToLower(subscription.Email), CustomerID: subscription.CustomerID}
+	if subscription.CustomerID != 0 {
+		customer, err := s.customers.GetCustomer(ctx, subscription.CustomerID)
+		switch {
+		case errors.Is(err, customers.ErrNotFound):
+			return false, translateStockSubscriptionError(s.repo.DeleteStockSubscription(ctx, subscription.ID))
+		case err != nil:
+			stockSubscriptionLogger.WarnContext(ctx, "back in stock notification postponed, customer lookup failed",
+				"subscription_id", subscription.ID, "product_id", product.ID, "customer_id", subscription.CustomerID, "error", err)
+			retryAt := s.now().UTC().Add(stockAlertRetryDelay)
+			return false, translateStockSubscriptionError(s.repo.SetStockSubscriptionNotifyAt(ctx, subscription.ID, &retryAt))
+		}
+		to = customer.Email
+	}
+	if to == "" {
+		return false, translateStockSubscriptionError(s.repo.DeleteStockSubscription(ctx, subscription.ID))
+	}
+
+	name := product.Name
+	translation, err := s.translations.GetTranslation(ctx, product.ID, subscription.Locale)
+	if err == nil {
+		name = translation.Name
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return false, translateProductError(err)
+	}
+
+	// A inscrição é removida com o aviso, então o link do e-mail leva um token do destinatário, que cancela
+	// os outros avisos dele
+	token, tokenHash, err := generateUnsubscribeToken()
+	if err != nil {
+		return false, err
+	}
+	recipient.TokenHash = tokenHash
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// O limite conta os avisos já enfileirados para o endereço, então um aviso que não chega à fila não o consome
+		if !s.limit.Unlimited() {
+			sent, err := s.notifications.CountQueued(ctx, mail.TemplateBackInStock, to, s.now().Add(-s.limit.Period))
+			if err != nil {
+				return err
+			}
+			if sent >= s.limit.Requests {
+				return errBackInStockLimited
+			}
+		}
+
+		if err := s.repo.SaveRecipientToken(ctx, recipient); err != nil {
+			return translateStockSubscriptionError(err)
+		}
+
+		err := s.notifications.Notify(ctx, Notification{
+			Key:      "back_in_stock:" + strconv.FormatUint(uint64(subscription.ID), 10),
+			Template: mail.TemplateBackInStock,
+			Locale:   subscription.Locale,
+			To:       to,
+			Data: mail.BackInStock{
+				ProductID: product.ID, ProductName: name, Price: product.Price,
+				UnsubscribeURL: strings.ReplaceAll(s.unsubscribeURL, "{token}", token),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return translateStockSubscriptionError(s.repo.DeleteStockSubscription(ctx, subscription.ID))
+	})
+	if errors.Is(err, errBackInStockLimited) {
+		stockSubscriptionLogger.WarnContext(ctx, "back in stock notification rate limited",
+			"subscription_id", subscription.ID, "product_id", product.ID, "limit", s.limit.String())
+		return false, translateStockSubscriptionError(s.repo.SetStockSubscriptionNotifyAt(ctx, subscription.ID, nil))
+	}
+
+	return err == nil, err
+}
+
+// checkCustomer verifica se o cliente existe e se é do titular autenticado; editores inscrevem qualquer cliente.
+// Clientes de outros titulares são tratados como inexistentes, para não revelar quais IDs existem.
+func (s *StockSubscriptionServiceRepo) checkCustomer(ctx context.Context, principal *auth.Principal, id uint) error {
+	customer, err := s.customers.GetCustomer(ctx, id)
+	if errors.Is(err, customers.ErrNotFound) {
+		return ValidationError(fieldError("customer_id", "exists", strconv.FormatUint(uint64(id), 10)))
+	}
+	if err != nil {
+		return customersError(err)
+	}
+
+	if principal == nil || (customer.Subject != principal.Subject && !principal.HasRole(auth.RoleEditor)) {
+		return ValidationError(fieldError("customer_id", "exists", strconv.FormatUint(uint64(id), 10)))
+	}
+
+	return nil
+}
+
+// validateStockSubscription aplica as regras declaradas no modelo e exige exatamente um destinatário
+func validateStockSubscription(request *models.StockSubscriptionRequest) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(request) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	switch {
+	case request.Email == "" && request.CustomerID == 0:
+		fields = append(fields, fieldError("email", "required", ""))
+	case request.Email != "" && request.CustomerID != 0:
+		fields = append(fields, fieldError("customer_id", "excluded_with", "email"))
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+// generateUnsubscribeToken cria o token de cancelamento e retorna o token em texto puro e o hash armazenado
+func generateUnsubscribeToken() (token, hash string, err error) {
+	secret := make([]byte, unsubscribeTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	token = unsubscribeTokenTag + hex.EncodeToString(secret)
+	return token, hashUnsubscribeToken(token), nil
+}
+
+func hashUnsubscribeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// customersError converte as falhas da API de clientes; erros de contexto são mantidos para que o controller responda 504/499
+func customersError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return UnavailableError(CodeCustomersUnavailable, "Customers API is unavailable", err)
+}
+
+func translateStockSubscriptionError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return NotFoundError(CodeStockSubscriptionNotFound, "Stock subscription not found", err)
+	case errors.Is(err, repositories.ErrDuplicated):
+		return ConflictError(CodeStockSubscriptionConflict, "Recipient is already subscribed to the product", err)
+	default:
+		return translateProductError(err)
+	}
+}
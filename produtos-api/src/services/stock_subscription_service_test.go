@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/customers"
+	"produtos-api/src/database"
+	"produtos-api/src/events"
+	"produtos-api/src/i18n"
+	"produtos-api/src/mail"
+	"produtos-api/src/models"
+	"produtos-api/src/ratelimit"
+	"produtos-api/src/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockStockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockSubscriptionRepository) CreateStockSubscription(ctx context.Context, subscription *models.StockSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionRepository) MarkStockSubscriptionsDue(ctx context.Context, productID uint, at time.Time) (int64, error) {
+	args := m.Called(ctx, productID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStockSubscriptionRepository) GetDueStockSubscriptions(ctx context.Context, now time.Time, limit int) ([]models.StockSubscription, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]models.StockSubscription), args.Error(1)
+}
+
+func (m *MockStockSubscriptionRepository) SetStockSubscriptionNotifyAt(ctx context.Context, id uint, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionRepository) DeleteStockSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionRepository) DeleteStockSubscriptionByToken(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionRepository) SaveRecipientToken(ctx context.Context, recipient *models.StockSubscriptionRecipient) error {
+	args := m.Called(ctx, recipient)
+	return args.Error(0)
+}
+
+func (m *MockStockSubscriptionRepository) DeleteRecipientSubscriptions(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+type MockCustomerClient struct {
+	mock.Mock
+}
+
+func (m *MockCustomerClient) GetCustomer(ctx context.Context, id uint) (*models.Customer, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) Notify(ctx context.Context, notification Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) CountQueued(ctx context.Context, template, to string, since time.Time) (int, error) {
+	args := m.Called(ctx, template, to, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationService) HandleEvent(ctx context.Context, msg broker.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) Deliver(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+type stockSubscriptionTest struct {
+	service       *StockSubscriptionServiceRepo
+	repo          *MockStockSubscriptionRepository
+	products      *MockProductRepository
+	translations  *MockProductTranslationRepository
+	customers     *MockCustomerClient
+	notifications *MockNotificationService
+}
+
+var backInStockLimit = ratelimit.Limit{Requests: 5, Period: time.Hour}
+
+func newStockSubscriptionTest() *stockSubscriptionTest {
+	test := &stockSubscriptionTest{
+		repo:          new(MockStockSubscriptionRepository),
+		products:      new(MockProductRepository),
+		translations:  new(MockProductTranslationRepository),
+		customers:     new(MockCustomerClient),
+		notifications: new(MockNotificationService),
+	}
+	test.service = NewStockSubscriptionService(test.repo, test.products, test.translations, passthroughTransactor{},
+		test.customers, test.notifications, backInStockLimit, "http://localhost:8080/stock-subscriptions/{token}")
+	test.service.now = func() time.Time { return notificationTestNow }
+
+	return test
+}
+
+func viewerContext(subject string) context.Context {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: subject, Roles: []auth.Role{auth.RoleViewer}})
+	return i18n.WithContentLocales(ctx, i18n.ContentCandidates("pt-BR,pt;q=0.9"))
+}
+
+func stockChangedMessage(t *testing.T, change events.StockChange) broker.Message {
+	value, err := json.Marshal(change)
+	require.NoError(t, err)
+
+	return broker.Message{ID: "e1", Type: events.StockChanged, Value: value}
+}
+
+func TestServiceSubscribeEmail(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Caneta", Stock: 0}, nil)
+
+	var created *models.StockSubscription
+	test.repo.On("CreateStockSubscription", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.StockSubscription)
+		created.ID = 7
+	}).Return(nil)
+
+	issued, err := test.service.Subscribe(viewerContext("maria"), 1, &models.StockSubscriptionRequest{Email: " maria@example.com "})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), issued.ID)
+	assert.Equal(t, "maria@example.com", created.Email)
+	assert.Equal(t, "pt-BR", created.Locale)
+	assert.Equal(t, "maria", created.CreatedBy)
+	assert.True(t, strings.HasPrefix(issued.UnsubscribeToken, "unsub_"))
+	assert.Equal(t, hashUnsubscribeToken(issued.UnsubscribeToken), created.TokenHash)
+	test.customers.AssertNotCalled(t, "GetCustomer", mock.Anything, mock.Anything)
+}
+
+func TestServiceSubscribeValidation(t *testing.T) {
+	test := newStockSubscriptionTest()
+
+	cases := map[string]struct {
+		request models.StockSubscriptionRequest
+		field   string
+		code    string
+	}{
+		"no recipient":   {models.StockSubscriptionRequest{}, "email", "required"},
+		"two recipients": {models.StockSubscriptionRequest{Email: "maria@example.com", CustomerID: 3}, "customer_id", "excluded_with"},
+		"invalid email":  {models.StockSubscriptionRequest{Email: "maria"}, "email", "email"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := test.service.Subscribe(viewerContext("maria"), 1, &tc.request)
+
+			domainErr, ok := AsDomainError(err)
+			require.True(t, ok)
+			assert.Equal(t, KindValidation, domainErr.Kind)
+			require.Len(t, domainErr.Fields, 1)
+			assert.Equal(t, tc.field, domainErr.Fields[0].Field)
+			assert.Equal(t, tc.code, domainErr.Fields[0].Code)
+		})
+	}
+	test.products.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+}
+
+func TestServiceSubscribeProductInStock(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Stock: 3}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(2)).Return((*models.Product)(nil), repositories.ErrNotFound)
+
+	_, err := test.service.Subscribe(viewerContext("maria"), 1, &models.StockSubscriptionRequest{Email: "maria@example.com"})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeProductInStock, domainErr.Code)
+
+	_, err = test.service.Subscribe(viewerContext("maria"), 2, &models.StockSubscriptionRequest{Email: "maria@example.com"})
+	domainErr, ok = AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeProductNotFound, domainErr.Code)
+	test.repo.AssertNotCalled(t, "CreateStockSubscription", mock.Anything, mock.Anything)
+}
+
+func TestServiceSubscribeCustomer(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1}, nil)
+	test.customers.On("GetCustomer", mock.Anything, uint(3)).Return(&models.Customer{ID: 3, Subject: "maria", Email: "maria@example.com"}, nil)
+	test.customers.On("GetCustomer", mock.Anything, uint(4)).Return((*models.Customer)(nil), customers.ErrNotFound)
+	test.customers.On("GetCustomer", mock.Anything, uint(5)).Return((*models.Customer)(nil), errors.New("connection refused"))
+	test.repo.On("CreateStockSubscription", mock.Anything, mock.Anything).Return(nil).Once()
+	test.repo.On("CreateStockSubscription", mock.Anything, mock.Anything).Return(repositories.ErrDuplicated)
+
+	issued, err := test.service.Subscribe(viewerContext("maria"), 1, &models.StockSubscriptionRequest{CustomerID: 3})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), issued.CustomerID)
+	assert.Empty(t, issued.Email)
+
+	_, err = test.service.Subscribe(viewerContext("maria"), 1, &models.StockSubscriptionRequest{CustomerID: 3})
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeStockSubscriptionConflict, domainErr.Code)
+
+	// Clientes de outros titulares são tratados como inexistentes
+	for _, id := range []uint{3, 4} {
+		_, err = test.service.Subscribe(viewerContext("joao"), 1, &models.StockSubscriptionRequest{CustomerID: id})
+		domainErr, ok = AsDomainError(err)
+		require.True(t, ok)
+		require.Len(t, domainErr.Fields, 1)
+		assert.Equal(t, "exists", domainErr.Fields[0].Code)
+	}
+
+	_, err = test.service.Subscribe(viewerContext("maria"), 1, &models.StockSubscriptionRequest{CustomerID: 5})
+	domainErr, ok = AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeCustomersUnavailable, domainErr.Code)
+	test.repo.AssertNumberOfCalls(t, "CreateStockSubscription", 2)
+}
+
+func TestServiceUnsubscribe(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.repo.On("DeleteStockSubscriptionByToken", mock.Anything, hashUnsubscribeToken("unsub_valid")).Return(nil)
+	test.repo.On("DeleteStockSubscriptionByToken", mock.Anything, mock.Anything).Return(repositories.ErrNotFound)
+	test.repo.On("DeleteRecipientSubscriptions", mock.Anything, hashUnsubscribeToken("unsub_emailed")).Return(nil)
+	test.repo.On("DeleteRecipientSubscriptions", mock.Anything, hashUnsubscribeToken("unsub_used")).Return(repositories.ErrNotFound)
+
+	assert.NoError(t, test.service.Unsubscribe(context.Background(), "unsub_valid"))
+	// O token do link do aviso enviado cancela os avisos do destinatário
+	assert.NoError(t, test.service.Unsubscribe(context.Background(), "unsub_emailed"))
+
+	for _, token := range []string{"unsub_used", "whsec_other"} {
+		err := test.service.Unsubscribe(context.Background(), token)
+		domainErr, ok := AsDomainError(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeStockSubscriptionNotFound, domainErr.Code)
+	}
+	test.repo.AssertNumberOfCalls(t, "DeleteStockSubscriptionByToken", 3)
+}
+
+func TestServiceHandleStockReplenished(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.repo.On("MarkStockSubscriptionsDue", mock.Anything, uint(1), notificationTestNow).Return(int64(3), nil)
+
+	err := test.service.HandleEvent(context.Background(), stockChangedMessage(t, events.StockChange{ProductID: 1, Previous: 0, Current: 10}))
+
+	// O Fanout da outbox só marca as inscrições: os clientes e os e-mails ficam para o worker
+	require.NoError(t, err)
+	test.repo.AssertExpectations(t)
+	test.customers.AssertNotCalled(t, "GetCustomer", mock.Anything, mock.Anything)
+	test.notifications.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestServiceHandleStockChangeIgnored(t *testing.T) {
+	test := newStockSubscriptionTest()
+
+	for _, msg := range []broker.Message{
+		stockChangedMessage(t, events.StockChange{ProductID: 1, Previous: 2, Current: 5}),
+		stockChangedMessage(t, events.StockChange{ProductID: 1, Previous: 2, Current: 0}),
+		{ID: "e2", Type: events.ProductUpdated, Value: []byte(`{}`)},
+		{ID: "e3", Type: events.StockChanged, Value: []byte(`{`)},
+	} {
+		assert.NoError(t, test.service.HandleEvent(context.Background(), msg))
+	}
+	test.repo.AssertNotCalled(t, "MarkStockSubscriptionsDue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceNotifyDue(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.repo.On("GetDueStockSubscriptions", mock.Anything, notificationTestNow, stockAlertBatchSize).Return([]models.StockSubscription{
+		{ID: 7, ProductID: 1, Email: "Ana@example.com", Locale: "en"},
+		{ID: 8, ProductID: 1, CustomerID: 3, Locale: "pt-BR"},
+		{ID: 9, ProductID: 1, CustomerID: 4, Locale: "pt-BR"},
+		{ID: 10, ProductID: 1, CustomerID: 5, Locale: "pt-BR"},
+		{ID: 11, ProductID: 1, Email: "joao@example.com", Locale: "en"},
+		{ID: 12, ProductID: 2, Email: "ana@example.com", Locale: "en"},
+	}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Pen", Price: 12.5, Stock: 10}, nil).Once()
+	test.products.On("GetProductByID", mock.Anything, uint(2)).Return(&models.Product{ID: 2, Name: "Pencil", Stock: 0}, nil).Once()
+	test.translations.On("GetTranslation", mock.Anything, uint(1), "en").Return((*models.ProductTranslation)(nil), repositories.ErrNotFound)
+	test.translations.On("GetTranslation", mock.Anything, uint(1), "pt-BR").Return(&models.ProductTranslation{Name: "Caneta"}, nil)
+	test.customers.On("GetCustomer", mock.Anything, uint(3)).Return(&models.Customer{ID: 3, Email: "Maria@example.com"}, nil)
+	test.customers.On("GetCustomer", mock.Anything, uint(4)).Return((*models.Customer)(nil), customers.ErrNotFound)
+	test.customers.On("GetCustomer", mock.Anything, uint(5)).Return((*models.Customer)(nil), errors.New("unexpected status 401"))
+	test.notifications.On("CountQueued", mock.Anything, mail.TemplateBackInStock, mock.Anything, notificationTestNow.Add(-time.Hour)).Return(4, nil)
+	var recipients []*models.StockSubscriptionRecipient
+	test.repo.On("SaveRecipientToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recipients = append(recipients, args.Get(1).(*models.StockSubscriptionRecipient))
+	}).Return(nil)
+	var notifications []Notification
+	test.notifications.On("Notify", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		notifications = append(notifications, args.Get(1).(Notification))
+	}).Return(nil)
+	test.repo.On("DeleteStockSubscription", mock.Anything, mock.Anything).Return(nil)
+	test.repo.On("SetStockSubscriptionNotifyAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	queued, err := test.service.NotifyDue(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, queued)
+	require.Len(t, notifications, 3)
+	assert.Equal(t, "back_in_stock:7", notifications[0].Key)
+	assert.Equal(t, "Ana@example.com", notifications[0].To)
+	assert.Equal(t, "Pen", notifications[0].Data.(mail.BackInStock).ProductName)
+	assert.Equal(t, "back_in_stock:8", notifications[1].Key)
+	assert.Equal(t, "pt-BR", notifications[1].Locale)
+	assert.Equal(t, "Maria@example.com", notifications[1].To)
+	assert.Equal(t, mail.BackInStock{ProductID: 1, ProductName: "Caneta", Price: 12.5,
+		UnsubscribeURL: notifications[1].Data.(mail.BackInStock).UnsubscribeURL}, notifications[1].Data)
+	assert.Equal(t, "back_in_stock:11", notifications[2].Key)
+	test.notifications.AssertCalled(t, "CountQueued", mock.Anything, mail.TemplateBackInStock, "Maria@example.com", notificationTestNow.Add(-time.Hour))
+
+	// Cada aviso leva no link o token do destinatário, guardado como hash
+	require.Len(t, recipients, 3)
+	assert.Equal(t, "ana@example.com", recipients[0].Email)
+	assert.Equal(t, uint(3), recipients[1].CustomerID)
+	for i, notification := range notifications {
+		url := notification.Data.(mail.BackInStock).UnsubscribeURL
+		token := strings.TrimPrefix(url, "http://localhost:8080/stock-subscriptions/")
+		require.True(t, strings.HasPrefix(token, unsubscribeTokenTag), url)
+		assert.Equal(t, hashUnsubscribeToken(token), recipients[i].TokenHash)
+	}
+
+	// A inscrição do cliente excluído é removida sem aviso; a do cliente que não foi buscado é tentada de novo
+	// depois, e a do produto que voltou a ficar sem estoque aguarda a próxima reposição
+	for _, id := range []uint{7, 8, 9, 11} {
+		test.repo.AssertCalled(t, "DeleteStockSubscription", mock.Anything, id)
+	}
+	retryAt := notificationTestNow.Add(stockAlertRetryDelay)
+	test.repo.AssertCalled(t, "SetStockSubscriptionNotifyAt", mock.Anything, uint(10), &retryAt)
+	test.repo.AssertCalled(t, "SetStockSubscriptionNotifyAt", mock.Anything, uint(12), (*time.Time)(nil))
+	test.repo.AssertNotCalled(t, "DeleteStockSubscription", mock.Anything, uint(10))
+	test.repo.AssertNotCalled(t, "DeleteStockSubscription", mock.Anything, uint(12))
+	test.products.AssertExpectations(t)
+}
+
+func TestServiceNotifyDueRateLimited(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.repo.On("GetDueStockSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]models.StockSubscription{
+		{ID: 7, ProductID: 1, Email: "ana@example.com", Locale: "en"},
+	}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Pen", Stock: 1}, nil)
+	test.translations.On("GetTranslation", mock.Anything, uint(1), "en").Return((*models.ProductTranslation)(nil), repositories.ErrNotFound)
+	test.notifications.On("CountQueued", mock.Anything, mail.TemplateBackInStock, "ana@example.com", mock.Anything).Return(5, nil)
+	test.repo.On("SetStockSubscriptionNotifyAt", mock.Anything, uint(7), (*time.Time)(nil)).Return(nil)
+
+	queued, err := test.service.NotifyDue(context.Background())
+
+	// A inscrição volta a aguardar a próxima reposição
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+	test.notifications.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	test.repo.AssertNotCalled(t, "DeleteStockSubscription", mock.Anything, mock.Anything)
+	test.repo.AssertExpectations(t)
+}
+
+func TestServiceNotifyDueWhenQueueFails(t *testing.T) {
+	test := newStockSubscriptionTest()
+	test.repo.On("GetDueStockSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]models.StockSubscription{
+		{ID: 7, ProductID: 1, Email: "ana@example.com", Locale: "en"},
+	}, nil)
+	test.products.On("GetProductByID", mock.Anything, uint(1)).Return(&models.Product{ID: 1, Name: "Pen", Stock: 1}, nil)
+	test.translations.On("GetTranslation", mock.Anything, uint(1), "en").Return((*models.ProductTranslation)(nil), repositories.ErrNotFound)
+	test.notifications.On("CountQueued", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	test.repo.On("SaveRecipientToken", mock.Anything, mock.Anything).Return(nil)
+	test.notifications.On("Notify", mock.Anything, mock.Anything).Return(
+		UnavailableError(CodeDatabaseUnavailable, "Mail queue is unavailable", errors.New("disk I/O error")))
+
+	_, err := test.service.NotifyDue(context.Background())
+
+	// A inscrição continua marcada e é tentada de novo na próxima execução do worker
+	domainErr, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, KindUnavailable, domainErr.Kind)
+	test.repo.AssertNotCalled(t, "DeleteStockSubscription", mock.Anything, mock.Anything)
+	test.repo.AssertNotCalled(t, "SetStockSubscriptionNotifyAt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceNotifyDueLimitSurvivesFailedTransactions(t *testing.T) {
+	db, err := database.SetupTestDatabase()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Cada conexão com :memory: abre um banco vazio
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(database.Products.Models...))
+
+	products := repositories.NewProductRepository(db)
+	for _, product := range []*models.Product{{Name: "Caneta", Category: "Papelaria", Price: 2.5}, {Name: "Lápis", Category: "Papelaria", Price: 1}} {
+		require.NoError(t, db.Create(product).Error)
+	}
+	repo := repositories.NewStockSubscriptionRepository(db)
+	for i, productID := range []uint{1, 2} {
+		require.NoError(t, repo.CreateStockSubscription(context.Background(), &models.StockSubscription{
+			ProductID: productID, Email: "ana@example.com", Locale: "pt-BR", TokenHash: strconv.Itoa(i)}))
+	}
+
+	// A fila falha na primeira tentativa: sem o aviso na fila, o limite de 1 por hora não é consumido
+	mailRepo := &failingMailRepository{MailRepositoryDB: repositories.NewMailRepository(db), failures: 1}
+	notifications := NewNotificationService(mailRepo, mail.NewMemoryTransport(), NotificationConfig{MaxAttempts: 3})
+	service := NewStockSubscriptionService(repo, products, repositories.NewProductTranslationRepository(db), repositories.NewTransactor(db),
+		new(MockCustomerClient), notifications, ratelimit.Limit{Requests: 1, Period: time.Hour}, "http://localhost:8080/stock-subscriptions/{token}")
+
+	require.NoError(t, db.Model(&models.Product{}).Where("id = 1").Update("stock", 3).Error)
+	require.NoError(t, service.HandleEvent(context.Background(), stockChangedMessage(t, events.StockChange{ProductID: 1, Previous: 0, Current: 3})))
+
+	_, err = service.NotifyDue(context.Background())
+	require.Error(t, err)
+	queued, err := service.NotifyDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	// O segundo aviso do mesmo endereço na hora fica para a próxima reposição do produto
+	require.NoError(t, db.Model(&models.Product{}).Where("id = 2").Update("stock", 1).Error)
+	require.NoError(t, service.HandleEvent(context.Background(), stockChangedMessage(t, events.StockChange{ProductID: 2, Previous: 0, Current: 1})))
+	queued, err = service.NotifyDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+
+	var remaining []models.StockSubscription
+	require.NoError(t, db.Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, uint(2), remaining[0].ProductID)
+	assert.Nil(t, remaining[0].NotifyAt)
+}
+
+// failingMailRepository falha as primeiras chamadas de CreateMailMessage
+type failingMailRepository struct {
+	*repositories.MailRepositoryDB
+	failures int
+}
+
+func (r *failingMailRepository) CreateMailMessage(ctx context.Context, message *models.MailMessage) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("disk I/O error")
+	}
+	return r.MailRepositoryDB.CreateMailMessage(ctx, message)
+}
@@ -0,0 +1,519 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/logging"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/validation"
+	"produtos-api/src/webhooks"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Códigos dos erros de webhooks
+const (
+	CodeWebhookNotFound           = "webhook_not_found"
+	CodeDeliveryNotFound          = "webhook_delivery_not_found"
+	CodeWebhookDisabled           = "webhook_disabled"
+	CodeWebhookConflict           = "webhook_conflict"
+	CodeWebhookStorageUnavailable = "webhook_storage_unavailable"
+)
+
+// Limites da consulta de entregas, lote de envio e trecho da resposta guardado nas falhas
+const (
+	DefaultDeliveryLimit = 100
+	MaxDeliveryLimit     = 1000
+	webhookBatchSize     = 100
+	webhookRetryBase     = 5 * time.Second
+	webhookRetryMax      = time.Hour
+	webhookResponseLimit = 512
+)
+
+var webhookLogger = logging.Logger("webhooks")
+
+// WebhookConfig define o envio das entregas: prazo de cada requisição, tentativas por entrega, falhas
+// seguidas que desativam o webhook e por quanto tempo as entregas concluídas ficam no registro
+type WebhookConfig struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	DisableAfter int
+	Retention    time.Duration
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.IssuedWebhook, error)
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id uint) error
+	RotateWebhookSecret(ctx context.Context, id uint) (*models.IssuedWebhook, error)
+	GetWebhookDeliveries(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID uint) (*models.WebhookDelivery, error)
+}
+
+type WebhookServiceRepo struct {
+	repo   repositories.WebhookRepository
+	client *http.Client
+	config WebhookConfig
+	now    func() time.Time
+}
+
+// NewWebhookService cria o serviço de webhooks, que cadastra as assinaturas, enfileira os eventos
+// publicados pela outbox e os entrega
+func NewWebhookService(repo repositories.WebhookRepository, config WebhookConfig) *WebhookServiceRepo {
+	return &WebhookServiceRepo{repo: repo, client: &http.Client{Timeout: config.Timeout}, config: config, now: time.Now}
+}
+
+// webhookEnvelope é o corpo das entregas: os metadados do evento e o payload em data
+type webhookEnvelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// CreateWebhook valida e cadastra o webhook, já ativo; o segredo só é retornado nesta chamada
+func (s *WebhookServiceRepo) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.IssuedWebhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.ID = 0
+	webhook.Secret = secret
+	webhook.Active = true
+	webhook.ConsecutiveFailures, webhook.DisabledAt, webhook.DisabledReason = 0, nil, ""
+	webhook.CreatedBy = ""
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		webhook.CreatedBy = principal.Subject
+	}
+
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	return &models.IssuedWebhook{Webhook: *webhook, Secret: secret}, nil
+}
+
+func (s *WebhookServiceRepo) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetWebhooks")
+	defer span.End()
+
+	webhooks, err := s.repo.GetWebhooks(ctx)
+	return webhooks, translateWebhookError(err)
+}
+
+func (s *WebhookServiceRepo) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetWebhook")
+	defer span.End()
+
+	webhook, err := s.repo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook substitui URL, tipos de evento e estado. Reativar um webhook desativado por falhas zera
+// a contagem de falhas, e as entregas pendentes voltam a ser enviadas.
+func (s *WebhookServiceRepo) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.UpdateWebhook")
+	defer span.End()
+
+	current, err := s.repo.GetWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		return translateWebhookError(err)
+	}
+
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+
+	current.URL, current.EventTypes = webhook.URL, webhook.EventTypes
+	if webhook.Active && !current.Active {
+		current.ConsecutiveFailures, current.DisabledAt, current.DisabledReason = 0, nil, ""
+	}
+	current.Active = webhook.Active
+
+	if err := s.repo.SaveWebhook(ctx, current); err != nil {
+		return translateWebhookError(err)
+	}
+
+	*webhook = *current
+	return nil
+}
+
+// DeleteWebhook remove o webhook e o seu registro de entregas
+func (s *WebhookServiceRepo) DeleteWebhook(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	return translateWebhookError(s.repo.DeleteWebhook(ctx, id))
+}
+
+// RotateWebhookSecret gera um novo segredo; as próximas entregas já são assinadas com ele
+func (s *WebhookServiceRepo) RotateWebhookSecret(ctx context.Context, id uint) (*models.IssuedWebhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.RotateWebhookSecret")
+	defer span.End()
+
+	webhook, err := s.repo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	if err := s.repo.SaveWebhook(ctx, webhook); err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	return &models.IssuedWebhook{Webhook: *webhook, Secret: secret}, nil
+}
+
+// GetWebhookDeliveries consulta o registro de entregas do webhook, da mais recente para a mais antiga
+func (s *WebhookServiceRepo) GetWebhookDeliveries(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetWebhookDeliveries")
+	defer span.End()
+
+	var fields []FieldError
+	if filter.Limit == 0 {
+		filter.Limit = DefaultDeliveryLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxDeliveryLimit {
+		fields = append(fields, fieldError("limit", "invalid", ""))
+	}
+	switch filter.Status {
+	case "", models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed:
+	default:
+		fields = append(fields, fieldError("status", "invalid", ""))
+	}
+	if len(fields) > 0 {
+		return nil, ValidationError(fields...)
+	}
+
+	if _, err := s.repo.GetWebhookByID(ctx, filter.WebhookID); err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	deliveries, err := s.repo.FindWebhookDeliveries(ctx, filter)
+	return deliveries, translateWebhookError(err)
+}
+
+// ReplayWebhookDelivery coloca a entrega de novo na fila, com as tentativas zeradas. A entrega mantém o
+// ID do evento, para que o receptor possa descartá-la se já a tiver processado.
+func (s *WebhookServiceRepo) ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ReplayWebhookDelivery")
+	defer span.End()
+
+	webhook, err := s.repo.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		return nil, translateWebhookError(err)
+	}
+	if !webhook.Active {
+		return nil, ConflictError(CodeWebhookDisabled, "Webhook is disabled", nil)
+	}
+
+	delivery, err := s.repo.GetWebhookDelivery(ctx, webhookID, deliveryID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, NotFoundError(CodeDeliveryNotFound, "Webhook delivery not found", err)
+	}
+	if err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	now := s.now().UTC()
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.ReplayedAt = &now
+
+	if err := s.repo.SaveWebhookDelivery(ctx, delivery); err != nil {
+		return nil, translateWebhookError(err)
+	}
+
+	return delivery, nil
+}
+
+// Enqueue cria uma entrega da mensagem para cada webhook ativo que assina o seu tipo. Implementa o lado
+// dos webhooks da publicação da outbox: um evento já enfileirado para um webhook não é duplicado.
+func (s *WebhookServiceRepo) Enqueue(ctx context.Context, msg broker.Message) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.Enqueue")
+	defer span.End()
+	span.SetAttributes(attribute.String("event.id", msg.ID), attribute.String("event.type", msg.Type))
+
+	active, err := s.repo.GetActiveWebhooks(ctx)
+	if err != nil {
+		return translateWebhookError(err)
+	}
+
+	var payload []byte
+	now := s.now().UTC()
+	for i := range active {
+		webhook := &active[i]
+		if !webhook.Accepts(msg.Type) {
+			continue
+		}
+
+		if payload == nil {
+			envelope := webhookEnvelope{ID: msg.ID, Type: msg.Type, SchemaVersion: msg.SchemaVersion, OccurredAt: msg.Timestamp, Data: msg.Value}
+			if payload, err = json.Marshal(envelope); err != nil {
+				return err
+			}
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       msg.ID,
+			EventType:     msg.Type,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+		}
+		if err := s.repo.CreateWebhookDelivery(ctx, delivery); err != nil && !errors.Is(err, repositories.ErrDuplicated) {
+			return translateWebhookError(err)
+		}
+	}
+
+	return nil
+}
+
+// Deliver envia as entregas pendentes e retorna quantas foram aceitas. As entregas de cada webhook seguem
+// a ordem dos eventos: uma entrega que falha é reagendada com backoff e bloqueia as seguintes do mesmo
+// webhook até ser aceita ou esgotar as tentativas. Após DisableAfter falhas seguidas o webhook é desativado.
+// Falhas dos receptores são registradas nas entregas; o erro retornado indica falhas do próprio registro.
+func (s *WebhookServiceRepo) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Deliver")
+	defer span.End()
+
+	now := s.now().UTC()
+	delivered := 0
+	defer func() { span.SetAttributes(attribute.Int("webhooks.delivered", delivered)) }()
+
+	webhooks := map[uint]*models.Webhook{}
+	blocked := map[uint]bool{}
+	var afterID uint
+	for {
+		deliveries, err := s.repo.GetPendingWebhookDeliveries(ctx, afterID, webhookBatchSize)
+		if err != nil {
+			return delivered, translateWebhookError(err)
+		}
+
+		for i := range deliveries {
+			delivery := &deliveries[i]
+			if blocked[delivery.WebhookID] {
+				continue
+			}
+			if delivery.NextAttemptAt.After(now) {
+				blocked[delivery.WebhookID] = true
+				continue
+			}
+
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				if webhook, err = s.repo.GetWebhookByID(ctx, delivery.WebhookID); err != nil {
+					return delivered, translateWebhookError(err)
+				}
+				webhooks[delivery.WebhookID] = webhook
+			}
+			// Entregas de webhooks desativados aguardam a reativação
+			if !webhook.Active {
+				blocked[delivery.WebhookID] = true
+				continue
+			}
+
+			status, err := s.send(ctx, webhook, delivery, now)
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			previousAttempts := delivery.Attempts
+			before := *webhook
+			accepted := s.record(ctx, webhook, delivery, status, err, now)
+			if !accepted {
+				blocked[delivery.WebhookID] = true
+			}
+
+			// Apenas as colunas do resultado são gravadas, e só se a entrega e o webhook ainda existirem: um
+			// reenvio ou uma remoção durante o envio prevalece sobre o resultado desta tentativa
+			err = s.repo.UpdateWebhookDeliveryAttempt(ctx, delivery, previousAttempts)
+			if errors.Is(err, repositories.ErrNotFound) {
+				webhookLogger.InfoContext(ctx, "webhook delivery changed during the attempt, result discarded",
+					"webhook_id", webhook.ID, "delivery_id", delivery.ID)
+				continue
+			}
+			if err != nil {
+				return delivered, translateWebhookError(err)
+			}
+			if accepted {
+				delivered++
+			}
+
+			if webhookHealthChanged(&before, webhook) {
+				err := s.repo.UpdateWebhookHealth(ctx, webhook)
+				if errors.Is(err, repositories.ErrNotFound) {
+					blocked[delivery.WebhookID] = true
+					continue
+				}
+				if err != nil {
+					return delivered, translateWebhookError(err)
+				}
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			break
+		}
+		afterID = deliveries[len(deliveries)-1].ID
+	}
+
+	if s.config.Retention > 0 {
+		if _, err := s.repo.DeleteWebhookDeliveries(ctx, now.Add(-s.config.Retention)); err != nil {
+			return delivered, translateWebhookError(err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// send faz a requisição assinada da entrega e retorna o status HTTP da resposta
+func (s *WebhookServiceRepo) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.send")
+	defer span.End()
+	span.SetAttributes(attribute.Int("webhook.id", int(webhook.ID)), attribute.Int("webhook.delivery.id", int(delivery.ID)))
+
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "produtos-api-webhooks")
+	request.Header.Set(webhooks.HeaderEventID, delivery.EventID)
+	request.Header.Set(webhooks.HeaderEventType, delivery.EventType)
+	request.Header.Set(webhooks.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(webhooks.HeaderSignature, webhooks.Sign(webhook.Secret, now, body))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(response.Body, webhookResponseLimit))
+		if snippet = bytes.TrimSpace(snippet); len(snippet) == 0 {
+			return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+		}
+		return response.StatusCode, fmt.Errorf("unexpected status %d: %s", response.StatusCode, snippet)
+	}
+	io.Copy(io.Discard, io.LimitReader(response.Body, webhookResponseLimit))
+
+	return response.StatusCode, nil
+}
+
+// record registra o resultado da tentativa na entrega e no webhook; retorna se a entrega foi aceita
+func (s *WebhookServiceRepo) record(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, status int, err error, now time.Time) bool {
+	attemptedAt := now
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &attemptedAt
+		delivery.LastError = ""
+		webhook.ConsecutiveFailures = 0
+		return true
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= s.config.MaxAttempts {
+		delivery.Status = models.DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = now.Add(retryBackoff(delivery.Attempts, webhookRetryBase, webhookRetryMax))
+	}
+	webhookLogger.WarnContext(ctx, "webhook delivery failed", "webhook_id", webhook.ID, "delivery_id", delivery.ID,
+		"event_id", delivery.EventID, "attempts", delivery.Attempts, "status", delivery.Status, "error", err)
+
+	webhook.ConsecutiveFailures++
+	if s.config.DisableAfter > 0 && webhook.ConsecutiveFailures >= s.config.DisableAfter {
+		disabledAt := now
+		webhook.Active = false
+		webhook.DisabledAt = &disabledAt
+		webhook.DisabledReason = fmt.Sprintf("%d consecutive failed deliveries, last: %v", webhook.ConsecutiveFailures, err)
+		webhookLogger.WarnContext(ctx, "webhook disabled", "webhook_id", webhook.ID, "failures", webhook.ConsecutiveFailures)
+	}
+
+	return false
+}
+
+// webhookHealthChanged indica se a tentativa alterou a contagem de falhas ou desativou o webhook
+func webhookHealthChanged(before, after *models.Webhook) bool {
+	return before.ConsecutiveFailures != after.ConsecutiveFailures || before.Active != after.Active ||
+		before.DisabledReason != after.DisabledReason
+}
+
+// validateWebhook aplica as regras declaradas no modelo e verifica os tipos de evento assinados
+func validateWebhook(webhook *models.Webhook) error {
+	var fields []FieldError
+	for _, fe := range validation.Struct(webhook) {
+		fields = append(fields, FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: fe.Message})
+	}
+
+	for _, eventType := range webhook.EventTypes {
+		if !slices.Contains(events.Published, eventType) {
+			fields = append(fields, fieldError("event_types", "event_type", eventType))
+		}
+	}
+
+	if len(fields) > 0 {
+		return ValidationError(fields...)
+	}
+
+	return nil
+}
+
+func translateWebhookError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, repositories.ErrNotFound):
+		return NotFoundError(CodeWebhookNotFound, "Webhook not found", err)
+	case errors.Is(err, repositories.ErrDuplicated):
+		return ConflictError(CodeWebhookConflict, "Webhook conflicts with an existing webhook or delivery", err)
+	default:
+		return UnavailableError(CodeWebhookStorageUnavailable, "Webhook storage is unavailable", err)
+	}
+}
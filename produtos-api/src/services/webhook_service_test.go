@@ -0,0 +1,588 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"produtos-api/src/auth"
+	"produtos-api/src/broker"
+	"produtos-api/src/events"
+	"produtos-api/src/models"
+	"produtos-api/src/repositories"
+	"produtos-api/src/webhooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhookRepository guarda webhooks e entregas em memória, o que permite exercitar o envio
+// com várias rodadas sem reproduzir cada chamada num mock
+type memoryWebhookRepository struct {
+	webhooks   map[uint]*models.Webhook
+	deliveries map[uint]*models.WebhookDelivery
+	nextID     uint
+	// healthUpdates conta as gravações do estado dos webhooks
+	healthUpdates int
+}
+
+func newMemoryWebhookRepository() *memoryWebhookRepository {
+	return &memoryWebhookRepository{webhooks: map[uint]*models.Webhook{}, deliveries: map[uint]*models.WebhookDelivery{}}
+}
+
+func (m *memoryWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.nextID++
+	webhook.ID = m.nextID
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *memoryWebhookRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var list []models.Webhook
+	for _, id := range sortedKeys(m.webhooks) {
+		list = append(list, *m.webhooks[id])
+	}
+	return list, nil
+}
+
+func (m *memoryWebhookRepository) GetActiveWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var list []models.Webhook
+	for _, id := range sortedKeys(m.webhooks) {
+		if m.webhooks[id].Active {
+			list = append(list, *m.webhooks[id])
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryWebhookRepository) GetWebhookByID(ctx context.Context, id uint) (*models.Webhook, error) {
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (m *memoryWebhookRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *memoryWebhookRepository) UpdateWebhookHealth(ctx context.Context, webhook *models.Webhook) error {
+	m.healthUpdates++
+	stored, ok := m.webhooks[webhook.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	stored.ConsecutiveFailures, stored.Active = webhook.ConsecutiveFailures, webhook.Active
+	stored.DisabledAt, stored.DisabledReason = webhook.DisabledAt, webhook.DisabledReason
+	return nil
+}
+
+func (m *memoryWebhookRepository) DeleteWebhook(ctx context.Context, id uint) error {
+	if _, ok := m.webhooks[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(m.webhooks, id)
+	for deliveryID, delivery := range m.deliveries {
+		if delivery.WebhookID == id {
+			delete(m.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (m *memoryWebhookRepository) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	for _, existing := range m.deliveries {
+		if existing.WebhookID == delivery.WebhookID && existing.EventID == delivery.EventID {
+			return repositories.ErrDuplicated
+		}
+	}
+	m.nextID++
+	delivery.ID = m.nextID
+	stored := *delivery
+	m.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (m *memoryWebhookRepository) GetWebhookDelivery(ctx context.Context, webhookID, id uint) (*models.WebhookDelivery, error) {
+	delivery, ok := m.deliveries[id]
+	if !ok || delivery.WebhookID != webhookID {
+		return nil, repositories.ErrNotFound
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (m *memoryWebhookRepository) FindWebhookDeliveries(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	ids := sortedKeys(m.deliveries)
+	var list []models.WebhookDelivery
+	for i := len(ids) - 1; i >= 0 && len(list) < filter.Limit; i-- {
+		delivery := m.deliveries[ids[i]]
+		if delivery.WebhookID == filter.WebhookID && (filter.Status == "" || delivery.Status == filter.Status) &&
+			(filter.EventType == "" || delivery.EventType == filter.EventType) && (filter.BeforeID == 0 || delivery.ID < filter.BeforeID) {
+			list = append(list, *delivery)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryWebhookRepository) GetPendingWebhookDeliveries(ctx context.Context, afterID uint, limit int) ([]models.WebhookDelivery, error) {
+	var list []models.WebhookDelivery
+	for _, id := range sortedKeys(m.deliveries) {
+		if delivery := m.deliveries[id]; id > afterID && delivery.Status == models.DeliveryPending && len(list) < limit {
+			list = append(list, *delivery)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryWebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	stored := *delivery
+	m.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (m *memoryWebhookRepository) UpdateWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery, previousAttempts int) error {
+	stored, ok := m.deliveries[delivery.ID]
+	if !ok || stored.Status != models.DeliveryPending || stored.Attempts != previousAttempts {
+		return repositories.ErrNotFound
+	}
+	stored.Status, stored.Attempts, stored.NextAttemptAt = delivery.Status, delivery.Attempts, delivery.NextAttemptAt
+	stored.LastAttemptAt, stored.ResponseStatus, stored.LastError = delivery.LastAttemptAt, delivery.ResponseStatus, delivery.LastError
+	stored.DeliveredAt = delivery.DeliveredAt
+	return nil
+}
+
+func (m *memoryWebhookRepository) DeleteWebhookDeliveries(ctx context.Context, attemptedBefore time.Time) (int64, error) {
+	var deleted int64
+	for id, delivery := range m.deliveries {
+		if delivery.Status != models.DeliveryPending && delivery.LastAttemptAt != nil && delivery.LastAttemptAt.Before(attemptedBefore) {
+			delete(m.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func sortedKeys[T any](items map[uint]T) []uint {
+	keys := make([]uint, 0, len(items))
+	for id := range items {
+		keys = append(keys, id)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// webhookReceiver é um endpoint local que verifica a assinatura das entregas e responde com os status
+// configurados, em ordem; depois do último, repete-o
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	invalid  []error
+	// onRequest, se definido, é chamado antes da resposta, simulando alterações concorrentes ao envio
+	onRequest func()
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(receiver.secret, r.Header.Get(webhooks.HeaderSignature), body, time.Now(), 0); err != nil {
+			receiver.invalid = append(receiver.invalid, err)
+		}
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		if receiver.onRequest != nil {
+			receiver.onRequest()
+		}
+
+		status := receiver.statuses[0]
+		if len(receiver.statuses) > 1 {
+			receiver.statuses = receiver.statuses[1:]
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("receiver says " + http.StatusText(status)))
+	}))
+	t.Cleanup(receiver.Close)
+
+	return receiver
+}
+
+func (r *webhookReceiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.requests)
+}
+
+type webhookTest struct {
+	service *WebhookServiceRepo
+	repo    *memoryWebhookRepository
+	now     time.Time
+}
+
+func newWebhookTest() *webhookTest {
+	test := &webhookTest{repo: newMemoryWebhookRepository(), now: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+	test.service = NewWebhookService(test.repo, WebhookConfig{Timeout: time.Second, MaxAttempts: 3, DisableAfter: 5})
+	test.service.now = func() time.Time { return test.now }
+
+	return test
+}
+
+// subscribe cadastra um webhook apontando para o receptor, que passa a conhecer o segredo
+func (test *webhookTest) subscribe(t *testing.T, receiver *webhookReceiver, eventTypes ...string) *models.IssuedWebhook {
+	issued, err := test.service.CreateWebhook(context.Background(), &models.Webhook{URL: receiver.URL, EventTypes: eventTypes})
+	require.NoError(t, err)
+	receiver.secret = issued.Secret
+
+	return issued
+}
+
+func (test *webhookTest) publish(t *testing.T, id, eventType string) {
+	require.NoError(t, test.service.Enqueue(context.Background(), broker.Message{
+		ID:            id,
+		Type:          eventType,
+		SchemaVersion: 1,
+		Timestamp:     test.now,
+		Value:         []byte(`{"id":4,"name":"Caneta"}`),
+	}))
+}
+
+func (test *webhookTest) delivery(t *testing.T, webhookID uint) models.WebhookDelivery {
+	deliveries, err := test.repo.FindWebhookDeliveries(context.Background(), repositories.WebhookDeliveryFilter{WebhookID: webhookID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	return deliveries[0]
+}
+
+func TestServiceCreateWebhook(t *testing.T) {
+	test := newWebhookTest()
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []auth.Role{auth.RoleAdmin}})
+	issued, err := test.service.CreateWebhook(ctx, &models.Webhook{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []string{events.ProductCreated},
+		Secret:     "ignored",
+		Active:     false,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "root", issued.CreatedBy)
+	assert.True(t, issued.Active)
+	assert.True(t, strings.HasPrefix(issued.Secret, "whsec_"))
+	assert.Equal(t, issued.Secret, test.repo.webhooks[issued.ID].Secret)
+
+	// O segredo não é serializado no cadastro, apenas na resposta da criação
+	body, _ := json.Marshal(issued.Webhook)
+	assert.NotContains(t, string(body), issued.Secret)
+}
+
+func TestServiceCreateWebhookValidation(t *testing.T) {
+	test := newWebhookTest()
+
+	_, err := test.service.CreateWebhook(context.Background(), &models.Webhook{
+		URL:        "ftp://partner.example.com",
+		EventTypes: []string{events.ProductCreated, events.OrderPlaced},
+	})
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Equal(t, []FieldError{
+		{Field: "url", Code: "http_url", Message: "url must be an HTTP or HTTPS URL"},
+		{Field: "event_types", Code: "event_type", Param: events.OrderPlaced, Message: "event_types contains an unknown event type: OrderPlaced"},
+	}, domainErr.Fields)
+	assert.Empty(t, test.repo.webhooks)
+}
+
+func TestServiceWebhookEnqueueFiltersEventTypes(t *testing.T) {
+	test := newWebhookTest()
+	all := test.subscribe(t, newWebhookReceiver(t, http.StatusOK))
+	stock := test.subscribe(t, newWebhookReceiver(t, http.StatusOK), events.StockChanged)
+	inactive := test.subscribe(t, newWebhookReceiver(t, http.StatusOK))
+	test.repo.webhooks[inactive.ID].Active = false
+
+	test.publish(t, "e1", events.ProductCreated)
+	test.publish(t, "e2", events.StockChanged)
+	// A outbox pode publicar o mesmo evento de novo
+	test.publish(t, "e2", events.StockChanged)
+
+	count := map[uint]int{}
+	for _, delivery := range test.repo.deliveries {
+		count[delivery.WebhookID]++
+	}
+	assert.Equal(t, map[uint]int{all.ID: 2, stock.ID: 1}, count)
+}
+
+func TestServiceWebhookDeliverSignsRequests(t *testing.T) {
+	test := newWebhookTest()
+	receiver := newWebhookReceiver(t, http.StatusNoContent)
+	webhook := test.subscribe(t, receiver)
+	test.publish(t, "e1", events.ProductCreated)
+
+	delivered, err := test.service.Deliver(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Equal(t, 1, receiver.received())
+	assert.Empty(t, receiver.invalid)
+
+	request := receiver.requests[0]
+	assert.Equal(t, "e1", request.Header.Get(webhooks.HeaderEventID))
+	assert.Equal(t, events.ProductCreated, request.Header.Get(webhooks.HeaderEventType))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+	var envelope map[string]interface{}
+	require.NoError(t, json.Unmarshal(receiver.bodies[0], &envelope))
+	assert.Equal(t, "e1", envelope["id"])
+	assert.Equal(t, events.ProductCreated, envelope["type"])
+	assert.Equal(t, map[string]interface{}{"id": float64(4), "name": "Caneta"}, envelope["data"])
+
+	delivery := test.delivery(t, webhook.ID)
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.NotNil(t, delivery.DeliveredAt)
+	// Sem falhas anteriores, o estado do webhook não muda e não é gravado
+	assert.Equal(t, 0, test.repo.healthUpdates)
+
+	// Entregas concluídas não são enviadas de novo
+	delivered, err = test.service.Deliver(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, receiver.received())
+}
+
+func TestServiceWebhookDeliverRetriesWithBackoff(t *testing.T) {
+	test := newWebhookTest()
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusOK)
+	webhook := test.subscribe(t, receiver)
+	test.publish(t, "e1", events.ProductCreated)
+	test.publish(t, "e2", events.ProductUpdated)
+
+	delivered, err := test.service.Deliver(context.Background())
+
+	// A falha bloqueia as entregas seguintes do webhook, preservando a ordem dos eventos
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, receiver.received())
+	failed := test.repo.deliveries[sortedKeys(test.repo.deliveries)[0]]
+	assert.Equal(t, models.DeliveryPending, failed.Status)
+	assert.Equal(t, http.StatusInternalServerError, failed.ResponseStatus)
+	assert.Contains(t, failed.LastError, "receiver says Internal Server Error")
+	assert.Equal(t, test.now.Add(retryBackoff(1, webhookRetryBase, webhookRetryMax)), failed.NextAttemptAt)
+	assert.Equal(t, 1, test.repo.webhooks[webhook.ID].ConsecutiveFailures)
+
+	// Antes do backoff nada é enviado
+	delivered, _ = test.service.Deliver(context.Background())
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, receiver.received())
+
+	test.now = failed.NextAttemptAt
+	delivered, err = test.service.Deliver(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []string{"e1", "e1", "e2"}, []string{
+		receiver.requests[0].Header.Get(webhooks.HeaderEventID),
+		receiver.requests[1].Header.Get(webhooks.HeaderEventID),
+		receiver.requests[2].Header.Get(webhooks.HeaderEventID),
+	})
+	assert.Empty(t, receiver.invalid)
+	assert.Equal(t, 0, test.repo.webhooks[webhook.ID].ConsecutiveFailures)
+}
+
+func TestServiceWebhookDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	test := newWebhookTest()
+	receiver := newWebhookReceiver(t, http.StatusBadGateway)
+	webhook := test.subscribe(t, receiver)
+	test.publish(t, "e1", events.ProductCreated)
+
+	for i := 0; i < 3; i++ {
+		_, err := test.service.Deliver(context.Background())
+		assert.NoError(t, err)
+		test.now = test.now.Add(webhookRetryMax)
+	}
+	_, err := test.service.Deliver(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, receiver.received())
+	delivery := test.delivery(t, webhook.ID)
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.True(t, test.repo.webhooks[webhook.ID].Active)
+}
+
+func TestServiceWebhookDisabledAfterConsecutiveFailures(t *testing.T) {
+	test := newWebhookTest()
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	webhook := test.subscribe(t, receiver)
+	for _, id := range []string{"e1", "e2", "e3"} {
+		test.publish(t, id, events.ProductCreated)
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err := test.service.Deliver(context.Background())
+		assert.NoError(t, err)
+		test.now = test.now.Add(webhookRetryMax)
+	}
+
+	// Cada rodada faz no máximo uma tentativa com falha por webhook: e1 esgota as tentativas e e2 falha
+	// mais duas vezes, e a quinta falha seguida, na quinta rodada, desativa o webhook
+	assert.Equal(t, 5, receiver.received())
+	stored := test.repo.webhooks[webhook.ID]
+	assert.False(t, stored.Active)
+	assert.Equal(t, time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC), *stored.DisabledAt)
+	assert.Contains(t, stored.DisabledReason, "5 consecutive failed deliveries")
+
+	// Webhooks desativados não recebem novos eventos nem aceitam reenvios
+	test.publish(t, "e4", events.ProductCreated)
+	assert.Len(t, test.repo.deliveries, 3)
+	_, err := test.service.ReplayWebhookDelivery(context.Background(), webhook.ID, test.delivery(t, webhook.ID).ID)
+	domainErr, _ := AsDomainError(err)
+	assert.Equal(t, CodeWebhookDisabled, domainErr.Code)
+
+	// Reativar zera as falhas e retoma as entregas pendentes
+	receiver.statuses = []int{http.StatusOK}
+	update := &models.Webhook{ID: webhook.ID, URL: receiver.URL, Active: true}
+	assert.NoError(t, test.service.UpdateWebhook(context.Background(), update))
+	assert.Equal(t, 0, update.ConsecutiveFailures)
+	assert.Nil(t, update.DisabledAt)
+
+	delivered, err := test.service.Deliver(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+}
+
+func TestServiceWebhookDeliverKeepsConcurrentChanges(t *testing.T) {
+	test := newWebhookTest()
+	rotatedReceiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	rotated := test.subscribe(t, rotatedReceiver)
+	deletedReceiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	deleted := test.subscribe(t, deletedReceiver)
+	test.publish(t, "e1", events.ProductCreated)
+
+	// O segredo é trocado e o outro webhook é removido enquanto as entregas estão em andamento
+	rotatedReceiver.onRequest = func() { test.repo.webhooks[rotated.ID].Secret = "whsec_rotated" }
+	deletedReceiver.onRequest = func() { require.NoError(t, test.repo.DeleteWebhook(context.Background(), deleted.ID)) }
+
+	delivered, err := test.service.Deliver(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, "whsec_rotated", test.repo.webhooks[rotated.ID].Secret)
+	assert.Equal(t, 1, test.repo.webhooks[rotated.ID].ConsecutiveFailures)
+	assert.Equal(t, 1, test.delivery(t, rotated.ID).Attempts)
+	// O webhook removido e as suas entregas não são recriados
+	assert.NotContains(t, test.repo.webhooks, deleted.ID)
+	for _, delivery := range test.repo.deliveries {
+		assert.NotEqual(t, deleted.ID, delivery.WebhookID)
+	}
+}
+
+func TestServiceReplayWebhookDelivery(t *testing.T) {
+	test := newWebhookTest()
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	webhook := test.subscribe(t, receiver)
+	test.publish(t, "e1", events.ProductCreated)
+	_, err := test.service.Deliver(context.Background())
+	require.NoError(t, err)
+
+	// O segredo trocado vale também para os reenvios
+	rotated, err := test.service.RotateWebhookSecret(context.Background(), webhook.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, webhook.Secret, rotated.Secret)
+	receiver.secret = rotated.Secret
+
+	test.now = test.now.Add(time.Hour)
+	replayed, err := test.service.ReplayWebhookDelivery(context.Background(), webhook.ID, test.delivery(t, webhook.ID).ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+	assert.Equal(t, test.now, *replayed.ReplayedAt)
+
+	delivered, err := test.service.Deliver(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 2, receiver.received())
+	assert.Equal(t, receiver.bodies[0], receiver.bodies[1])
+	assert.Empty(t, receiver.invalid)
+
+	_, err = test.service.ReplayWebhookDelivery(context.Background(), webhook.ID, 999)
+	domainErr, _ := AsDomainError(err)
+	assert.Equal(t, CodeDeliveryNotFound, domainErr.Code)
+}
+
+func TestServiceWebhookDeliveriesRetention(t *testing.T) {
+	test := newWebhookTest()
+	test.service.config.Retention = 24 * time.Hour
+	webhook := test.subscribe(t, newWebhookReceiver(t, http.StatusOK))
+	test.publish(t, "e1", events.ProductCreated)
+	_, err := test.service.Deliver(context.Background())
+	require.NoError(t, err)
+
+	test.now = test.now.Add(25 * time.Hour)
+	test.publish(t, "e2", events.ProductUpdated)
+	test.repo.deliveries[sortedKeys(test.repo.deliveries)[1]].NextAttemptAt = test.now.Add(time.Hour)
+	_, err = test.service.Deliver(context.Background())
+
+	assert.NoError(t, err)
+	deliveries, _ := test.service.GetWebhookDeliveries(context.Background(), repositories.WebhookDeliveryFilter{WebhookID: webhook.ID})
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "e2", deliveries[0].EventID)
+}
+
+func TestServiceGetWebhookDeliveriesValidation(t *testing.T) {
+	test := newWebhookTest()
+
+	_, err := test.service.GetWebhookDeliveries(context.Background(), repositories.WebhookDeliveryFilter{WebhookID: 1, Status: "lost", Limit: 5000})
+
+	domainErr, ok := AsDomainError(err)
+	assert.True(t, ok)
+	assert.Equal(t, KindValidation, domainErr.Kind)
+	assert.Equal(t, []string{"limit", "status"}, []string{domainErr.Fields[0].Field, domainErr.Fields[1].Field})
+
+	_, err = test.service.GetWebhookDeliveries(context.Background(), repositories.WebhookDeliveryFilter{WebhookID: 1})
+	domainErr, _ = AsDomainError(err)
+	assert.Equal(t, CodeWebhookNotFound, domainErr.Code)
+}
+
+func TestTranslateWebhookError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{repositories.ErrNotFound, CodeWebhookNotFound},
+		{repositories.ErrDuplicated, CodeWebhookConflict},
+		{errors.New("database is locked"), CodeWebhookStorageUnavailable},
+	}
+
+	for _, tt := range tests {
+		domainErr, ok := AsDomainError(translateWebhookError(tt.err))
+		assert.True(t, ok)
+		assert.Equal(t, tt.code, domainErr.Code)
+	}
+	assert.ErrorIs(t, translateWebhookError(context.DeadlineExceeded), context.DeadlineExceeded)
+	assert.NoError(t, translateWebhookError(nil))
+}
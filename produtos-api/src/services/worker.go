@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"produtos-api/src/broker"
+	"produtos-api/src/logging"
+)
+
+var workerLogger = logging.Logger("workers")
+
+// RunPeriodic executa fn imediatamente e depois a cada interval, até o contexto ser cancelado.
+// O resultado de cada execução é reportado no estado do worker verificado pela prontidão.
+func RunPeriodic(ctx context.Context, name string, state *WorkerState, interval time.Duration, fn func(ctx context.Context) error) {
+	state.Start()
+	defer state.Stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := fn(ctx)
+		state.Report(err)
+		if err != nil && ctx.Err() == nil {
+			workerLogger.ErrorContext(ctx, "worker run failed", "worker", name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunConsumer entrega as mensagens do consumer ao handler até o cancelamento de ctx. O resultado de cada
+// mensagem é reportado no estado do worker: enquanto uma mensagem falha e é reprocessada, a prontidão falha.
+func RunConsumer(ctx context.Context, name string, state *WorkerState, consumer broker.Consumer, handler broker.Handler) {
+	state.Start()
+	defer state.Stop()
+
+	err := consumer.Consume(ctx, func(ctx context.Context, msg broker.Message) error {
+		err := handler(ctx, msg)
+		state.Report(err)
+		return err
+	})
+	if err != nil && ctx.Err() == nil {
+		workerLogger.ErrorContext(ctx, "consumer stopped", "worker", name, "error", err)
+	}
+}
+
+// retryBackoff retorna o intervalo antes da próxima tentativa: base na primeira falha, dobrando a cada
+// falha seguinte, até ceiling
+func retryBackoff(attempts int, base, ceiling time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+
+	return min(delay, ceiling)
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// spanRecorder recebe os spans do pacote; o provider global só pode ser definido uma vez por processo
+var spanRecorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	os.Exit(m.Run())
+}
+
+// spansOf retorna os spans finalizados do trace
+func spansOf(traceID trace.TraceID) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range spanRecorder.Ended() {
+		if span.SpanContext().TraceID() == traceID {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&widget{}))
+	require.NoError(t, db.Use(NewGormPlugin()))
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "request")
+	tx := db.WithContext(ctx)
+	require.NoError(t, tx.Create(&widget{Name: "a"}).Error)
+	var found widget
+	assert.ErrorIs(t, tx.First(&found, 99).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, tx.Table("missing").Find(&[]widget{}).Error)
+	root.End()
+
+	spans := spansOf(root.SpanContext().TraceID())
+	require.Len(t, spans, 4)
+
+	// As consultas são filhas do span da requisição, com a operação, a tabela e o SQL executado
+	create := spans[0]
+	assert.Equal(t, "gorm.create", create.Name())
+	assert.Equal(t, trace.SpanKindClient, create.SpanKind())
+	assert.Equal(t, root.SpanContext().SpanID(), create.Parent().SpanID())
+	assert.Equal(t, "widgets", attributeOf(create, "db.collection.name"))
+	assert.Contains(t, attributeOf(create, "db.query.text"), "INSERT INTO `widgets`")
+	assert.Equal(t, "1", attributeOf(create, "db.rows_affected"))
+
+	// Registro não encontrado não é erro; tabela inexistente é
+	assert.Equal(t, "gorm.query", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	assert.Equal(t, "missing", attributeOf(spans[2], "db.collection.name"))
+}
+
+func TestSetupExporters(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ServiceName)
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "jaeger")
+	_, err = Setup(context.Background(), ServiceName)
+	assert.ErrorContains(t, err, "jaeger")
+
+	t.Setenv("OTEL_TRACES_FILE", filepath.Join(t.TempDir(), "traces.json"))
+	exporter, closer, err := newExporter(context.Background(), "file")
+	require.NoError(t, err)
+	assert.NotNil(t, exporter)
+	assert.NoError(t, closer.Close())
+	_, err = os.Stat(os.Getenv("OTEL_TRACES_FILE"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"produtos-api/src/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError descreve uma violação de uma regra declarada na tag `validate` de um campo
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// instance cria o validador uma única vez, registrando as regras customizadas da aplicação
+func instance() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+
+		// Os campos são reportados pelo nome usado no JSON
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		validate.RegisterValidation("notblank", notBlank)
+		validate.RegisterValidation("precision", precision)
+		validate.RegisterValidation("cpf_cnpj", cpfCNPJ)
+		validate.RegisterValidation("uf", uf)
+		validate.RegisterValidation("cep", cep)
+	})
+
+	return validate
+}
+
+// Struct valida a struct conforme as tags `validate` e retorna todas as violações encontradas
+func Struct(value interface{}) []FieldError {
+	err := instance().Struct(value)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldError{{Rule: "invalid", Message: err.Error()}}
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe),
+		})
+	}
+
+	return fieldErrors
+}
+
+// notBlank rejeita strings compostas apenas por espaços
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// precision limita a quantidade de casas decimais de um número, ex: `precision=2` para valores monetários
+func precision(fl validator.FieldLevel) bool {
+	digits, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	scaled := fl.Field().Float() * math.Pow10(digits)
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
+}
+
+// Digits remove a pontuação usual de documentos e CEPs ("123.456.789-09", "01310-100"); outros caracteres são
+// mantidos para que a validação os rejeite
+func Digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '/', ' ':
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// cpfCNPJ aceita um CPF (11 dígitos) ou CNPJ (14 dígitos) com os dígitos verificadores corretos
+func cpfCNPJ(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	switch len(value) {
+	case 11:
+		return checkDigits(value, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+	case 14:
+		return checkDigits(value, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	default:
+		return false
+	}
+}
+
+// checkDigits confere os dois dígitos verificadores (módulo 11) com os pesos de cada um. Sequências
+// de um único dígito, como 111.111.111-11, passam no cálculo mas não são documentos válidos.
+func checkDigits(value string, firstWeights, secondWeights []int) bool {
+	if strings.Count(value, value[:1]) == len(value) {
+		return false
+	}
+
+	digits := make([]int, len(value))
+	for i, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digits[i] = int(r - '0')
+	}
+
+	for _, weights := range [][]int{firstWeights, secondWeights} {
+		sum := 0
+		for i, weight := range weights {
+			sum += digits[i] * weight
+		}
+
+		check := 11 - sum%11
+		if check >= 10 {
+			check = 0
+		}
+		if digits[len(weights)] != check {
+			return false
+		}
+	}
+
+	return true
+}
+
+// states são as siglas das unidades federativas
+var states = []string{"AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA", "PB",
+	"PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO"}
+
+// uf aceita a sigla de uma unidade federativa, ex: SP
+func uf(fl validator.FieldLevel) bool {
+	return slices.Contains(states, fl.Field().String())
+}
+
+// cep aceita um CEP de 8 dígitos, sem pontuação
+func cep(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if len(value) != 8 {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// message usa o catálogo do locale padrão; os controllers traduzem a mensagem para o idioma do cliente
+func message(fe validator.FieldError) string {
+	return i18n.T(i18n.DefaultLocale, "validation."+fe.Tag(), map[string]string{
+		"field": fe.Field(),
+		"param": fe.Param(),
+	})
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rules reporta as regras violadas pela struct, na ordem dos campos
+func rules(value interface{}) []string {
+	var violated []string
+	for _, fe := range Struct(value) {
+		violated = append(violated, fe.Rule)
+	}
+	return violated
+}
+
+func TestNotBlankAndLength(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" validate:"required,notblank,min=2,max=120"`
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"valid", "Caneta", nil},
+		{"empty", "", []string{"required"}},
+		{"only spaces", "   ", []string{"notblank"}},
+		{"too short", "C", []string{"min"}},
+		{"max length", strings.Repeat("a", 120), nil},
+		{"too long", strings.Repeat("a", 121), []string{"max"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(payload{Name: tt.value}))
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	type payload struct {
+		Price float64 `json:"price" validate:"precision=2"`
+	}
+
+	tests := []struct {
+		name  string
+		value float64
+		want  []string
+	}{
+		{"integer", 10, nil},
+		{"two decimals", 19.99, nil},
+		{"binary rounding", 0.1 + 0.2, nil},
+		{"three decimals", 19.999, []string{"precision"}},
+		{"negative three decimals", -0.001, []string{"precision"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(payload{Price: tt.value}))
+		})
+	}
+}
+
+func TestCPFCNPJ(t *testing.T) {
+	type payload struct {
+		Document string `json:"document" validate:"cpf_cnpj"`
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"cpf", "52998224725", true},
+		{"cpf with punctuation removed", Digits("529.982.247-25"), true},
+		{"cpf wrong first digit", "52998224715", false},
+		{"cpf wrong second digit", "52998224724", false},
+		{"cpf repeated digits", "11111111111", false},
+		{"cnpj", "11222333000181", true},
+		{"cnpj with punctuation removed", Digits("11.222.333/0001-81"), true},
+		{"cnpj wrong digit", "11222333000182", false},
+		{"cnpj repeated digits", "00000000000000", false},
+		{"letters", "5299822472a", false},
+		{"wrong length", "1234567890", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, len(Struct(payload{Document: tt.value})) == 0)
+		})
+	}
+}
+
+func TestUFAndCEP(t *testing.T) {
+	type payload struct {
+		State      string `json:"state" validate:"uf"`
+		PostalCode string `json:"postal_code" validate:"cep"`
+	}
+
+	tests := []struct {
+		name  string
+		value payload
+		want  []string
+	}{
+		{"valid", payload{State: "SP", PostalCode: "01310100"}, nil},
+		{"cep with punctuation removed", payload{State: "DF", PostalCode: Digits("70040-010")}, nil},
+		{"lowercase uf", payload{State: "sp", PostalCode: "01310100"}, []string{"uf"}},
+		{"unknown uf", payload{State: "XX", PostalCode: "01310100"}, []string{"uf"}},
+		{"cep with dash", payload{State: "SP", PostalCode: "01310-100"}, []string{"cep"}},
+		{"short cep", payload{State: "SP", PostalCode: "0131010"}, []string{"cep"}},
+		{"cep with letters", payload{State: "SP", PostalCode: "0131010a"}, []string{"cep"}},
+		{"both invalid", payload{State: "", PostalCode: ""}, []string{"uf", "cep"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules(tt.value))
+		})
+	}
+}
+
+func TestDigits(t *testing.T) {
+	assert.Equal(t, "52998224725", Digits("529.982.247-25"))
+	assert.Equal(t, "11222333000181", Digits("11.222.333/0001-81"))
+	assert.Equal(t, "01310100", Digits("01310 100"))
+	assert.Equal(t, "12a4", Digits("12a4"))
+}
+
+func TestStructReportsFieldAndMessage(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" validate:"notblank"`
+	}
+
+	errs := Struct(payload{Name: " "})
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "name", errs[0].Field)
+		assert.Equal(t, "notblank", errs[0].Rule)
+		assert.NotEmpty(t, errs[0].Message)
+	}
+}
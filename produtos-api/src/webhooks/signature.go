@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers das requisições de entrega dos webhooks
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEventID   = "X-Webhook-Event-ID"
+	HeaderEventType = "X-Webhook-Event-Type"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Os segredos têm o formato whsec_<segredo>. Diferente das chaves de API, o segredo é armazenado
+// em texto puro, já que a API precisa dele para assinar as entregas.
+const (
+	secretTag   = "whsec_"
+	secretBytes = 32
+)
+
+var (
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	ErrSignatureMismatch  = errors.New("webhook signature mismatch")
+	ErrSignatureExpired   = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// GenerateSecret cria um novo segredo de assinatura
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return secretTag + hex.EncodeToString(secret), nil
+}
+
+// Sign calcula o header de assinatura no formato t=<unix>,v1=<hex>, em que v1 é o HMAC-SHA256
+// de "<unix>.<corpo>" com o segredo. O instante assinado permite ao receptor rejeitar reenvios antigos.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac(secret, unix, body))
+}
+
+// Verify confere o header de assinatura de uma entrega; tolerance limita a idade do instante assinado,
+// e zero não a verifica
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrMalformedSignature
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrMalformedSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(seconds, 0)).Abs() > tolerance {
+		return ErrSignatureExpired
+	}
+
+	// Durante a troca do segredo o receptor pode receber mais de uma assinatura v1
+	expected := mac(secret, unix, body)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+func mac(secret, unix string, body []byte) []byte {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(unix))
+	hash.Write([]byte("."))
+	hash.Write(body)
+
+	return hash.Sum(nil)
+}
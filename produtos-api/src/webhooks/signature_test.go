@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	signedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"e1"}`)
+	header := Sign(secret, signedAt, body)
+	assert.True(t, strings.HasPrefix(header, "t=1792411200,v1="))
+
+	assert.NoError(t, Verify(secret, header, body, signedAt.Add(4*time.Minute), 5*time.Minute))
+	// Relógios adiantados também são tolerados
+	assert.NoError(t, Verify(secret, header, body, signedAt.Add(-4*time.Minute), 5*time.Minute))
+	// Durante a troca do segredo, basta uma das assinaturas v1 conferir
+	rotated := Sign("whsec_old", signedAt, body) + "," + strings.Split(header, ",")[1]
+	assert.NoError(t, Verify(secret, rotated, body, signedAt, 5*time.Minute))
+}
+
+func TestVerifyExpired(t *testing.T) {
+	signedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	header := Sign("whsec_secret", signedAt, []byte("{}"))
+
+	assert.ErrorIs(t, Verify("whsec_secret", header, []byte("{}"), signedAt.Add(6*time.Minute), 5*time.Minute), ErrSignatureExpired)
+	assert.ErrorIs(t, Verify("whsec_secret", header, []byte("{}"), signedAt.Add(-6*time.Minute), 5*time.Minute), ErrSignatureExpired)
+	// Sem tolerância, a idade não é verificada
+	assert.NoError(t, Verify("whsec_secret", header, []byte("{}"), signedAt.Add(24*time.Hour), 0))
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	signedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	header := Sign("whsec_secret", signedAt, []byte(`{"id":"e1"}`))
+
+	assert.ErrorIs(t, Verify("whsec_other", header, []byte(`{"id":"e1"}`), signedAt, time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, Verify("whsec_secret", header, []byte(`{"id":"e2"}`), signedAt, time.Minute), ErrSignatureMismatch)
+	// O instante faz parte da assinatura
+	tampered := strings.Replace(header, "t=1792411200", "t=1792411260", 1)
+	assert.ErrorIs(t, Verify("whsec_secret", tampered, []byte(`{"id":"e1"}`), signedAt, time.Minute), ErrSignatureMismatch)
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	valid := Sign("whsec_secret", now, []byte("{}"))
+	signature := strings.Split(valid, ",")[1]
+
+	for _, header := range []string{
+		"",
+		"t=1792411200",            // sem assinatura
+		signature,                 // sem instante
+		"t=abc," + signature,      // instante inválido
+		"t=1792411200,v1=zz",      // assinatura fora de hex
+		"v1=" + valid + ",t=1792", // assinatura com o header inteiro
+	} {
+		assert.ErrorIs(t, Verify("whsec_secret", header, []byte("{}"), now, time.Minute), ErrMalformedSignature, header)
+	}
+}